@@ -0,0 +1,146 @@
+package database
+
+import (
+	"institution-service/model"
+
+	"gorm.io/gorm"
+)
+
+// BackfillMoney fills the _minor and _currency columns of posts and fund
+// collects created before amounts were stored in minor units, from the float
+// columns they replace. Those rows were all in IDR. It only touches rows whose
+// _minor column is still NULL, so it is safe to run after every AutoMigrate.
+func BackfillMoney(db *gorm.DB) error {
+	statements := []string{
+		`UPDATE posts SET fund_target_minor = ROUND(fund_target * 100), fund_target_currency = 'IDR'
+			WHERE fund_target_minor IS NULL`,
+		`UPDATE posts SET fund_achieved_minor = ROUND(COALESCE(fund_achieved, 0) * 100), fund_achieved_currency = 'IDR'
+			WHERE fund_achieved_minor IS NULL`,
+		`UPDATE fund_collects SET amount_minor = ROUND(amount * 100), amount_currency = 'IDR'
+			WHERE amount_minor IS NULL`,
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, statement := range statements {
+			if err := tx.Exec(statement).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// DedupeFundCollects deletes the fund collects a redelivered donation wrote
+// twice before fund collects were unique per transaction, keeping the earliest
+// of each, and recomputes fund_achieved of the posts they inflated from the
+// rows that remain. It must run before AutoMigrate creates the unique index on
+// transaction_id, which fails while duplicates exist. It returns the number of
+// rows deleted.
+func DedupeFundCollects(db *gorm.DB) (int64, error) {
+	migrator := db.Migrator()
+	if !migrator.HasTable("fund_collects") {
+		return 0, nil
+	}
+
+	var deleted int64
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var postIDs []string
+		err := tx.Raw(`SELECT DISTINCT post_id FROM fund_collects WHERE transaction_id IN
+			(SELECT transaction_id FROM fund_collects GROUP BY transaction_id HAVING COUNT(*) > 1)`).
+			Scan(&postIDs).Error
+		if err != nil {
+			return err
+		}
+		if len(postIDs) == 0 {
+			return nil
+		}
+
+		result := tx.Exec(`DELETE FROM fund_collects duplicate USING fund_collects earliest
+			WHERE duplicate.transaction_id = earliest.transaction_id
+			AND (earliest.created_at, earliest.fund_collect_id) < (duplicate.created_at, duplicate.fund_collect_id)`)
+		if result.Error != nil {
+			return result.Error
+		}
+		deleted = result.RowsAffected
+
+		err = tx.Exec(`UPDATE posts SET fund_achieved = COALESCE((SELECT SUM(amount) FROM fund_collects
+			WHERE fund_collects.post_id = posts.post_id AND fund_collects.deleted_at IS NULL), 0)
+			WHERE post_id IN ?`, postIDs).Error
+		if err != nil {
+			return err
+		}
+
+		// Databases migrated past minor units keep the total there too. Elsewhere
+		// BackfillMoney derives it from fund_achieved.
+		if !migrator.HasColumn("posts", "fund_achieved_minor") || !migrator.HasColumn("fund_collects", "amount_minor") {
+			return nil
+		}
+		return tx.Exec(`UPDATE posts SET fund_achieved_minor = COALESCE((SELECT SUM(amount_minor) FROM fund_collects
+			WHERE fund_collects.post_id = posts.post_id AND fund_collects.deleted_at IS NULL), 0)
+			WHERE post_id IN ?`, postIDs).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return deleted, nil
+}
+
+// AnonymizeDonorEmails renames fund collects recorded under the donor's email
+// address, from before donors chose how to appear, to Anonymous.
+func AnonymizeDonorEmails(db *gorm.DB) error {
+	return db.Exec(`UPDATE fund_collects SET user_name = ? WHERE user_name LIKE ?`,
+		model.AnonymousDonorName, "%@%").Error
+}
+
+// AddPostSearchVector adds the generated tsvector column over post titles and
+// bodies that the public listing searches, and its GIN index. The simple
+// configuration is used since Postgres has no Indonesian dictionary.
+func AddPostSearchVector(db *gorm.DB) error {
+	statements := []string{
+		`ALTER TABLE posts ADD COLUMN IF NOT EXISTS search_vector tsvector
+			GENERATED ALWAYS AS (to_tsvector('simple', coalesce(title, '') || ' ' || coalesce(body, ''))) STORED`,
+		`CREATE INDEX IF NOT EXISTS idx_posts_search_vector ON posts USING GIN (search_vector)`,
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, statement := range statements {
+			if err := tx.Exec(statement).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// defaultCategories are created on the first start so browse pages are not
+// empty before an admin sets categories up.
+var defaultCategories = []model.Category{
+	{Slug: "scholarships", Name: "Scholarships", Description: "Tuition and living costs of students"},
+	{Slug: "school-infrastructure", Name: "School Infrastructure", Description: "Classrooms, sanitation and school buildings"},
+	{Slug: "books", Name: "Books", Description: "Textbooks, libraries and reading materials"},
+	{Slug: "teacher-training", Name: "Teacher Training", Description: "Courses and certification of teachers"},
+}
+
+// SeedCategories creates the default categories while there are none, so
+// categories an admin deleted do not come back.
+func SeedCategories(db *gorm.DB) error {
+	var count int64
+	if err := db.Model(&model.Category{}).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+
+	categories := make([]model.Category, len(defaultCategories))
+	copy(categories, defaultCategories)
+
+	return db.Create(&categories).Error
+}
+
+// BackfillPostStatus publishes the posts created before posts had a status.
+// They were all live, so they stay listed.
+func BackfillPostStatus(db *gorm.DB) error {
+	return db.Exec(`UPDATE posts SET status = ? WHERE status IS NULL OR status = ''`, model.PostStatusPublished).Error
+}
@@ -0,0 +1,29 @@
+package database
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/rabbitmq/amqp091-go"
+)
+
+// InitRabbitMQ connects to the broker transaction-service publishes donation
+// events to. It returns nil without error when MQHOST is not set, so the
+// service can run locally without RabbitMQ.
+func InitRabbitMQ() (*amqp091.Connection, error) {
+	host := os.Getenv("MQHOST")
+	if host == "" {
+		return nil, nil
+	}
+
+	conStr := fmt.Sprintf("amqp://%s:%s@%s:%s/%s",
+		os.Getenv("MQUSER"), os.Getenv("MQPASS"), host, os.Getenv("MQPORT"), os.Getenv("MQVHOST"),
+	)
+
+	conn, err := amqp091.Dial(conStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to RabbitMQ: %v", err)
+	}
+
+	return conn, nil
+}
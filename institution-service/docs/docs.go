@@ -23,14 +23,14 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/v1/fund-collect/post/{id}": {
-            "get": {
+        "/v1/admin/categories": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get a specific funding collection by Post ID with authorization.",
+                "description": "Create a category posts can be filed under. Needs the token of a user listed in ADMIN_EMAILS.",
                 "consumes": [
                     "application/json"
                 ],
@@ -38,9 +38,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "FundCollect"
+                    "Category"
                 ],
-                "summary": "Get funding collection by Post ID.",
+                "summary": "Create a new Category.",
                 "parameters": [
                     {
                         "type": "string",
@@ -50,28 +50,36 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "type": "string",
-                        "description": "Post ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Category details",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.CategoryRequest"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Success get funding collection data",
+                    "201": {
+                        "description": "Category created successfully",
                         "schema": {
-                            "$ref": "#/definitions/model.FundCollectResponse"
+                            "$ref": "#/definitions/model.CategoryResponse"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "400": {
+                        "description": "Invalid category",
                         "schema": {
                             "$ref": "#/definitions/httputil.HTTPError"
                         }
                     },
-                    "404": {
-                        "description": "Funding collection not found",
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Slug already taken",
                         "schema": {
                             "$ref": "#/definitions/httputil.HTTPError"
                         }
@@ -79,14 +87,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/v1/institution": {
-            "get": {
+        "/v1/admin/categories/{id}": {
+            "put": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get data institution with authorization.",
+                "description": "Update the slug, name or description of a category; empty fields are kept. Needs the token of a user listed in ADMIN_EMAILS.",
                 "consumes": [
                     "application/json"
                 ],
@@ -94,9 +102,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Institution"
+                    "Category"
                 ],
-                "summary": "Get Institution.",
+                "summary": "Update Category.",
                 "parameters": [
                     {
                         "type": "string",
@@ -104,33 +112,64 @@ const docTemplate = `{
                         "name": "Authorization",
                         "in": "header",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Category ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Updated category details",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.CategoryRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Success get institution data",
+                        "description": "Success update category data",
                         "schema": {
-                            "$ref": "#/definitions/model.InstitutionResponse"
+                            "$ref": "#/definitions/model.CategoryResponse"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "400": {
+                        "description": "Invalid category",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/httputil.HTTPError"
                         }
                     },
                     "404": {
-                        "description": "Data not found",
+                        "description": "Category not found",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Slug already taken",
                         "schema": {
                             "$ref": "#/definitions/httputil.HTTPError"
                         }
                     }
                 }
-            }
-        },
-        "/v1/institution/login": {
-            "post": {
-                "description": "Login Institution with email and password.",
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Delete a category. Its posts stay listed without a category. Needs the token of a user listed in ADMIN_EMAILS.",
                 "consumes": [
                     "application/json"
                 ],
@@ -138,29 +177,40 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Institution"
+                    "Category"
                 ],
-                "summary": "Login Institution.",
+                "summary": "Delete Category.",
                 "parameters": [
                     {
-                        "description": "Institution login",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/model.InstitutionLoginRequest"
-                        }
+                        "type": "string",
+                        "description": "Bearer token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Category ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Institution login successfully",
+                        "description": "Success delete category data",
                         "schema": {
-                            "$ref": "#/definitions/model.InstitutionToken"
+                            "$ref": "#/definitions/model.CategoryDeleteResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal server error",
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Category not found",
                         "schema": {
                             "$ref": "#/definitions/httputil.HTTPError"
                         }
@@ -168,9 +218,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/v1/institution/register": {
-            "post": {
-                "description": "Register institution with name, email, etc. Email must be unique and password will be hashed before saved to database.",
+        "/v1/admin/moderation/posts": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the submitted posts awaiting review, longest waiting first. Needs the token of a user listed in MODERATOR_EMAILS or ADMIN_EMAILS.",
                 "consumes": [
                     "application/json"
                 ],
@@ -178,29 +233,27 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Institution"
+                    "Moderation"
                 ],
-                "summary": "Register a new Institution.",
+                "summary": "Get the moderation queue.",
                 "parameters": [
                     {
-                        "description": "Institution created details",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/model.InstitutionRequest"
-                        }
+                        "type": "string",
+                        "description": "Bearer token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Institution created successfully",
+                        "description": "Success get moderation queue",
                         "schema": {
-                            "$ref": "#/definitions/model.InstitutionResponse"
+                            "$ref": "#/definitions/model.ModerationQueueResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal server error",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/httputil.HTTPError"
                         }
@@ -208,14 +261,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/v1/institution/{id}": {
-            "put": {
+        "/v1/admin/moderation/posts/{id}": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Update an existing institution with authorization.",
+                "description": "Decide on a submitted post: APPROVE publishes it, REJECT rejects it for good and REQUEST_CHANGES sends it back to DRAFT for the institution to edit and resubmit. A reason is required unless the post is approved. The institution is emailed the decision and its reason, and the decision is kept in the moderation history of the post. Needs the token of a user listed in MODERATOR_EMAILS or ADMIN_EMAILS.",
                 "consumes": [
                     "application/json"
                 ],
@@ -223,9 +276,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Institution"
+                    "Moderation"
                 ],
-                "summary": "Update Institution.",
+                "summary": "Moderate a submitted Post.",
                 "parameters": [
                     {
                         "type": "string",
@@ -236,49 +289,63 @@ const docTemplate = `{
                     },
                     {
                         "type": "string",
-                        "description": "Institution ID",
+                        "description": "Post ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Updated institution data",
-                        "name": "user",
+                        "description": "Decision",
+                        "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/model.InstitutionRequest"
+                            "$ref": "#/definitions/model.ModerationRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Success update institution data",
+                        "description": "Success moderate post",
                         "schema": {
-                            "$ref": "#/definitions/model.InstitutionResponse"
+                            "$ref": "#/definitions/model.ModerationResponse"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "400": {
+                        "description": "Invalid decision",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/httputil.HTTPError"
                         }
                     },
                     "404": {
-                        "description": "User not found",
+                        "description": "Post not found",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Post is not awaiting moderation",
                         "schema": {
                             "$ref": "#/definitions/httputil.HTTPError"
                         }
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/v1/admin/verifications": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Delete an existing institution with authorization.",
+                "description": "List the institutions whose documents await review, longest waiting first. Needs the token of a user listed in ADMIN_EMAILS.",
                 "consumes": [
                     "application/json"
                 ],
@@ -286,9 +353,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Institution"
+                    "Verification"
                 ],
-                "summary": "Delete Institution.",
+                "summary": "Get pending verifications.",
                 "parameters": [
                     {
                         "type": "string",
@@ -296,24 +363,17 @@ const docTemplate = `{
                         "name": "Authorization",
                         "in": "header",
                         "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Institution ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Success delete institution data",
+                        "description": "Success get pending verifications",
                         "schema": {
-                            "$ref": "#/definitions/model.InstitutionDeleteResponse"
+                            "$ref": "#/definitions/model.VerificationListResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal server error",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/httputil.HTTPError"
                         }
@@ -321,14 +381,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/v1/post": {
+        "/v1/admin/verifications/{institution_id}": {
             "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Create post with title, body, etc.",
+                "description": "APPROVE verifies the institution, which lets its posts be published. REJECT, with a reason shown to the institution, lets it upload new documents and submit again. Needs the token of a user listed in ADMIN_EMAILS.",
                 "consumes": [
                     "application/json"
                 ],
@@ -336,9 +396,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Post"
+                    "Verification"
                 ],
-                "summary": "Create a new Post.",
+                "summary": "Review the documents of an Institution.",
                 "parameters": [
                     {
                         "type": "string",
@@ -350,29 +410,47 @@ const docTemplate = `{
                     {
                         "type": "string",
                         "description": "Institution ID",
-                        "name": "id",
+                        "name": "institution_id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Post created details",
+                        "description": "Decision",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/model.PostRequest"
+                            "$ref": "#/definitions/model.VerificationRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Post created successfully",
+                        "description": "Success review verification",
                         "schema": {
-                            "$ref": "#/definitions/model.PostResponse"
+                            "$ref": "#/definitions/model.VerificationResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal server error",
+                    "400": {
+                        "description": "Invalid decision",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Institution not found",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Institution is not awaiting review",
                         "schema": {
                             "$ref": "#/definitions/httputil.HTTPError"
                         }
@@ -380,14 +458,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/v1/post/institution/{id}": {
+        "/v1/admin/verifications/{institution_id}/documents": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get all post by Institution ID with authorization.",
+                "description": "List the documents an institution uploaded for review. Needs the token of a user listed in ADMIN_EMAILS.",
                 "consumes": [
                     "application/json"
                 ],
@@ -395,9 +473,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Post"
+                    "Verification"
                 ],
-                "summary": "Get all Post by Institution ID.",
+                "summary": "Get the verification documents of an Institution.",
                 "parameters": [
                     {
                         "type": "string",
@@ -409,26 +487,20 @@ const docTemplate = `{
                     {
                         "type": "string",
                         "description": "Institution ID",
-                        "name": "id",
+                        "name": "institution_id",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Success get post data",
+                        "description": "Success get documents",
                         "schema": {
-                            "$ref": "#/definitions/model.PostResponse"
-                        }
-                    },
-                    "401": {
-                        "description": "Unauthorized",
-                        "schema": {
-                            "$ref": "#/definitions/httputil.HTTPError"
+                            "$ref": "#/definitions/model.DocumentListResponse"
                         }
                     },
-                    "404": {
-                        "description": "Post not found",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/httputil.HTTPError"
                         }
@@ -436,24 +508,23 @@ const docTemplate = `{
                 }
             }
         },
-        "/v1/post/{id}": {
+        "/v1/admin/verifications/{institution_id}/documents/{document_id}": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get a specific post by ID with authorization.",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Download a document an institution uploaded for review. Needs the token of a user listed in ADMIN_EMAILS.",
                 "produces": [
-                    "application/json"
+                    "application/pdf",
+                    "image/jpeg",
+                    "image/png"
                 ],
                 "tags": [
-                    "Post"
+                    "Verification"
                 ],
-                "summary": "Get Post by ID.",
+                "summary": "Download a verification document of an Institution.",
                 "parameters": [
                     {
                         "type": "string",
@@ -464,40 +535,44 @@ const docTemplate = `{
                     },
                     {
                         "type": "string",
-                        "description": "Post ID",
-                        "name": "id",
+                        "description": "Institution ID",
+                        "name": "institution_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Document ID",
+                        "name": "document_id",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Success get post data",
+                        "description": "Document",
                         "schema": {
-                            "$ref": "#/definitions/model.PostResponse"
+                            "type": "file"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/httputil.HTTPError"
                         }
                     },
                     "404": {
-                        "description": "Post not found",
+                        "description": "Document not found",
                         "schema": {
                             "$ref": "#/definitions/httputil.HTTPError"
                         }
                     }
                 }
-            },
-            "put": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Update an existing post with authorization.",
+            }
+        },
+        "/v1/categories": {
+            "get": {
+                "description": "List categories by name without authentication, each with the number of its posts, how many are raising funds now, and what they target and raised in total, one amount per currency in minor units.",
                 "consumes": [
                     "application/json"
                 ],
@@ -505,21 +580,807 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Post"
+                    "Category"
                 ],
-                "summary": "Update Post.",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Bearer token",
-                        "name": "Authorization",
-                        "in": "header",
-                        "required": true
+                "summary": "Get all Categories.",
+                "responses": {
+                    "200": {
+                        "description": "Success get category data",
+                        "schema": {
+                            "$ref": "#/definitions/model.CategoryListResponse"
+                        }
                     },
-                    {
-                        "type": "string",
-                        "description": "Post ID",
-                        "name": "id",
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/fund-collect/post/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the funding collection of a post. Only the institution that owns the post can get it.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "FundCollect"
+                ],
+                "summary": "Get funding collection by Post ID.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Post ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success get funding collection data",
+                        "schema": {
+                            "$ref": "#/definitions/model.FundCollectResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Funding collection not found",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/fund-collect/{id}/message": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Hide or show a donation's message on the supporters wall. The donation stays listed. Only the institution that owns the post can moderate it.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "FundCollect"
+                ],
+                "summary": "Hide or show a donation message.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Fund collect ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Moderation decision",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.DonationMessageModerationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success moderate donation message",
+                        "schema": {
+                            "$ref": "#/definitions/model.FundCollectResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Fund collect not found",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/institution": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get data institution with authorization.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Institution"
+                ],
+                "summary": "Get Institution.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success get institution data",
+                        "schema": {
+                            "$ref": "#/definitions/model.InstitutionResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Data not found",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/institution/documents": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the documents of the authenticated institution.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Verification"
+                ],
+                "summary": "Get verification documents.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success get documents",
+                        "schema": {
+                            "$ref": "#/definitions/model.DocumentListResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Upload a legal document of the authenticated institution: its registration certificate, its tax ID (NPWP) or proof of its bank account. The file must be a PDF, JPEG or PNG of at most 3 MB and replaces the document of the same type. Documents cannot be changed while they are under review or once the institution is verified.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Verification"
+                ],
+                "summary": "Upload a verification document.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "REGISTRATION_CERTIFICATE, TAX_ID or BANK_ACCOUNT_PROOF",
+                        "name": "type",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "Document",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success upload document",
+                        "schema": {
+                            "$ref": "#/definitions/model.DocumentResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid document",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Documents cannot be changed",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/institution/documents/{document_id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Download a document of the authenticated institution.",
+                "produces": [
+                    "application/pdf",
+                    "image/jpeg",
+                    "image/png"
+                ],
+                "tags": [
+                    "Verification"
+                ],
+                "summary": "Download a verification document.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Document ID",
+                        "name": "document_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Document",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Document not found",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/institution/login": {
+            "post": {
+                "description": "Login Institution with email and password.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Institution"
+                ],
+                "summary": "Login Institution.",
+                "parameters": [
+                    {
+                        "description": "Institution login",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.InstitutionLoginRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Institution login successfully",
+                        "schema": {
+                            "$ref": "#/definitions/model.InstitutionToken"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/institution/register": {
+            "post": {
+                "description": "Register institution with name, email, etc. Email must be unique and password will be hashed before saved to database.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Institution"
+                ],
+                "summary": "Register a new Institution.",
+                "parameters": [
+                    {
+                        "description": "Institution created details",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.InstitutionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Institution created successfully",
+                        "schema": {
+                            "$ref": "#/definitions/model.InstitutionResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/institution/verification": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Submit the documents of the authenticated institution for review once all of REGISTRATION_CERTIFICATE, TAX_ID and BANK_ACCOUNT_PROOF are uploaded. Until an admin verifies the institution, its posts can only be drafts.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Verification"
+                ],
+                "summary": "Submit documents for verification.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success submit verification",
+                        "schema": {
+                            "$ref": "#/definitions/model.VerificationResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Documents missing or already submitted",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/institution/{id}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Update an existing institution with authorization.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Institution"
+                ],
+                "summary": "Update Institution.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Institution ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Updated institution data",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.InstitutionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success update institution data",
+                        "schema": {
+                            "$ref": "#/definitions/model.InstitutionResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Delete an existing institution with authorization.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Institution"
+                ],
+                "summary": "Delete Institution.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Institution ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success delete institution data",
+                        "schema": {
+                            "$ref": "#/definitions/model.InstitutionDeleteResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/post": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create post with title, body, etc. fund_target_v2 is in minor units, e.g. 100000000 for IDR 1,000,000; the float fund_target is deprecated. category_id is one of GET /v1/categories; tags are free-form, at most 10.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Post"
+                ],
+                "summary": "Create a new Post.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Institution ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Post created details",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.PostRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Post created successfully",
+                        "schema": {
+                            "$ref": "#/definitions/model.PostResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/post/institution/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get all post by Institution ID with authorization.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Post"
+                ],
+                "summary": "Get all Post by Institution ID.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Institution ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success get post data",
+                        "schema": {
+                            "$ref": "#/definitions/model.PostResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Post not found",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/post/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get a specific post by ID. Posts that are not PUBLISHED, CLOSED or COMPLETED are only shown to the institution that owns them and to moderators.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Post"
+                ],
+                "summary": "Get Post by ID.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Post ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success get post data",
+                        "schema": {
+                            "$ref": "#/definitions/model.PostResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Post not found",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Update an existing post with authorization. Given tags replace the post's tags; clear_tags removes them.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Post"
+                ],
+                "summary": "Update Post.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Post ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     },
@@ -603,9 +1464,70 @@ const docTemplate = `{
                 }
             }
         },
-        "/v1/posts": {
+        "/v1/post/{id}/moderation": {
             "get": {
-                "description": "Get all Post without authentication.",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the moderation decisions on a post, oldest first. Open to the institution that owns the post and to moderators.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Moderation"
+                ],
+                "summary": "Get the moderation history of a Post.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Post ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success get moderation history",
+                        "schema": {
+                            "$ref": "#/definitions/model.ModerationHistoryResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Post not found",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/post/{id}/status": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Move a post along its lifecycle. New posts are DRAFT and only PUBLISHED posts are listed and accept donations. The owning institution submits a draft (SUBMITTED) once it is verified, see /v1/institution/documents, withdraws it (DRAFT), closes a published post early (CLOSED), completes it once ended or funded (COMPLETED) and archives drafts, rejected, closed and completed posts (ARCHIVED). Moderators publish, reject or send back submitted posts, see /v1/admin/moderation/posts. Admins, with a user token listed in ADMIN_EMAILS, close published posts.",
                 "consumes": [
                     "application/json"
                 ],
@@ -615,16 +1537,109 @@ const docTemplate = `{
                 "tags": [
                     "Post"
                 ],
-                "summary": "Get all Post.",
+                "summary": "Change the status of a Post.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Post ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New status",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.PostStatusRequest"
+                        }
+                    }
+                ],
                 "responses": {
                     "200": {
-                        "description": "Success get post data",
+                        "description": "Success change post status",
                         "schema": {
                             "$ref": "#/definitions/model.PostResponse"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "400": {
+                        "description": "Invalid status",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Post not found",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Status change not allowed",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/post/{id}/supporters": {
+            "get": {
+                "description": "List a post's donations newest first without authentication, with the donor's chosen name, the amount rounded down to 1, 2 or 5 times a power of ten, and any message the institution has not hidden. Pass next_cursor as cursor for the next page.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "FundCollect"
+                ],
+                "summary": "Get the supporters wall of a Post.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Post ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size, 20 by default and at most 100",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success get supporters data",
+                        "schema": {
+                            "$ref": "#/definitions/model.SupporterListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
                         "schema": {
                             "$ref": "#/definitions/httputil.HTTPError"
                         }
@@ -637,6 +1652,115 @@ const docTemplate = `{
                     }
                 }
             }
+        },
+        "/v1/posts": {
+            "get": {
+                "description": "List published posts without authentication, newest first, one page at a time. Pass next_cursor back as cursor, with the same filters and sort, to get the next page. A post that reached its target is FUNDED whatever its dates. Targets are in minor units of target_currency, e.g. 100000000 for IDR 1,000,000.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Post"
+                ],
+                "summary": "Get all Post.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Search in titles and bodies",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "UPCOMING, ACTIVE, ENDED or FUNDED",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Institution ID",
+                        "name": "institution_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Category ID or slug",
+                        "name": "category",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated tags, posts with any of them",
+                        "name": "tags",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Running on or after, YYYY-MM-DD or RFC3339",
+                        "name": "date_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Running on or before, YYYY-MM-DD or RFC3339",
+                        "name": "date_to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Minimum fund target in minor units",
+                        "name": "target_min",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum fund target in minor units",
+                        "name": "target_max",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Currency of the target range, IDR by default",
+                        "name": "target_currency",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "NEWEST, ENDING_SOON, MOST_FUNDED or PERCENT_FUNDED",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor from the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size, at most 100",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success get post data",
+                        "schema": {
+                            "$ref": "#/definitions/model.PostListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid filter",
+                        "schema": {
+                            "$ref": "#/definitions/httputil.HTTPError"
+                        }
+                    }
+                }
+            }
         }
     },
     "definitions": {
@@ -648,15 +1772,138 @@ const docTemplate = `{
                 }
             }
         },
+        "model.CategoryDeleteResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.CategoryListResponse": {
+            "type": "object",
+            "properties": {
+                "categories": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.CategoryResponse"
+                    }
+                }
+            }
+        },
+        "model.CategoryRequest": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "slug": {
+                    "description": "Slug defaults to the name in lowercase with dashes.",
+                    "type": "string"
+                }
+            }
+        },
+        "model.CategoryResponse": {
+            "type": "object",
+            "properties": {
+                "active_post_count": {
+                    "type": "integer"
+                },
+                "category_id": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "fund_achieved": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Money"
+                    }
+                },
+                "fund_target": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Money"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                },
+                "post_count": {
+                    "type": "integer"
+                },
+                "slug": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.DocumentListResponse": {
+            "type": "object",
+            "properties": {
+                "documents": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.DocumentResponse"
+                    }
+                }
+            }
+        },
+        "model.DocumentResponse": {
+            "type": "object",
+            "properties": {
+                "content_type": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "document_id": {
+                    "type": "string"
+                },
+                "filename": {
+                    "type": "string"
+                },
+                "size": {
+                    "type": "integer"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.DonationMessageModerationRequest": {
+            "type": "object",
+            "properties": {
+                "hidden": {
+                    "type": "boolean"
+                }
+            }
+        },
         "model.FundCollectResponse": {
             "type": "object",
             "properties": {
                 "amount": {
                     "type": "number"
                 },
+                "amount_v2": {
+                    "$ref": "#/definitions/model.Money"
+                },
                 "fund_collect_id": {
                     "type": "string"
                 },
+                "message": {
+                    "type": "string"
+                },
+                "message_hidden": {
+                    "type": "boolean"
+                },
+                "original_amount": {
+                    "$ref": "#/definitions/model.Money"
+                },
                 "post_id": {
                     "type": "string"
                 },
@@ -731,6 +1978,17 @@ const docTemplate = `{
                 "phone": {
                     "type": "string"
                 },
+                "verification_note": {
+                    "type": "string"
+                },
+                "verification_status": {
+                    "description": "VerificationStatus is UNVERIFIED, PENDING, VERIFIED or REJECTED.",
+                    "type": "string"
+                },
+                "verified": {
+                    "description": "Verified is the badge of institutions whose documents were verified.",
+                    "type": "boolean"
+                },
                 "website": {
                     "type": "string"
                 }
@@ -744,6 +2002,95 @@ const docTemplate = `{
                 }
             }
         },
+        "model.ModerationHistoryResponse": {
+            "type": "object",
+            "properties": {
+                "moderations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.ModerationResponse"
+                    }
+                }
+            }
+        },
+        "model.ModerationQueueResponse": {
+            "type": "object",
+            "properties": {
+                "posts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.QueuedPostResponse"
+                    }
+                }
+            }
+        },
+        "model.ModerationRequest": {
+            "type": "object",
+            "properties": {
+                "decision": {
+                    "description": "Decision is APPROVE, REJECT or REQUEST_CHANGES.",
+                    "type": "string"
+                },
+                "reason": {
+                    "description": "Reason is required unless the post is approved. It is emailed to the\ninstitution.",
+                    "type": "string"
+                }
+            }
+        },
+        "model.ModerationResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "decision": {
+                    "type": "string"
+                },
+                "from_status": {
+                    "type": "string"
+                },
+                "moderation_id": {
+                    "type": "string"
+                },
+                "moderator_email": {
+                    "type": "string"
+                },
+                "post_id": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "to_status": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.Money": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "integer"
+                },
+                "currency": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.PostCategoryResponse": {
+            "type": "object",
+            "properties": {
+                "category_id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "slug": {
+                    "type": "string"
+                }
+            }
+        },
         "model.PostDeleteResponse": {
             "type": "object",
             "properties": {
@@ -752,12 +2099,33 @@ const docTemplate = `{
                 }
             }
         },
+        "model.PostListResponse": {
+            "type": "object",
+            "properties": {
+                "next_cursor": {
+                    "type": "string"
+                },
+                "posts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.PostResponse"
+                    }
+                }
+            }
+        },
         "model.PostRequest": {
             "type": "object",
             "properties": {
                 "body": {
                     "type": "string"
                 },
+                "category_id": {
+                    "type": "string"
+                },
+                "clear_tags": {
+                    "description": "ClearTags removes every tag of the post on update, when Tags is empty.",
+                    "type": "boolean"
+                },
                 "date_end": {
                     "type": "string"
                 },
@@ -765,8 +2133,22 @@ const docTemplate = `{
                     "type": "string"
                 },
                 "fund_target": {
+                    "description": "FundTarget is deprecated in favour of FundTargetV2.",
                     "type": "number"
                 },
+                "fund_target_v2": {
+                    "$ref": "#/definitions/model.Money"
+                },
+                "overflow_policy": {
+                    "description": "OverflowPolicy is ACCEPT, CAP or REJECT and defaults to ACCEPT.",
+                    "type": "string"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
                 "title": {
                     "type": "string"
                 }
@@ -778,6 +2160,14 @@ const docTemplate = `{
                 "body": {
                     "type": "string"
                 },
+                "category": {
+                    "description": "Category is omitted from posts not filed under one.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.PostCategoryResponse"
+                        }
+                    ]
+                },
                 "date_end": {
                     "type": "string"
                 },
@@ -787,16 +2177,163 @@ const docTemplate = `{
                 "fund_achieved": {
                     "type": "number"
                 },
+                "fund_achieved_v2": {
+                    "$ref": "#/definitions/model.Money"
+                },
                 "fund_target": {
                     "type": "number"
                 },
+                "fund_target_v2": {
+                    "$ref": "#/definitions/model.Money"
+                },
+                "institution_id": {
+                    "type": "string"
+                },
+                "overflow_policy": {
+                    "type": "string"
+                },
+                "post_id": {
+                    "type": "string"
+                },
+                "status": {
+                    "description": "Status is DRAFT, SUBMITTED, PUBLISHED, REJECTED, CLOSED, COMPLETED or\nARCHIVED.",
+                    "type": "string"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.PostStatusRequest": {
+            "type": "object",
+            "properties": {
+                "status": {
+                    "description": "Status is SUBMITTED, DRAFT, CLOSED, COMPLETED or ARCHIVED. Posts are\npublished or rejected through moderation.",
+                    "type": "string"
+                }
+            }
+        },
+        "model.QueuedPostResponse": {
+            "type": "object",
+            "properties": {
+                "body": {
+                    "type": "string"
+                },
+                "category": {
+                    "type": "string"
+                },
+                "date_end": {
+                    "type": "string"
+                },
+                "date_start": {
+                    "type": "string"
+                },
+                "fund_target": {
+                    "$ref": "#/definitions/model.Money"
+                },
+                "institution_id": {
+                    "type": "string"
+                },
+                "institution_name": {
+                    "type": "string"
+                },
                 "post_id": {
                     "type": "string"
                 },
+                "submitted_at": {
+                    "type": "string"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
                 "title": {
                     "type": "string"
                 }
             }
+        },
+        "model.SupporterListResponse": {
+            "type": "object",
+            "properties": {
+                "next_cursor": {
+                    "type": "string"
+                },
+                "supporters": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.SupporterResponse"
+                    }
+                }
+            }
+        },
+        "model.SupporterResponse": {
+            "type": "object",
+            "properties": {
+                "amount_bucket": {
+                    "$ref": "#/definitions/model.Money"
+                },
+                "display_name": {
+                    "type": "string"
+                },
+                "donated_at": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.VerificationListResponse": {
+            "type": "object",
+            "properties": {
+                "institutions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.VerificationResponse"
+                    }
+                }
+            }
+        },
+        "model.VerificationRequest": {
+            "type": "object",
+            "properties": {
+                "decision": {
+                    "description": "Decision is APPROVE or REJECT.",
+                    "type": "string"
+                },
+                "reason": {
+                    "description": "Reason is required to reject. The institution sees it and can upload\nnew documents and submit again.",
+                    "type": "string"
+                }
+            }
+        },
+        "model.VerificationResponse": {
+            "type": "object",
+            "properties": {
+                "institution_id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "verification_note": {
+                    "type": "string"
+                },
+                "verification_status": {
+                    "type": "string"
+                },
+                "verified": {
+                    "type": "boolean"
+                }
+            }
         }
     }
 }`
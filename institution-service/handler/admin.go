@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"context"
+
+	"institution-service/middlewares"
+	"institution-service/utils"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// requireAdmin lets through user tokens whose email is listed in
+// ADMIN_EMAILS. action completes "only admins can ..." in the error.
+func requireAdmin(ctx context.Context, action string) error {
+	email, ok := ctx.Value(middlewares.EmailKey).(string)
+	if !ok || !utils.IsAdmin(email) {
+		return status.Errorf(codes.PermissionDenied, "only admins can %s", action)
+	}
+
+	return nil
+}
+
+// requireModerator lets through user tokens whose email is listed in
+// MODERATOR_EMAILS or ADMIN_EMAILS, and returns that email.
+func requireModerator(ctx context.Context, action string) (string, error) {
+	email, ok := ctx.Value(middlewares.EmailKey).(string)
+	if !ok || !utils.IsModerator(email) {
+		return "", status.Errorf(codes.PermissionDenied, "only moderators can %s", action)
+	}
+
+	return email, nil
+}
+
+// requireService lets through the tokens other services sign for their own
+// calls. action completes "only services can ..." in the error.
+func requireService(ctx context.Context, action string) error {
+	service, ok := ctx.Value(middlewares.ServiceKey).(string)
+	if !ok || service == "" {
+		return status.Errorf(codes.PermissionDenied, "only services can %s", action)
+	}
+
+	return nil
+}
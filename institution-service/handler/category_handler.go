@@ -0,0 +1,146 @@
+package handler
+
+import (
+	"context"
+	"errors"
+
+	"institution-service/model"
+	pb "institution-service/pb/category"
+	"institution-service/usecase"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+)
+
+type ICategoryHandler interface {
+	CreateCategory(ctx context.Context, req *pb.CreateCategoryRequest) (*pb.CategoryResponse, error)
+	GetAllCategories(ctx context.Context, req *pb.GetAllCategoriesRequest) (*pb.GetAllCategoriesResponse, error)
+	UpdateCategory(ctx context.Context, req *pb.UpdateCategoryRequest) (*pb.CategoryResponse, error)
+	DeleteCategory(ctx context.Context, req *pb.DeleteCategoryRequest) (*pb.DeleteCategoryResponse, error)
+}
+
+type CategoryServer struct {
+	pb.UnimplementedCategoryServiceServer
+	categoryUsecase usecase.ICategoryUsecase
+}
+
+func NewCategoryHandler(categoryUsecase usecase.ICategoryUsecase) *CategoryServer {
+	return &CategoryServer{
+		categoryUsecase: categoryUsecase,
+	}
+}
+
+func (s *CategoryServer) CreateCategory(ctx context.Context, req *pb.CreateCategoryRequest) (*pb.CategoryResponse, error) {
+	if err := requireAdmin(ctx, "manage categories"); err != nil {
+		return nil, err
+	}
+
+	category, err := s.categoryUsecase.CreateCategory(ctx, &model.Category{
+		Slug:        req.Slug,
+		Name:        req.Name,
+		Description: req.Description,
+	})
+	if err != nil {
+		return nil, categoryError("create category error", err)
+	}
+
+	return toCategoryResponse(&model.CategorySummary{Category: *category}), nil
+}
+
+// GetAllCategories is public so browse pages can list categories with the
+// number of posts and totals of each.
+func (s *CategoryServer) GetAllCategories(ctx context.Context, req *pb.GetAllCategoriesRequest) (*pb.GetAllCategoriesResponse, error) {
+	summaries, err := s.categoryUsecase.GetAllCategories(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "get all categories error: %v", err)
+	}
+
+	categories := make([]*pb.CategoryResponse, 0, len(summaries))
+	for i := range summaries {
+		categories = append(categories, toCategoryResponse(&summaries[i]))
+	}
+
+	return &pb.GetAllCategoriesResponse{
+		Categories: categories,
+	}, nil
+}
+
+func (s *CategoryServer) UpdateCategory(ctx context.Context, req *pb.UpdateCategoryRequest) (*pb.CategoryResponse, error) {
+	if err := requireAdmin(ctx, "manage categories"); err != nil {
+		return nil, err
+	}
+
+	categoryID, err := uuid.Parse(req.CategoryId)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid category ID format: %v", err)
+	}
+
+	category, err := s.categoryUsecase.UpdateCategory(ctx, &model.Category{
+		CategoryID:  categoryID,
+		Slug:        req.Slug,
+		Name:        req.Name,
+		Description: req.Description,
+	})
+	if err != nil {
+		return nil, categoryError("update category error", err)
+	}
+
+	return toCategoryResponse(&model.CategorySummary{Category: *category}), nil
+}
+
+func (s *CategoryServer) DeleteCategory(ctx context.Context, req *pb.DeleteCategoryRequest) (*pb.DeleteCategoryResponse, error) {
+	if err := requireAdmin(ctx, "manage categories"); err != nil {
+		return nil, err
+	}
+
+	categoryID, err := uuid.Parse(req.CategoryId)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid category ID format: %v", err)
+	}
+
+	err = s.categoryUsecase.DeleteCategory(ctx, categoryID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, status.Errorf(codes.NotFound, "category not found")
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "delete category error: %v", err)
+	}
+
+	return &pb.DeleteCategoryResponse{
+		Message: "Category deleted successfully",
+	}, nil
+}
+
+// categoryError maps the errors of creating and updating a category to gRPC
+// codes. Those are validation errors unless the category or slug is at fault.
+func categoryError(message string, err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return status.Errorf(codes.NotFound, "category not found")
+	case errors.Is(err, usecase.ErrCategorySlugTaken):
+		return status.Errorf(codes.AlreadyExists, "%v", err)
+	}
+
+	return status.Errorf(codes.InvalidArgument, "%s: %v", message, err)
+}
+
+func toCategoryResponse(summary *model.CategorySummary) *pb.CategoryResponse {
+	res := &pb.CategoryResponse{
+		CategoryId:      summary.Category.CategoryID.String(),
+		Slug:            summary.Category.Slug,
+		Name:            summary.Category.Name,
+		Description:     summary.Category.Description,
+		PostCount:       summary.PostCount,
+		ActivePostCount: summary.ActivePostCount,
+	}
+	for _, amount := range summary.FundTarget {
+		res.FundTarget = append(res.FundTarget, &pb.Money{Amount: amount.Amount, Currency: amount.Currency})
+	}
+	for _, amount := range summary.FundAchieved {
+		res.FundAchieved = append(res.FundAchieved, &pb.Money{Amount: amount.Amount, Currency: amount.Currency})
+	}
+
+	return res
+}
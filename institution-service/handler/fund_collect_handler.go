@@ -2,17 +2,22 @@ package handler
 
 import (
 	"context"
+	"errors"
+	"time"
 
+	"institution-service/middlewares"
 	"institution-service/model"
 	pbFundCollect "institution-service/pb/fund_collect"
 	pbPost "institution-service/pb/post"
 	"institution-service/usecase"
 
 	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
 )
 
 type IFundCollectHandler interface {
-	CreateFundCollect(ctx context.Context, fundCollect *model.FundCollect) (*model.FundCollect, error)
 	GetFundCollectByPostID(ctx context.Context, postID string) ([]model.FundCollect, error)
 }
 
@@ -30,58 +35,150 @@ func NewFundCollectHandler(fundCollectUsecase usecase.IFundCollectUsecase, postU
 	}
 }
 
-func (s *FundCollectServer) CreateFundCollect(ctx context.Context, req *pbFundCollect.CreateFundCollectRequest) (*pbFundCollect.CreateFundCollectResponse, error) {
-	postID, err := uuid.Parse(req.PostId)
+// GetFundCollectByPostID lists the post's fund collects as recorded, with the
+// donors' user IDs and the names of anonymous donors. Only other services and
+// the institution that owns the post can list them.
+func (s *FundCollectServer) GetFundCollectByPostID(ctx context.Context, req *pbFundCollect.GetFundCollectByPostIDRequest) (*pbFundCollect.GetFundCollectByPostIDResponse, error) {
+	if err := requireService(ctx, "list fund collects"); err != nil {
+		authenticatedInstitutionID, ok := ctx.Value(middlewares.InstitutionIDKey).(string)
+		if !ok {
+			return nil, status.Errorf(codes.PermissionDenied, "only services and the post's institution can list fund collects")
+		}
+
+		postID, err := uuid.Parse(req.PostId)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid post ID format: %v", err)
+		}
+
+		post, err := s.postUsecase.GetPostByID(ctx, postID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, status.Errorf(codes.NotFound, "post not found")
+		}
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "get post by ID error: %v", err)
+		}
+		if post.InstitutionID.String() != authenticatedInstitutionID {
+			return nil, status.Errorf(codes.PermissionDenied, "unauthorized access")
+		}
+	}
+
+	fund_collects, err := s.fundCollectUsecase.GetFundCollectByPostID(ctx, req.PostId)
 	if err != nil {
 		return nil, err
 	}
 
-	fund_collect_model := &model.FundCollect{
-		PostID:        postID,
-		UserID:        req.UserId,
-		UserName:      req.UserName,
-		Amount:        float64(req.Amount),
-		TransactionID: req.TransactionId,
+	var fund_collect_responses []*pbFundCollect.FundCollectResponse
+	for i := range fund_collects {
+		fund_collect_responses = append(fund_collect_responses, toFundCollectResponse(&fund_collects[i]))
+	}
+
+	return &pbFundCollect.GetFundCollectByPostIDResponse{
+		Funds: fund_collect_responses,
+	}, nil
+}
+
+// GetPostSupporters is public: it lists the post's supporters wall with the
+// donor's chosen name, a rounded amount and any message the institution has
+// not hidden. Like GetPostByID, posts that are not public are NotFound to
+// anyone but their institution and moderators.
+func (s *FundCollectServer) GetPostSupporters(ctx context.Context, req *pbFundCollect.GetPostSupportersRequest) (*pbFundCollect.GetPostSupportersResponse, error) {
+	postID, err := uuid.Parse(req.PostId)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid post ID format: %v", err)
 	}
 
-	fund_collect, err := s.fundCollectUsecase.CreateFundCollect(ctx, fund_collect_model)
+	post, err := s.postUsecase.GetPostByID(ctx, postID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, status.Errorf(codes.NotFound, "post not found")
+	}
 	if err != nil {
-		return nil, err
+		return nil, status.Errorf(codes.Internal, "get post by ID error: %v", err)
+	}
+	if !post.Status.IsPublic() && !canViewPost(ctx, post) {
+		return nil, status.Errorf(codes.NotFound, "post not found")
 	}
-	_, err = s.postUsecase.AddPostFundAchieved(ctx, fund_collect.PostID, fund_collect.Amount)
+
+	page, err := s.fundCollectUsecase.GetPostSupporters(ctx, postID, req.Cursor, int(req.Limit))
 	if err != nil {
-		return nil, err
+		return nil, status.Errorf(codes.InvalidArgument, "failed to get supporters: %v", err)
 	}
 
-	return &pbFundCollect.CreateFundCollectResponse{
-		FundCollectId: fund_collect.FundCollectID.String(),
-		PostId:        fund_collect.PostID.String(),
-		UserId:        fund_collect.UserID,
-		UserName:      fund_collect.UserName,
-		Amount:        float32(fund_collect.Amount),
-		TransactionId: fund_collect.TransactionID,
-	}, nil
+	res := &pbFundCollect.GetPostSupportersResponse{
+		Supporters: make([]*pbFundCollect.Supporter, 0, len(page.FundCollects)),
+		NextCursor: page.NextCursor,
+	}
+	for _, fundCollect := range page.FundCollects {
+		bucket := fundCollect.Amount.Bucket()
+		supporter := &pbFundCollect.Supporter{
+			DisplayName:  fundCollect.UserName,
+			AmountBucket: &pbFundCollect.Money{Amount: bucket.Amount, Currency: bucket.Currency},
+			DonatedAt:    fundCollect.CreatedAt.Format(time.RFC3339),
+		}
+		if !fundCollect.MessageHidden {
+			supporter.Message = fundCollect.Message
+		}
+		res.Supporters = append(res.Supporters, supporter)
+	}
+
+	return res, nil
 }
 
-func (s *FundCollectServer) GetFundCollectByPostID(ctx context.Context, req *pbFundCollect.GetFundCollectByPostIDRequest) (*pbFundCollect.GetFundCollectByPostIDResponse, error) {
-	fund_collects, err := s.fundCollectUsecase.GetFundCollectByPostID(ctx, req.PostId)
+// ModerateDonationMessage hides or shows a donation's message on the
+// supporters wall. Only the institution that owns the post can moderate it.
+func (s *FundCollectServer) ModerateDonationMessage(ctx context.Context, req *pbFundCollect.ModerateDonationMessageRequest) (*pbFundCollect.FundCollectResponse, error) {
+	authenticatedInstitutionID, ok := ctx.Value(middlewares.InstitutionIDKey).(string)
+	if !ok {
+		return nil, status.Errorf(codes.Internal, "failed to get authenticated institution ID from context")
+	}
+
+	fundCollectID, err := uuid.Parse(req.FundCollectId)
 	if err != nil {
-		return nil, err
+		return nil, status.Errorf(codes.InvalidArgument, "invalid fund collect ID format: %v", err)
 	}
 
-	var fund_collect_responses []*pbFundCollect.FundCollectResponse
-	for _, fund_collect := range fund_collects {
-		fund_collect_responses = append(fund_collect_responses, &pbFundCollect.FundCollectResponse{
-			FundCollectId: fund_collect.FundCollectID.String(),
-			PostId:        fund_collect.PostID.String(),
-			UserId:        fund_collect.UserID,
-			UserName:      fund_collect.UserName,
-			Amount:        float32(fund_collect.Amount),
-			TransactionId: fund_collect.TransactionID,
-		})
+	fundCollect, err := s.fundCollectUsecase.GetFundCollectByID(ctx, fundCollectID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, status.Errorf(codes.NotFound, "fund collect not found")
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "get fund collect by ID error: %v", err)
 	}
 
-	return &pbFundCollect.GetFundCollectByPostIDResponse{
-		Funds: fund_collect_responses,
-	}, nil
+	post, err := s.postUsecase.GetPostByID(ctx, fundCollect.PostID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "get post by ID error: %v", err)
+	}
+	if post.InstitutionID.String() != authenticatedInstitutionID {
+		return nil, status.Errorf(codes.PermissionDenied, "unauthorized access")
+	}
+
+	fundCollect, err = s.fundCollectUsecase.SetMessageHidden(ctx, fundCollectID, req.Hidden)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to moderate donation message: %v", err)
+	}
+
+	return toFundCollectResponse(fundCollect), nil
+}
+
+func toFundCollectResponse(fundCollect *model.FundCollect) *pbFundCollect.FundCollectResponse {
+	return &pbFundCollect.FundCollectResponse{
+		FundCollectId:  fundCollect.FundCollectID.String(),
+		PostId:         fundCollect.PostID.String(),
+		UserId:         fundCollect.UserID,
+		UserName:       fundCollect.UserName,
+		Amount:         float32(fundCollect.Amount.Major()),
+		AmountV2:       &pbFundCollect.Money{Amount: fundCollect.Amount.Amount, Currency: fundCollect.Amount.Currency},
+		OriginalAmount: toOriginalAmount(fundCollect.OriginalAmount),
+		TransactionId:  fundCollect.TransactionID,
+		Message:        fundCollect.Message,
+		MessageHidden:  fundCollect.MessageHidden,
+	}
+}
+
+func toOriginalAmount(amount model.Money) *pbFundCollect.Money {
+	if amount.Currency == "" {
+		return nil
+	}
+
+	return &pbFundCollect.Money{Amount: amount.Amount, Currency: amount.Currency}
 }
@@ -2,6 +2,7 @@ package handler
 
 import (
 	"context"
+	"errors"
 
 	"institution-service/middlewares"
 	"institution-service/model"
@@ -13,6 +14,7 @@ import (
 	"golang.org/x/crypto/bcrypt"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
 )
 
 type IInstitutionHandler interface {
@@ -20,6 +22,7 @@ type IInstitutionHandler interface {
 	LoginInstitution(ctx context.Context, req *pb.LoginInstitutionRequest) (*pb.LoginInstitutionResponse, error)
 
 	GetInstitutionByID(ctx context.Context, req *pb.GetInstitutionByIDRequest) (*pb.InstitutionResponse, error)
+	GetPublicInstitution(ctx context.Context, req *pb.GetInstitutionByIDRequest) (*pb.PublicInstitutionResponse, error)
 	GetInstitutionByEmail(ctx context.Context, req *pb.GetInstitutionByEmailRequest) (*pb.InstitutionResponse, error)
 	UpdateInstitution(ctx context.Context, req *pb.UpdateInstitutionRequest) (*pb.InstitutionResponse, error)
 	DeleteInstitution(ctx context.Context, req *pb.DeleteInstitutionRequest) (*pb.DeleteInstitutionResponse, error)
@@ -58,9 +61,11 @@ func (s *InstitutionServer) RegisterInstitution(ctx context.Context, req *pb.Reg
 	}
 
 	return &pb.InstitutionResponse{
-		InstitutionId: institution.InstitutionID.String(),
-		Name:          institution.Name,
-		Email:         institution.Email,
+		InstitutionId:      institution.InstitutionID.String(),
+		Name:               institution.Name,
+		Email:              institution.Email,
+		Verified:           institution.IsVerified(),
+		VerificationStatus: string(institution.VerificationStatus),
 	}, nil
 }
 
@@ -97,12 +102,38 @@ func (s *InstitutionServer) GetInstitutionByID(ctx context.Context, req *pb.GetI
 	}
 
 	return &pb.InstitutionResponse{
+		InstitutionId:      institution.InstitutionID.String(),
+		Name:               institution.Name,
+		Email:              institution.Email,
+		Address:            institution.Address,
+		Phone:              institution.Phone,
+		Website:            institution.Website,
+		Verified:           institution.IsVerified(),
+		VerificationStatus: string(institution.VerificationStatus),
+		VerificationNote:   institution.VerificationNote,
+	}, nil
+}
+
+func (s *InstitutionServer) GetPublicInstitution(ctx context.Context, req *pb.GetInstitutionByIDRequest) (*pb.PublicInstitutionResponse, error) {
+	institutionID, err := uuid.Parse(req.InstitutionId)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid institution ID format: %v", err)
+	}
+
+	institution, err := s.userUsecase.GetInstitutionByID(ctx, institutionID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, status.Errorf(codes.NotFound, "institution not found")
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "get institution by ID error: %v", err)
+	}
+
+	return &pb.PublicInstitutionResponse{
 		InstitutionId: institution.InstitutionID.String(),
 		Name:          institution.Name,
-		Email:         institution.Email,
 		Address:       institution.Address,
-		Phone:         institution.Phone,
 		Website:       institution.Website,
+		Verified:      institution.IsVerified(),
 	}, nil
 }
 
@@ -113,9 +144,11 @@ func (s *InstitutionServer) GetInstitutionByEmail(ctx context.Context, req *pb.G
 	}
 
 	return &pb.InstitutionResponse{
-		InstitutionId: institution.InstitutionID.String(),
-		Name:          institution.Name,
-		Email:         institution.Email,
+		InstitutionId:      institution.InstitutionID.String(),
+		Name:               institution.Name,
+		Email:              institution.Email,
+		Verified:           institution.IsVerified(),
+		VerificationStatus: string(institution.VerificationStatus),
 	}, nil
 }
 
@@ -169,9 +202,11 @@ func (s *InstitutionServer) UpdateInstitution(ctx context.Context, req *pb.Updat
 	}
 
 	return &pb.InstitutionResponse{
-		InstitutionId: institution.InstitutionID.String(),
-		Name:          institution.Name,
-		Email:         institution.Email,
+		InstitutionId:      institution.InstitutionID.String(),
+		Name:               institution.Name,
+		Email:              institution.Email,
+		Verified:           institution.IsVerified(),
+		VerificationStatus: string(institution.VerificationStatus),
 	}, nil
 }
 
@@ -0,0 +1,175 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"institution-service/middlewares"
+	"institution-service/model"
+	pb "institution-service/pb/moderation"
+	"institution-service/queue"
+	"institution-service/usecase"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+)
+
+type IModerationHandler interface {
+	GetModerationQueue(ctx context.Context, req *pb.GetModerationQueueRequest) (*pb.GetModerationQueueResponse, error)
+	ModeratePost(ctx context.Context, req *pb.ModeratePostRequest) (*pb.ModerationResponse, error)
+	GetModerationHistory(ctx context.Context, req *pb.GetModerationHistoryRequest) (*pb.GetModerationHistoryResponse, error)
+}
+
+type ModerationServer struct {
+	pb.UnimplementedModerationServiceServer
+	moderationUsecase usecase.IModerationUsecase
+	postUsecase       usecase.IPostUsecase
+	emailPublisher    queue.IEmailPublisher
+}
+
+func NewModerationHandler(moderationUsecase usecase.IModerationUsecase, postUsecase usecase.IPostUsecase, emailPublisher queue.IEmailPublisher) *ModerationServer {
+	return &ModerationServer{
+		moderationUsecase: moderationUsecase,
+		postUsecase:       postUsecase,
+		emailPublisher:    emailPublisher,
+	}
+}
+
+func (s *ModerationServer) GetModerationQueue(ctx context.Context, req *pb.GetModerationQueueRequest) (*pb.GetModerationQueueResponse, error) {
+	if _, err := requireModerator(ctx, "see the moderation queue"); err != nil {
+		return nil, err
+	}
+
+	posts, err := s.moderationUsecase.GetModerationQueue(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "get moderation queue error: %v", err)
+	}
+
+	queued := make([]*pb.QueuedPost, 0, len(posts))
+	for i := range posts {
+		queued = append(queued, toQueuedPost(&posts[i]))
+	}
+
+	return &pb.GetModerationQueueResponse{
+		Posts: queued,
+	}, nil
+}
+
+// ModeratePost records the decision of a moderator and emails it to the
+// institution. A failed email does not undo the decision, which stays in the
+// history of the post.
+func (s *ModerationServer) ModeratePost(ctx context.Context, req *pb.ModeratePostRequest) (*pb.ModerationResponse, error) {
+	moderatorEmail, err := requireModerator(ctx, "moderate posts")
+	if err != nil {
+		return nil, err
+	}
+
+	postID, err := uuid.Parse(req.PostId)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid post ID format: %v", err)
+	}
+
+	moderation, err := s.moderationUsecase.ModeratePost(ctx, &model.PostModeration{
+		PostID:         postID,
+		Decision:       model.ModerationDecision(strings.ToUpper(req.Decision)),
+		Reason:         req.Reason,
+		ModeratorEmail: moderatorEmail,
+	})
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return nil, status.Errorf(codes.NotFound, "post not found")
+	case errors.Is(err, usecase.ErrPostTransitionRefused), errors.Is(err, model.ErrPostStatusChanged),
+		errors.Is(err, usecase.ErrInstitutionNotVerified):
+		return nil, status.Errorf(codes.FailedPrecondition, "%v", err)
+	case errors.Is(err, usecase.ErrInvalidModerationDecision), errors.Is(err, usecase.ErrModerationReasonRequired):
+		return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+	case err != nil:
+		return nil, status.Errorf(codes.Internal, "moderate post error: %v", err)
+	}
+
+	post := moderation.Post
+	if err := s.emailPublisher.PublishModerationDecision(&post.Institution, post, moderation); err != nil {
+		log.Printf("Failed to notify institution %s of the %s decision on post %s: %v", post.InstitutionID, moderation.Decision, post.PostID, err)
+	}
+
+	return toModerationResponse(moderation), nil
+}
+
+// GetModerationHistory is open to moderators and to the institution that owns
+// the post.
+func (s *ModerationServer) GetModerationHistory(ctx context.Context, req *pb.GetModerationHistoryRequest) (*pb.GetModerationHistoryResponse, error) {
+	postID, err := uuid.Parse(req.PostId)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid post ID format: %v", err)
+	}
+
+	post, err := s.postUsecase.GetPostByID(ctx, postID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, status.Errorf(codes.NotFound, "post not found")
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "get post by ID error: %v", err)
+	}
+
+	if authenticatedInstitutionID, ok := ctx.Value(middlewares.InstitutionIDKey).(string); ok {
+		if post.InstitutionID.String() != authenticatedInstitutionID {
+			return nil, status.Errorf(codes.PermissionDenied, "unauthorized access")
+		}
+	} else if _, err := requireModerator(ctx, "see the moderation history of other institutions"); err != nil {
+		return nil, err
+	}
+
+	moderations, err := s.moderationUsecase.GetModerationHistory(ctx, postID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "get moderation history error: %v", err)
+	}
+
+	res := make([]*pb.ModerationResponse, 0, len(moderations))
+	for i := range moderations {
+		res = append(res, toModerationResponse(&moderations[i]))
+	}
+
+	return &pb.GetModerationHistoryResponse{
+		Moderations: res,
+	}, nil
+}
+
+func toModerationResponse(moderation *model.PostModeration) *pb.ModerationResponse {
+	return &pb.ModerationResponse{
+		ModerationId:   moderation.ModerationID.String(),
+		PostId:         moderation.PostID.String(),
+		Decision:       string(moderation.Decision),
+		FromStatus:     string(moderation.FromStatus),
+		ToStatus:       string(moderation.ToStatus),
+		Reason:         moderation.Reason,
+		ModeratorEmail: moderation.ModeratorEmail,
+		CreatedAt:      moderation.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+func toQueuedPost(post *model.Post) *pb.QueuedPost {
+	res := &pb.QueuedPost{
+		PostId:          post.PostID.String(),
+		InstitutionId:   post.InstitutionID.String(),
+		InstitutionName: post.Institution.Name,
+		Title:           post.Title,
+		Body:            post.Body,
+		DateStart:       post.DateStart.Format("2006-01-02"),
+		DateEnd:         post.DateEnd.Format("2006-01-02"),
+		FundTarget:      &pb.Money{Amount: post.FundTarget.Amount, Currency: post.FundTarget.Currency},
+		SubmittedAt:     post.UpdatedAt.Format(time.RFC3339),
+	}
+	if post.Category != nil {
+		res.Category = post.Category.Name
+	}
+	for _, tag := range post.Tags {
+		res.Tags = append(res.Tags, tag.Name)
+	}
+
+	return res
+}
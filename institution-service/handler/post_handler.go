@@ -2,6 +2,9 @@ package handler
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	"institution-service/middlewares"
@@ -12,16 +15,18 @@ import (
 	"github.com/google/uuid"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
 )
 
 type IPostHandler interface {
 	CreatePost(ctx context.Context, req *pb.CreatePostRequest) (*pb.PostResponse, error)
 	GetAllPost(ctx context.Context, req *pb.GetAllPostRequest) (*pb.GetAllPostResponse, error)
 	GetPostByID(ctx context.Context, req *pb.GetPostByIDRequest) (*pb.PostResponse, error)
+	LookupPost(ctx context.Context, req *pb.LookupPostRequest) (*pb.PostResponse, error)
 	GetAllPostByInstitutionID(ctx context.Context, req *pb.GetAllPostByInstitutionIDRequest) (*pb.GetAllPostByInstitutionIDResponse, error)
 	UpdatePost(ctx context.Context, req *pb.UpdatePostRequest) (*pb.PostResponse, error)
 	DeletePost(ctx context.Context, req *pb.DeletePostRequest) (*pb.DeletePostResponse, error)
-	AddPostFundAchieved(ctx context.Context, req *pb.AddPostFundAchievedRequest) (*pb.AddPostFundAchievedResponse, error)
+	ChangePostStatus(ctx context.Context, req *pb.ChangePostStatusRequest) (*pb.PostResponse, error)
 }
 
 type PostServer struct {
@@ -64,16 +69,26 @@ func (s *PostServer) CreatePost(ctx context.Context, req *pb.CreatePostRequest)
 		}
 	}
 
+	fundTarget := moneyFromRequest(req.FundTargetV2, req.FundTarget)
+
+	categoryID, err := parseCategoryID(req.CategoryId)
+	if err != nil {
+		return nil, err
+	}
+
 	post := &model.Post{
-		Title:         req.Title,
-		Body:          req.Body,
-		InstitutionID: institutionID,
-		DateStart:     dateStart,
-		DateEnd:       dateEnd,
-		FundTarget:    float64(req.FundTarget),
-		FundAchieved:  0,
-		CreatedAt:     time.Now(),
-		UpdatedAt:     time.Now(),
+		Title:          req.Title,
+		Body:           req.Body,
+		InstitutionID:  institutionID,
+		DateStart:      dateStart,
+		DateEnd:        dateEnd,
+		FundTarget:     fundTarget,
+		FundAchieved:   model.NewMoney(0, fundTarget.Currency),
+		OverflowPolicy: model.OverflowPolicy(strings.ToUpper(req.OverflowPolicy)),
+		CategoryID:     categoryID,
+		Tags:           tagsFromRequest(req.Tags, false),
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
 	}
 
 	createdPost, err := s.postUsecase.CreatePost(ctx, post)
@@ -81,70 +96,85 @@ func (s *PostServer) CreatePost(ctx context.Context, req *pb.CreatePostRequest)
 		return nil, status.Errorf(codes.Internal, "create post error: %v", err)
 	}
 
-	return &pb.PostResponse{
-		PostId:       createdPost.PostID.String(),
-		Title:        createdPost.Title,
-		Body:         createdPost.Body,
-		DateStart:    createdPost.DateStart.Format("2006-01-02"),
-		DateEnd:      createdPost.DateEnd.Format("2006-01-02"),
-		FundTarget:   float32(createdPost.FundTarget),
-		FuncAchieved: float32(createdPost.FundAchieved),
-	}, nil
+	return toPostResponse(createdPost), nil
 }
 
 func (s *PostServer) GetAllPost(ctx context.Context, req *pb.GetAllPostRequest) (*pb.GetAllPostResponse, error) {
-	posts, err := s.postUsecase.GetAllPost(ctx)
+	filter, err := parsePostFilter(req)
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, "get all post error: %v", err)
+		return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+	}
+
+	page, err := s.postUsecase.GetAllPost(ctx, filter)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "get all post error: %v", err)
 	}
 
 	var postResponses []*pb.PostResponse
-	for _, post := range posts {
-		postResponses = append(postResponses, &pb.PostResponse{
-			PostId:       post.PostID.String(),
-			Title:        post.Title,
-			Body:         post.Body,
-			DateStart:    post.DateStart.Format("2006-01-02"),
-			DateEnd:      post.DateEnd.Format("2006-01-02"),
-			FundTarget:   float32(post.FundTarget),
-			FuncAchieved: float32(post.FundAchieved),
-		})
+	for _, post := range page.Posts {
+		postResponses = append(postResponses, toPostResponse(&post))
 	}
 
 	return &pb.GetAllPostResponse{
-		Posts: postResponses,
+		Posts:      postResponses,
+		NextCursor: page.NextCursor,
 	}, nil
 }
 
+// GetPostByID is public, like GetAllPost. Posts that are not public yet or
+// any more are only shown to their institution and to moderators; anyone else
+// gets NotFound, as for a post that does not exist.
 func (s *PostServer) GetPostByID(ctx context.Context, req *pb.GetPostByIDRequest) (*pb.PostResponse, error) {
-	authenticatedInstitutionID, ok := ctx.Value(middlewares.InstitutionIDKey).(string)
-	if !ok {
-		return nil, status.Errorf(codes.Internal, "failed to get authenticated institution ID from context")
-	}
-
 	postID, err := uuid.Parse(req.PostId)
 	if err != nil {
 		return nil, status.Errorf(codes.InvalidArgument, "invalid post ID format: %v", err)
 	}
 
 	post, err := s.postUsecase.GetPostByID(ctx, postID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, status.Errorf(codes.NotFound, "post not found")
+	}
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "get post by ID error: %v", err)
 	}
+	if !post.Status.IsPublic() && !canViewPost(ctx, post) {
+		return nil, status.Errorf(codes.NotFound, "post not found")
+	}
 
-	if post.InstitutionID.String() != authenticatedInstitutionID {
-		return nil, status.Errorf(codes.PermissionDenied, "unauthorized access")
+	return toPostResponse(post), nil
+}
+
+// canViewPost reports whether the caller owns post or moderates posts.
+func canViewPost(ctx context.Context, post *model.Post) bool {
+	if institutionID, ok := ctx.Value(middlewares.InstitutionIDKey).(string); ok && institutionID == post.InstitutionID.String() {
+		return true
 	}
+	_, err := requireModerator(ctx, "view posts")
 
-	return &pb.PostResponse{
-		PostId:       post.PostID.String(),
-		Title:        post.Title,
-		Body:         post.Body,
-		DateStart:    post.DateStart.Format("2006-01-02"),
-		DateEnd:      post.DateEnd.Format("2006-01-02"),
-		FundTarget:   float32(post.FundTarget),
-		FuncAchieved: float32(post.FundAchieved),
-	}, nil
+	return err == nil
+}
+
+// LookupPost serves other services, which need posts in any status and still
+// refund the donations of deleted ones.
+func (s *PostServer) LookupPost(ctx context.Context, req *pb.LookupPostRequest) (*pb.PostResponse, error) {
+	if err := requireService(ctx, "look up posts"); err != nil {
+		return nil, err
+	}
+
+	postID, err := uuid.Parse(req.PostId)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid post ID format: %v", err)
+	}
+
+	post, err := s.postUsecase.LookupPost(ctx, postID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, status.Errorf(codes.NotFound, "post not found")
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "look up post error: %v", err)
+	}
+
+	return toPostResponse(post), nil
 }
 
 func (s *PostServer) GetAllPostByInstitutionID(ctx context.Context, req *pb.GetAllPostByInstitutionIDRequest) (*pb.GetAllPostByInstitutionIDResponse, error) {
@@ -169,15 +199,7 @@ func (s *PostServer) GetAllPostByInstitutionID(ctx context.Context, req *pb.GetA
 
 	var postResponses []*pb.PostResponse
 	for _, post := range posts {
-		postResponses = append(postResponses, &pb.PostResponse{
-			PostId:       post.PostID.String(),
-			Title:        post.Title,
-			Body:         post.Body,
-			DateStart:    post.DateStart.Format("2006-01-02"),
-			DateEnd:      post.DateEnd.Format("2006-01-02"),
-			FundTarget:   float32(post.FundTarget),
-			FuncAchieved: float32(post.FundAchieved),
-		})
+		postResponses = append(postResponses, toPostResponse(&post))
 	}
 
 	return &pb.GetAllPostByInstitutionIDResponse{
@@ -241,32 +263,34 @@ func (s *PostServer) UpdatePost(ctx context.Context, req *pb.UpdatePostRequest)
 		return nil, status.Errorf(codes.InvalidArgument, "fundraising period cannot be more than 6 months")
 	}
 
+	categoryID, err := parseCategoryID(req.CategoryId)
+	if err != nil {
+		return nil, err
+	}
+
 	post := &model.Post{
-		PostID:        postID,
-		Title:         req.Title,
-		Body:          req.Body,
-		InstitutionID: institutionID,
-		DateStart:     dateStart,
-		DateEnd:       dateEnd,
-		FundTarget:    float64(req.FundTarget),
-		FundAchieved:  0,
-		UpdatedAt:     time.Now(),
+		PostID:         postID,
+		Title:          req.Title,
+		Body:           req.Body,
+		InstitutionID:  institutionID,
+		DateStart:      dateStart,
+		DateEnd:        dateEnd,
+		FundTarget:     moneyFromRequest(req.FundTargetV2, req.FundTarget),
+		OverflowPolicy: model.OverflowPolicy(strings.ToUpper(req.OverflowPolicy)),
+		CategoryID:     categoryID,
+		Tags:           tagsFromRequest(req.Tags, req.ClearTags),
+		UpdatedAt:      time.Now(),
 	}
 
 	updatedPost, err := s.postUsecase.UpdatePost(ctx, post)
+	if errors.Is(err, usecase.ErrPostNotEditable) || errors.Is(err, model.ErrPostStatusChanged) {
+		return nil, status.Errorf(codes.FailedPrecondition, "%v", err)
+	}
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "update post error: %v", err)
 	}
 
-	return &pb.PostResponse{
-		PostId:       updatedPost.PostID.String(),
-		Title:        updatedPost.Title,
-		Body:         updatedPost.Body,
-		DateStart:    updatedPost.DateStart.Format("2006-01-02"),
-		DateEnd:      updatedPost.DateEnd.Format("2006-01-02"),
-		FundTarget:   float32(updatedPost.FundTarget),
-		FuncAchieved: float32(updatedPost.FundAchieved),
-	}, nil
+	return toPostResponse(updatedPost), nil
 }
 
 func (s *PostServer) DeletePost(ctx context.Context, req *pb.DeletePostRequest) (*pb.DeletePostResponse, error) {
@@ -299,21 +323,190 @@ func (s *PostServer) DeletePost(ctx context.Context, req *pb.DeletePostRequest)
 	}, nil
 }
 
-func (s *PostServer) AddPostFundAchieved(ctx context.Context, req *pb.AddPostFundAchievedRequest) (*pb.AddPostFundAchievedResponse, error) {
+// ChangePostStatus acts for the institution that owns the post, or for an
+// admin when called with an admin user token. Submitted posts are reviewed
+// through the ModerationService instead.
+func (s *PostServer) ChangePostStatus(ctx context.Context, req *pb.ChangePostStatusRequest) (*pb.PostResponse, error) {
 	postID, err := uuid.Parse(req.PostId)
 	if err != nil {
 		return nil, status.Errorf(codes.InvalidArgument, "invalid post ID format: %v", err)
 	}
 
-	amount := float64(req.Amount)
+	post, err := s.postUsecase.GetPostByID(ctx, postID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, status.Errorf(codes.NotFound, "post not found")
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "get post by ID error: %v", err)
+	}
+
+	actor := model.PostActorInstitution
+	if authenticatedInstitutionID, ok := ctx.Value(middlewares.InstitutionIDKey).(string); ok {
+		if post.InstitutionID.String() != authenticatedInstitutionID {
+			return nil, status.Errorf(codes.PermissionDenied, "unauthorized access")
+		}
+	} else {
+		if err := requireAdmin(ctx, "change the status of posts of other institutions"); err != nil {
+			return nil, err
+		}
+		actor = model.PostActorAdmin
+	}
+
+	updatedPost, err := s.postUsecase.ChangePostStatus(ctx, postID, model.PostStatus(strings.ToUpper(req.Status)), actor)
+	switch {
+	case errors.Is(err, usecase.ErrInvalidPostStatus):
+		return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+	case errors.Is(err, usecase.ErrPostTransitionRefused), errors.Is(err, model.ErrPostStatusChanged),
+		errors.Is(err, usecase.ErrInstitutionNotVerified):
+		return nil, status.Errorf(codes.FailedPrecondition, "%v", err)
+	case err != nil:
+		return nil, status.Errorf(codes.Internal, "change post status error: %v", err)
+	}
 
-	post, err := s.postUsecase.AddPostFundAchieved(ctx, postID, amount)
+	return toPostResponse(updatedPost), nil
+}
+
+func toPostResponse(post *model.Post) *pb.PostResponse {
+	res := &pb.PostResponse{
+		PostId:         post.PostID.String(),
+		Title:          post.Title,
+		Body:           post.Body,
+		DateStart:      post.DateStart.Format("2006-01-02"),
+		DateEnd:        post.DateEnd.Format("2006-01-02"),
+		FundTarget:     float32(post.FundTarget.Major()),
+		FuncAchieved:   float32(post.FundAchieved.Major()),
+		OverflowPolicy: string(post.OverflowPolicy),
+		FundTargetV2:   toMoneyResponse(post.FundTarget),
+		FundAchievedV2: toMoneyResponse(post.FundAchieved),
+		InstitutionId:  post.InstitutionID.String(),
+		Status:         string(post.Status),
+		Deleted:        post.IsDeleted(),
+	}
+	if post.Category != nil {
+		res.Category = &pb.PostCategory{
+			CategoryId: post.Category.CategoryID.String(),
+			Slug:       post.Category.Slug,
+			Name:       post.Category.Name,
+		}
+	}
+	for _, tag := range post.Tags {
+		res.Tags = append(res.Tags, tag.Name)
+	}
+
+	return res
+}
+
+// parseCategoryID reads the optional category of a post request.
+func parseCategoryID(value string) (*uuid.UUID, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	categoryID, err := uuid.Parse(value)
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, "add post fund achieved error: %v", err)
+		return nil, status.Errorf(codes.InvalidArgument, "invalid category ID format: %v", err)
 	}
 
-	return &pb.AddPostFundAchievedResponse{
-		PostId:       post.PostID.String(),
-		FuncAchieved: float32(post.FundAchieved),
-	}, nil
+	return &categoryID, nil
+}
+
+// tagsFromRequest returns nil, keeping the tags of a post as they are, unless
+// tags are given or clear is set.
+func tagsFromRequest(names []string, clear bool) []model.Tag {
+	if len(names) == 0 && !clear {
+		return nil
+	}
+
+	tags := make([]model.Tag, 0, len(names))
+	for _, name := range names {
+		tags = append(tags, model.Tag{Name: name})
+	}
+
+	return tags
+}
+
+func parsePostFilter(req *pb.GetAllPostRequest) (model.PostFilter, error) {
+	filter := model.PostFilter{
+		Query:  strings.TrimSpace(req.Query),
+		Status: model.CampaignStatus(strings.ToUpper(req.Status)),
+		Sort:   model.PostSort(strings.ToUpper(req.Sort)),
+		Tags:   req.Tags,
+		Limit:  int(req.Limit),
+	}
+
+	if category := strings.TrimSpace(req.Category); category != "" {
+		if categoryID, err := uuid.Parse(category); err == nil {
+			filter.CategoryID = categoryID
+		} else {
+			filter.CategorySlug = strings.ToLower(category)
+		}
+	}
+
+	var err error
+	if req.InstitutionId != "" {
+		if filter.InstitutionID, err = uuid.Parse(req.InstitutionId); err != nil {
+			return filter, fmt.Errorf("invalid institution ID format: %v", err)
+		}
+	}
+	if req.DateFrom != "" {
+		if filter.DateFrom, err = parseFilterDate(req.DateFrom, false); err != nil {
+			return filter, fmt.Errorf("invalid date_from format, expected YYYY-MM-DD or RFC3339: %v", err)
+		}
+	}
+	if req.DateTo != "" {
+		if filter.DateTo, err = parseFilterDate(req.DateTo, true); err != nil {
+			return filter, fmt.Errorf("invalid date_to format, expected YYYY-MM-DD or RFC3339: %v", err)
+		}
+	}
+	if req.TargetMin != nil {
+		target := model.NewMoney(req.TargetMin.Amount, req.TargetMin.Currency)
+		filter.TargetMin = &target
+	}
+	if req.TargetMax != nil {
+		target := model.NewMoney(req.TargetMax.Amount, req.TargetMax.Currency)
+		filter.TargetMax = &target
+	}
+	if req.Cursor != "" {
+		if filter.Cursor, err = model.DecodePostCursor(req.Cursor); err != nil {
+			return filter, err
+		}
+	}
+
+	return filter, nil
+}
+
+// parseFilterDate accepts RFC3339 or YYYY-MM-DD. A plain date used as the end
+// of a range includes that whole day.
+func parseFilterDate(value string, endOfRange bool) (time.Time, error) {
+	date, err := time.Parse(time.RFC3339, value)
+	if err == nil {
+		return date, nil
+	}
+
+	date, err = time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if endOfRange {
+		date = date.AddDate(0, 0, 1)
+	}
+
+	return date, nil
+}
+
+// moneyFromRequest prefers the Money field of a request and falls back to the
+// deprecated float field, which is always in IDR.
+func moneyFromRequest(amount *pb.Money, legacy float32) model.Money {
+	if amount != nil {
+		return model.NewMoney(amount.Amount, amount.Currency)
+	}
+
+	return model.MoneyFromMajor(float64(legacy), model.CurrencyIDR)
+}
+
+func toMoneyResponse(amount model.Money) *pb.Money {
+	return &pb.Money{
+		Amount:   amount.Amount,
+		Currency: amount.Currency,
+	}
 }
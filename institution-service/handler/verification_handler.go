@@ -0,0 +1,232 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"institution-service/middlewares"
+	"institution-service/model"
+	pb "institution-service/pb/verification"
+	"institution-service/storage"
+	"institution-service/usecase"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+)
+
+type IVerificationHandler interface {
+	UploadDocument(ctx context.Context, req *pb.UploadDocumentRequest) (*pb.DocumentResponse, error)
+	GetDocuments(ctx context.Context, req *pb.GetDocumentsRequest) (*pb.GetDocumentsResponse, error)
+	GetDocumentContent(ctx context.Context, req *pb.GetDocumentContentRequest) (*pb.DocumentContentResponse, error)
+	SubmitVerification(ctx context.Context, req *pb.SubmitVerificationRequest) (*pb.VerificationResponse, error)
+	GetPendingVerifications(ctx context.Context, req *pb.GetPendingVerificationsRequest) (*pb.GetPendingVerificationsResponse, error)
+	ReviewVerification(ctx context.Context, req *pb.ReviewVerificationRequest) (*pb.VerificationResponse, error)
+}
+
+type VerificationServer struct {
+	pb.UnimplementedVerificationServiceServer
+	verificationUsecase usecase.IVerificationUsecase
+}
+
+func NewVerificationHandler(verificationUsecase usecase.IVerificationUsecase) *VerificationServer {
+	return &VerificationServer{
+		verificationUsecase: verificationUsecase,
+	}
+}
+
+func (s *VerificationServer) UploadDocument(ctx context.Context, req *pb.UploadDocumentRequest) (*pb.DocumentResponse, error) {
+	institutionID, err := authenticatedInstitution(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	document, err := s.verificationUsecase.UploadDocument(ctx, &model.InstitutionDocument{
+		InstitutionID: institutionID,
+		Type:          model.DocumentType(strings.ToUpper(req.Type)),
+		Filename:      req.Filename,
+	}, req.Content)
+	if err != nil {
+		return nil, verificationError("upload document error", err)
+	}
+
+	return toDocumentResponse(document), nil
+}
+
+func (s *VerificationServer) GetDocuments(ctx context.Context, req *pb.GetDocumentsRequest) (*pb.GetDocumentsResponse, error) {
+	institutionID, err := documentOwner(ctx, req.InstitutionId)
+	if err != nil {
+		return nil, err
+	}
+
+	documents, err := s.verificationUsecase.GetDocuments(ctx, institutionID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "get documents error: %v", err)
+	}
+
+	res := make([]*pb.DocumentResponse, 0, len(documents))
+	for i := range documents {
+		res = append(res, toDocumentResponse(&documents[i]))
+	}
+
+	return &pb.GetDocumentsResponse{
+		Documents: res,
+	}, nil
+}
+
+func (s *VerificationServer) GetDocumentContent(ctx context.Context, req *pb.GetDocumentContentRequest) (*pb.DocumentContentResponse, error) {
+	institutionID, err := documentOwner(ctx, req.InstitutionId)
+	if err != nil {
+		return nil, err
+	}
+
+	documentID, err := uuid.Parse(req.DocumentId)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid document ID format: %v", err)
+	}
+
+	document, content, err := s.verificationUsecase.GetDocumentContent(ctx, institutionID, documentID)
+	if errors.Is(err, gorm.ErrRecordNotFound) || errors.Is(err, storage.ErrNotFound) {
+		return nil, status.Errorf(codes.NotFound, "document not found")
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "get document error: %v", err)
+	}
+
+	return &pb.DocumentContentResponse{
+		Filename:    document.Filename,
+		ContentType: document.ContentType,
+		Content:     content,
+	}, nil
+}
+
+func (s *VerificationServer) SubmitVerification(ctx context.Context, req *pb.SubmitVerificationRequest) (*pb.VerificationResponse, error) {
+	institutionID, err := authenticatedInstitution(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	institution, err := s.verificationUsecase.SubmitVerification(ctx, institutionID)
+	if err != nil {
+		return nil, verificationError("submit verification error", err)
+	}
+
+	return toVerificationResponse(institution), nil
+}
+
+func (s *VerificationServer) GetPendingVerifications(ctx context.Context, req *pb.GetPendingVerificationsRequest) (*pb.GetPendingVerificationsResponse, error) {
+	if err := requireAdmin(ctx, "review verifications"); err != nil {
+		return nil, err
+	}
+
+	institutions, err := s.verificationUsecase.GetPendingVerifications(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "get pending verifications error: %v", err)
+	}
+
+	res := make([]*pb.VerificationResponse, 0, len(institutions))
+	for i := range institutions {
+		res = append(res, toVerificationResponse(&institutions[i]))
+	}
+
+	return &pb.GetPendingVerificationsResponse{
+		Institutions: res,
+	}, nil
+}
+
+func (s *VerificationServer) ReviewVerification(ctx context.Context, req *pb.ReviewVerificationRequest) (*pb.VerificationResponse, error) {
+	if err := requireAdmin(ctx, "review verifications"); err != nil {
+		return nil, err
+	}
+
+	institutionID, err := uuid.Parse(req.InstitutionId)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid institution ID format: %v", err)
+	}
+
+	institution, err := s.verificationUsecase.ReviewVerification(ctx, institutionID,
+		model.VerificationDecision(strings.ToUpper(req.Decision)), req.Reason)
+	if err != nil {
+		return nil, verificationError("review verification error", err)
+	}
+
+	return toVerificationResponse(institution), nil
+}
+
+// authenticatedInstitution returns the institution of an institution token.
+func authenticatedInstitution(ctx context.Context) (uuid.UUID, error) {
+	authenticatedInstitutionID, ok := ctx.Value(middlewares.InstitutionIDKey).(string)
+	if !ok {
+		return uuid.Nil, status.Errorf(codes.PermissionDenied, "only institutions can manage their verification")
+	}
+
+	institutionID, err := uuid.Parse(authenticatedInstitutionID)
+	if err != nil {
+		return uuid.Nil, status.Errorf(codes.Internal, "failed to parse authenticated institution ID: %v", err)
+	}
+
+	return institutionID, nil
+}
+
+// documentOwner returns whose documents are asked for: the authenticated
+// institution's own, or those of institution_id for admins.
+func documentOwner(ctx context.Context, institutionID string) (uuid.UUID, error) {
+	if authenticatedInstitutionID, ok := ctx.Value(middlewares.InstitutionIDKey).(string); ok {
+		if institutionID != "" && institutionID != authenticatedInstitutionID {
+			return uuid.Nil, status.Errorf(codes.PermissionDenied, "unauthorized access")
+		}
+		return authenticatedInstitution(ctx)
+	}
+
+	if err := requireAdmin(ctx, "see documents of other institutions"); err != nil {
+		return uuid.Nil, err
+	}
+
+	id, err := uuid.Parse(institutionID)
+	if err != nil {
+		return uuid.Nil, status.Errorf(codes.InvalidArgument, "invalid institution ID format: %v", err)
+	}
+
+	return id, nil
+}
+
+// verificationError maps the errors of uploading, submitting and reviewing
+// documents to gRPC codes.
+func verificationError(message string, err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return status.Errorf(codes.NotFound, "institution not found")
+	case errors.Is(err, usecase.ErrInvalidDocument),
+		errors.Is(err, usecase.ErrInvalidVerificationDecision),
+		errors.Is(err, usecase.ErrVerificationReasonRequired):
+		return status.Errorf(codes.InvalidArgument, "%v", err)
+	case errors.Is(err, usecase.ErrVerificationRefused), errors.Is(err, model.ErrVerificationStatusChanged):
+		return status.Errorf(codes.FailedPrecondition, "%v", err)
+	}
+
+	return status.Errorf(codes.Internal, "%s: %v", message, err)
+}
+
+func toDocumentResponse(document *model.InstitutionDocument) *pb.DocumentResponse {
+	return &pb.DocumentResponse{
+		DocumentId:  document.DocumentID.String(),
+		Type:        string(document.Type),
+		Filename:    document.Filename,
+		ContentType: document.ContentType,
+		Size:        document.Size,
+		CreatedAt:   document.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+func toVerificationResponse(institution *model.Institution) *pb.VerificationResponse {
+	return &pb.VerificationResponse{
+		InstitutionId:      institution.InstitutionID.String(),
+		Name:               institution.Name,
+		VerificationStatus: string(institution.VerificationStatus),
+		VerificationNote:   institution.VerificationNote,
+		Verified:           institution.IsVerified(),
+	}
+}
@@ -14,11 +14,16 @@ import (
 	"institution-service/handler"
 	"institution-service/middlewares"
 	"institution-service/model"
+	"institution-service/pb/category"
 	"institution-service/pb/fund_collect"
 	"institution-service/pb/institution"
+	"institution-service/pb/moderation"
 	"institution-service/pb/post"
+	"institution-service/pb/verification"
+	"institution-service/queue"
 	"institution-service/repository"
 	"institution-service/routes"
+	"institution-service/storage"
 	"institution-service/usecase"
 
 	"github.com/labstack/echo/v4"
@@ -63,15 +68,80 @@ func main() {
 	if err := db.AutoMigrate(&model.Institution{}); err != nil {
 		logger.Fatalf("Failed to migrate Institution table: %v", err)
 	}
+	if err := db.AutoMigrate(&model.InstitutionDocument{}); err != nil {
+		logger.Fatalf("Failed to migrate InstitutionDocument table: %v", err)
+	}
+	if err := db.AutoMigrate(&model.Category{}, &model.Tag{}); err != nil {
+		logger.Fatalf("Failed to migrate Category and Tag tables: %v", err)
+	}
+	if err := db.SetupJoinTable(&model.Post{}, "Tags", &model.PostTag{}); err != nil {
+		logger.Fatalf("Failed to set up post tags join table: %v", err)
+	}
 	if err := db.AutoMigrate(&model.Post{}); err != nil {
 		logger.Fatalf("Failed to migrate Post table: %v", err)
 	}
+	if err := db.AutoMigrate(&model.PostModeration{}); err != nil {
+		logger.Fatalf("Failed to migrate PostModeration table: %v", err)
+	}
+	deduped, err := database.DedupeFundCollects(db)
+	if err != nil {
+		logger.Fatalf("Failed to dedupe fund collects: %v", err)
+	}
+	if deduped > 0 {
+		logger.Infof("Deleted %d duplicate fund collects and recomputed their posts' fund achieved", deduped)
+	}
 	if err := db.AutoMigrate(&model.FundCollect{}); err != nil {
 		logger.Fatalf("Failed to migrate FundCollect table: %v", err)
 	}
+	if err := db.AutoMigrate(&model.FundCollectRefund{}); err != nil {
+		logger.Fatalf("Failed to migrate FundCollectRefund table: %v", err)
+	}
+	if err := database.BackfillMoney(db); err != nil {
+		logger.Fatalf("Failed to backfill minor-unit amounts: %v", err)
+	}
+	if err := database.AnonymizeDonorEmails(db); err != nil {
+		logger.Fatalf("Failed to anonymize donor emails: %v", err)
+	}
+	if err := database.AddPostSearchVector(db); err != nil {
+		logger.Fatalf("Failed to add post search vector: %v", err)
+	}
+	if err := database.BackfillPostStatus(db); err != nil {
+		logger.Fatalf("Failed to backfill post status: %v", err)
+	}
+	if err := database.SeedCategories(db); err != nil {
+		logger.Fatalf("Failed to seed categories: %v", err)
+	}
 
 	fmt.Println("Database migrated successfully!")
 
+	documentStorage, err := storage.NewStorage()
+	if err != nil {
+		logger.Fatalf("Failed to initialize document storage: %v", err)
+	}
+
+	var emailPublisher queue.IEmailPublisher = queue.LogEmailPublisher{}
+	mqConn, err := database.InitRabbitMQ()
+	if err != nil {
+		logger.Fatalf("Failed to initialize RabbitMQ: %v", err)
+	}
+	if mqConn != nil {
+		defer mqConn.Close()
+
+		fundCollectUsecase := usecase.NewFundCollectUsecase(repository.NewFundCollectRepository(db))
+		go queue.StartDonationConsumer(mqConn, fundCollectUsecase, logger)
+
+		emailChannel, err := mqConn.Channel()
+		if err != nil {
+			logger.Fatalf("Failed to open RabbitMQ channel for emails: %v", err)
+		}
+		emailPublisher, err = queue.NewEmailPublisher(emailChannel, queue.EmailQueue)
+		if err != nil {
+			logger.Fatalf("Failed to declare email queue: %v", err)
+		}
+	} else {
+		logger.Warn("MQHOST is not set, donation events will not be consumed and email notifications will only be logged")
+	}
+
 	sigChan := make(chan os.Signal, 1)
 	errChan := make(chan error, 1)
 	quitChan := make(chan bool, 1)
@@ -105,7 +175,7 @@ func main() {
 	}
 
 	go InitHTTPServer(errChan, port, grpcEndpoint, grpcPort)
-	go InitGRPCServer(db, errChan, grpcEndpoint, grpcPort)
+	go InitGRPCServer(db, emailPublisher, documentStorage, errChan, grpcEndpoint, grpcPort)
 
 	<-quitChan
 	logger.Info("Shutting down...")
@@ -132,6 +202,9 @@ func InitHTTPServer(errChan chan error, port, grpcEndpoint, grpcPort string) {
 	insClient := institution.NewInstitutionServiceClient(conn)
 	postClient := post.NewPostServiceClient(conn)
 	fundClient := fund_collect.NewFundCollectServiceClient(conn)
+	categoryClient := category.NewCategoryServiceClient(conn)
+	moderationClient := moderation.NewModerationServiceClient(conn)
+	verificationClient := verification.NewVerificationServiceClient(conn)
 
 	e := echo.New()
 
@@ -160,11 +233,20 @@ func InitHTTPServer(errChan chan error, port, grpcEndpoint, grpcPort string) {
 	fundCollectRoutes := routes.NewFundCollectHTTPHandler(fundClient)
 	fundCollectRoutes.Routes(e)
 
+	categoryRoutes := routes.NewCategoryHTTPHandler(categoryClient)
+	categoryRoutes.Routes(e)
+
+	moderationRoutes := routes.NewModerationHTTPHandler(moderationClient)
+	moderationRoutes.Routes(e)
+
+	verificationRoutes := routes.NewVerificationHTTPHandler(verificationClient)
+	verificationRoutes.Routes(e)
+
 	log.Info("Starting HTTP Server at port: ", port)
 	errChan <- e.Start(":" + port)
 }
 
-func InitGRPCServer(db *gorm.DB, errChan chan error, grpcEndpoint, grpcPort string) {
+func InitGRPCServer(db *gorm.DB, emailPublisher queue.IEmailPublisher, documentStorage storage.IStorage, errChan chan error, grpcEndpoint, grpcPort string) {
 	listener, err := net.Listen("tcp", fmt.Sprintf("%s:%s", grpcEndpoint, grpcPort))
 	if err != nil {
 		panic(err)
@@ -182,19 +264,34 @@ func InitGRPCServer(db *gorm.DB, errChan chan error, grpcEndpoint, grpcPort stri
 	insUsecase := usecase.NewInstitutionUsecase(insRepo)
 	insHandler := handler.NewInstitutionHandler(insUsecase)
 
+	categoryRepo := repository.NewCategoryRepository(db)
+	categoryUsecase := usecase.NewCategoryUsecase(categoryRepo)
+	categoryHandler := handler.NewCategoryHandler(categoryUsecase)
+
 	postRepo := repository.NewPostRepository(db)
-	postUsecase := usecase.NewPostUsecase(postRepo)
+	postUsecase := usecase.NewPostUsecase(postRepo, categoryRepo, insRepo)
 	postHandler := handler.NewPostHandler(postUsecase)
 
 	fundCollectRepo := repository.NewFundCollectRepository(db)
 	fundCollectUsecase := usecase.NewFundCollectUsecase(fundCollectRepo)
 	fundCollectHandler := handler.NewFundCollectHandler(fundCollectUsecase, postUsecase)
 
+	moderationRepo := repository.NewModerationRepository(db)
+	moderationUsecase := usecase.NewModerationUsecase(moderationRepo, postRepo, insRepo)
+	moderationHandler := handler.NewModerationHandler(moderationUsecase, postUsecase, emailPublisher)
+
+	verificationRepo := repository.NewVerificationRepository(db)
+	verificationUsecase := usecase.NewVerificationUsecase(verificationRepo, insRepo, documentStorage)
+	verificationHandler := handler.NewVerificationHandler(verificationUsecase)
+
 	grpcServer := grpc.NewServer(opts...)
 
 	institution.RegisterInstitutionServiceServer(grpcServer, insHandler)
 	post.RegisterPostServiceServer(grpcServer, postHandler)
 	fund_collect.RegisterFundCollectServiceServer(grpcServer, fundCollectHandler)
+	category.RegisterCategoryServiceServer(grpcServer, categoryHandler)
+	moderation.RegisterModerationServiceServer(grpcServer, moderationHandler)
+	verification.RegisterVerificationServiceServer(grpcServer, verificationHandler)
 
 	log.Info("Starting gRPC Server at", grpcEndpoint, ":", grpcPort)
 	if err := grpcServer.Serve(listener); err != nil {
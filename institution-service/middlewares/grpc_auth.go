@@ -16,17 +16,27 @@ type contextKey string
 
 const (
 	InstitutionIDKey contextKey = "institution_id"
+	EmailKey         contextKey = "email"
+	ServiceKey       contextKey = "service"
 )
 
 var publicEndpoints = map[string]bool{
-	"/institution.InstitutionService/RegisterInstitution": true,
-	"/institution.InstitutionService/LoginInstitution":    true,
-	"/fund_collect.FundCollectService/CreateFundCollect":  true,
-	"/post.PostService/GetAllPost":                        true,
+	"/institution.InstitutionService/RegisterInstitution":  true,
+	"/institution.InstitutionService/LoginInstitution":     true,
+	"/institution.InstitutionService/GetPublicInstitution": true,
+	"/fund_collect.FundCollectService/GetPostSupporters":   true,
+	"/post.PostService/GetAllPost":                         true,
+	"/post.PostService/GetPostByID":                        true,
+	"/category.CategoryService/GetAllCategories":           true,
 }
 
+// SelectiveAuthInterceptor lets anyone call the public endpoints, still
+// passing them the identity of callers that send a valid token.
 func SelectiveAuthInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
 	if publicEndpoints[info.FullMethod] {
+		if authCtx, err := authenticate(ctx); err == nil {
+			ctx = authCtx
+		}
 		return handler(ctx, req)
 	}
 
@@ -34,6 +44,16 @@ func SelectiveAuthInterceptor(ctx context.Context, req interface{}, info *grpc.U
 }
 
 func AuthGRPCInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	ctx, err := authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return handler(ctx, req)
+}
+
+// authenticate returns ctx with the identity carried by its bearer token.
+func authenticate(ctx context.Context) (context.Context, error) {
 	md, ok := metadata.FromIncomingContext(ctx)
 	if !ok {
 		return nil, status.Errorf(codes.Unauthenticated, "missing metadata")
@@ -55,8 +75,20 @@ func AuthGRPCInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryS
 		return nil, status.Errorf(codes.Unauthenticated, "invalid token: %v", err)
 	}
 
-	institutionID := (*claims)["institution_id"].(string)
-	newCtx := context.WithValue(ctx, InstitutionIDKey, institutionID)
+	// Other services sign their calls with a service claim naming them.
+	if service, ok := (*claims)["service"].(string); ok && service != "" {
+		return context.WithValue(ctx, ServiceKey, service), nil
+	}
+
+	if institutionID, ok := (*claims)["institution_id"].(string); ok && institutionID != "" {
+		return context.WithValue(ctx, InstitutionIDKey, institutionID), nil
+	}
+
+	// User tokens, signed with the same secret, carry an email instead. Only
+	// the admin endpoints accept them.
+	if email, ok := (*claims)["email"].(string); ok && email != "" {
+		return context.WithValue(ctx, EmailKey, email), nil
+	}
 
-	return handler(newCtx, req)
+	return nil, status.Errorf(codes.Unauthenticated, "invalid token: missing institution_id, email or service claim")
 }
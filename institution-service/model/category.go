@@ -0,0 +1,105 @@
+package model
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Category is a cause posts are filed under, such as scholarships or school
+// infrastructure. Only admins manage categories.
+type Category struct {
+	CategoryID  uuid.UUID `json:"category_id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	Slug        string    `json:"slug" gorm:"type:varchar(64); not null; uniqueIndex"`
+	Name        string    `json:"name" gorm:"type:varchar(100); not null"`
+	Description string    `json:"description" gorm:"type:text"`
+	CreatedAt   time.Time `json:"created_at" gorm:"type:timestamp; not null; autoCreateTime"`
+	UpdatedAt   time.Time `json:"updated_at" gorm:"type:timestamp; not null; autoUpdateTime"`
+}
+
+var (
+	slugPattern   = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+	slugSeparator = regexp.MustCompile(`[^a-z0-9]+`)
+)
+
+// Slugify turns a category name into its URL slug, e.g. "Teacher Training"
+// into "teacher-training".
+func Slugify(name string) string {
+	return strings.Trim(slugSeparator.ReplaceAllString(strings.ToLower(name), "-"), "-")
+}
+
+func IsValidSlug(slug string) bool {
+	return len(slug) <= 64 && slugPattern.MatchString(slug)
+}
+
+// Tag is a free-form label institutions attach to their posts. Names are
+// kept normalized, see NormalizeTagName, so "Papua" and "papua " are one tag.
+type Tag struct {
+	TagID uuid.UUID `json:"tag_id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	Name  string    `json:"name" gorm:"type:varchar(30); not null; uniqueIndex"`
+}
+
+// PostTag links a post to one of its tags.
+type PostTag struct {
+	PostID uuid.UUID `gorm:"type:uuid;primaryKey"`
+	TagID  uuid.UUID `gorm:"type:uuid;primaryKey"`
+}
+
+const (
+	MaxPostTags      = 10
+	MaxTagNameLength = 30
+)
+
+// NormalizeTagName lowercases name and collapses its whitespace.
+func NormalizeTagName(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
+
+// CategoryStat is one row of the per-category aggregates, for the posts of a
+// category raised in one currency.
+type CategoryStat struct {
+	CategoryID        uuid.UUID
+	Currency          string
+	PostCount         int64
+	ActivePostCount   int64
+	FundTargetMinor   int64
+	FundAchievedMinor int64
+}
+
+// CategorySummary is a category with the totals of its posts, for browse
+// pages. FundTarget and FundAchieved hold one sum per currency.
+type CategorySummary struct {
+	Category        Category
+	PostCount       int64
+	ActivePostCount int64
+	FundTarget      []Money
+	FundAchieved    []Money
+}
+
+type CategoryRequest struct {
+	// Slug defaults to the name in lowercase with dashes.
+	Slug        string `json:"slug"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type CategoryResponse struct {
+	CategoryID      string  `json:"category_id"`
+	Slug            string  `json:"slug"`
+	Name            string  `json:"name"`
+	Description     string  `json:"description"`
+	PostCount       int64   `json:"post_count"`
+	ActivePostCount int64   `json:"active_post_count"`
+	FundTarget      []Money `json:"fund_target"`
+	FundAchieved    []Money `json:"fund_achieved"`
+}
+
+type CategoryListResponse struct {
+	Categories []CategoryResponse `json:"categories"`
+}
+
+type CategoryDeleteResponse struct {
+	Message string `json:"message"`
+}
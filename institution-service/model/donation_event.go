@@ -0,0 +1,35 @@
+package model
+
+import "time"
+
+const (
+	EventTypeDonationSettled  = "DonationSettled"
+	EventTypeDonationRefunded = "DonationRefunded"
+)
+
+// DonationSettledEvent is published by transaction-service when a donation is
+// paid. It may be delivered more than once, so it is applied once per
+// TransactionID.
+type DonationSettledEvent struct {
+	EventID        string    `json:"event_id"`
+	TransactionID  string    `json:"transaction_id"`
+	PostID         string    `json:"post_id"`
+	UserID         string    `json:"user_id"`
+	UserName       string    `json:"user_name"`
+	Amount         Money     `json:"amount"`
+	OriginalAmount *Money    `json:"original_amount,omitempty"`
+	Message        string    `json:"message,omitempty"`
+	PaidAt         time.Time `json:"paid_at"`
+}
+
+// DonationRefundedEvent is published by transaction-service when part or all
+// of a paid donation is refunded. Amount is in the post's currency and is
+// applied once per RefundID.
+type DonationRefundedEvent struct {
+	EventID       string    `json:"event_id"`
+	TransactionID string    `json:"transaction_id"`
+	PostID        string    `json:"post_id"`
+	RefundID      string    `json:"refund_id"`
+	Amount        Money     `json:"amount"`
+	RefundedAt    time.Time `json:"refunded_at"`
+}
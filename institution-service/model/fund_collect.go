@@ -7,31 +7,91 @@ import (
 	"gorm.io/gorm"
 )
 
+// AnonymousDonorName replaces the name of donors who chose not to be named,
+// and the email addresses older donations were recorded under.
+const AnonymousDonorName = "Anonymous"
+
 type FundCollect struct {
-	FundCollectID uuid.UUID      `json:"fund_collect_id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
-	PostID        uuid.UUID      `json:"post_id" gorm:"type:uuid; not null"`
-	UserID        string         `json:"user_id" gorm:"type:varchar(255); not null"`
-	UserName      string         `json:"user_name" gorm:"type:varchar(255); not null"`
-	Amount        float64        `json:"amount" gorm:"type:float; not null"`
-	TransactionID string         `json:"transaction_id" gorm:"type:varchar(255); not null"`
-	CreatedAt     time.Time      `json:"created_at" gorm:"type:timestamp; not null; autoCreateTime"`
+	FundCollectID uuid.UUID `json:"fund_collect_id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	PostID        uuid.UUID `json:"post_id" gorm:"type:uuid; not null; index:idx_fund_collects_post_created,priority:1"`
+	UserID        string    `json:"user_id" gorm:"type:varchar(255); not null"`
+	UserName      string    `json:"user_name" gorm:"type:varchar(255); not null"`
+	Amount        Money     `json:"amount" gorm:"embedded;embeddedPrefix:amount_"`
+	// LegacyAmount mirrors Amount in the old float column until every reader
+	// uses amount_minor.
+	LegacyAmount float64 `json:"-" gorm:"column:amount;type:float; not null"`
+	// OriginalAmount is what the donor paid when it was in another currency
+	// than the post. It is zero for donations in the post's currency.
+	OriginalAmount Money  `json:"original_amount" gorm:"embedded;embeddedPrefix:original_amount_"`
+	TransactionID  string `json:"transaction_id" gorm:"type:varchar(255); not null; uniqueIndex"`
+	// Message is the donor's note for the supporters wall. The post's
+	// institution can hide it without hiding the donation.
+	Message       string         `json:"message" gorm:"type:varchar(280); not null; default:''"`
+	MessageHidden bool           `json:"message_hidden" gorm:"not null; default:false"`
+	CreatedAt     time.Time      `json:"created_at" gorm:"type:timestamp; not null; autoCreateTime; index:idx_fund_collects_post_created,priority:2"`
 	UpdatedAt     time.Time      `json:"updated_at" gorm:"type:timestamp; not null; autoUpdateTime"`
 	DeletedAt     gorm.DeletedAt `json:"deleted_at" gorm:"type:timestamp"`
 }
 
+func (f *FundCollect) BeforeSave(tx *gorm.DB) error {
+	f.LegacyAmount = f.Amount.Major()
+	return nil
+}
+
+// FundCollectRefund is a refund taken off a fund collect. It is kept so a
+// redelivered refund is not taken off twice.
+type FundCollectRefund struct {
+	RefundID      string    `json:"refund_id" gorm:"type:varchar(255);primaryKey"`
+	TransactionID string    `json:"transaction_id" gorm:"type:varchar(255); not null; index"`
+	Amount        Money     `json:"amount" gorm:"embedded;embeddedPrefix:amount_"`
+	CreatedAt     time.Time `json:"created_at" gorm:"type:timestamp; not null; autoCreateTime"`
+}
+
+// FundCollectPage is a page of fund collects, newest first. NextCursor is the
+// ID of the last fund collect and is empty on the last page.
+type FundCollectPage struct {
+	FundCollects []FundCollect
+	NextCursor   string
+}
+
 type FundCollectRequest struct {
-	PostID        string  `json:"post_id"`
-	UserID        string  `json:"user_id"`
-	UserName      string  `json:"user_name"`
-	Amount        float64 `json:"amount"`
-	TransactionID string  `json:"transaction_id"`
+	PostID         string  `json:"post_id"`
+	UserID         string  `json:"user_id"`
+	UserName       string  `json:"user_name"`
+	Amount         float64 `json:"amount"`
+	AmountV2       *Money  `json:"amount_v2"`
+	OriginalAmount *Money  `json:"original_amount"`
+	TransactionID  string  `json:"transaction_id"`
 }
 
 type FundCollectResponse struct {
-	FundCollectID string  `json:"fund_collect_id"`
-	PostID        string  `json:"post_id"`
-	UserID        string  `json:"user_id"`
-	UserName      string  `json:"user_name"`
-	Amount        float64 `json:"amount"`
-	TransactionID string  `json:"transaction_id"`
+	FundCollectID  string  `json:"fund_collect_id"`
+	PostID         string  `json:"post_id"`
+	UserID         string  `json:"user_id"`
+	UserName       string  `json:"user_name"`
+	Amount         float64 `json:"amount"`
+	AmountV2       Money   `json:"amount_v2"`
+	OriginalAmount Money   `json:"original_amount"`
+	TransactionID  string  `json:"transaction_id"`
+	Message        string  `json:"message"`
+	MessageHidden  bool    `json:"message_hidden"`
+}
+
+type DonationMessageModerationRequest struct {
+	Hidden bool `json:"hidden"`
+}
+
+// SupporterResponse is a donation as shown on a post's public supporters
+// wall. AmountBucket is the amount rounded down to 1, 2 or 5 times a power of
+// ten whole units, so exact amounts are not exposed.
+type SupporterResponse struct {
+	DisplayName  string `json:"display_name"`
+	AmountBucket Money  `json:"amount_bucket"`
+	Message      string `json:"message"`
+	DonatedAt    string `json:"donated_at"`
+}
+
+type SupporterListResponse struct {
+	Supporters []SupporterResponse `json:"supporters"`
+	NextCursor string              `json:"next_cursor"`
 }
@@ -19,6 +19,17 @@ type Institution struct {
 	CreatedAt     time.Time      `json:"created_at" gorm:"type:timestamp; not null; autoCreateTime"`
 	UpdatedAt     time.Time      `json:"updated_at" gorm:"type:timestamp; not null; autoUpdateTime"`
 	DeletedAt     gorm.DeletedAt `json:"deleted_at" gorm:"type:timestamp"`
+	// VerificationStatus is only changed through the verification review.
+	VerificationStatus VerificationStatus `json:"verification_status" gorm:"type:varchar(20); not null; default:'UNVERIFIED'; index"`
+	// VerificationNote is the reason given when verification is rejected.
+	VerificationNote string     `json:"verification_note" gorm:"type:text"`
+	VerifiedAt       *time.Time `json:"verified_at" gorm:"type:timestamp"`
+}
+
+// IsVerified reports whether an admin verified the documents of u. Only
+// verified institutions can submit posts for publication.
+func (u *Institution) IsVerified() bool {
+	return u.VerificationStatus == VerificationStatusVerified
 }
 
 func (u *Institution) CompareHashAndPassword(password string) error {
@@ -46,6 +57,11 @@ type InstitutionResponse struct {
 	Address string `json:"address"`
 	Phone   string `json:"phone"`
 	Website string `json:"website"`
+	// Verified is the badge of institutions whose documents were verified.
+	Verified bool `json:"verified"`
+	// VerificationStatus is UNVERIFIED, PENDING, VERIFIED or REJECTED.
+	VerificationStatus string `json:"verification_status"`
+	VerificationNote   string `json:"verification_note"`
 }
 
 type InstitutionToken struct {
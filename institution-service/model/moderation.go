@@ -0,0 +1,98 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ModerationDecision is what a moderator decides on a submitted post.
+type ModerationDecision string
+
+const (
+	ModerationDecisionApprove        ModerationDecision = "APPROVE"
+	ModerationDecisionReject         ModerationDecision = "REJECT"
+	ModerationDecisionRequestChanges ModerationDecision = "REQUEST_CHANGES"
+)
+
+// moderationOutcomes maps each decision to the status it moves the post to.
+// Posts sent back for changes are drafts again, to be edited and resubmitted.
+var moderationOutcomes = map[ModerationDecision]PostStatus{
+	ModerationDecisionApprove:        PostStatusPublished,
+	ModerationDecisionReject:         PostStatusRejected,
+	ModerationDecisionRequestChanges: PostStatusDraft,
+}
+
+func (d ModerationDecision) IsValid() bool {
+	_, ok := moderationOutcomes[d]
+	return ok
+}
+
+// Status returns the status a post gets from the decision, or "" when the
+// decision is not valid.
+func (d ModerationDecision) Status() PostStatus {
+	return moderationOutcomes[d]
+}
+
+// RequiresReason reports whether the institution must be told why, which is
+// the case unless the post is approved.
+func (d ModerationDecision) RequiresReason() bool {
+	return d != ModerationDecisionApprove
+}
+
+// PostModeration records one decision on a post. The rows of a post make up
+// its moderation history.
+type PostModeration struct {
+	ModerationID   uuid.UUID          `json:"moderation_id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	PostID         uuid.UUID          `json:"post_id" gorm:"type:uuid; not null; index"`
+	Decision       ModerationDecision `json:"decision" gorm:"type:varchar(20); not null"`
+	FromStatus     PostStatus         `json:"from_status" gorm:"type:varchar(20); not null"`
+	ToStatus       PostStatus         `json:"to_status" gorm:"type:varchar(20); not null"`
+	Reason         string             `json:"reason" gorm:"type:text"`
+	ModeratorEmail string             `json:"moderator_email" gorm:"type:varchar(255); not null"`
+	CreatedAt      time.Time          `json:"created_at" gorm:"type:timestamp; not null; autoCreateTime"`
+	Post           *Post              `json:"-" gorm:"foreignKey:PostID;references:PostID; constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+type ModerationRequest struct {
+	// Decision is APPROVE, REJECT or REQUEST_CHANGES.
+	Decision string `json:"decision"`
+	// Reason is required unless the post is approved. It is emailed to the
+	// institution.
+	Reason string `json:"reason"`
+}
+
+type ModerationResponse struct {
+	ModerationID   string `json:"moderation_id"`
+	PostID         string `json:"post_id"`
+	Decision       string `json:"decision"`
+	FromStatus     string `json:"from_status"`
+	ToStatus       string `json:"to_status"`
+	Reason         string `json:"reason"`
+	ModeratorEmail string `json:"moderator_email"`
+	CreatedAt      string `json:"created_at"`
+}
+
+type ModerationHistoryResponse struct {
+	Moderations []ModerationResponse `json:"moderations"`
+}
+
+// QueuedPostResponse is a post awaiting moderation, with what a moderator
+// needs to review it.
+type QueuedPostResponse struct {
+	PostID          string   `json:"post_id"`
+	InstitutionID   string   `json:"institution_id"`
+	InstitutionName string   `json:"institution_name"`
+	Title           string   `json:"title"`
+	Body            string   `json:"body"`
+	DateStart       string   `json:"date_start"`
+	DateEnd         string   `json:"date_end"`
+	FundTarget      Money    `json:"fund_target"`
+	Category        string   `json:"category"`
+	Tags            []string `json:"tags"`
+	SubmittedAt     string   `json:"submitted_at"`
+}
+
+type ModerationQueueResponse struct {
+	Posts []QueuedPostResponse `json:"posts"`
+}
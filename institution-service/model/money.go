@@ -0,0 +1,161 @@
+package model
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Money is an amount in the minor unit of its ISO 4217 currency, e.g. sen for
+// IDR, so sums and comparisons are exact. The same shape is used by
+// transaction-service and by the Money message in the protos.
+//
+// Embedded in a GORM model with embeddedPrefix:fund_target_ it maps to the
+// fund_target_minor and fund_target_currency columns.
+type Money struct {
+	Amount   int64  `json:"amount" bson:"amount" gorm:"column:minor;type:bigint"`
+	Currency string `json:"currency" bson:"currency" gorm:"column:currency;type:varchar(3)"`
+}
+
+const CurrencyIDR = "IDR"
+
+var currencyExponents = map[string]int{
+	"IDR": 2,
+	"USD": 2,
+	"SGD": 2,
+	"MYR": 2,
+	"EUR": 2,
+	"JPY": 0,
+}
+
+// CurrencyExponent is the number of minor-unit digits of a currency.
+func CurrencyExponent(currency string) (int, bool) {
+	exponent, ok := currencyExponents[strings.ToUpper(currency)]
+	return exponent, ok
+}
+
+func NewMoney(amount int64, currency string) Money {
+	return Money{Amount: amount, Currency: strings.ToUpper(currency)}
+}
+
+// IDR builds an amount from whole rupiah.
+func IDR(rupiah int64) Money {
+	return Money{Amount: rupiah * 100, Currency: CurrencyIDR}
+}
+
+// MoneyFromMajor converts a float amount in major units, as sent by the
+// deprecated float proto fields and by the payment gateway, rounding to the
+// nearest minor unit.
+func MoneyFromMajor(value float64, currency string) Money {
+	exponent, _ := CurrencyExponent(currency)
+	return NewMoney(int64(math.Round(value*math.Pow10(exponent))), currency)
+}
+
+// Major is the amount in major units, for the payment gateway and the
+// deprecated float fields only.
+func (m Money) Major() float64 {
+	exponent, _ := CurrencyExponent(m.Currency)
+	return float64(m.Amount) / math.Pow10(exponent)
+}
+
+// IsWhole reports whether the amount has no fractional major unit, e.g. no
+// sen in a rupiah amount.
+func (m Money) IsWhole() bool {
+	exponent, _ := CurrencyExponent(m.Currency)
+	return m.Amount%int64(math.Pow10(exponent)) == 0
+}
+
+// CeilMajor rounds the amount up to a whole major unit.
+func (m Money) CeilMajor() Money {
+	exponent, _ := CurrencyExponent(m.Currency)
+	unit := int64(math.Pow10(exponent))
+	if remainder := m.Amount % unit; remainder > 0 {
+		m.Amount += unit - remainder
+	} else if remainder < 0 {
+		m.Amount -= remainder
+	}
+
+	return m
+}
+
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+func (m Money) Validate() error {
+	if _, ok := CurrencyExponent(m.Currency); !ok {
+		return fmt.Errorf("unsupported currency %q", m.Currency)
+	}
+
+	return nil
+}
+
+// Add, Sub and Cmp panic when the currencies differ, which is a programming
+// error: amounts must be converted before they are combined. A zero Money
+// without a currency, such as an unset refunded amount, matches any currency.
+func (m Money) Add(other Money) Money {
+	currency := m.mustMatch(other)
+	return Money{Amount: m.Amount + other.Amount, Currency: currency}
+}
+
+func (m Money) Sub(other Money) Money {
+	currency := m.mustMatch(other)
+	return Money{Amount: m.Amount - other.Amount, Currency: currency}
+}
+
+// Cmp returns -1, 0 or 1 as m is less than, equal to or greater than other.
+func (m Money) Cmp(other Money) int {
+	m.mustMatch(other)
+	switch {
+	case m.Amount < other.Amount:
+		return -1
+	case m.Amount > other.Amount:
+		return 1
+	}
+
+	return 0
+}
+
+// Bucket rounds the amount down to 1, 2 or 5 times a power of ten whole major
+// units, e.g. IDR 75,000 to IDR 50,000. Amounts under one unit bucket to zero.
+func (m Money) Bucket() Money {
+	exponent, _ := CurrencyExponent(m.Currency)
+	unit := int64(math.Pow10(exponent))
+	major := m.Amount / unit
+	if major < 1 {
+		return Money{Currency: m.Currency}
+	}
+
+	power := int64(1)
+	for power <= major/10 {
+		power *= 10
+	}
+
+	bucket := power
+	for _, step := range []int64{5, 2} {
+		if power*step <= major {
+			bucket = power * step
+			break
+		}
+	}
+
+	return Money{Amount: bucket * unit, Currency: m.Currency}
+}
+
+func (m Money) String() string {
+	exponent, _ := CurrencyExponent(m.Currency)
+	return fmt.Sprintf("%s %.*f", m.Currency, exponent, m.Major())
+}
+
+func (m Money) mustMatch(other Money) string {
+	switch {
+	case m.Currency == other.Currency:
+		return m.Currency
+	case m.Currency == "" && m.Amount == 0:
+		return other.Currency
+	case other.Currency == "" && other.Amount == 0:
+		return m.Currency
+	}
+
+	panic(fmt.Sprintf("money: currency mismatch %s and %s", m.Currency, other.Currency))
+}
@@ -1,48 +1,342 @@
 package model
 
 import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strconv"
 	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
+type OverflowPolicy string
+
+// OverflowPolicy decides what happens to a donation larger than what is left
+// of the post's FundTarget.
+const (
+	OverflowPolicyAccept OverflowPolicy = "ACCEPT"
+	OverflowPolicyCap    OverflowPolicy = "CAP"
+	OverflowPolicyReject OverflowPolicy = "REJECT"
+)
+
+func (p OverflowPolicy) IsValid() bool {
+	switch p {
+	case OverflowPolicyAccept, OverflowPolicyCap, OverflowPolicyReject:
+		return true
+	}
+
+	return false
+}
+
+// PostStatus is the stage of a post's lifecycle. New posts are drafts; only
+// PUBLISHED posts are listed and accept donations.
+type PostStatus string
+
+const (
+	PostStatusDraft     PostStatus = "DRAFT"
+	PostStatusSubmitted PostStatus = "SUBMITTED"
+	PostStatusPublished PostStatus = "PUBLISHED"
+	PostStatusClosed    PostStatus = "CLOSED"
+	PostStatusCompleted PostStatus = "COMPLETED"
+	PostStatusArchived  PostStatus = "ARCHIVED"
+	PostStatusRejected  PostStatus = "REJECTED"
+)
+
+func (s PostStatus) IsValid() bool {
+	switch s {
+	case PostStatusDraft, PostStatusSubmitted, PostStatusPublished, PostStatusClosed, PostStatusCompleted, PostStatusArchived, PostStatusRejected:
+		return true
+	}
+
+	return false
+}
+
+// IsPublic reports whether posts in status s can be read by anyone. Other
+// posts are only shown to their institution and to moderators.
+func (s PostStatus) IsPublic() bool {
+	return s == PostStatusPublished || s == PostStatusClosed || s == PostStatusCompleted
+}
+
+// IsEditable reports whether an institution can edit posts in status s. Posts
+// under review or already approved cannot change behind the moderator's back.
+func (s PostStatus) IsEditable() bool {
+	return s == PostStatusDraft || s == PostStatusRejected
+}
+
+// PostActor is who changes the status of a post: the institution that owns
+// it, a moderator reviewing it or an admin.
+type PostActor string
+
+const (
+	PostActorInstitution PostActor = "INSTITUTION"
+	PostActorModerator   PostActor = "MODERATOR"
+	PostActorAdmin       PostActor = "ADMIN"
+)
+
+type postTransition struct {
+	from, to PostStatus
+}
+
+// postTransitions lists the status changes of the lifecycle and who may make
+// each. Any change not listed is refused.
+var postTransitions = map[postTransition][]PostActor{
+	// Submitted for review, and withdrawn or sent back for changes.
+	{PostStatusDraft, PostStatusSubmitted}:     {PostActorInstitution},
+	{PostStatusSubmitted, PostStatusDraft}:     {PostActorInstitution, PostActorModerator},
+	{PostStatusSubmitted, PostStatusPublished}: {PostActorModerator},
+	{PostStatusSubmitted, PostStatusRejected}:  {PostActorModerator},
+	// Closed before its end, or completed once ended or funded.
+	{PostStatusPublished, PostStatusClosed}:    {PostActorInstitution, PostActorAdmin},
+	{PostStatusPublished, PostStatusCompleted}: {PostActorInstitution},
+	{PostStatusDraft, PostStatusArchived}:      {PostActorInstitution},
+	{PostStatusClosed, PostStatusArchived}:     {PostActorInstitution},
+	{PostStatusCompleted, PostStatusArchived}:  {PostActorInstitution},
+	{PostStatusRejected, PostStatusArchived}:   {PostActorInstitution},
+}
+
+// ErrPostStatusChanged is returned when a post no longer has the status a
+// change was checked against.
+var ErrPostStatusChanged = errors.New("post status was changed concurrently")
+
+// CanTransitionPost reports whether actor may move a post from one status to
+// another.
+func CanTransitionPost(from, to PostStatus, actor PostActor) bool {
+	for _, allowed := range postTransitions[postTransition{from, to}] {
+		if allowed == actor {
+			return true
+		}
+	}
+
+	return false
+}
+
 type Post struct {
-	PostID        uuid.UUID      `json:"post_id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
-	InstitutionID uuid.UUID      `json:"institution_id" gorm:"type:uuid; not null"`
-	Title         string         `json:"title" gorm:"type:varchar(255); not null"`
-	Body          string         `json:"body" gorm:"type:text; not null"`
-	DateStart     time.Time      `json:"date_start" gorm:"type:timestamp; not null"`
-	DateEnd       time.Time      `json:"date_end" gorm:"type:timestamp; not null"`
-	FundTarget    float64        `json:"fund_target" gorm:"type:float; not null"`
-	FundAchieved  float64        `json:"fund_achieved" gorm:"type:float; default:0"`
-	CreatedAt     time.Time      `json:"created_at" gorm:"type:timestamp; not null; autoCreateTime"`
-	UpdatedAt     time.Time      `json:"updated_at" gorm:"type:timestamp; not null; autoUpdateTime"`
-	DeletedAt     gorm.DeletedAt `json:"deleted_at" gorm:"type:timestamp"`
-	Institution   Institution    `json:"institution" gorm:"foreignKey:InstitutionID;references:InstitutionID; constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	PostID        uuid.UUID `json:"post_id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	InstitutionID uuid.UUID `json:"institution_id" gorm:"type:uuid; not null"`
+	Title         string    `json:"title" gorm:"type:varchar(255); not null"`
+	Body          string    `json:"body" gorm:"type:text; not null"`
+	DateStart     time.Time `json:"date_start" gorm:"type:timestamp; not null"`
+	DateEnd       time.Time `json:"date_end" gorm:"type:timestamp; not null"`
+	FundTarget    Money     `json:"fund_target" gorm:"embedded;embeddedPrefix:fund_target_"`
+	FundAchieved  Money     `json:"fund_achieved" gorm:"embedded;embeddedPrefix:fund_achieved_"`
+	// LegacyFundTarget and LegacyFundAchieved mirror the Money fields in the
+	// old float columns until every reader uses the _minor columns.
+	LegacyFundTarget   float64        `json:"-" gorm:"column:fund_target;type:float; not null"`
+	LegacyFundAchieved float64        `json:"-" gorm:"column:fund_achieved;type:float; default:0"`
+	OverflowPolicy     OverflowPolicy `json:"overflow_policy" gorm:"type:varchar(10); not null; default:'ACCEPT'"`
+	CreatedAt          time.Time      `json:"created_at" gorm:"type:timestamp; not null; autoCreateTime"`
+	UpdatedAt          time.Time      `json:"updated_at" gorm:"type:timestamp; not null; autoUpdateTime"`
+	DeletedAt          gorm.DeletedAt `json:"deleted_at" gorm:"type:timestamp"`
+	Institution        Institution    `json:"institution" gorm:"foreignKey:InstitutionID;references:InstitutionID; constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	CategoryID         *uuid.UUID     `json:"category_id" gorm:"type:uuid; index"`
+	Category           *Category      `json:"category" gorm:"foreignKey:CategoryID;references:CategoryID; constraint:OnUpdate:CASCADE,OnDelete:SET NULL"`
+	Tags               []Tag          `json:"tags" gorm:"many2many:post_tags;joinForeignKey:PostID;joinReferences:TagID"`
+	// Status is only changed through the transitions of CanTransitionPost.
+	Status PostStatus `json:"status" gorm:"type:varchar(20); index"`
+}
+
+// HasEnded reports whether the fundraising period of p is over at now.
+func (p *Post) HasEnded(now time.Time) bool {
+	return p.DateEnd.Before(now)
+}
+
+// IsDeleted reports whether p was soft deleted. Posts deleted before
+// DeletedAt was nullable carry the zero time instead of NULL.
+func (p *Post) IsDeleted() bool {
+	return p.DeletedAt.Valid && !p.DeletedAt.Time.IsZero()
+}
+
+// IsFunded reports whether p has raised its FundTarget.
+func (p *Post) IsFunded() bool {
+	return p.FundAchieved.Amount >= p.FundTarget.Amount
+}
+
+func (p *Post) BeforeSave(tx *gorm.DB) error {
+	p.LegacyFundTarget = p.FundTarget.Major()
+	p.LegacyFundAchieved = p.FundAchieved.Major()
+	return nil
+}
+
+// CampaignStatus is where a post stands in its fundraising period. A post
+// that has reached its FundTarget is FUNDED whatever its dates.
+type CampaignStatus string
+
+const (
+	CampaignStatusUpcoming CampaignStatus = "UPCOMING"
+	CampaignStatusActive   CampaignStatus = "ACTIVE"
+	CampaignStatusEnded    CampaignStatus = "ENDED"
+	CampaignStatusFunded   CampaignStatus = "FUNDED"
+)
+
+func (s CampaignStatus) IsValid() bool {
+	switch s {
+	case CampaignStatusUpcoming, CampaignStatusActive, CampaignStatusEnded, CampaignStatusFunded:
+		return true
+	}
+
+	return false
+}
+
+type PostSort string
+
+const (
+	PostSortNewest        PostSort = "NEWEST"
+	PostSortEndingSoon    PostSort = "ENDING_SOON"
+	PostSortMostFunded    PostSort = "MOST_FUNDED"
+	PostSortPercentFunded PostSort = "PERCENT_FUNDED"
+)
+
+func (s PostSort) IsValid() bool {
+	switch s {
+	case PostSortNewest, PostSortEndingSoon, PostSortMostFunded, PostSortPercentFunded:
+		return true
+	}
+
+	return false
+}
+
+// SortValue is the value of post that s orders by, as kept in a cursor.
+func (s PostSort) SortValue(post *Post) string {
+	switch s {
+	case PostSortEndingSoon:
+		return post.DateEnd.Format(time.RFC3339Nano)
+	case PostSortMostFunded:
+		return strconv.FormatInt(post.FundAchieved.Amount, 10)
+	case PostSortPercentFunded:
+		return strconv.FormatFloat(post.PercentFunded(), 'g', -1, 64)
+	}
+
+	return post.CreatedAt.Format(time.RFC3339Nano)
+}
+
+// PercentFunded is FundAchieved as a fraction of FundTarget, computed the
+// same way as the PERCENT_FUNDED sort in Postgres.
+func (p *Post) PercentFunded() float64 {
+	if p.FundTarget.Amount == 0 {
+		return 0
+	}
+
+	return float64(p.FundAchieved.Amount) / float64(p.FundTarget.Amount)
+}
+
+var ErrInvalidPostCursor = errors.New("invalid cursor")
+
+// PostCursor is the position after the last post of a page: the value the
+// page is sorted by, with the post ID breaking ties.
+type PostCursor struct {
+	Sort   PostSort  `json:"s"`
+	Value  string    `json:"v"`
+	PostID uuid.UUID `json:"id"`
+}
+
+func NewPostCursor(sort PostSort, post *Post) PostCursor {
+	return PostCursor{Sort: sort, Value: sort.SortValue(post), PostID: post.PostID}
+}
+
+func (c PostCursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodePostCursor reads a cursor returned with a previous page.
+func DecodePostCursor(value string) (*PostCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, ErrInvalidPostCursor
+	}
+
+	var cursor PostCursor
+	if err := json.Unmarshal(data, &cursor); err != nil || !cursor.Sort.IsValid() || cursor.PostID == uuid.Nil {
+		return nil, ErrInvalidPostCursor
+	}
+
+	return &cursor, nil
+}
+
+// PostFilter narrows the public post listing. Query is matched against the
+// title and body; DateFrom and DateTo keep posts whose fundraising period
+// overlaps them; TargetMin and TargetMax bound the FundTarget in the currency
+// they are given in. Tags keeps posts with any of the tags.
+type PostFilter struct {
+	Query         string
+	Status        CampaignStatus
+	InstitutionID uuid.UUID
+	CategoryID    uuid.UUID
+	CategorySlug  string
+	Tags          []string
+	DateFrom      time.Time
+	DateTo        time.Time
+	TargetMin     *Money
+	TargetMax     *Money
+	Sort          PostSort
+	Cursor        *PostCursor
+	Limit         int
+}
+
+type PostPage struct {
+	Posts      []Post
+	NextCursor string
 }
 
 type PostRequest struct {
-	Title      string    `json:"title"`
-	Body       string    `json:"body"`
-	DateStart  time.Time `json:"date_start"`
-	DateEnd    time.Time `json:"date_end"`
-	FundTarget float64   `json:"fund_target"`
+	Title     string    `json:"title"`
+	Body      string    `json:"body"`
+	DateStart time.Time `json:"date_start"`
+	DateEnd   time.Time `json:"date_end"`
+	// FundTarget is deprecated in favour of FundTargetV2.
+	FundTarget   float64 `json:"fund_target"`
+	FundTargetV2 *Money  `json:"fund_target_v2"`
+	// OverflowPolicy is ACCEPT, CAP or REJECT and defaults to ACCEPT.
+	OverflowPolicy string   `json:"overflow_policy"`
+	CategoryID     string   `json:"category_id"`
+	Tags           []string `json:"tags"`
+	// ClearTags removes every tag of the post on update, when Tags is empty.
+	ClearTags bool `json:"clear_tags"`
 }
 
 type PostResponse struct {
-	PostID       string  `json:"post_id"`
-	Title        string  `json:"title"`
-	Body         string  `json:"body"`
-	DateStart    string  `json:"date_start"`
-	DateEnd      string  `json:"date_end"`
-	FundTarget   float32 `json:"fund_target"`
-	FundAchieved float32 `json:"fund_achieved"`
+	PostID         string  `json:"post_id"`
+	Title          string  `json:"title"`
+	Body           string  `json:"body"`
+	DateStart      string  `json:"date_start"`
+	DateEnd        string  `json:"date_end"`
+	FundTarget     float32 `json:"fund_target"`
+	FundAchieved   float32 `json:"fund_achieved"`
+	OverflowPolicy string  `json:"overflow_policy"`
+	FundTargetV2   Money   `json:"fund_target_v2"`
+	FundAchievedV2 Money   `json:"fund_achieved_v2"`
+	InstitutionID  string  `json:"institution_id"`
+	// Category is omitted from posts not filed under one.
+	Category *PostCategoryResponse `json:"category"`
+	Tags     []string              `json:"tags"`
+	// Status is DRAFT, SUBMITTED, PUBLISHED, REJECTED, CLOSED, COMPLETED or
+	// ARCHIVED.
+	Status string `json:"status"`
+}
+
+type PostStatusRequest struct {
+	// Status is SUBMITTED, DRAFT, CLOSED, COMPLETED or ARCHIVED. Posts are
+	// published or rejected through moderation.
+	Status string `json:"status"`
+}
+
+type PostCategoryResponse struct {
+	CategoryID string `json:"category_id"`
+	Slug       string `json:"slug"`
+	Name       string `json:"name"`
+}
+
+type PostListResponse struct {
+	Posts      []PostResponse `json:"posts"`
+	NextCursor string         `json:"next_cursor"`
 }
 
 type PostFundAchievedResponse struct {
-	PostID       string  `json:"post_id"`
-	FundAchieved float32 `json:"fund_achieved"`
+	PostID         string  `json:"post_id"`
+	FundAchieved   float32 `json:"fund_achieved"`
+	FundAchievedV2 Money   `json:"fund_achieved_v2"`
 }
 
 type PostDeleteResponse struct {
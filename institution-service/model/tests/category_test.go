@@ -0,0 +1,22 @@
+package tests
+
+import (
+	"testing"
+
+	"institution-service/model"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSlugify(t *testing.T) {
+	assert.Equal(t, "teacher-training", model.Slugify("Teacher Training"))
+	assert.Equal(t, "books-stationery", model.Slugify(" Books & Stationery! "))
+	assert.True(t, model.IsValidSlug(model.Slugify("School Infrastructure")))
+	assert.False(t, model.IsValidSlug("School Infrastructure"))
+	assert.False(t, model.IsValidSlug(""))
+}
+
+func TestNormalizeTagName(t *testing.T) {
+	assert.Equal(t, "sekolah dasar", model.NormalizeTagName("  Sekolah\tDasar "))
+	assert.Equal(t, "", model.NormalizeTagName("   "))
+}
@@ -0,0 +1,31 @@
+package tests
+
+import (
+	"testing"
+
+	"institution-service/model"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMoneyBucket(t *testing.T) {
+	tests := []struct {
+		name     string
+		amount   model.Money
+		expected model.Money
+	}{
+		{"success - rounds down to five", model.IDR(75000), model.IDR(50000)},
+		{"success - rounds down to two", model.IDR(49999), model.IDR(20000)},
+		{"success - rounds down to one", model.IDR(19000), model.IDR(10000)},
+		{"success - keeps an exact bucket", model.IDR(100000), model.IDR(100000)},
+		{"success - ignores minor units", model.NewMoney(32550, "USD"), model.NewMoney(20000, "USD")},
+		{"success - currencies without minor units", model.NewMoney(1200, "JPY"), model.NewMoney(1000, "JPY")},
+		{"success - under one unit", model.NewMoney(50, "USD"), model.NewMoney(0, "USD")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.amount.Bucket())
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package tests
+
+import (
+	"testing"
+
+	"institution-service/model"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPostCursor(t *testing.T) {
+	t.Run("success - encoded cursor decodes to the same position", func(t *testing.T) {
+		post := &model.Post{
+			PostID:       uuid.New(),
+			FundTarget:   model.IDR(3000000),
+			FundAchieved: model.IDR(1000000),
+		}
+		cursor := model.NewPostCursor(model.PostSortPercentFunded, post)
+
+		decoded, err := model.DecodePostCursor(cursor.Encode())
+
+		assert.NoError(t, err)
+		assert.Equal(t, cursor, *decoded)
+		assert.Equal(t, "0.3333333333333333", decoded.Value)
+	})
+
+	t.Run("failed - malformed cursor", func(t *testing.T) {
+		for _, value := range []string{"not base64!", "e30", model.PostCursor{Sort: "OLDEST", PostID: uuid.New()}.Encode()} {
+			cursor, err := model.DecodePostCursor(value)
+
+			assert.Nil(t, cursor)
+			assert.ErrorIs(t, err, model.ErrInvalidPostCursor)
+		}
+	})
+}
+
+func TestCanTransitionPost(t *testing.T) {
+	assert.True(t, model.CanTransitionPost(model.PostStatusDraft, model.PostStatusSubmitted, model.PostActorInstitution))
+	assert.True(t, model.CanTransitionPost(model.PostStatusSubmitted, model.PostStatusPublished, model.PostActorModerator))
+	assert.True(t, model.CanTransitionPost(model.PostStatusSubmitted, model.PostStatusDraft, model.PostActorModerator))
+	assert.True(t, model.CanTransitionPost(model.PostStatusSubmitted, model.PostStatusRejected, model.PostActorModerator))
+	assert.True(t, model.CanTransitionPost(model.PostStatusPublished, model.PostStatusClosed, model.PostActorAdmin))
+	assert.False(t, model.CanTransitionPost(model.PostStatusSubmitted, model.PostStatusPublished, model.PostActorInstitution))
+	assert.False(t, model.CanTransitionPost(model.PostStatusSubmitted, model.PostStatusPublished, model.PostActorAdmin))
+	assert.False(t, model.CanTransitionPost(model.PostStatusDraft, model.PostStatusPublished, model.PostActorModerator))
+	assert.False(t, model.CanTransitionPost(model.PostStatusRejected, model.PostStatusSubmitted, model.PostActorInstitution))
+	assert.False(t, model.CanTransitionPost(model.PostStatusArchived, model.PostStatusPublished, model.PostActorInstitution))
+	assert.False(t, model.CanTransitionPost(model.PostStatusPublished, model.PostStatusPublished, model.PostActorModerator))
+}
+
+func TestModerationDecision(t *testing.T) {
+	assert.Equal(t, model.PostStatusPublished, model.ModerationDecisionApprove.Status())
+	assert.Equal(t, model.PostStatusRejected, model.ModerationDecisionReject.Status())
+	assert.Equal(t, model.PostStatusDraft, model.ModerationDecisionRequestChanges.Status())
+	assert.False(t, model.ModerationDecision("PUBLISH").IsValid())
+	assert.False(t, model.ModerationDecisionApprove.RequiresReason())
+	assert.True(t, model.ModerationDecisionReject.RequiresReason())
+}
+
+func TestPostStatusIsPublic(t *testing.T) {
+	assert.True(t, model.PostStatusPublished.IsPublic())
+	assert.True(t, model.PostStatusClosed.IsPublic())
+	assert.True(t, model.PostStatusCompleted.IsPublic())
+	assert.False(t, model.PostStatusDraft.IsPublic())
+	assert.False(t, model.PostStatusSubmitted.IsPublic())
+	assert.False(t, model.PostStatusRejected.IsPublic())
+	assert.False(t, model.PostStatusArchived.IsPublic())
+}
@@ -0,0 +1,29 @@
+package tests
+
+import (
+	"testing"
+
+	"institution-service/model"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerificationStatusAcceptsDocuments(t *testing.T) {
+	t.Run("success - documents can change until submitted and after a rejection", func(t *testing.T) {
+		assert.True(t, model.VerificationStatusUnverified.AcceptsDocuments())
+		assert.True(t, model.VerificationStatusRejected.AcceptsDocuments())
+		assert.False(t, model.VerificationStatusPending.AcceptsDocuments())
+		assert.False(t, model.VerificationStatusVerified.AcceptsDocuments())
+	})
+}
+
+func TestVerificationDecision(t *testing.T) {
+	t.Run("success - decisions lead to their status", func(t *testing.T) {
+		assert.Equal(t, model.VerificationStatusVerified, model.VerificationDecisionApprove.Status())
+		assert.Equal(t, model.VerificationStatusRejected, model.VerificationDecisionReject.Status())
+	})
+
+	t.Run("failed - unknown decision", func(t *testing.T) {
+		assert.Equal(t, model.VerificationStatus(""), model.VerificationDecision("REQUEST_CHANGES").Status())
+	})
+}
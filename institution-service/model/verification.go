@@ -0,0 +1,145 @@
+package model
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// VerificationStatus is where an institution is in the review of its legal
+// documents. Institutions start UNVERIFIED, upload their documents and submit
+// them, which makes them PENDING until an admin verifies or rejects them.
+type VerificationStatus string
+
+const (
+	VerificationStatusUnverified VerificationStatus = "UNVERIFIED"
+	VerificationStatusPending    VerificationStatus = "PENDING"
+	VerificationStatusVerified   VerificationStatus = "VERIFIED"
+	VerificationStatusRejected   VerificationStatus = "REJECTED"
+)
+
+// AcceptsDocuments reports whether documents can be uploaded and submitted,
+// which they cannot while under review or once verified.
+func (s VerificationStatus) AcceptsDocuments() bool {
+	return s == VerificationStatusUnverified || s == VerificationStatusRejected
+}
+
+// ErrVerificationStatusChanged is returned when an institution no longer has
+// the verification status a change was checked against.
+var ErrVerificationStatusChanged = errors.New("verification status was changed concurrently")
+
+// VerificationDecision is what an admin decides on submitted documents.
+type VerificationDecision string
+
+const (
+	VerificationDecisionApprove VerificationDecision = "APPROVE"
+	VerificationDecisionReject  VerificationDecision = "REJECT"
+)
+
+// Status returns the verification status the decision leads to, or "" when
+// the decision is not valid.
+func (d VerificationDecision) Status() VerificationStatus {
+	switch d {
+	case VerificationDecisionApprove:
+		return VerificationStatusVerified
+	case VerificationDecisionReject:
+		return VerificationStatusRejected
+	}
+
+	return ""
+}
+
+// DocumentType is the kind of legal document an institution uploads.
+type DocumentType string
+
+const (
+	DocumentTypeRegistrationCertificate DocumentType = "REGISTRATION_CERTIFICATE"
+	DocumentTypeTaxID                   DocumentType = "TAX_ID"
+	DocumentTypeBankAccountProof        DocumentType = "BANK_ACCOUNT_PROOF"
+)
+
+// RequiredDocumentTypes are the documents an institution needs before it can
+// submit them for verification: its registration certificate, its tax ID
+// (NPWP) and proof of the bank account donations are paid out to.
+var RequiredDocumentTypes = []DocumentType{
+	DocumentTypeRegistrationCertificate,
+	DocumentTypeTaxID,
+	DocumentTypeBankAccountProof,
+}
+
+func (t DocumentType) IsValid() bool {
+	for _, required := range RequiredDocumentTypes {
+		if t == required {
+			return true
+		}
+	}
+
+	return false
+}
+
+// MaxDocumentSize keeps an upload, once in a gRPC message, under the 4 MB
+// the server accepts.
+const MaxDocumentSize = 3 << 20
+
+// documentExtensions maps the content types accepted for documents to the
+// extension they are stored with.
+var documentExtensions = map[string]string{
+	"application/pdf": ".pdf",
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+}
+
+// DocumentExtension returns the extension documents of contentType are stored
+// with, and false when the content type is not accepted.
+func DocumentExtension(contentType string) (string, bool) {
+	extension, ok := documentExtensions[contentType]
+	return extension, ok
+}
+
+// InstitutionDocument is an uploaded document. Its content is kept in object
+// storage under StorageKey; an institution has at most one of each type.
+type InstitutionDocument struct {
+	DocumentID    uuid.UUID    `json:"document_id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	InstitutionID uuid.UUID    `json:"institution_id" gorm:"type:uuid; not null; uniqueIndex:idx_institution_document_type"`
+	Type          DocumentType `json:"type" gorm:"type:varchar(30); not null; uniqueIndex:idx_institution_document_type"`
+	Filename      string       `json:"filename" gorm:"type:varchar(255); not null"`
+	ContentType   string       `json:"content_type" gorm:"type:varchar(100); not null"`
+	Size          int64        `json:"size" gorm:"not null"`
+	StorageKey    string       `json:"-" gorm:"type:varchar(255); not null"`
+	CreatedAt     time.Time    `json:"created_at" gorm:"type:timestamp; not null; autoCreateTime"`
+	Institution   *Institution `json:"-" gorm:"foreignKey:InstitutionID;references:InstitutionID; constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+type DocumentResponse struct {
+	DocumentID  string `json:"document_id"`
+	Type        string `json:"type"`
+	Filename    string `json:"filename"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+	CreatedAt   string `json:"created_at"`
+}
+
+type DocumentListResponse struct {
+	Documents []DocumentResponse `json:"documents"`
+}
+
+type VerificationRequest struct {
+	// Decision is APPROVE or REJECT.
+	Decision string `json:"decision"`
+	// Reason is required to reject. The institution sees it and can upload
+	// new documents and submit again.
+	Reason string `json:"reason"`
+}
+
+type VerificationResponse struct {
+	InstitutionID      string `json:"institution_id"`
+	Name               string `json:"name"`
+	VerificationStatus string `json:"verification_status"`
+	VerificationNote   string `json:"verification_note"`
+	Verified           bool   `json:"verified"`
+}
+
+type VerificationListResponse struct {
+	Institutions []VerificationResponse `json:"institutions"`
+}
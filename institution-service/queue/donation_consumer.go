@@ -0,0 +1,107 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"institution-service/model"
+	"institution-service/usecase"
+
+	"github.com/rabbitmq/amqp091-go"
+	"github.com/sirupsen/logrus"
+)
+
+const DonationEventsQueue = "donation_events"
+
+// redeliveryDelay keeps a failing event from being redelivered in a tight
+// loop while the database is unavailable.
+const redeliveryDelay = time.Second
+
+// StartDonationConsumer applies the donation events published by
+// transaction-service. A message is acknowledged only once it has been
+// applied, so events survive a crash and are redelivered.
+func StartDonationConsumer(conn *amqp091.Connection, uc usecase.IFundCollectUsecase, logger *logrus.Logger) {
+	ch, err := conn.Channel()
+	if err != nil {
+		logger.Fatal("Failed to open RabbitMQ channel:", err)
+	}
+	defer ch.Close()
+
+	q, err := ch.QueueDeclare(
+		DonationEventsQueue,
+		true,
+		false,
+		false,
+		false,
+		nil,
+	)
+	if err != nil {
+		logger.Fatal("Failed to declare queue:", err)
+	}
+
+	if err := ch.Qos(10, 0, false); err != nil {
+		logger.Fatal("Failed to set prefetch:", err)
+	}
+
+	msgs, err := ch.Consume(
+		q.Name,
+		"",
+		false,
+		false,
+		false,
+		false,
+		nil,
+	)
+	if err != nil {
+		logger.Fatal("Failed to register consumer:", err)
+	}
+
+	logger.Info("Waiting for donation events from RabbitMQ...")
+
+	for msg := range msgs {
+		err := HandleDonationEvent(context.Background(), uc, msg.Type, msg.Body)
+		if err == nil {
+			msg.Ack(false)
+			continue
+		}
+
+		fields := logrus.Fields{
+			"message_id": msg.MessageId,
+			"type":       msg.Type,
+			"error":      err.Error(),
+		}
+		if errors.Is(err, usecase.ErrInvalidDonationEvent) {
+			logger.WithFields(fields).Error("Dropping donation event that cannot be applied")
+			msg.Nack(false, false)
+			continue
+		}
+
+		logger.WithFields(fields).Warn("Failed to apply donation event, requeueing")
+		time.Sleep(redeliveryDelay)
+		msg.Nack(false, true)
+	}
+}
+
+// HandleDonationEvent applies one message from the donation events queue.
+// Unknown event types are ignored so producers can add events first.
+func HandleDonationEvent(ctx context.Context, uc usecase.IFundCollectUsecase, eventType string, body []byte) error {
+	switch eventType {
+	case model.EventTypeDonationSettled:
+		var event model.DonationSettledEvent
+		if err := json.Unmarshal(body, &event); err != nil {
+			return fmt.Errorf("%w: %v", usecase.ErrInvalidDonationEvent, err)
+		}
+		return uc.ApplyDonationSettled(ctx, event)
+	case model.EventTypeDonationRefunded:
+		var event model.DonationRefundedEvent
+		if err := json.Unmarshal(body, &event); err != nil {
+			return fmt.Errorf("%w: %v", usecase.ErrInvalidDonationEvent, err)
+		}
+		return uc.ApplyDonationRefunded(ctx, event)
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,120 @@
+package queue
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+
+	"institution-service/model"
+
+	"github.com/rabbitmq/amqp091-go"
+	"github.com/sirupsen/logrus"
+)
+
+const EmailQueue = "email"
+
+type IEmailPublisher interface {
+	PublishModerationDecision(institution *model.Institution, post *model.Post, moderation *model.PostModeration) error
+}
+
+// EmailPublisher sends messages to the queue consumed by notification-service.
+type EmailPublisher struct {
+	channel *amqp091.Channel
+	queue   amqp091.Queue
+}
+
+func NewEmailPublisher(channel *amqp091.Channel, queueName string) (*EmailPublisher, error) {
+	queue, err := channel.QueueDeclare(
+		queueName,
+		true,
+		false,
+		false,
+		false,
+		nil,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &EmailPublisher{
+		channel: channel,
+		queue:   queue,
+	}, nil
+}
+
+func (p *EmailPublisher) PublishModerationDecision(institution *model.Institution, post *model.Post, moderation *model.PostModeration) error {
+	return p.publish(institution.Email, ModerationSubject(moderation.Decision), ModerationMessage(institution, post, moderation))
+}
+
+func (p *EmailPublisher) publish(email, subject, message string) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"email":   email,
+		"subject": subject,
+		"message": message,
+	})
+	if err != nil {
+		return err
+	}
+
+	err = p.channel.Publish(
+		"",
+		p.queue.Name,
+		false,
+		false,
+		amqp091.Publishing{
+			ContentType: "application/json",
+			Body:        body,
+		},
+	)
+	if err != nil {
+		logrus.WithError(err).Errorf("Failed to publish %s email", subject)
+		return err
+	}
+
+	logrus.WithField("email", email).Infof("%s email published", subject)
+	return nil
+}
+
+// LogEmailPublisher logs notifications instead of sending them. It is used
+// when RabbitMQ is not configured.
+type LogEmailPublisher struct{}
+
+func (LogEmailPublisher) PublishModerationDecision(institution *model.Institution, post *model.Post, moderation *model.PostModeration) error {
+	logrus.WithField("email", institution.Email).Infof("Moderation decision not sent, RabbitMQ is not configured: %s on post %s", moderation.Decision, post.PostID)
+	return nil
+}
+
+// ModerationSubject is the subject of the email telling an institution the
+// decision on its post.
+func ModerationSubject(decision model.ModerationDecision) string {
+	switch decision {
+	case model.ModerationDecisionApprove:
+		return "Kampanye Disetujui"
+	case model.ModerationDecisionReject:
+		return "Kampanye Ditolak"
+	default:
+		return "Kampanye Perlu Diperbaiki"
+	}
+}
+
+// ModerationMessage is the body of the email telling an institution the
+// decision on its post. The reason is written by a moderator and escaped.
+func ModerationMessage(institution *model.Institution, post *model.Post, moderation *model.PostModeration) string {
+	message := fmt.Sprintf("\n\t\t<p>Halo %s,</p>\n", html.EscapeString(institution.Name))
+
+	title := html.EscapeString(post.Title)
+	switch moderation.Decision {
+	case model.ModerationDecisionApprove:
+		message += fmt.Sprintf("\t\t<p>Kampanye <b>%s</b> telah disetujui dan kini dapat menerima donasi.</p>\n", title)
+	case model.ModerationDecisionReject:
+		message += fmt.Sprintf("\t\t<p>Mohon maaf, kampanye <b>%s</b> tidak dapat kami terbitkan.</p>\n", title)
+	default:
+		message += fmt.Sprintf("\t\t<p>Kampanye <b>%s</b> perlu diperbaiki sebelum dapat diterbitkan. Silakan ubah lalu ajukan kembali.</p>\n", title)
+	}
+
+	if moderation.Reason != "" {
+		message += fmt.Sprintf("\t\t<p>Alasan: %s</p>\n", html.EscapeString(moderation.Reason))
+	}
+
+	return message + "\t\t<p>Terima kasih.</p>\n"
+}
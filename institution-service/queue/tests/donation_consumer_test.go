@@ -0,0 +1,96 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"institution-service/mocks"
+	"institution-service/model"
+	"institution-service/queue"
+	"institution-service/usecase"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandleDonationEvent(t *testing.T) {
+	t.Run("success - applies a donation settled event", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockFundCollectUsecase := mocks.NewMockIFundCollectUsecase(ctrl)
+
+		event := model.DonationSettledEvent{
+			EventID:       uuid.New().String(),
+			TransactionID: "65f1c0a2b3c4d5e6f7a8b9c0",
+			PostID:        uuid.New().String(),
+			Amount:        model.IDR(50000),
+		}
+		body, err := json.Marshal(event)
+		assert.NoError(t, err)
+
+		mockFundCollectUsecase.EXPECT().
+			ApplyDonationSettled(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, received model.DonationSettledEvent) error {
+				assert.Equal(t, event.TransactionID, received.TransactionID)
+				assert.Equal(t, event.Amount, received.Amount)
+				return nil
+			})
+
+		err = queue.HandleDonationEvent(context.Background(), mockFundCollectUsecase, model.EventTypeDonationSettled, body)
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("success - applies a donation refunded event", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockFundCollectUsecase := mocks.NewMockIFundCollectUsecase(ctrl)
+
+		event := model.DonationRefundedEvent{
+			EventID:       uuid.New().String(),
+			TransactionID: "65f1c0a2b3c4d5e6f7a8b9c0",
+			PostID:        uuid.New().String(),
+			RefundID:      "rfd-1",
+			Amount:        model.IDR(20000),
+		}
+		body, err := json.Marshal(event)
+		assert.NoError(t, err)
+
+		mockFundCollectUsecase.EXPECT().
+			ApplyDonationRefunded(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, received model.DonationRefundedEvent) error {
+				assert.Equal(t, event.RefundID, received.RefundID)
+				assert.Equal(t, event.Amount, received.Amount)
+				return nil
+			})
+
+		err = queue.HandleDonationEvent(context.Background(), mockFundCollectUsecase, model.EventTypeDonationRefunded, body)
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("success - unknown event type is ignored", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockFundCollectUsecase := mocks.NewMockIFundCollectUsecase(ctrl)
+
+		err := queue.HandleDonationEvent(context.Background(), mockFundCollectUsecase, "SomethingElse", []byte(`{}`))
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("failed - malformed payload is not retried", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockFundCollectUsecase := mocks.NewMockIFundCollectUsecase(ctrl)
+
+		err := queue.HandleDonationEvent(context.Background(), mockFundCollectUsecase, model.EventTypeDonationSettled, []byte(`{`))
+
+		assert.ErrorIs(t, err, usecase.ErrInvalidDonationEvent)
+	})
+}
@@ -0,0 +1,38 @@
+package tests
+
+import (
+	"institution-service/model"
+	"institution-service/queue"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestModerationMessage(t *testing.T) {
+	institution := &model.Institution{Name: "Yayasan Pesisir", Email: "yayasan@email.com"}
+	post := &model.Post{Title: "Beasiswa Anak Pesisir"}
+
+	t.Run("success - changes requested with the reason escaped", func(t *testing.T) {
+		moderation := &model.PostModeration{
+			Decision: model.ModerationDecisionRequestChanges,
+			Reason:   "Add the <b>budget</b>",
+		}
+
+		message := queue.ModerationMessage(institution, post, moderation)
+
+		assert.Equal(t, "Kampanye Perlu Diperbaiki", queue.ModerationSubject(moderation.Decision))
+		assert.Contains(t, message, "Halo Yayasan Pesisir")
+		assert.Contains(t, message, "<b>Beasiswa Anak Pesisir</b> perlu diperbaiki")
+		assert.Contains(t, message, "Alasan: Add the &lt;b&gt;budget&lt;/b&gt;")
+	})
+
+	t.Run("success - approved without a reason", func(t *testing.T) {
+		moderation := &model.PostModeration{Decision: model.ModerationDecisionApprove}
+
+		message := queue.ModerationMessage(institution, post, moderation)
+
+		assert.Equal(t, "Kampanye Disetujui", queue.ModerationSubject(moderation.Decision))
+		assert.Contains(t, message, "telah disetujui")
+		assert.NotContains(t, message, "Alasan")
+	})
+}
@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"institution-service/model"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ICategoryRepository interface {
+	CreateCategory(ctx context.Context, category *model.Category) (*model.Category, error)
+	GetAllCategories(ctx context.Context) ([]model.Category, error)
+	GetCategoryByID(ctx context.Context, categoryID uuid.UUID) (*model.Category, error)
+	GetCategoryBySlug(ctx context.Context, slug string) (*model.Category, error)
+	UpdateCategory(ctx context.Context, category *model.Category) (*model.Category, error)
+	DeleteCategory(ctx context.Context, categoryID uuid.UUID) error
+	GetCategoryStats(ctx context.Context) ([]model.CategoryStat, error)
+}
+
+type CategoryRepository struct {
+	db *gorm.DB
+}
+
+func NewCategoryRepository(db *gorm.DB) *CategoryRepository {
+	return &CategoryRepository{
+		db: db,
+	}
+}
+
+func (r *CategoryRepository) CreateCategory(ctx context.Context, category *model.Category) (*model.Category, error) {
+	if err := r.db.WithContext(ctx).Create(category).Error; err != nil {
+		return nil, err
+	}
+
+	return category, nil
+}
+
+func (r *CategoryRepository) GetAllCategories(ctx context.Context) ([]model.Category, error) {
+	var categories []model.Category
+	if err := r.db.WithContext(ctx).Order("name").Find(&categories).Error; err != nil {
+		return nil, err
+	}
+
+	return categories, nil
+}
+
+func (r *CategoryRepository) GetCategoryByID(ctx context.Context, categoryID uuid.UUID) (*model.Category, error) {
+	var category model.Category
+	if err := r.db.WithContext(ctx).Where("category_id = ?", categoryID).First(&category).Error; err != nil {
+		return nil, err
+	}
+
+	return &category, nil
+}
+
+func (r *CategoryRepository) GetCategoryBySlug(ctx context.Context, slug string) (*model.Category, error) {
+	var category model.Category
+	if err := r.db.WithContext(ctx).Where("slug = ?", slug).First(&category).Error; err != nil {
+		return nil, err
+	}
+
+	return &category, nil
+}
+
+func (r *CategoryRepository) UpdateCategory(ctx context.Context, category *model.Category) (*model.Category, error) {
+	err := r.db.WithContext(ctx).Model(&model.Category{}).Where("category_id = ?", category.CategoryID).
+		Updates(map[string]interface{}{
+			"slug":        category.Slug,
+			"name":        category.Name,
+			"description": category.Description,
+			"updated_at":  time.Now(),
+		}).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return r.GetCategoryByID(ctx, category.CategoryID)
+}
+
+// DeleteCategory removes the category. Its posts stay, without a category.
+func (r *CategoryRepository) DeleteCategory(ctx context.Context, categoryID uuid.UUID) error {
+	result := r.db.WithContext(ctx).Where("category_id = ?", categoryID).Delete(&model.Category{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// GetCategoryStats counts and sums the published posts of every category, one
+// row per category and currency, as the public listing shows them.
+func (r *CategoryRepository) GetCategoryStats(ctx context.Context) ([]model.CategoryStat, error) {
+	now := time.Now()
+
+	var stats []model.CategoryStat
+	err := r.db.WithContext(ctx).Model(&model.Post{}).
+		Select(`category_id, fund_target_currency AS currency, COUNT(*) AS post_count,
+			COUNT(*) FILTER (WHERE NOT (`+postFunded+`) AND date_start <= ? AND date_end >= ?) AS active_post_count,
+			COALESCE(SUM(fund_target_minor), 0) AS fund_target_minor,
+			COALESCE(SUM(fund_achieved_minor), 0) AS fund_achieved_minor`, now, now).
+		Where("category_id IS NOT NULL AND status = ? AND (deleted_at IS NULL OR deleted_at = ?)",
+			model.PostStatusPublished, "0001-01-01 00:00:00").
+		Group("category_id, fund_target_currency").
+		Scan(&stats).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
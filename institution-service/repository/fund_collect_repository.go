@@ -2,14 +2,21 @@ package repository
 
 import (
 	"context"
+	"fmt"
 
 	"institution-service/model"
+	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type IFundCollectRepository interface {
-	CreateFundCollect(ctx context.Context, fundCollect *model.FundCollect) (*model.FundCollect, error)
 	GetFundCollectByPostID(ctx context.Context, postID string) ([]model.FundCollect, error)
+	CreditFundCollect(ctx context.Context, fundCollect *model.FundCollect) (model.Money, bool, error)
+	DebitFundCollect(ctx context.Context, postID uuid.UUID, refund *model.FundCollectRefund) (model.Money, bool, error)
+	GetFundCollectByID(ctx context.Context, fundCollectID uuid.UUID) (*model.FundCollect, error)
+	GetSupporters(ctx context.Context, postID uuid.UUID, cursor uuid.UUID, limit int) ([]model.FundCollect, error)
+	SetMessageHidden(ctx context.Context, fundCollectID uuid.UUID, hidden bool) (*model.FundCollect, error)
 }
 
 type FundCollectRepository struct {
@@ -22,20 +29,164 @@ func NewFundCollectRepository(db *gorm.DB) *FundCollectRepository {
 	}
 }
 
-func (r *FundCollectRepository) CreateFundCollect(ctx context.Context, fund_collect *model.FundCollect) (*model.FundCollect, error) {
-	if err := r.db.Create(fund_collect).Error; err != nil {
+func (r *FundCollectRepository) GetFundCollectByPostID(ctx context.Context, post_id string) ([]model.FundCollect, error) {
+	var fund_collects []model.FundCollect
+
+	if err := r.db.Where("post_id = ?", post_id).Find(&fund_collects).Error; err != nil {
 		return nil, err
 	}
 
-	return fund_collect, nil
+	return fund_collects, nil
 }
 
-func (r *FundCollectRepository) GetFundCollectByPostID(ctx context.Context, post_id string) ([]model.FundCollect, error) {
-	var fund_collects []model.FundCollect
+func (r *FundCollectRepository) GetFundCollectByID(ctx context.Context, fundCollectID uuid.UUID) (*model.FundCollect, error) {
+	var fundCollect model.FundCollect
+	if err := r.db.WithContext(ctx).Where("fund_collect_id = ?", fundCollectID).First(&fundCollect).Error; err != nil {
+		return nil, err
+	}
 
-	if err := r.db.Where("post_id = ?", post_id).Find(&fund_collects).Error; err != nil {
+	return &fundCollect, nil
+}
+
+// GetSupporters lists the post's donations newest first, leaving out those
+// refunded in full. Cursor is the ID of the last fund collect of the previous
+// page.
+func (r *FundCollectRepository) GetSupporters(ctx context.Context, postID uuid.UUID, cursor uuid.UUID, limit int) ([]model.FundCollect, error) {
+	var fundCollects []model.FundCollect
+
+	query := r.db.WithContext(ctx).Where("post_id = ? AND amount_minor > 0", postID)
+	if cursor != uuid.Nil {
+		query = query.Where("(created_at, fund_collect_id) < (SELECT created_at, fund_collect_id FROM fund_collects WHERE fund_collect_id = ?)", cursor)
+	}
+
+	err := query.Order("created_at DESC, fund_collect_id DESC").Limit(limit).Find(&fundCollects).Error
+	if err != nil {
 		return nil, err
 	}
 
-	return fund_collects, nil
+	return fundCollects, nil
+}
+
+func (r *FundCollectRepository) SetMessageHidden(ctx context.Context, fundCollectID uuid.UUID, hidden bool) (*model.FundCollect, error) {
+	var fundCollect model.FundCollect
+	result := r.db.WithContext(ctx).Model(&fundCollect).
+		Clauses(clause.Returning{}).
+		Where("fund_collect_id = ?", fundCollectID).
+		Update("message_hidden", hidden)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return &fundCollect, nil
+}
+
+// CreditFundCollect inserts the fund collect and adds its amount to the post in
+// one database transaction, returning the post's new fund achieved. It returns
+// false without touching the post when the transaction was already credited.
+func (r *FundCollectRepository) CreditFundCollect(ctx context.Context, fundCollect *model.FundCollect) (model.Money, bool, error) {
+	var fundAchieved model.Money
+	credited := false
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "transaction_id"}},
+			DoNothing: true,
+		}).Create(fundCollect)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		var err error
+		if fundAchieved, err = addFundAchieved(tx, fundCollect.PostID, fundCollect.Amount); err != nil {
+			return err
+		}
+
+		credited = true
+		return nil
+	})
+	if err != nil {
+		return model.Money{}, false, err
+	}
+
+	return fundAchieved, credited, nil
+}
+
+// DebitFundCollect records the refund and takes its amount back off the fund
+// collect and its post in one database transaction, returning the post's new
+// fund achieved. It returns false without touching either when the refund was
+// already taken off.
+func (r *FundCollectRepository) DebitFundCollect(ctx context.Context, postID uuid.UUID, refund *model.FundCollectRefund) (model.Money, bool, error) {
+	var fundAchieved model.Money
+	debited := false
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "refund_id"}},
+			DoNothing: true,
+		}).Create(refund)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		result = tx.Model(&model.FundCollect{}).
+			Where("transaction_id = ? AND amount_currency = ?", refund.TransactionID, refund.Amount.Currency).
+			Updates(map[string]interface{}{
+				"amount_minor": gorm.Expr("amount_minor - ?", refund.Amount.Amount),
+				"amount":       gorm.Expr("amount - ?", refund.Amount.Major()),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("fund collect for transaction %s not found", refund.TransactionID)
+		}
+
+		var err error
+		if fundAchieved, err = addFundAchieved(tx, postID, model.NewMoney(-refund.Amount.Amount, refund.Amount.Currency)); err != nil {
+			return err
+		}
+
+		debited = true
+		return nil
+	})
+	if err != nil {
+		return model.Money{}, false, err
+	}
+
+	return fundAchieved, debited, nil
+}
+
+// addFundAchieved adds amount, which may be negative, to the post's
+// fund_achieved_minor and to the legacy fund_achieved column in one UPDATE,
+// and returns the new fund achieved. It is unscoped so a donation paid or
+// refunded after its post was deleted is still applied instead of being
+// redelivered forever.
+func addFundAchieved(tx *gorm.DB, postID uuid.UUID, amount model.Money) (model.Money, error) {
+	var post model.Post
+	result := tx.Unscoped().Model(&post).
+		Clauses(clause.Returning{Columns: []clause.Column{{Name: "fund_achieved_minor"}, {Name: "fund_achieved_currency"}}}).
+		Where("post_id = ? AND fund_achieved_currency = ?", postID, amount.Currency).
+		Updates(map[string]interface{}{
+			"fund_achieved_minor": gorm.Expr("fund_achieved_minor + ?", amount.Amount),
+			"fund_achieved":       gorm.Expr("fund_achieved + ?", amount.Major()),
+		})
+	if result.Error != nil {
+		return model.Money{}, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return model.Money{}, fmt.Errorf("post %s not found or not raised in %s", postID, amount.Currency)
+	}
+
+	return post.FundAchieved, nil
 }
@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"institution-service/model"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type IModerationRepository interface {
+	GetModerationQueue(ctx context.Context) ([]model.Post, error)
+	CreateModeration(ctx context.Context, moderation *model.PostModeration) (*model.PostModeration, error)
+	GetModerationHistory(ctx context.Context, post_id uuid.UUID) ([]model.PostModeration, error)
+}
+
+type ModerationRepository struct {
+	db *gorm.DB
+}
+
+func NewModerationRepository(db *gorm.DB) *ModerationRepository {
+	return &ModerationRepository{
+		db: db,
+	}
+}
+
+// GetModerationQueue returns the submitted posts with their institution,
+// longest waiting first. A post is submitted by its last status change.
+func (r *ModerationRepository) GetModerationQueue(ctx context.Context) ([]model.Post, error) {
+	var posts []model.Post
+	err := r.db.WithContext(ctx).Scopes(preloadPostTaxonomy).Preload("Institution").
+		Where("status = ? AND (deleted_at IS NULL OR deleted_at = ?)", model.PostStatusSubmitted, "0001-01-01 00:00:00").
+		Order("updated_at, post_id").
+		Find(&posts).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return posts, nil
+}
+
+// CreateModeration moves the post from moderation.FromStatus to
+// moderation.ToStatus and records the decision, both or neither. It returns
+// model.ErrPostStatusChanged when the post no longer has FromStatus.
+func (r *ModerationRepository) CreateModeration(ctx context.Context, moderation *model.PostModeration) (*model.PostModeration, error) {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.Post{}).
+			Where("post_id = ? AND status = ? AND (deleted_at IS NULL OR deleted_at = ?)",
+				moderation.PostID, moderation.FromStatus, "0001-01-01 00:00:00").
+			Updates(map[string]interface{}{
+				"status":     moderation.ToStatus,
+				"updated_at": time.Now(),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return model.ErrPostStatusChanged
+		}
+
+		return tx.Omit("Post").Create(moderation).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return moderation, nil
+}
+
+// GetModerationHistory returns the decisions on the post, oldest first.
+func (r *ModerationRepository) GetModerationHistory(ctx context.Context, post_id uuid.UUID) ([]model.PostModeration, error) {
+	var moderations []model.PostModeration
+	err := r.db.WithContext(ctx).Where("post_id = ?", post_id).
+		Order("created_at, moderation_id").
+		Find(&moderations).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return moderations, nil
+}
@@ -2,22 +2,26 @@ package repository
 
 import (
 	"context"
+	"fmt"
+	"strconv"
 	"time"
 
 	"institution-service/model"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type IPostRepository interface {
 	CreatePost(ctx context.Context, post *model.Post) (*model.Post, error)
-	GetAllPost(ctx context.Context) ([]model.Post, error)
+	GetAllPost(ctx context.Context, filter model.PostFilter) ([]model.Post, error)
 	GetPostByID(ctx context.Context, post_id uuid.UUID) (*model.Post, error)
+	GetPostByIDWithDeleted(ctx context.Context, post_id uuid.UUID) (*model.Post, error)
 	GetAllPostByInstitutionID(ctx context.Context, institution_id uuid.UUID) ([]model.Post, error)
 	UpdatePost(ctx context.Context, post *model.Post) (*model.Post, error)
 	DeletePost(ctx context.Context, post_id uuid.UUID) error
-	AddPostFundAchieved(ctx context.Context, post_id uuid.UUID, amount float64) (*model.Post, error)
+	UpdatePostStatus(ctx context.Context, post_id uuid.UUID, from, to model.PostStatus) (*model.Post, error)
 }
 
 type PostRepository struct {
@@ -31,27 +35,186 @@ func NewPostRepository(db *gorm.DB) *PostRepository {
 }
 
 func (r *PostRepository) CreatePost(ctx context.Context, post *model.Post) (*model.Post, error) {
-	if err := r.db.Create(post).Error; err != nil {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Create(post).Error; err != nil {
+			return err
+		}
+		if len(post.Tags) == 0 {
+			return nil
+		}
+
+		return savePostTags(tx, post)
+	})
+	if err != nil {
 		return nil, err
 	}
 
 	return post, nil
 }
 
-func (r *PostRepository) GetAllPost(ctx context.Context) ([]model.Post, error) {
-	var posts []model.Post
+// savePostTags replaces the tags of post with post.Tags, matched by name, and
+// creates the tags no post had before.
+func savePostTags(tx *gorm.DB, post *model.Post) error {
+	if err := tx.Where("post_id = ?", post.PostID).Delete(&model.PostTag{}).Error; err != nil {
+		return err
+	}
+	if len(post.Tags) == 0 {
+		return nil
+	}
+
+	names := make([]string, len(post.Tags))
+	for i, tag := range post.Tags {
+		names[i] = tag.Name
+	}
 
-	err := r.db.Where("deleted_at IS NULL OR deleted_at = ?", "0001-01-01 00:00:00").Find(&posts).Error
+	err := tx.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "name"}}, DoNothing: true}).
+		Create(&post.Tags).Error
 	if err != nil {
+		return err
+	}
+
+	var tags []model.Tag
+	if err := tx.Where("name IN ?", names).Order("name").Find(&tags).Error; err != nil {
+		return err
+	}
+
+	links := make([]model.PostTag, len(tags))
+	for i, tag := range tags {
+		links[i] = model.PostTag{PostID: post.PostID, TagID: tag.TagID}
+	}
+	if err := tx.Create(&links).Error; err != nil {
+		return err
+	}
+
+	post.Tags = tags
+	return nil
+}
+
+// preloadPostTaxonomy loads the category and tags of the posts a query finds.
+func preloadPostTaxonomy(db *gorm.DB) *gorm.DB {
+	return db.Preload("Category").Preload("Tags", func(db *gorm.DB) *gorm.DB {
+		return db.Order("name")
+	})
+}
+
+// postSorts maps each sort to the expression it orders by and its direction.
+// post_id breaks ties in the same direction so cursors are unambiguous.
+var postSorts = map[model.PostSort]struct {
+	expr string
+	desc bool
+}{
+	model.PostSortNewest:        {"created_at", true},
+	model.PostSortEndingSoon:    {"date_end", false},
+	model.PostSortMostFunded:    {"fund_achieved_minor", true},
+	model.PostSortPercentFunded: {"COALESCE(fund_achieved_minor::float8 / NULLIF(fund_target_minor, 0), 0)", true},
+}
+
+const postFunded = "fund_achieved_minor >= fund_target_minor"
+
+// GetAllPost returns one page of the public post listing, which only has
+// published posts. The caller sets filter.Limit, usually one more than the
+// page size.
+func (r *PostRepository) GetAllPost(ctx context.Context, filter model.PostFilter) ([]model.Post, error) {
+	now := time.Now()
+	query := r.db.WithContext(ctx).Where("deleted_at IS NULL OR deleted_at = ?", "0001-01-01 00:00:00").
+		Where("status = ?", model.PostStatusPublished)
+
+	if filter.Query != "" {
+		query = query.Where("search_vector @@ websearch_to_tsquery('simple', ?)", filter.Query)
+	}
+
+	switch filter.Status {
+	case model.CampaignStatusFunded:
+		query = query.Where(postFunded)
+	case model.CampaignStatusUpcoming:
+		query = query.Where("NOT ("+postFunded+") AND date_start > ?", now)
+	case model.CampaignStatusActive:
+		query = query.Where("NOT ("+postFunded+") AND date_start <= ? AND date_end >= ?", now, now)
+	case model.CampaignStatusEnded:
+		query = query.Where("NOT ("+postFunded+") AND date_end < ?", now)
+	}
+
+	if filter.InstitutionID != uuid.Nil {
+		query = query.Where("institution_id = ?", filter.InstitutionID)
+	}
+	if filter.CategoryID != uuid.Nil {
+		query = query.Where("category_id = ?", filter.CategoryID)
+	}
+	if filter.CategorySlug != "" {
+		query = query.Where("category_id IN (SELECT category_id FROM categories WHERE slug = ?)", filter.CategorySlug)
+	}
+	if len(filter.Tags) > 0 {
+		query = query.Where("post_id IN (SELECT post_tags.post_id FROM post_tags JOIN tags ON tags.tag_id = post_tags.tag_id WHERE tags.name IN ?)", filter.Tags)
+	}
+	if !filter.DateFrom.IsZero() {
+		query = query.Where("date_end >= ?", filter.DateFrom)
+	}
+	if !filter.DateTo.IsZero() {
+		query = query.Where("date_start < ?", filter.DateTo)
+	}
+	if filter.TargetMin != nil {
+		query = query.Where("fund_target_currency = ? AND fund_target_minor >= ?", filter.TargetMin.Currency, filter.TargetMin.Amount)
+	}
+	if filter.TargetMax != nil {
+		query = query.Where("fund_target_currency = ? AND fund_target_minor <= ?", filter.TargetMax.Currency, filter.TargetMax.Amount)
+	}
+
+	sort, ok := postSorts[filter.Sort]
+	if !ok {
+		sort = postSorts[model.PostSortNewest]
+	}
+	direction, comparison := "ASC", ">"
+	if sort.desc {
+		direction, comparison = "DESC", "<"
+	}
+
+	if filter.Cursor != nil {
+		value, err := postCursorValue(filter.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where(fmt.Sprintf("(%s, post_id) %s (?, ?)", sort.expr, comparison), value, filter.Cursor.PostID)
+	}
+
+	query = query.Order(fmt.Sprintf("%s %s, post_id %s", sort.expr, direction, direction))
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+
+	var posts []model.Post
+	if err := query.Scopes(preloadPostTaxonomy).Find(&posts).Error; err != nil {
 		return nil, err
 	}
 
 	return posts, nil
 }
 
+// postCursorValue converts the sort value kept in a cursor back to the type
+// of the column it is compared with.
+func postCursorValue(cursor *model.PostCursor) (interface{}, error) {
+	var (
+		value interface{}
+		err   error
+	)
+
+	switch cursor.Sort {
+	case model.PostSortMostFunded:
+		value, err = strconv.ParseInt(cursor.Value, 10, 64)
+	case model.PostSortPercentFunded:
+		value, err = strconv.ParseFloat(cursor.Value, 64)
+	default:
+		value, err = time.Parse(time.RFC3339Nano, cursor.Value)
+	}
+	if err != nil {
+		return nil, model.ErrInvalidPostCursor
+	}
+
+	return value, nil
+}
+
 func (r *PostRepository) GetPostByID(ctx context.Context, post_id uuid.UUID) (*model.Post, error) {
 	var post model.Post
-	if err := r.db.Where("post_id = ? AND (deleted_at IS NULL OR deleted_at = ?)",
+	if err := r.db.Scopes(preloadPostTaxonomy).Where("post_id = ? AND (deleted_at IS NULL OR deleted_at = ?)",
 		post_id, "0001-01-01 00:00:00").First(&post).Error; err != nil {
 		return nil, err
 	}
@@ -59,10 +222,21 @@ func (r *PostRepository) GetPostByID(ctx context.Context, post_id uuid.UUID) (*m
 	return &post, nil
 }
 
+// GetPostByIDWithDeleted also finds soft deleted posts, which transactions
+// made before the deletion still refer to.
+func (r *PostRepository) GetPostByIDWithDeleted(ctx context.Context, post_id uuid.UUID) (*model.Post, error) {
+	var post model.Post
+	if err := r.db.Unscoped().Scopes(preloadPostTaxonomy).Where("post_id = ?", post_id).First(&post).Error; err != nil {
+		return nil, err
+	}
+
+	return &post, nil
+}
+
 func (r *PostRepository) GetAllPostByInstitutionID(ctx context.Context, institution_id uuid.UUID) ([]model.Post, error) {
 	var posts []model.Post
 
-	err := r.db.Where("institution_id = ? AND (deleted_at IS NULL OR deleted_at = ?)",
+	err := r.db.Scopes(preloadPostTaxonomy).Where("institution_id = ? AND (deleted_at IS NULL OR deleted_at = ?)",
 		institution_id, "0001-01-01 00:00:00").Find(&posts).Error
 	if err != nil {
 		return nil, err
@@ -86,9 +260,35 @@ func (r *PostRepository) UpdatePost(ctx context.Context, post *model.Post) (*mod
 	if !post.DateEnd.IsZero() {
 		updates["date_end"] = post.DateEnd
 	}
+	if !post.FundTarget.IsZero() {
+		updates["fund_target_minor"] = post.FundTarget.Amount
+		updates["fund_target"] = post.FundTarget.Major()
+	}
+	if post.OverflowPolicy != "" {
+		updates["overflow_policy"] = post.OverflowPolicy
+	}
+	if post.CategoryID != nil {
+		updates["category_id"] = post.CategoryID
+	}
+
+	// Only editable posts are updated, so a post submitted meanwhile is not
+	// changed under review. A nil Tags leaves the tags as they are; an empty
+	// one removes them.
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&post).Omit(clause.Associations).Where("post_id = ? AND (deleted_at IS NULL OR deleted_at = ?) AND status IN ?",
+			post.PostID, "0001-01-01 00:00:00", []model.PostStatus{model.PostStatusDraft, model.PostStatusRejected}).Updates(updates)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return model.ErrPostStatusChanged
+		}
+		if post.Tags == nil {
+			return nil
+		}
 
-	err := r.db.Model(&post).Where("post_id = ? AND (deleted_at IS NULL OR deleted_at = ?)",
-		post.PostID, "0001-01-01 00:00:00").Updates(updates).Error
+		return savePostTags(tx, post)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -111,20 +311,23 @@ func (r *PostRepository) DeletePost(ctx context.Context, post_id uuid.UUID) erro
 	return nil
 }
 
-func (r *PostRepository) AddPostFundAchieved(ctx context.Context, post_id uuid.UUID, amount float64) (*model.Post, error) {
-	var post model.Post
-	err := r.db.Where("post_id = ? AND (deleted_at IS NULL OR deleted_at = ?)",
-		post_id, "0001-01-01 00:00:00").First(&post).Error
-	if err != nil {
-		return nil, err
+// UpdatePostStatus moves the post from one status to another. The change only
+// applies while the post still has the from status, so two concurrent changes
+// cannot both succeed.
+func (r *PostRepository) UpdatePostStatus(ctx context.Context, post_id uuid.UUID, from, to model.PostStatus) (*model.Post, error) {
+	result := r.db.WithContext(ctx).Model(&model.Post{}).
+		Where("post_id = ? AND status = ? AND (deleted_at IS NULL OR deleted_at = ?)",
+			post_id, from, "0001-01-01 00:00:00").
+		Updates(map[string]interface{}{
+			"status":     to,
+			"updated_at": time.Now(),
+		})
+	if result.Error != nil {
+		return nil, result.Error
 	}
-
-	post.FundAchieved += amount
-	err = r.db.Model(&post).Where("post_id = ? AND (deleted_at IS NULL OR deleted_at = ?)",
-		post_id, "0001-01-01 00:00:00").Update("fund_achieved", post.FundAchieved).Error
-	if err != nil {
-		return nil, err
+	if result.RowsAffected == 0 {
+		return nil, model.ErrPostStatusChanged
 	}
 
-	return &post, nil
+	return r.GetPostByID(ctx, post_id)
 }
@@ -0,0 +1,96 @@
+package tests
+
+import (
+	"context"
+	"institution-service/model"
+	"institution-service/repository"
+	"log"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func NewCategoryMockDB() (*gorm.DB, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatalf("An error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{
+		Conn: db,
+	}), &gorm.Config{})
+
+	if err != nil {
+		log.Fatalf("An error '%s' was not expected when opening gorm database", err)
+	}
+
+	return gormDB, mock
+}
+
+func TestGetCategoryStats(t *testing.T) {
+	t.Run("success - one row per category and currency", func(t *testing.T) {
+		db, mock := NewCategoryMockDB()
+		repo := repository.NewCategoryRepository(db)
+
+		categoryID := uuid.New()
+
+		mock.ExpectQuery(`SELECT category_id, fund_target_currency AS currency, COUNT\(\*\) AS post_count,.+`+
+			`COUNT\(\*\) FILTER \(WHERE NOT \(fund_achieved_minor >= fund_target_minor\) AND date_start <= \$1 AND date_end >= \$2\) AS active_post_count,.+`+
+			`FROM "posts" WHERE \(category_id IS NOT NULL AND status = \$3 AND \(deleted_at IS NULL OR deleted_at = \$4\)\) `+
+			`AND "posts"."deleted_at" IS NULL GROUP BY category_id, fund_target_currency`).
+			WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), model.PostStatusPublished, sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"category_id", "currency", "post_count", "active_post_count", "fund_target_minor", "fund_achieved_minor"}).
+				AddRow(categoryID, "IDR", 3, 2, int64(300000000), int64(120000000)).
+				AddRow(categoryID, "USD", 1, 0, int64(500000), int64(500000)))
+
+		stats, err := repo.GetCategoryStats(context.Background())
+
+		assert.NoError(t, err)
+		assert.Equal(t, []model.CategoryStat{
+			{CategoryID: categoryID, Currency: "IDR", PostCount: 3, ActivePostCount: 2, FundTargetMinor: 300000000, FundAchievedMinor: 120000000},
+			{CategoryID: categoryID, Currency: "USD", PostCount: 1, ActivePostCount: 0, FundTargetMinor: 500000, FundAchievedMinor: 500000},
+		}, stats)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestDeleteCategory(t *testing.T) {
+	t.Run("success - delete category", func(t *testing.T) {
+		db, mock := NewCategoryMockDB()
+		repo := repository.NewCategoryRepository(db)
+
+		categoryID := uuid.New()
+
+		mock.ExpectBegin()
+		mock.ExpectExec(`DELETE FROM "categories" WHERE category_id = \$1`).
+			WithArgs(categoryID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		err := repo.DeleteCategory(context.Background(), categoryID)
+
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("failed - category not found", func(t *testing.T) {
+		db, mock := NewCategoryMockDB()
+		repo := repository.NewCategoryRepository(db)
+
+		categoryID := uuid.New()
+
+		mock.ExpectBegin()
+		mock.ExpectExec(`DELETE FROM "categories" WHERE category_id = \$1`).
+			WithArgs(categoryID).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectCommit()
+
+		err := repo.DeleteCategory(context.Background(), categoryID)
+
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	})
+}
@@ -0,0 +1,312 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"institution-service/model"
+	"institution-service/repository"
+	"log"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func NewFundCollectMockDB() (*gorm.DB, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatalf("An error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{
+		Conn: db,
+	}), &gorm.Config{})
+
+	if err != nil {
+		log.Fatalf("An error '%s' was not expected when opening gorm database", err)
+	}
+
+	return gormDB, mock
+}
+
+func TestCreditFundCollect(t *testing.T) {
+	t.Run("success - credit new transaction", func(t *testing.T) {
+		db, mock := NewFundCollectMockDB()
+		repo := repository.NewFundCollectRepository(db)
+
+		fundCollect := &model.FundCollect{
+			PostID:        uuid.New(),
+			UserID:        uuid.New().String(),
+			UserName:      "donor@email.com",
+			Amount:        model.IDR(50000),
+			TransactionID: "65f1c0a2b3c4d5e6f7a8b9c0",
+		}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(`INSERT INTO "fund_collects" .+ ON CONFLICT \("transaction_id"\) DO NOTHING`).
+			WillReturnRows(sqlmock.NewRows([]string{"fund_collect_id"}).AddRow(uuid.New()))
+		mock.ExpectQuery(`UPDATE "posts" SET "fund_achieved"=fund_achieved \+ \$1,"fund_achieved_minor"=fund_achieved_minor \+ \$2,"updated_at"=\$3 WHERE post_id = \$4 AND fund_achieved_currency = \$5 RETURNING "fund_achieved_minor","fund_achieved_currency"`).
+			WithArgs(float64(50000), int64(5000000), sqlmock.AnyArg(), fundCollect.PostID, "IDR").
+			WillReturnRows(sqlmock.NewRows([]string{"fund_achieved_minor", "fund_achieved_currency"}).AddRow(int64(15000000), "IDR"))
+		mock.ExpectCommit()
+
+		ctx := context.Background()
+		fundAchieved, credited, err := repo.CreditFundCollect(ctx, fundCollect)
+
+		assert.NoError(t, err)
+		assert.True(t, credited)
+		assert.Equal(t, model.IDR(150000), fundAchieved)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success - already credited transaction leaves post untouched", func(t *testing.T) {
+		db, mock := NewFundCollectMockDB()
+		repo := repository.NewFundCollectRepository(db)
+
+		fundCollect := &model.FundCollect{
+			PostID:        uuid.New(),
+			UserID:        uuid.New().String(),
+			UserName:      "donor@email.com",
+			Amount:        model.IDR(50000),
+			TransactionID: "65f1c0a2b3c4d5e6f7a8b9c0",
+		}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(`INSERT INTO "fund_collects" .+ ON CONFLICT \("transaction_id"\) DO NOTHING`).
+			WillReturnRows(sqlmock.NewRows([]string{"fund_collect_id"}))
+		mock.ExpectCommit()
+
+		ctx := context.Background()
+		fundAchieved, credited, err := repo.CreditFundCollect(ctx, fundCollect)
+
+		assert.NoError(t, err)
+		assert.False(t, credited)
+		assert.True(t, fundAchieved.IsZero())
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("failed - update post error rolls back", func(t *testing.T) {
+		db, mock := NewFundCollectMockDB()
+		repo := repository.NewFundCollectRepository(db)
+
+		fundCollect := &model.FundCollect{
+			PostID:        uuid.New(),
+			UserID:        uuid.New().String(),
+			UserName:      "donor@email.com",
+			Amount:        model.IDR(50000),
+			TransactionID: "65f1c0a2b3c4d5e6f7a8b9c0",
+		}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(`INSERT INTO "fund_collects" .+ ON CONFLICT \("transaction_id"\) DO NOTHING`).
+			WillReturnRows(sqlmock.NewRows([]string{"fund_collect_id"}).AddRow(uuid.New()))
+		mock.ExpectQuery(`UPDATE "posts" SET "fund_achieved"=fund_achieved \+ \$1,"fund_achieved_minor"=fund_achieved_minor \+ \$2,"updated_at"=\$3 WHERE post_id = \$4 AND fund_achieved_currency = \$5 RETURNING "fund_achieved_minor","fund_achieved_currency"`).
+			WithArgs(float64(50000), int64(5000000), sqlmock.AnyArg(), fundCollect.PostID, "IDR").
+			WillReturnError(fmt.Errorf("unexpected error"))
+		mock.ExpectRollback()
+
+		ctx := context.Background()
+		fundAchieved, credited, err := repo.CreditFundCollect(ctx, fundCollect)
+
+		assert.Error(t, err)
+		assert.False(t, credited)
+		assert.True(t, fundAchieved.IsZero())
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("failed - post raised in another currency rolls back", func(t *testing.T) {
+		db, mock := NewFundCollectMockDB()
+		repo := repository.NewFundCollectRepository(db)
+
+		fundCollect := &model.FundCollect{
+			PostID:        uuid.New(),
+			UserID:        uuid.New().String(),
+			UserName:      "donor@email.com",
+			Amount:        model.NewMoney(2500, "USD"),
+			TransactionID: "65f1c0a2b3c4d5e6f7a8b9c0",
+		}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(`INSERT INTO "fund_collects" .+ ON CONFLICT \("transaction_id"\) DO NOTHING`).
+			WillReturnRows(sqlmock.NewRows([]string{"fund_collect_id"}).AddRow(uuid.New()))
+		mock.ExpectQuery(`UPDATE "posts" SET "fund_achieved"=fund_achieved \+ \$1,"fund_achieved_minor"=fund_achieved_minor \+ \$2,"updated_at"=\$3 WHERE post_id = \$4 AND fund_achieved_currency = \$5 RETURNING "fund_achieved_minor","fund_achieved_currency"`).
+			WithArgs(float64(25), int64(2500), sqlmock.AnyArg(), fundCollect.PostID, "USD").
+			WillReturnRows(sqlmock.NewRows([]string{"fund_achieved_minor", "fund_achieved_currency"}))
+		mock.ExpectRollback()
+
+		ctx := context.Background()
+		fundAchieved, credited, err := repo.CreditFundCollect(ctx, fundCollect)
+
+		assert.Error(t, err)
+		assert.False(t, credited)
+		assert.True(t, fundAchieved.IsZero())
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestDebitFundCollect(t *testing.T) {
+	newRefund := func() *model.FundCollectRefund {
+		return &model.FundCollectRefund{
+			RefundID:      "rfd-1",
+			TransactionID: "65f1c0a2b3c4d5e6f7a8b9c0",
+			Amount:        model.IDR(20000),
+		}
+	}
+
+	t.Run("success - debit fund collect and post", func(t *testing.T) {
+		db, mock := NewFundCollectMockDB()
+		repo := repository.NewFundCollectRepository(db)
+
+		postID := uuid.New()
+
+		mock.ExpectBegin()
+		mock.ExpectExec(`INSERT INTO "fund_collect_refunds" .+ ON CONFLICT \("refund_id"\) DO NOTHING`).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`UPDATE "fund_collects" SET "amount"=amount - \$1,"amount_minor"=amount_minor - \$2,"updated_at"=\$3 WHERE \(transaction_id = \$4 AND amount_currency = \$5\)`).
+			WithArgs(float64(20000), int64(2000000), sqlmock.AnyArg(), "65f1c0a2b3c4d5e6f7a8b9c0", "IDR").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery(`UPDATE "posts" SET "fund_achieved"=fund_achieved \+ \$1,"fund_achieved_minor"=fund_achieved_minor \+ \$2,"updated_at"=\$3 WHERE post_id = \$4 AND fund_achieved_currency = \$5 RETURNING "fund_achieved_minor","fund_achieved_currency"`).
+			WithArgs(float64(-20000), int64(-2000000), sqlmock.AnyArg(), postID, "IDR").
+			WillReturnRows(sqlmock.NewRows([]string{"fund_achieved_minor", "fund_achieved_currency"}).AddRow(int64(3000000), "IDR"))
+		mock.ExpectCommit()
+
+		ctx := context.Background()
+		fundAchieved, debited, err := repo.DebitFundCollect(ctx, postID, newRefund())
+
+		assert.NoError(t, err)
+		assert.True(t, debited)
+		assert.Equal(t, model.IDR(30000), fundAchieved)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success - already debited refund leaves fund collect untouched", func(t *testing.T) {
+		db, mock := NewFundCollectMockDB()
+		repo := repository.NewFundCollectRepository(db)
+
+		mock.ExpectBegin()
+		mock.ExpectExec(`INSERT INTO "fund_collect_refunds" .+ ON CONFLICT \("refund_id"\) DO NOTHING`).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectCommit()
+
+		ctx := context.Background()
+		fundAchieved, debited, err := repo.DebitFundCollect(ctx, uuid.New(), newRefund())
+
+		assert.NoError(t, err)
+		assert.False(t, debited)
+		assert.True(t, fundAchieved.IsZero())
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("failed - missing fund collect rolls back", func(t *testing.T) {
+		db, mock := NewFundCollectMockDB()
+		repo := repository.NewFundCollectRepository(db)
+
+		mock.ExpectBegin()
+		mock.ExpectExec(`INSERT INTO "fund_collect_refunds" .+ ON CONFLICT \("refund_id"\) DO NOTHING`).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`UPDATE "fund_collects" SET "amount"=amount - \$1,"amount_minor"=amount_minor - \$2,"updated_at"=\$3 WHERE \(transaction_id = \$4 AND amount_currency = \$5\)`).
+			WithArgs(float64(20000), int64(2000000), sqlmock.AnyArg(), "65f1c0a2b3c4d5e6f7a8b9c0", "IDR").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectRollback()
+
+		ctx := context.Background()
+		fundAchieved, debited, err := repo.DebitFundCollect(ctx, uuid.New(), newRefund())
+
+		assert.Error(t, err)
+		assert.False(t, debited)
+		assert.True(t, fundAchieved.IsZero())
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestGetSupporters(t *testing.T) {
+	t.Run("success - first page", func(t *testing.T) {
+		db, mock := NewFundCollectMockDB()
+		repo := repository.NewFundCollectRepository(db)
+
+		postID := uuid.New()
+		fundCollectID := uuid.New()
+
+		rows := sqlmock.NewRows([]string{"fund_collect_id", "post_id", "user_name", "amount_minor", "amount_currency", "message"}).
+			AddRow(fundCollectID, postID, "Hamba Allah", int64(7500000), "IDR", "Semoga lekas pulih")
+
+		mock.ExpectQuery(`SELECT \* FROM "fund_collects" WHERE \(post_id = \$1 AND amount_minor > 0\) AND "fund_collects"."deleted_at" IS NULL ORDER BY created_at DESC, fund_collect_id DESC LIMIT \$2`).
+			WithArgs(postID, 21).
+			WillReturnRows(rows)
+
+		ctx := context.Background()
+		result, err := repo.GetSupporters(ctx, postID, uuid.Nil, 21)
+
+		assert.NoError(t, err)
+		assert.Len(t, result, 1)
+		assert.Equal(t, "Semoga lekas pulih", result[0].Message)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success - page after cursor", func(t *testing.T) {
+		db, mock := NewFundCollectMockDB()
+		repo := repository.NewFundCollectRepository(db)
+
+		postID := uuid.New()
+		cursor := uuid.New()
+
+		mock.ExpectQuery(`SELECT \* FROM "fund_collects" WHERE \(post_id = \$1 AND amount_minor > 0\) AND \(created_at, fund_collect_id\) < \(SELECT created_at, fund_collect_id FROM fund_collects WHERE fund_collect_id = \$2\) .+ LIMIT \$3`).
+			WithArgs(postID, cursor, 21).
+			WillReturnRows(sqlmock.NewRows([]string{"fund_collect_id"}))
+
+		ctx := context.Background()
+		result, err := repo.GetSupporters(ctx, postID, cursor, 21)
+
+		assert.NoError(t, err)
+		assert.Empty(t, result)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestSetMessageHidden(t *testing.T) {
+	t.Run("success - hide message", func(t *testing.T) {
+		db, mock := NewFundCollectMockDB()
+		repo := repository.NewFundCollectRepository(db)
+
+		fundCollectID := uuid.New()
+
+		rows := sqlmock.NewRows([]string{"fund_collect_id", "message", "message_hidden"}).
+			AddRow(fundCollectID, "Spam", true)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(`UPDATE "fund_collects" SET "message_hidden"=\$1,"updated_at"=\$2 WHERE fund_collect_id = \$3 AND "fund_collects"."deleted_at" IS NULL RETURNING \*`).
+			WithArgs(true, sqlmock.AnyArg(), fundCollectID).
+			WillReturnRows(rows)
+		mock.ExpectCommit()
+
+		ctx := context.Background()
+		result, err := repo.SetMessageHidden(ctx, fundCollectID, true)
+
+		assert.NoError(t, err)
+		assert.True(t, result.MessageHidden)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("failed - fund collect not found", func(t *testing.T) {
+		db, mock := NewFundCollectMockDB()
+		repo := repository.NewFundCollectRepository(db)
+
+		fundCollectID := uuid.New()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(`UPDATE "fund_collects" SET .+ RETURNING \*`).
+			WithArgs(true, sqlmock.AnyArg(), fundCollectID).
+			WillReturnRows(sqlmock.NewRows([]string{"fund_collect_id"}))
+		mock.ExpectCommit()
+
+		ctx := context.Background()
+		result, err := repo.SetMessageHidden(ctx, fundCollectID, true)
+
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+		assert.Nil(t, result)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -58,6 +58,9 @@ func TestRegisterInstitution(t *testing.T) {
 				sqlmock.AnyArg(),
 				sqlmock.AnyArg(),
 				sqlmock.AnyArg(),
+				model.VerificationStatusUnverified,
+				"",
+				nil,
 			).
 			WillReturnRows(
 				sqlmock.NewRows([]string{"institution_id"}).AddRow(testInstitution.InstitutionID),
@@ -145,6 +148,9 @@ func TestRegisterInstitution(t *testing.T) {
 				sqlmock.AnyArg(),
 				sqlmock.AnyArg(),
 				sqlmock.AnyArg(),
+				model.VerificationStatusUnverified,
+				"",
+				nil,
 			).
 			WillReturnError(gorm.ErrRecordNotFound)
 		mock.ExpectRollback()
@@ -181,6 +187,9 @@ func TestRegisterInstitution(t *testing.T) {
 				sqlmock.AnyArg(),
 				sqlmock.AnyArg(),
 				sqlmock.AnyArg(),
+				model.VerificationStatusUnverified,
+				"",
+				nil,
 			).
 			WillReturnError(&pq.Error{Code: "23505"})
 		mock.ExpectRollback()
@@ -0,0 +1,97 @@
+package tests
+
+import (
+	"context"
+	"institution-service/model"
+	"institution-service/repository"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateModeration(t *testing.T) {
+	t.Run("success - moves the post and records the decision", func(t *testing.T) {
+		db, mock := NewPostMockDB()
+		repo := repository.NewModerationRepository(db)
+
+		moderation := &model.PostModeration{
+			PostID:         uuid.New(),
+			Decision:       model.ModerationDecisionReject,
+			FromStatus:     model.PostStatusSubmitted,
+			ToStatus:       model.PostStatusRejected,
+			Reason:         "The bank account does not belong to the institution",
+			ModeratorEmail: "moderator@email.com",
+		}
+		moderationID := uuid.New()
+
+		mock.ExpectBegin()
+		mock.ExpectExec(`UPDATE "posts" SET "status"=\$1,"updated_at"=\$2 WHERE \(post_id = \$3 AND status = \$4 AND .+`).
+			WithArgs(model.PostStatusRejected, sqlmock.AnyArg(), moderation.PostID, model.PostStatusSubmitted, sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery(`INSERT INTO "post_moderations" \("post_id","decision","from_status","to_status","reason","moderator_email","created_at"\)`).
+			WithArgs(moderation.PostID, model.ModerationDecisionReject, model.PostStatusSubmitted, model.PostStatusRejected,
+				moderation.Reason, moderation.ModeratorEmail, sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"moderation_id"}).AddRow(moderationID))
+		mock.ExpectCommit()
+
+		result, err := repo.CreateModeration(context.Background(), moderation)
+
+		assert.NoError(t, err)
+		assert.Equal(t, moderationID, result.ModerationID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("failed - status changed concurrently", func(t *testing.T) {
+		db, mock := NewPostMockDB()
+		repo := repository.NewModerationRepository(db)
+
+		moderation := &model.PostModeration{
+			PostID:         uuid.New(),
+			Decision:       model.ModerationDecisionApprove,
+			FromStatus:     model.PostStatusSubmitted,
+			ToStatus:       model.PostStatusPublished,
+			ModeratorEmail: "moderator@email.com",
+		}
+
+		mock.ExpectBegin()
+		mock.ExpectExec(`UPDATE "posts" SET "status"=\$1,"updated_at"=\$2 WHERE \(post_id = \$3 AND status = \$4 AND .+`).
+			WithArgs(model.PostStatusPublished, sqlmock.AnyArg(), moderation.PostID, model.PostStatusSubmitted, sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectRollback()
+
+		result, err := repo.CreateModeration(context.Background(), moderation)
+
+		assert.ErrorIs(t, err, model.ErrPostStatusChanged)
+		assert.Nil(t, result)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestGetModerationHistory(t *testing.T) {
+	t.Run("success - oldest decision first", func(t *testing.T) {
+		db, mock := NewPostMockDB()
+		repo := repository.NewModerationRepository(db)
+
+		postID := uuid.New()
+		requested := time.Now().Add(-48 * time.Hour)
+		approved := time.Now()
+
+		mock.ExpectQuery(`SELECT \* FROM "post_moderations" WHERE post_id = \$1 ORDER BY created_at, moderation_id`).
+			WithArgs(postID).
+			WillReturnRows(sqlmock.NewRows([]string{"moderation_id", "post_id", "decision", "from_status", "to_status", "reason", "moderator_email", "created_at"}).
+				AddRow(uuid.New(), postID, model.ModerationDecisionRequestChanges, model.PostStatusSubmitted, model.PostStatusDraft, "Add the budget", "moderator@email.com", requested).
+				AddRow(uuid.New(), postID, model.ModerationDecisionApprove, model.PostStatusSubmitted, model.PostStatusPublished, "", "moderator@email.com", approved))
+
+		moderations, err := repo.GetModerationHistory(context.Background(), postID)
+
+		assert.NoError(t, err)
+		assert.Len(t, moderations, 2)
+		assert.Equal(t, model.ModerationDecisionRequestChanges, moderations[0].Decision)
+		assert.Equal(t, "Add the budget", moderations[0].Reason)
+		assert.Equal(t, model.PostStatusPublished, moderations[1].ToStatus)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -45,7 +45,8 @@ func TestCreatePost(t *testing.T) {
 			Body:          "Body",
 			DateStart:     time.Now(),
 			DateEnd:       time.Now(),
-			FundTarget:    1000000,
+			FundTarget:    model.IDR(1000000),
+			Status:        model.PostStatusDraft,
 		}
 
 		mock.ExpectBegin()
@@ -56,11 +57,18 @@ func TestCreatePost(t *testing.T) {
 				post.Body,
 				post.DateStart,
 				post.DateEnd,
-				post.FundTarget,
+				post.FundTarget.Amount,
+				post.FundTarget.Currency,
+				int64(0),
+				"",
+				float64(1000000),
 				float64(0),
+				"ACCEPT",
 				sqlmock.AnyArg(),
 				sqlmock.AnyArg(),
 				nil,
+				nil,
+				post.Status,
 				post.PostID,
 			).
 			WillReturnRows(sqlmock.NewRows([]string{"post_id"}).AddRow(post.PostID))
@@ -80,6 +88,46 @@ func TestCreatePost(t *testing.T) {
 		assert.Equal(t, post.FundTarget, result.FundTarget)
 	})
 
+	t.Run("success - create post with new and existing tags", func(t *testing.T) {
+		db, mock := NewPostMockDB()
+		repo := repository.NewPostRepository(db)
+
+		post := &model.Post{
+			PostID:        uuid.New(),
+			InstitutionID: uuid.New(),
+			Title:         "Title",
+			Body:          "Body",
+			DateStart:     time.Now(),
+			DateEnd:       time.Now(),
+			FundTarget:    model.IDR(1000000),
+			Tags:          []model.Tag{{Name: "papua"}, {Name: "sd"}},
+		}
+		papua, sd := uuid.New(), uuid.New()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(`INSERT INTO "posts"`).
+			WillReturnRows(sqlmock.NewRows([]string{"post_id"}).AddRow(post.PostID))
+		mock.ExpectExec(`DELETE FROM "post_tags" WHERE post_id = \$1`).
+			WithArgs(post.PostID).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(`INSERT INTO "tags" \("name"\) VALUES \(\$1\),\(\$2\) ON CONFLICT \("name"\) DO NOTHING RETURNING "tag_id"`).
+			WithArgs("papua", "sd").
+			WillReturnRows(sqlmock.NewRows([]string{"tag_id"}).AddRow(sd))
+		mock.ExpectQuery(`SELECT \* FROM "tags" WHERE name IN \(\$1,\$2\) ORDER BY name`).
+			WithArgs("papua", "sd").
+			WillReturnRows(sqlmock.NewRows([]string{"tag_id", "name"}).AddRow(papua, "papua").AddRow(sd, "sd"))
+		mock.ExpectExec(`INSERT INTO "post_tags" \("post_id","tag_id"\) VALUES \(\$1,\$2\),\(\$3,\$4\)`).
+			WithArgs(post.PostID, papua, post.PostID, sd).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectCommit()
+
+		result, err := repo.CreatePost(context.Background(), post)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []model.Tag{{TagID: papua, Name: "papua"}, {TagID: sd, Name: "sd"}}, result.Tags)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
 	t.Run("failed - create post", func(t *testing.T) {
 		db, mock := NewPostMockDB()
 		repo := repository.NewPostRepository(db)
@@ -91,7 +139,8 @@ func TestCreatePost(t *testing.T) {
 			Body:          "Body",
 			DateStart:     time.Now(),
 			DateEnd:       time.Now(),
-			FundTarget:    1000000,
+			FundTarget:    model.IDR(1000000),
+			Status:        model.PostStatusDraft,
 		}
 
 		mock.ExpectBegin()
@@ -102,11 +151,18 @@ func TestCreatePost(t *testing.T) {
 				post.Body,
 				post.DateStart,
 				post.DateEnd,
-				post.FundTarget,
+				post.FundTarget.Amount,
+				post.FundTarget.Currency,
+				int64(0),
+				"",
+				float64(1000000),
 				float64(0),
+				"ACCEPT",
 				sqlmock.AnyArg(),
 				sqlmock.AnyArg(),
 				nil,
+				nil,
+				post.Status,
 				post.PostID,
 			).
 			WillReturnError(fmt.Errorf("unexpected error"))
@@ -133,11 +189,11 @@ func TestGetPostByID(t *testing.T) {
 			Body:          "Body",
 			DateStart:     time.Now(),
 			DateEnd:       time.Now(),
-			FundTarget:    1000000,
+			FundTarget:    model.IDR(1000000),
 		}
 
-		rows := sqlmock.NewRows([]string{"post_id", "institution_id", "title", "body", "date_start", "date_end", "fund_target", "fund_achieved"}).
-			AddRow(post.PostID, post.InstitutionID, post.Title, post.Body, post.DateStart, post.DateEnd, post.FundTarget, float64(0))
+		rows := sqlmock.NewRows([]string{"post_id", "institution_id", "title", "body", "date_start", "date_end", "fund_target_minor", "fund_target_currency", "fund_achieved_minor", "fund_achieved_currency"}).
+			AddRow(post.PostID, post.InstitutionID, post.Title, post.Body, post.DateStart, post.DateEnd, post.FundTarget.Amount, post.FundTarget.Currency, int64(0), "IDR")
 
 		mock.ExpectQuery(`SELECT \* FROM "posts" WHERE .+post_id = \$1.+`).
 			WithArgs(
@@ -146,6 +202,9 @@ func TestGetPostByID(t *testing.T) {
 				sqlmock.AnyArg(),
 			).
 			WillReturnRows(rows)
+		mock.ExpectQuery(`SELECT \* FROM "post_tags" WHERE "post_tags"."post_id" = \$1`).
+			WithArgs(postID).
+			WillReturnRows(sqlmock.NewRows([]string{"post_id", "tag_id"}))
 
 		ctx := context.Background()
 		result, err := repo.GetPostByID(ctx, postID)
@@ -183,6 +242,31 @@ func TestGetPostByID(t *testing.T) {
 	})
 }
 
+func TestGetPostByIDWithDeleted(t *testing.T) {
+	t.Run("success - finds a deleted post", func(t *testing.T) {
+		db, mock := NewPostMockDB()
+		repo := repository.NewPostRepository(db)
+
+		postID := uuid.New()
+		deletedAt := time.Now()
+
+		mock.ExpectQuery(`SELECT \* FROM "posts" WHERE post_id = \$1 ORDER BY .+ LIMIT \$2`).
+			WithArgs(postID, 1).
+			WillReturnRows(sqlmock.NewRows([]string{"post_id", "institution_id", "title", "status", "deleted_at"}).
+				AddRow(postID, uuid.New(), "Title", model.PostStatusPublished, deletedAt))
+		mock.ExpectQuery(`SELECT \* FROM "post_tags" WHERE "post_tags"."post_id" = \$1`).
+			WithArgs(postID).
+			WillReturnRows(sqlmock.NewRows([]string{"post_id", "tag_id"}))
+
+		result, err := repo.GetPostByIDWithDeleted(context.Background(), postID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, postID, result.PostID)
+		assert.True(t, result.IsDeleted())
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
 func TestUpdatePost(t *testing.T) {
 	t.Run("success - update post", func(t *testing.T) {
 		db, mock := NewPostMockDB()
@@ -196,7 +280,7 @@ func TestUpdatePost(t *testing.T) {
 			Body:          "Body",
 			DateStart:     time.Now(),
 			DateEnd:       time.Now(),
-			FundTarget:    1000000,
+			FundTarget:    model.IDR(1000000),
 		}
 
 		mock.ExpectBegin()
@@ -205,17 +289,21 @@ func TestUpdatePost(t *testing.T) {
 				post.Body,
 				post.DateEnd,
 				post.DateStart,
+				post.FundTarget.Major(),
+				post.FundTarget.Amount,
 				post.Title,
 				sqlmock.AnyArg(),
 				post.PostID,
 				sqlmock.AnyArg(),
+				model.PostStatusDraft,
+				model.PostStatusRejected,
 				post.PostID,
 			).
 			WillReturnResult(sqlmock.NewResult(0, 1))
 		mock.ExpectCommit()
 
-		rows := sqlmock.NewRows([]string{"post_id", "institution_id", "title", "body", "date_start", "date_end", "fund_target", "fund_achieved"}).
-			AddRow(post.PostID, post.InstitutionID, post.Title, post.Body, post.DateStart, post.DateEnd, post.FundTarget, float64(0))
+		rows := sqlmock.NewRows([]string{"post_id", "institution_id", "title", "body", "date_start", "date_end", "fund_target_minor", "fund_target_currency", "fund_achieved_minor", "fund_achieved_currency"}).
+			AddRow(post.PostID, post.InstitutionID, post.Title, post.Body, post.DateStart, post.DateEnd, post.FundTarget.Amount, post.FundTarget.Currency, int64(0), "IDR")
 
 		mock.ExpectQuery(`SELECT \* FROM "posts" WHERE .+post_id = \$1.+`).
 			WithArgs(
@@ -224,6 +312,9 @@ func TestUpdatePost(t *testing.T) {
 				sqlmock.AnyArg(),
 			).
 			WillReturnRows(rows)
+		mock.ExpectQuery(`SELECT \* FROM "post_tags" WHERE "post_tags"."post_id" = \$1`).
+			WithArgs(post.PostID).
+			WillReturnRows(sqlmock.NewRows([]string{"post_id", "tag_id"}))
 
 		ctx := context.Background()
 		result, err := repo.UpdatePost(ctx, post)
@@ -239,6 +330,29 @@ func TestUpdatePost(t *testing.T) {
 		assert.Equal(t, post.FundTarget, result.FundTarget)
 	})
 
+	t.Run("failed - post is no longer editable", func(t *testing.T) {
+		db, mock := NewPostMockDB()
+		repo := repository.NewPostRepository(db)
+
+		post := &model.Post{
+			PostID: uuid.New(),
+			Title:  "Title",
+		}
+
+		mock.ExpectBegin()
+		mock.ExpectExec(`UPDATE "posts" SET .+ WHERE .+status IN \(\$\d+,\$\d+\).+`).
+			WithArgs(post.Title, sqlmock.AnyArg(), post.PostID, sqlmock.AnyArg(), model.PostStatusDraft, model.PostStatusRejected, post.PostID).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectRollback()
+
+		ctx := context.Background()
+		result, err := repo.UpdatePost(ctx, post)
+
+		assert.ErrorIs(t, err, model.ErrPostStatusChanged)
+		assert.Nil(t, result)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
 	t.Run("failed - update post", func(t *testing.T) {
 		db, mock := NewPostMockDB()
 		repo := repository.NewPostRepository(db)
@@ -251,7 +365,7 @@ func TestUpdatePost(t *testing.T) {
 			Body:          "Body",
 			DateStart:     time.Now(),
 			DateEnd:       time.Now(),
-			FundTarget:    1000000,
+			FundTarget:    model.IDR(1000000),
 		}
 
 		mock.ExpectBegin()
@@ -260,10 +374,14 @@ func TestUpdatePost(t *testing.T) {
 				post.Body,
 				post.DateEnd,
 				post.DateStart,
+				post.FundTarget.Major(),
+				post.FundTarget.Amount,
 				post.Title,
 				sqlmock.AnyArg(),
 				post.PostID,
 				sqlmock.AnyArg(),
+				model.PostStatusDraft,
+				model.PostStatusRejected,
 				post.PostID,
 			).
 			WillReturnError(fmt.Errorf("unexpected error"))
@@ -324,3 +442,109 @@ func TestDeletePost(t *testing.T) {
 		assert.Error(t, err)
 	})
 }
+
+func TestGetAllPost(t *testing.T) {
+	t.Run("success - search, filter and continue after the cursor", func(t *testing.T) {
+		db, mock := NewPostMockDB()
+		repo := repository.NewPostRepository(db)
+
+		institutionID := uuid.New()
+		postID := uuid.New()
+		categoryID := uuid.New()
+		tagID := uuid.New()
+		cursor := &model.PostCursor{Sort: model.PostSortPercentFunded, Value: "0.5", PostID: uuid.New()}
+
+		rows := sqlmock.NewRows([]string{"post_id", "title", "fund_target_minor", "fund_target_currency", "fund_achieved_minor", "fund_achieved_currency", "category_id"}).
+			AddRow(postID, "Beasiswa", int64(100000000), "IDR", int64(40000000), "IDR", categoryID)
+
+		mock.ExpectQuery(`SELECT \* FROM "posts" WHERE \(deleted_at IS NULL OR deleted_at = \$1\) AND status = \$2 `+
+			`AND search_vector @@ websearch_to_tsquery\('simple', \$3\) `+
+			`AND \(NOT \(fund_achieved_minor >= fund_target_minor\) AND date_start <= \$4 AND date_end >= \$5\) `+
+			`AND institution_id = \$6 `+
+			`AND category_id IN \(SELECT category_id FROM categories WHERE slug = \$7\) `+
+			`AND post_id IN \(SELECT post_tags.post_id FROM post_tags JOIN tags ON tags.tag_id = post_tags.tag_id WHERE tags.name IN \(\$8,\$9\)\) `+
+			`AND \(fund_target_currency = \$10 AND fund_target_minor >= \$11\) `+
+			`AND \(COALESCE\(fund_achieved_minor::float8 / NULLIF\(fund_target_minor, 0\), 0\), post_id\) < \(\$12, \$13\) `+
+			`AND "posts"."deleted_at" IS NULL `+
+			`ORDER BY COALESCE\(fund_achieved_minor::float8 / NULLIF\(fund_target_minor, 0\), 0\) DESC, post_id DESC LIMIT \$14`).
+			WithArgs(
+				sqlmock.AnyArg(),
+				model.PostStatusPublished,
+				"beasiswa",
+				sqlmock.AnyArg(),
+				sqlmock.AnyArg(),
+				institutionID,
+				"scholarships",
+				"papua",
+				"sd",
+				"IDR",
+				int64(100000000),
+				0.5,
+				cursor.PostID,
+				21,
+			).
+			WillReturnRows(rows)
+		mock.ExpectQuery(`SELECT \* FROM "categories" WHERE "categories"."category_id" = \$1`).
+			WithArgs(categoryID).
+			WillReturnRows(sqlmock.NewRows([]string{"category_id", "slug", "name"}).AddRow(categoryID, "scholarships", "Scholarships"))
+		mock.ExpectQuery(`SELECT \* FROM "post_tags" WHERE "post_tags"."post_id" = \$1`).
+			WithArgs(postID).
+			WillReturnRows(sqlmock.NewRows([]string{"post_id", "tag_id"}).AddRow(postID, tagID))
+		mock.ExpectQuery(`SELECT \* FROM "tags" WHERE "tags"."tag_id" = \$1 ORDER BY name`).
+			WithArgs(tagID).
+			WillReturnRows(sqlmock.NewRows([]string{"tag_id", "name"}).AddRow(tagID, "papua"))
+
+		target := model.IDR(1000000)
+		posts, err := repo.GetAllPost(context.Background(), model.PostFilter{
+			Query:         "beasiswa",
+			Status:        model.CampaignStatusActive,
+			InstitutionID: institutionID,
+			CategorySlug:  "scholarships",
+			Tags:          []string{"papua", "sd"},
+			TargetMin:     &target,
+			Sort:          model.PostSortPercentFunded,
+			Cursor:        cursor,
+			Limit:         21,
+		})
+
+		assert.NoError(t, err)
+		assert.Len(t, posts, 1)
+		assert.Equal(t, "Scholarships", posts[0].Category.Name)
+		assert.Equal(t, []model.Tag{{TagID: tagID, Name: "papua"}}, posts[0].Tags)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("failed - cursor value of the wrong type", func(t *testing.T) {
+		db, _ := NewPostMockDB()
+		repo := repository.NewPostRepository(db)
+
+		posts, err := repo.GetAllPost(context.Background(), model.PostFilter{
+			Sort:   model.PostSortMostFunded,
+			Cursor: &model.PostCursor{Sort: model.PostSortMostFunded, Value: "lots", PostID: uuid.New()},
+		})
+
+		assert.ErrorIs(t, err, model.ErrInvalidPostCursor)
+		assert.Nil(t, posts)
+	})
+}
+
+func TestUpdatePostStatus(t *testing.T) {
+	t.Run("failed - status changed concurrently", func(t *testing.T) {
+		db, mock := NewPostMockDB()
+		repo := repository.NewPostRepository(db)
+
+		postID := uuid.New()
+
+		mock.ExpectBegin()
+		mock.ExpectExec(`UPDATE "posts" SET "status"=\$1,"updated_at"=\$2 WHERE \(post_id = \$3 AND status = \$4 AND .+`).
+			WithArgs(model.PostStatusPublished, sqlmock.AnyArg(), postID, model.PostStatusSubmitted, sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectCommit()
+
+		post, err := repo.UpdatePostStatus(context.Background(), postID, model.PostStatusSubmitted, model.PostStatusPublished)
+
+		assert.ErrorIs(t, err, model.ErrPostStatusChanged)
+		assert.Nil(t, post)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
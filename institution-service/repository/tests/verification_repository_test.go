@@ -0,0 +1,100 @@
+package tests
+
+import (
+	"context"
+	"institution-service/model"
+	"institution-service/repository"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSaveDocument(t *testing.T) {
+	t.Run("success - replaces the document of the same type", func(t *testing.T) {
+		db, mock := NewInstitutionMockDB()
+		repo := repository.NewVerificationRepository(db)
+
+		document := &model.InstitutionDocument{
+			DocumentID:    uuid.New(),
+			InstitutionID: uuid.New(),
+			Type:          model.DocumentTypeTaxID,
+			Filename:      "npwp.pdf",
+			ContentType:   "application/pdf",
+			Size:          1024,
+			StorageKey:    "institutions/new.pdf",
+		}
+		previousID := uuid.New()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT \* FROM "institution_documents" WHERE institution_id = \$1 AND type = \$2 ORDER BY .+ LIMIT \$3`).
+			WithArgs(document.InstitutionID, model.DocumentTypeTaxID, 1).
+			WillReturnRows(sqlmock.NewRows([]string{"document_id", "institution_id", "type", "storage_key"}).
+				AddRow(previousID, document.InstitutionID, model.DocumentTypeTaxID, "institutions/old.pdf"))
+		mock.ExpectExec(`DELETE FROM "institution_documents" WHERE "institution_documents"."document_id" = \$1`).
+			WithArgs(previousID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery(`INSERT INTO "institution_documents"`).
+			WithArgs(document.InstitutionID, model.DocumentTypeTaxID, "npwp.pdf", "application/pdf", int64(1024),
+				"institutions/new.pdf", sqlmock.AnyArg(), document.DocumentID).
+			WillReturnRows(sqlmock.NewRows([]string{"document_id"}).AddRow(document.DocumentID))
+		mock.ExpectCommit()
+
+		replaced, err := repo.SaveDocument(context.Background(), document)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "institutions/old.pdf", replaced.StorageKey)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestUpdateVerificationStatus(t *testing.T) {
+	t.Run("success - approval records when the institution was verified", func(t *testing.T) {
+		db, mock := NewInstitutionMockDB()
+		repo := repository.NewVerificationRepository(db)
+
+		institutionID := uuid.New()
+
+		mock.ExpectBegin()
+		mock.ExpectExec(`UPDATE "institutions" SET "updated_at"=\$1,"verification_note"=\$2,"verification_status"=\$3,"verified_at"=\$4 WHERE \(institution_id = \$5 AND verification_status = \$6 AND .+`).
+			WithArgs(sqlmock.AnyArg(), "", model.VerificationStatusVerified, sqlmock.AnyArg(),
+				institutionID, model.VerificationStatusPending, sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+		mock.ExpectQuery(`SELECT \* FROM "institutions" WHERE institution_id = \$1`).
+			WithArgs(institutionID, 1).
+			WillReturnRows(sqlmock.NewRows([]string{"institution_id", "name", "verification_status", "verified_at"}).
+				AddRow(institutionID, "Yayasan Pesisir", model.VerificationStatusVerified, time.Now()))
+
+		institution, err := repo.UpdateVerificationStatus(context.Background(), institutionID,
+			model.VerificationStatusPending, model.VerificationStatusVerified, "")
+
+		assert.NoError(t, err)
+		assert.True(t, institution.IsVerified())
+		assert.NotNil(t, institution.VerifiedAt)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("failed - status changed concurrently", func(t *testing.T) {
+		db, mock := NewInstitutionMockDB()
+		repo := repository.NewVerificationRepository(db)
+
+		institutionID := uuid.New()
+
+		mock.ExpectBegin()
+		mock.ExpectExec(`UPDATE "institutions" SET "updated_at"=\$1,"verification_note"=\$2,"verification_status"=\$3 WHERE \(institution_id = \$4 AND verification_status = \$5 AND .+`).
+			WithArgs(sqlmock.AnyArg(), "", model.VerificationStatusPending,
+				institutionID, model.VerificationStatusUnverified, sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectCommit()
+
+		institution, err := repo.UpdateVerificationStatus(context.Background(), institutionID,
+			model.VerificationStatusUnverified, model.VerificationStatusPending, "")
+
+		assert.ErrorIs(t, err, model.ErrVerificationStatusChanged)
+		assert.Nil(t, institution)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"institution-service/model"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type IVerificationRepository interface {
+	SaveDocument(ctx context.Context, document *model.InstitutionDocument) (*model.InstitutionDocument, error)
+	GetDocuments(ctx context.Context, institution_id uuid.UUID) ([]model.InstitutionDocument, error)
+	GetDocumentByID(ctx context.Context, institution_id, document_id uuid.UUID) (*model.InstitutionDocument, error)
+	UpdateVerificationStatus(ctx context.Context, institution_id uuid.UUID, from, to model.VerificationStatus, note string) (*model.Institution, error)
+	GetInstitutionsByVerificationStatus(ctx context.Context, status model.VerificationStatus) ([]model.Institution, error)
+}
+
+type VerificationRepository struct {
+	db *gorm.DB
+}
+
+func NewVerificationRepository(db *gorm.DB) *VerificationRepository {
+	return &VerificationRepository{
+		db: db,
+	}
+}
+
+// SaveDocument stores document in place of the institution's document of the
+// same type. It returns the replaced document, or nil when there was none, so
+// its content can be removed from storage.
+func (r *VerificationRepository) SaveDocument(ctx context.Context, document *model.InstitutionDocument) (*model.InstitutionDocument, error) {
+	var replaced *model.InstitutionDocument
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var previous model.InstitutionDocument
+		err := tx.Where("institution_id = ? AND type = ?", document.InstitutionID, document.Type).
+			First(&previous).Error
+		switch {
+		case err == nil:
+			if err := tx.Delete(&previous).Error; err != nil {
+				return err
+			}
+			replaced = &previous
+		case !errors.Is(err, gorm.ErrRecordNotFound):
+			return err
+		}
+
+		return tx.Omit("Institution").Create(document).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return replaced, nil
+}
+
+func (r *VerificationRepository) GetDocuments(ctx context.Context, institution_id uuid.UUID) ([]model.InstitutionDocument, error) {
+	var documents []model.InstitutionDocument
+	if err := r.db.WithContext(ctx).Where("institution_id = ?", institution_id).
+		Order("type").Find(&documents).Error; err != nil {
+		return nil, err
+	}
+
+	return documents, nil
+}
+
+func (r *VerificationRepository) GetDocumentByID(ctx context.Context, institution_id, document_id uuid.UUID) (*model.InstitutionDocument, error) {
+	var document model.InstitutionDocument
+	if err := r.db.WithContext(ctx).Where("document_id = ? AND institution_id = ?", document_id, institution_id).
+		First(&document).Error; err != nil {
+		return nil, err
+	}
+
+	return &document, nil
+}
+
+// UpdateVerificationStatus moves the institution from one verification status
+// to another, with note as the reason shown to it. The change only applies
+// while the institution still has the from status.
+func (r *VerificationRepository) UpdateVerificationStatus(ctx context.Context, institution_id uuid.UUID, from, to model.VerificationStatus, note string) (*model.Institution, error) {
+	now := time.Now()
+	updates := map[string]interface{}{
+		"verification_status": to,
+		"verification_note":   note,
+		"updated_at":          now,
+	}
+	if to == model.VerificationStatusVerified {
+		updates["verified_at"] = now
+	}
+
+	result := r.db.WithContext(ctx).Model(&model.Institution{}).
+		Where("institution_id = ? AND verification_status = ? AND (deleted_at IS NULL OR deleted_at = ?)",
+			institution_id, from, "0001-01-01 00:00:00").
+		Updates(updates)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, model.ErrVerificationStatusChanged
+	}
+
+	var institution model.Institution
+	if err := r.db.WithContext(ctx).Where("institution_id = ?", institution_id).First(&institution).Error; err != nil {
+		return nil, err
+	}
+
+	return &institution, nil
+}
+
+// GetInstitutionsByVerificationStatus lists the institutions with status,
+// longest waiting first.
+func (r *VerificationRepository) GetInstitutionsByVerificationStatus(ctx context.Context, status model.VerificationStatus) ([]model.Institution, error) {
+	var institutions []model.Institution
+	err := r.db.WithContext(ctx).
+		Where("verification_status = ? AND (deleted_at IS NULL OR deleted_at = ?)", status, "0001-01-01 00:00:00").
+		Order("updated_at, institution_id").
+		Find(&institutions).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return institutions, nil
+}
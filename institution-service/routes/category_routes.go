@@ -0,0 +1,156 @@
+package routes
+
+import (
+	"net/http"
+
+	"institution-service/httputil"
+	pb "institution-service/pb/category"
+
+	"github.com/labstack/echo/v4"
+	"google.golang.org/grpc/status"
+)
+
+type CategoryHTTPHandler struct {
+	categoryClient pb.CategoryServiceClient
+}
+
+func NewCategoryHTTPHandler(categoryClient pb.CategoryServiceClient) *CategoryHTTPHandler {
+	return &CategoryHTTPHandler{
+		categoryClient: categoryClient,
+	}
+}
+
+func (h *CategoryHTTPHandler) Routes(e *echo.Echo) {
+	e.GET("/v1/categories", h.GetAllCategories)
+
+	groupAdmin := e.Group("/v1/admin/categories")
+	groupAdmin.Use(AuthMiddleware)
+	groupAdmin.POST("", h.CreateCategory)
+	groupAdmin.PUT("/:id", h.UpdateCategory)
+	groupAdmin.DELETE("/:id", h.DeleteCategory)
+}
+
+// GetAllCategories godoc
+// @Summary      Get all Categories.
+// @Description  List categories by name without authentication, each with the number of its posts, how many are raising funds now, and what they target and raised in total, one amount per currency in minor units.
+// @Tags         Category
+// @Accept       json
+// @Produce      json
+// @Success      200  {object}  model.CategoryListResponse "Success get category data"
+// @Failure      500  {object}  httputil.HTTPError "Internal server error"
+// @Router       /v1/categories [get]
+func (h *CategoryHTTPHandler) GetAllCategories(c echo.Context) error {
+	res, err := h.categoryClient.GetAllCategories(c.Request().Context(), &pb.GetAllCategoriesRequest{})
+	if err != nil {
+		return echo.NewHTTPError(httpStatusFromGRPC(err), httputil.HTTPError{
+			Message: status.Convert(err).Message(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "Success get category data",
+		"data":    res,
+	})
+}
+
+// CreateCategory godoc
+// @Summary      Create a new Category.
+// @Description  Create a category posts can be filed under. Needs the token of a user listed in ADMIN_EMAILS.
+// @Tags         Category
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        Authorization  header    string  true  "Bearer token"
+// @Param        request        body      model.CategoryRequest  true  "Category details"
+// @Success      201  {object}  model.CategoryResponse "Category created successfully"
+// @Failure      400  {object}  httputil.HTTPError "Invalid category"
+// @Failure      403  {object}  httputil.HTTPError "Forbidden"
+// @Failure      409  {object}  httputil.HTTPError "Slug already taken"
+// @Router       /v1/admin/categories [post]
+func (h *CategoryHTTPHandler) CreateCategory(c echo.Context) error {
+	req := new(pb.CreateCategoryRequest)
+	if err := c.Bind(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, httputil.HTTPError{
+			Message: "Invalid request body",
+		})
+	}
+
+	res, err := h.categoryClient.CreateCategory(c.Request().Context(), req)
+	if err != nil {
+		return echo.NewHTTPError(httpStatusFromGRPC(err), httputil.HTTPError{
+			Message: status.Convert(err).Message(),
+		})
+	}
+
+	return c.JSON(http.StatusCreated, map[string]interface{}{
+		"message": "Category created successfully",
+		"data":    res,
+	})
+}
+
+// UpdateCategory godoc
+// @Summary      Update Category.
+// @Description  Update the slug, name or description of a category; empty fields are kept. Needs the token of a user listed in ADMIN_EMAILS.
+// @Tags         Category
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        Authorization  header    string  true  "Bearer token"
+// @Param        id             path      string  true  "Category ID"
+// @Param        request        body      model.CategoryRequest  true  "Updated category details"
+// @Success      200  {object}  model.CategoryResponse "Success update category data"
+// @Failure      400  {object}  httputil.HTTPError "Invalid category"
+// @Failure      403  {object}  httputil.HTTPError "Forbidden"
+// @Failure      404  {object}  httputil.HTTPError "Category not found"
+// @Failure      409  {object}  httputil.HTTPError "Slug already taken"
+// @Router       /v1/admin/categories/{id} [put]
+func (h *CategoryHTTPHandler) UpdateCategory(c echo.Context) error {
+	req := new(pb.UpdateCategoryRequest)
+	if err := c.Bind(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, httputil.HTTPError{
+			Message: "Invalid request body",
+		})
+	}
+	req.CategoryId = c.Param("id")
+
+	res, err := h.categoryClient.UpdateCategory(c.Request().Context(), req)
+	if err != nil {
+		return echo.NewHTTPError(httpStatusFromGRPC(err), httputil.HTTPError{
+			Message: status.Convert(err).Message(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "Success update category data",
+		"data":    res,
+	})
+}
+
+// DeleteCategory godoc
+// @Summary      Delete Category.
+// @Description  Delete a category. Its posts stay listed without a category. Needs the token of a user listed in ADMIN_EMAILS.
+// @Tags         Category
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        Authorization  header    string  true  "Bearer token"
+// @Param        id             path      string  true  "Category ID"
+// @Success      200  {object}  model.CategoryDeleteResponse "Success delete category data"
+// @Failure      403  {object}  httputil.HTTPError "Forbidden"
+// @Failure      404  {object}  httputil.HTTPError "Category not found"
+// @Router       /v1/admin/categories/{id} [delete]
+func (h *CategoryHTTPHandler) DeleteCategory(c echo.Context) error {
+	_, err := h.categoryClient.DeleteCategory(c.Request().Context(), &pb.DeleteCategoryRequest{
+		CategoryId: c.Param("id"),
+	})
+	if err != nil {
+		return echo.NewHTTPError(httpStatusFromGRPC(err), httputil.HTTPError{
+			Message: status.Convert(err).Message(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "Success delete category data",
+		"data":    map[string]interface{}{},
+	})
+}
@@ -2,10 +2,14 @@ package routes
 
 import (
 	"net/http"
+	"strconv"
 
+	"institution-service/httputil"
 	pb "institution-service/pb/fund_collect"
 
 	"github.com/labstack/echo/v4"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 type FundCollectHTTPHandler struct {
@@ -19,14 +23,17 @@ func NewFundCollectHTTPHandler(fundCollectClient pb.FundCollectServiceClient) *F
 }
 
 func (h *FundCollectHTTPHandler) Routes(e *echo.Echo) {
+	e.GET("/v1/post/:id/supporters", h.GetPostSupporters)
+
 	groupFundCollect := e.Group("/v1/fund-collect")
 	groupFundCollect.Use(AuthMiddleware)
 	groupFundCollect.GET("/post/:id", h.GetFundCollectByPostID)
+	groupFundCollect.PUT("/:id/message", h.ModerateDonationMessage)
 }
 
 // GetFundCollectByPostID godoc
 // @Summary      Get funding collection by Post ID.
-// @Description  Get a specific funding collection by Post ID with authorization.
+// @Description  Get the funding collection of a post. Only the institution that owns the post can get it.
 // @Tags         FundCollect
 // @Accept       json
 // @Produce      json
@@ -53,3 +60,100 @@ func (h *FundCollectHTTPHandler) GetFundCollectByPostID(c echo.Context) error {
 		"data":    res,
 	})
 }
+
+// GetPostSupporters godoc
+// @Summary      Get the supporters wall of a Post.
+// @Description  List a post's donations newest first without authentication, with the donor's chosen name, the amount rounded down to 1, 2 or 5 times a power of ten, and any message the institution has not hidden. Pass next_cursor as cursor for the next page.
+// @Tags         FundCollect
+// @Accept       json
+// @Produce      json
+// @Param        id      path      string  true   "Post ID"
+// @Param        cursor  query     string  false  "next_cursor of the previous page"
+// @Param        limit   query     int     false  "Page size, 20 by default and at most 100"
+// @Success      200  {object}  model.SupporterListResponse "Success get supporters data"
+// @Failure      400  {object}  httputil.HTTPError "Invalid request"
+// @Failure      404  {object}  httputil.HTTPError "Post not found"
+// @Router       /v1/post/{id}/supporters [get]
+func (h *FundCollectHTTPHandler) GetPostSupporters(c echo.Context) error {
+	req := &pb.GetPostSupportersRequest{
+		PostId: c.Param("id"),
+		Cursor: c.QueryParam("cursor"),
+	}
+	if limit := c.QueryParam("limit"); limit != "" {
+		value, err := strconv.Atoi(limit)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, httputil.HTTPError{
+				Message: "Invalid limit",
+			})
+		}
+		req.Limit = int32(value)
+	}
+
+	res, err := h.fundCollectClient.GetPostSupporters(c.Request().Context(), req)
+	if err != nil {
+		return echo.NewHTTPError(httpStatusFromGRPC(err), httputil.HTTPError{
+			Message: status.Convert(err).Message(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "Success get supporters data",
+		"data":    res,
+	})
+}
+
+// ModerateDonationMessage godoc
+// @Summary      Hide or show a donation message.
+// @Description  Hide or show a donation's message on the supporters wall. The donation stays listed. Only the institution that owns the post can moderate it.
+// @Tags         FundCollect
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        Authorization  header    string  true  "Bearer token"
+// @Param        id             path      string  true  "Fund collect ID"
+// @Param        request        body      model.DonationMessageModerationRequest  true  "Moderation decision"
+// @Success      200  {object}  model.FundCollectResponse "Success moderate donation message"
+// @Failure      401  {object}  httputil.HTTPError "Unauthorized"
+// @Failure      403  {object}  httputil.HTTPError "Forbidden"
+// @Failure      404  {object}  httputil.HTTPError "Fund collect not found"
+// @Router       /v1/fund-collect/{id}/message [put]
+func (h *FundCollectHTTPHandler) ModerateDonationMessage(c echo.Context) error {
+	req := new(pb.ModerateDonationMessageRequest)
+	if err := c.Bind(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, httputil.HTTPError{
+			Message: "Invalid request body",
+		})
+	}
+
+	res, err := h.fundCollectClient.ModerateDonationMessage(c.Request().Context(), &pb.ModerateDonationMessageRequest{
+		FundCollectId: c.Param("id"),
+		Hidden:        req.Hidden,
+	})
+	if err != nil {
+		return echo.NewHTTPError(httpStatusFromGRPC(err), httputil.HTTPError{
+			Message: status.Convert(err).Message(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "Success moderate donation message",
+		"data":    res,
+	})
+}
+
+func httpStatusFromGRPC(err error) int {
+	switch status.Code(err) {
+	case codes.InvalidArgument:
+		return http.StatusBadRequest
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.FailedPrecondition:
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,124 @@
+package routes
+
+import (
+	"net/http"
+
+	"institution-service/httputil"
+	pb "institution-service/pb/moderation"
+
+	"github.com/labstack/echo/v4"
+	"google.golang.org/grpc/status"
+)
+
+type ModerationHTTPHandler struct {
+	moderationClient pb.ModerationServiceClient
+}
+
+func NewModerationHTTPHandler(moderationClient pb.ModerationServiceClient) *ModerationHTTPHandler {
+	return &ModerationHTTPHandler{
+		moderationClient: moderationClient,
+	}
+}
+
+func (h *ModerationHTTPHandler) Routes(e *echo.Echo) {
+	groupAdmin := e.Group("/v1/admin/moderation/posts")
+	groupAdmin.Use(AuthMiddleware)
+	groupAdmin.GET("", h.GetModerationQueue)
+	groupAdmin.POST("/:id", h.ModeratePost)
+
+	groupPost := e.Group("/v1/post")
+	groupPost.Use(AuthMiddleware)
+	groupPost.GET("/:id/moderation", h.GetModerationHistory)
+}
+
+// GetModerationQueue godoc
+// @Summary      Get the moderation queue.
+// @Description  List the submitted posts awaiting review, longest waiting first. Needs the token of a user listed in MODERATOR_EMAILS or ADMIN_EMAILS.
+// @Tags         Moderation
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        Authorization  header    string  true  "Bearer token"
+// @Success      200  {object}  model.ModerationQueueResponse "Success get moderation queue"
+// @Failure      403  {object}  httputil.HTTPError "Forbidden"
+// @Router       /v1/admin/moderation/posts [get]
+func (h *ModerationHTTPHandler) GetModerationQueue(c echo.Context) error {
+	res, err := h.moderationClient.GetModerationQueue(c.Request().Context(), &pb.GetModerationQueueRequest{})
+	if err != nil {
+		return echo.NewHTTPError(httpStatusFromGRPC(err), httputil.HTTPError{
+			Message: status.Convert(err).Message(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "Success get moderation queue",
+		"data":    res,
+	})
+}
+
+// ModeratePost godoc
+// @Summary      Moderate a submitted Post.
+// @Description  Decide on a submitted post: APPROVE publishes it, REJECT rejects it for good and REQUEST_CHANGES sends it back to DRAFT for the institution to edit and resubmit. A reason is required unless the post is approved. The institution is emailed the decision and its reason, and the decision is kept in the moderation history of the post. Needs the token of a user listed in MODERATOR_EMAILS or ADMIN_EMAILS.
+// @Tags         Moderation
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        Authorization  header    string  true  "Bearer token"
+// @Param        id             path      string  true  "Post ID"
+// @Param        request        body      model.ModerationRequest  true  "Decision"
+// @Success      200  {object}  model.ModerationResponse "Success moderate post"
+// @Failure      400  {object}  httputil.HTTPError "Invalid decision"
+// @Failure      403  {object}  httputil.HTTPError "Forbidden"
+// @Failure      404  {object}  httputil.HTTPError "Post not found"
+// @Failure      409  {object}  httputil.HTTPError "Post is not awaiting moderation"
+// @Router       /v1/admin/moderation/posts/{id} [post]
+func (h *ModerationHTTPHandler) ModeratePost(c echo.Context) error {
+	req := new(pb.ModeratePostRequest)
+	if err := c.Bind(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, httputil.HTTPError{
+			Message: "Invalid request body",
+		})
+	}
+	req.PostId = c.Param("id")
+
+	res, err := h.moderationClient.ModeratePost(c.Request().Context(), req)
+	if err != nil {
+		return echo.NewHTTPError(httpStatusFromGRPC(err), httputil.HTTPError{
+			Message: status.Convert(err).Message(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "Success moderate post",
+		"data":    res,
+	})
+}
+
+// GetModerationHistory godoc
+// @Summary      Get the moderation history of a Post.
+// @Description  List the moderation decisions on a post, oldest first. Open to the institution that owns the post and to moderators.
+// @Tags         Moderation
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        Authorization  header    string  true  "Bearer token"
+// @Param        id             path      string  true  "Post ID"
+// @Success      200  {object}  model.ModerationHistoryResponse "Success get moderation history"
+// @Failure      403  {object}  httputil.HTTPError "Forbidden"
+// @Failure      404  {object}  httputil.HTTPError "Post not found"
+// @Router       /v1/post/{id}/moderation [get]
+func (h *ModerationHTTPHandler) GetModerationHistory(c echo.Context) error {
+	res, err := h.moderationClient.GetModerationHistory(c.Request().Context(), &pb.GetModerationHistoryRequest{
+		PostId: c.Param("id"),
+	})
+	if err != nil {
+		return echo.NewHTTPError(httpStatusFromGRPC(err), httputil.HTTPError{
+			Message: status.Convert(err).Message(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "Success get moderation history",
+		"data":    res,
+	})
+}
@@ -2,11 +2,15 @@ package routes
 
 import (
 	"net/http"
+	"strconv"
+	"strings"
 
 	"institution-service/httputil"
+	"institution-service/model"
 	pb "institution-service/pb/post"
 
 	"github.com/labstack/echo/v4"
+	"google.golang.org/grpc/status"
 )
 
 type PostHTTPHandler struct {
@@ -29,23 +33,73 @@ func (h *PostHTTPHandler) Routes(e *echo.Echo) {
 	groupPost.GET("/institution/:id", h.GetAllPostByInstitutionID)
 	groupPost.PUT("/:id", h.UpdatePost)
 	groupPost.DELETE("/:id", h.DeletePost)
+	groupPost.PUT("/:id/status", h.ChangePostStatus)
 }
 
 // GetAllPost godoc
 // @Summary      Get all Post.
-// @Description  Get all Post without authentication.
+// @Description  List published posts without authentication, newest first, one page at a time. Pass next_cursor back as cursor, with the same filters and sort, to get the next page. A post that reached its target is FUNDED whatever its dates. Targets are in minor units of target_currency, e.g. 100000000 for IDR 1,000,000.
 // @Tags         Post
 // @Accept       json
 // @Produce      json
-// @Success      200  {object}  model.PostResponse "Success get post data"
-// @Failure      401  {object}  httputil.HTTPError "Unauthorized"
-// @Failure      404  {object}  httputil.HTTPError "Post not found"
+// @Param        q                query     string  false  "Search in titles and bodies"
+// @Param        status           query     string  false  "UPCOMING, ACTIVE, ENDED or FUNDED"
+// @Param        institution_id   query     string  false  "Institution ID"
+// @Param        category         query     string  false  "Category ID or slug"
+// @Param        tags             query     string  false  "Comma-separated tags, posts with any of them"
+// @Param        date_from        query     string  false  "Running on or after, YYYY-MM-DD or RFC3339"
+// @Param        date_to          query     string  false  "Running on or before, YYYY-MM-DD or RFC3339"
+// @Param        target_min       query     int     false  "Minimum fund target in minor units"
+// @Param        target_max       query     int     false  "Maximum fund target in minor units"
+// @Param        target_currency  query     string  false  "Currency of the target range, IDR by default"
+// @Param        sort             query     string  false  "NEWEST, ENDING_SOON, MOST_FUNDED or PERCENT_FUNDED"
+// @Param        cursor           query     string  false  "Cursor from the previous page"
+// @Param        limit            query     int     false  "Page size, at most 100"
+// @Success      200  {object}  model.PostListResponse "Success get post data"
+// @Failure      400  {object}  httputil.HTTPError "Invalid filter"
 // @Router       /v1/posts [get]
 func (h *PostHTTPHandler) GetAllPost(c echo.Context) error {
-	res, err := h.postClient.GetAllPost(c.Request().Context(), &pb.GetAllPostRequest{})
+	req := &pb.GetAllPostRequest{
+		Query:         c.QueryParam("q"),
+		Status:        c.QueryParam("status"),
+		InstitutionId: c.QueryParam("institution_id"),
+		Category:      c.QueryParam("category"),
+		DateFrom:      c.QueryParam("date_from"),
+		DateTo:        c.QueryParam("date_to"),
+		Sort:          c.QueryParam("sort"),
+		Cursor:        c.QueryParam("cursor"),
+	}
+
+	if tags := c.QueryParam("tags"); tags != "" {
+		req.Tags = strings.Split(tags, ",")
+	}
+
+	currency := c.QueryParam("target_currency")
+	if currency == "" {
+		currency = model.CurrencyIDR
+	}
+
+	var err error
+	if req.TargetMin, err = queryMoney(c, "target_min", currency); err != nil {
+		return err
+	}
+	if req.TargetMax, err = queryMoney(c, "target_max", currency); err != nil {
+		return err
+	}
+	if limit := c.QueryParam("limit"); limit != "" {
+		n, err := strconv.ParseInt(limit, 10, 32)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, httputil.HTTPError{
+				Message: "Invalid limit",
+			})
+		}
+		req.Limit = int32(n)
+	}
+
+	res, err := h.postClient.GetAllPost(c.Request().Context(), req)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, httputil.HTTPError{
-			Message: err.Error(),
+		return echo.NewHTTPError(httpStatusFromGRPC(err), httputil.HTTPError{
+			Message: status.Convert(err).Message(),
 		})
 	}
 
@@ -55,9 +109,27 @@ func (h *PostHTTPHandler) GetAllPost(c echo.Context) error {
 	})
 }
 
+// queryMoney reads an amount in minor units from the query string, or nil
+// when it is not given.
+func queryMoney(c echo.Context, name, currency string) (*pb.Money, error) {
+	value := c.QueryParam(name)
+	if value == "" {
+		return nil, nil
+	}
+
+	amount, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, httputil.HTTPError{
+			Message: "Invalid " + name,
+		})
+	}
+
+	return &pb.Money{Amount: amount, Currency: currency}, nil
+}
+
 // CreatePost godoc
 // @Summary      Create a new Post.
-// @Description  Create post with title, body, etc.
+// @Description  Create post with title, body, etc. fund_target_v2 is in minor units, e.g. 100000000 for IDR 1,000,000; the float fund_target is deprecated. category_id is one of GET /v1/categories; tags are free-form, at most 10.
 // @Tags         Post
 // @Accept       json
 // @Produce      json
@@ -77,11 +149,15 @@ func (h *PostHTTPHandler) CreatePost(c echo.Context) error {
 	}
 
 	res, err := h.postClient.CreatePost(c.Request().Context(), &pb.CreatePostRequest{
-		Title:      req.Title,
-		Body:       req.Body,
-		DateStart:  req.DateStart,
-		DateEnd:    req.DateEnd,
-		FundTarget: req.FundTarget,
+		Title:          req.Title,
+		Body:           req.Body,
+		DateStart:      req.DateStart,
+		DateEnd:        req.DateEnd,
+		FundTarget:     req.FundTarget,
+		FundTargetV2:   req.FundTargetV2,
+		OverflowPolicy: req.OverflowPolicy,
+		CategoryId:     req.CategoryId,
+		Tags:           req.Tags,
 	})
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, httputil.HTTPError{
@@ -97,7 +173,7 @@ func (h *PostHTTPHandler) CreatePost(c echo.Context) error {
 
 // GetPostByID godoc
 // @Summary      Get Post by ID.
-// @Description  Get a specific post by ID with authorization.
+// @Description  Get a specific post by ID. Posts that are not PUBLISHED, CLOSED or COMPLETED are only shown to the institution that owns them and to moderators.
 // @Tags         Post
 // @Accept       json
 // @Produce      json
@@ -157,7 +233,7 @@ func (h *PostHTTPHandler) GetAllPostByInstitutionID(c echo.Context) error {
 
 // UpdatePost godoc
 // @Summary      Update Post.
-// @Description  Update an existing post with authorization.
+// @Description  Update an existing post with authorization. Given tags replace the post's tags; clear_tags removes them.
 // @Tags         Post
 // @Accept       json
 // @Produce      json
@@ -178,12 +254,17 @@ func (h *PostHTTPHandler) UpdatePost(c echo.Context) error {
 	}
 
 	res, err := h.postClient.UpdatePost(c.Request().Context(), &pb.UpdatePostRequest{
-		PostId:     c.Param("id"),
-		Title:      req.Title,
-		Body:       req.Body,
-		DateStart:  req.DateStart,
-		DateEnd:    req.DateEnd,
-		FundTarget: req.FundTarget,
+		PostId:         c.Param("id"),
+		Title:          req.Title,
+		Body:           req.Body,
+		DateStart:      req.DateStart,
+		DateEnd:        req.DateEnd,
+		FundTarget:     req.FundTarget,
+		FundTargetV2:   req.FundTargetV2,
+		OverflowPolicy: req.OverflowPolicy,
+		CategoryId:     req.CategoryId,
+		Tags:           req.Tags,
+		ClearTags:      req.ClearTags,
 	})
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, httputil.HTTPError{
@@ -225,3 +306,41 @@ func (h *PostHTTPHandler) DeletePost(c echo.Context) error {
 		"data":    map[string]interface{}{},
 	})
 }
+
+// ChangePostStatus godoc
+// @Summary      Change the status of a Post.
+// @Description  Move a post along its lifecycle. New posts are DRAFT and only PUBLISHED posts are listed and accept donations. The owning institution submits a draft (SUBMITTED) once it is verified, see /v1/institution/documents, withdraws it (DRAFT), closes a published post early (CLOSED), completes it once ended or funded (COMPLETED) and archives drafts, rejected, closed and completed posts (ARCHIVED). Moderators publish, reject or send back submitted posts, see /v1/admin/moderation/posts. Admins, with a user token listed in ADMIN_EMAILS, close published posts.
+// @Tags         Post
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        Authorization  header    string  true  "Bearer token"
+// @Param        id             path      string  true  "Post ID"
+// @Param        request        body      model.PostStatusRequest  true  "New status"
+// @Success      200  {object}  model.PostResponse "Success change post status"
+// @Failure      400  {object}  httputil.HTTPError "Invalid status"
+// @Failure      403  {object}  httputil.HTTPError "Forbidden"
+// @Failure      404  {object}  httputil.HTTPError "Post not found"
+// @Failure      409  {object}  httputil.HTTPError "Status change not allowed"
+// @Router       /v1/post/{id}/status [put]
+func (h *PostHTTPHandler) ChangePostStatus(c echo.Context) error {
+	req := new(pb.ChangePostStatusRequest)
+	if err := c.Bind(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, httputil.HTTPError{
+			Message: "Invalid request body",
+		})
+	}
+	req.PostId = c.Param("id")
+
+	res, err := h.postClient.ChangePostStatus(c.Request().Context(), req)
+	if err != nil {
+		return echo.NewHTTPError(httpStatusFromGRPC(err), httputil.HTTPError{
+			Message: status.Convert(err).Message(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "Success change post status",
+		"data":    res,
+	})
+}
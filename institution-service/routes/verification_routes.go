@@ -0,0 +1,277 @@
+package routes
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+
+	"institution-service/httputil"
+	"institution-service/model"
+	pb "institution-service/pb/verification"
+
+	"github.com/labstack/echo/v4"
+	"google.golang.org/grpc/status"
+)
+
+type VerificationHTTPHandler struct {
+	verificationClient pb.VerificationServiceClient
+}
+
+func NewVerificationHTTPHandler(verificationClient pb.VerificationServiceClient) *VerificationHTTPHandler {
+	return &VerificationHTTPHandler{
+		verificationClient: verificationClient,
+	}
+}
+
+func (h *VerificationHTTPHandler) Routes(e *echo.Echo) {
+	groupInstitution := e.Group("/v1/institution")
+	groupInstitution.Use(AuthMiddleware)
+	groupInstitution.POST("/documents", h.UploadDocument)
+	groupInstitution.GET("/documents", h.GetDocuments)
+	groupInstitution.GET("/documents/:document_id", h.GetDocumentContent)
+	groupInstitution.POST("/verification", h.SubmitVerification)
+
+	groupAdmin := e.Group("/v1/admin/verifications")
+	groupAdmin.Use(AuthMiddleware)
+	groupAdmin.GET("", h.GetPendingVerifications)
+	groupAdmin.GET("/:institution_id/documents", h.GetInstitutionDocuments)
+	groupAdmin.GET("/:institution_id/documents/:document_id", h.GetInstitutionDocumentContent)
+	groupAdmin.POST("/:institution_id", h.ReviewVerification)
+}
+
+// UploadDocument godoc
+// @Summary      Upload a verification document.
+// @Description  Upload a legal document of the authenticated institution: its registration certificate, its tax ID (NPWP) or proof of its bank account. The file must be a PDF, JPEG or PNG of at most 3 MB and replaces the document of the same type. Documents cannot be changed while they are under review or once the institution is verified.
+// @Tags         Verification
+// @Accept       multipart/form-data
+// @Produce      json
+// @Security     BearerAuth
+// @Param        Authorization  header    string  true  "Bearer token"
+// @Param        type           formData  string  true  "REGISTRATION_CERTIFICATE, TAX_ID or BANK_ACCOUNT_PROOF"
+// @Param        file           formData  file    true  "Document"
+// @Success      200  {object}  model.DocumentResponse "Success upload document"
+// @Failure      400  {object}  httputil.HTTPError "Invalid document"
+// @Failure      403  {object}  httputil.HTTPError "Forbidden"
+// @Failure      409  {object}  httputil.HTTPError "Documents cannot be changed"
+// @Router       /v1/institution/documents [post]
+func (h *VerificationHTTPHandler) UploadDocument(c echo.Context) error {
+	file, err := c.FormFile("file")
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, httputil.HTTPError{
+			Message: "File is required",
+		})
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, httputil.HTTPError{
+			Message: "Invalid file",
+		})
+	}
+	defer src.Close()
+
+	// Reading one byte past the limit lets the usecase reject oversized files
+	// without holding all of them in memory.
+	content, err := io.ReadAll(io.LimitReader(src, model.MaxDocumentSize+1))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, httputil.HTTPError{
+			Message: "Invalid file",
+		})
+	}
+
+	res, err := h.verificationClient.UploadDocument(c.Request().Context(), &pb.UploadDocumentRequest{
+		Type:     c.FormValue("type"),
+		Filename: file.Filename,
+		Content:  content,
+	})
+	if err != nil {
+		return echo.NewHTTPError(httpStatusFromGRPC(err), httputil.HTTPError{
+			Message: status.Convert(err).Message(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "Success upload document",
+		"data":    res,
+	})
+}
+
+// GetDocuments godoc
+// @Summary      Get verification documents.
+// @Description  List the documents of the authenticated institution.
+// @Tags         Verification
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        Authorization  header    string  true  "Bearer token"
+// @Success      200  {object}  model.DocumentListResponse "Success get documents"
+// @Failure      403  {object}  httputil.HTTPError "Forbidden"
+// @Router       /v1/institution/documents [get]
+func (h *VerificationHTTPHandler) GetDocuments(c echo.Context) error {
+	res, err := h.verificationClient.GetDocuments(c.Request().Context(), &pb.GetDocumentsRequest{
+		InstitutionId: c.Param("institution_id"),
+	})
+	if err != nil {
+		return echo.NewHTTPError(httpStatusFromGRPC(err), httputil.HTTPError{
+			Message: status.Convert(err).Message(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "Success get documents",
+		"data":    res,
+	})
+}
+
+// GetDocumentContent godoc
+// @Summary      Download a verification document.
+// @Description  Download a document of the authenticated institution.
+// @Tags         Verification
+// @Produce      application/pdf
+// @Produce      image/jpeg
+// @Produce      image/png
+// @Security     BearerAuth
+// @Param        Authorization  header    string  true  "Bearer token"
+// @Param        document_id    path      string  true  "Document ID"
+// @Success      200  {file}    file "Document"
+// @Failure      403  {object}  httputil.HTTPError "Forbidden"
+// @Failure      404  {object}  httputil.HTTPError "Document not found"
+// @Router       /v1/institution/documents/{document_id} [get]
+func (h *VerificationHTTPHandler) GetDocumentContent(c echo.Context) error {
+	res, err := h.verificationClient.GetDocumentContent(c.Request().Context(), &pb.GetDocumentContentRequest{
+		InstitutionId: c.Param("institution_id"),
+		DocumentId:    c.Param("document_id"),
+	})
+	if err != nil {
+		return echo.NewHTTPError(httpStatusFromGRPC(err), httputil.HTTPError{
+			Message: status.Convert(err).Message(),
+		})
+	}
+
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", res.Filename))
+	return c.Blob(http.StatusOK, res.ContentType, res.Content)
+}
+
+// GetInstitutionDocuments godoc
+// @Summary      Get the verification documents of an Institution.
+// @Description  List the documents an institution uploaded for review. Needs the token of a user listed in ADMIN_EMAILS.
+// @Tags         Verification
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        Authorization   header    string  true  "Bearer token"
+// @Param        institution_id  path      string  true  "Institution ID"
+// @Success      200  {object}  model.DocumentListResponse "Success get documents"
+// @Failure      403  {object}  httputil.HTTPError "Forbidden"
+// @Router       /v1/admin/verifications/{institution_id}/documents [get]
+func (h *VerificationHTTPHandler) GetInstitutionDocuments(c echo.Context) error {
+	return h.GetDocuments(c)
+}
+
+// GetInstitutionDocumentContent godoc
+// @Summary      Download a verification document of an Institution.
+// @Description  Download a document an institution uploaded for review. Needs the token of a user listed in ADMIN_EMAILS.
+// @Tags         Verification
+// @Produce      application/pdf
+// @Produce      image/jpeg
+// @Produce      image/png
+// @Security     BearerAuth
+// @Param        Authorization   header    string  true  "Bearer token"
+// @Param        institution_id  path      string  true  "Institution ID"
+// @Param        document_id     path      string  true  "Document ID"
+// @Success      200  {file}    file "Document"
+// @Failure      403  {object}  httputil.HTTPError "Forbidden"
+// @Failure      404  {object}  httputil.HTTPError "Document not found"
+// @Router       /v1/admin/verifications/{institution_id}/documents/{document_id} [get]
+func (h *VerificationHTTPHandler) GetInstitutionDocumentContent(c echo.Context) error {
+	return h.GetDocumentContent(c)
+}
+
+// SubmitVerification godoc
+// @Summary      Submit documents for verification.
+// @Description  Submit the documents of the authenticated institution for review once all of REGISTRATION_CERTIFICATE, TAX_ID and BANK_ACCOUNT_PROOF are uploaded. Until an admin verifies the institution, its posts can only be drafts.
+// @Tags         Verification
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        Authorization  header    string  true  "Bearer token"
+// @Success      200  {object}  model.VerificationResponse "Success submit verification"
+// @Failure      403  {object}  httputil.HTTPError "Forbidden"
+// @Failure      409  {object}  httputil.HTTPError "Documents missing or already submitted"
+// @Router       /v1/institution/verification [post]
+func (h *VerificationHTTPHandler) SubmitVerification(c echo.Context) error {
+	res, err := h.verificationClient.SubmitVerification(c.Request().Context(), &pb.SubmitVerificationRequest{})
+	if err != nil {
+		return echo.NewHTTPError(httpStatusFromGRPC(err), httputil.HTTPError{
+			Message: status.Convert(err).Message(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "Success submit verification",
+		"data":    res,
+	})
+}
+
+// GetPendingVerifications godoc
+// @Summary      Get pending verifications.
+// @Description  List the institutions whose documents await review, longest waiting first. Needs the token of a user listed in ADMIN_EMAILS.
+// @Tags         Verification
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        Authorization  header    string  true  "Bearer token"
+// @Success      200  {object}  model.VerificationListResponse "Success get pending verifications"
+// @Failure      403  {object}  httputil.HTTPError "Forbidden"
+// @Router       /v1/admin/verifications [get]
+func (h *VerificationHTTPHandler) GetPendingVerifications(c echo.Context) error {
+	res, err := h.verificationClient.GetPendingVerifications(c.Request().Context(), &pb.GetPendingVerificationsRequest{})
+	if err != nil {
+		return echo.NewHTTPError(httpStatusFromGRPC(err), httputil.HTTPError{
+			Message: status.Convert(err).Message(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "Success get pending verifications",
+		"data":    res,
+	})
+}
+
+// ReviewVerification godoc
+// @Summary      Review the documents of an Institution.
+// @Description  APPROVE verifies the institution, which lets its posts be published. REJECT, with a reason shown to the institution, lets it upload new documents and submit again. Needs the token of a user listed in ADMIN_EMAILS.
+// @Tags         Verification
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        Authorization   header    string  true  "Bearer token"
+// @Param        institution_id  path      string  true  "Institution ID"
+// @Param        request         body      model.VerificationRequest  true  "Decision"
+// @Success      200  {object}  model.VerificationResponse "Success review verification"
+// @Failure      400  {object}  httputil.HTTPError "Invalid decision"
+// @Failure      403  {object}  httputil.HTTPError "Forbidden"
+// @Failure      404  {object}  httputil.HTTPError "Institution not found"
+// @Failure      409  {object}  httputil.HTTPError "Institution is not awaiting review"
+// @Router       /v1/admin/verifications/{institution_id} [post]
+func (h *VerificationHTTPHandler) ReviewVerification(c echo.Context) error {
+	req := new(pb.ReviewVerificationRequest)
+	if err := c.Bind(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, httputil.HTTPError{
+			Message: "Invalid request body",
+		})
+	}
+	req.InstitutionId = c.Param("institution_id")
+
+	res, err := h.verificationClient.ReviewVerification(c.Request().Context(), req)
+	if err != nil {
+		return echo.NewHTTPError(httpStatusFromGRPC(err), httputil.HTTPError{
+			Message: status.Convert(err).Message(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "Success review verification",
+		"data":    res,
+	})
+}
@@ -0,0 +1,35 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// IStorage keeps uploaded files under keys made of slash-separated segments,
+// e.g. institutions/<id>/<document>.pdf.
+type IStorage interface {
+	Put(ctx context.Context, key string, content []byte) error
+	Get(ctx context.Context, key string) ([]byte, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// ErrNotFound is returned by Get when nothing is stored under the key.
+var ErrNotFound = errors.New("object not found")
+
+// NewStorage picks the storage named by STORAGE_DRIVER, defaulting to the
+// local disk under STORAGE_DIR (./uploads when unset).
+func NewStorage() (IStorage, error) {
+	switch strings.ToLower(os.Getenv("STORAGE_DRIVER")) {
+	case "", "local":
+		dir := os.Getenv("STORAGE_DIR")
+		if dir == "" {
+			dir = "./uploads"
+		}
+		return NewLocalStorage(dir)
+	default:
+		return nil, fmt.Errorf("unknown storage driver %q", os.Getenv("STORAGE_DRIVER"))
+	}
+}
@@ -0,0 +1,41 @@
+package tests
+
+import (
+	"context"
+	"institution-service/storage"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocalStorage(t *testing.T) {
+	t.Run("success - put, get and delete", func(t *testing.T) {
+		store, err := storage.NewLocalStorage(t.TempDir())
+		assert.NoError(t, err)
+
+		ctx := context.Background()
+		key := "institutions/1f0c/registration.pdf"
+
+		assert.NoError(t, store.Put(ctx, key, []byte("%PDF-1.4")))
+
+		content, err := store.Get(ctx, key)
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("%PDF-1.4"), content)
+
+		assert.NoError(t, store.Delete(ctx, key))
+		assert.NoError(t, store.Delete(ctx, key))
+
+		content, err = store.Get(ctx, key)
+		assert.Nil(t, content)
+		assert.ErrorIs(t, err, storage.ErrNotFound)
+	})
+
+	t.Run("failed - keys cannot leave the storage directory", func(t *testing.T) {
+		store, err := storage.NewLocalStorage(t.TempDir())
+		assert.NoError(t, err)
+
+		for _, key := range []string{"", "/etc/passwd", "../secret.pdf", "institutions/../../secret.pdf", "institutions//a.pdf"} {
+			assert.Error(t, store.Put(context.Background(), key, []byte("x")), key)
+		}
+	})
+}
@@ -0,0 +1,139 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"institution-service/model"
+	"institution-service/repository"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ICategoryUsecase interface {
+	CreateCategory(ctx context.Context, category *model.Category) (*model.Category, error)
+	GetAllCategories(ctx context.Context) ([]model.CategorySummary, error)
+	UpdateCategory(ctx context.Context, category *model.Category) (*model.Category, error)
+	DeleteCategory(ctx context.Context, categoryID uuid.UUID) error
+}
+
+var ErrCategorySlugTaken = errors.New("category slug is already taken")
+
+type CategoryUsecase struct {
+	categoryRepository repository.ICategoryRepository
+}
+
+func NewCategoryUsecase(categoryRepository repository.ICategoryRepository) *CategoryUsecase {
+	return &CategoryUsecase{
+		categoryRepository: categoryRepository,
+	}
+}
+
+func (u *CategoryUsecase) CreateCategory(ctx context.Context, category *model.Category) (*model.Category, error) {
+	if err := u.validateCategory(ctx, category); err != nil {
+		return nil, err
+	}
+
+	return u.categoryRepository.CreateCategory(ctx, category)
+}
+
+// GetAllCategories returns every category by name, with the number of posts
+// filed under it and what they target and raised.
+func (u *CategoryUsecase) GetAllCategories(ctx context.Context) ([]model.CategorySummary, error) {
+	categories, err := u.categoryRepository.GetAllCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	stats, err := u.categoryRepository.GetCategoryStats(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	summaries := make([]model.CategorySummary, len(categories))
+	index := make(map[uuid.UUID]*model.CategorySummary, len(categories))
+	for i, category := range categories {
+		summaries[i] = model.CategorySummary{Category: category}
+		index[category.CategoryID] = &summaries[i]
+	}
+
+	for _, stat := range stats {
+		summary, ok := index[stat.CategoryID]
+		if !ok {
+			continue
+		}
+		summary.PostCount += stat.PostCount
+		summary.ActivePostCount += stat.ActivePostCount
+		summary.FundTarget = append(summary.FundTarget, model.NewMoney(stat.FundTargetMinor, stat.Currency))
+		summary.FundAchieved = append(summary.FundAchieved, model.NewMoney(stat.FundAchievedMinor, stat.Currency))
+	}
+
+	return summaries, nil
+}
+
+// UpdateCategory changes the fields given in category and keeps the others.
+func (u *CategoryUsecase) UpdateCategory(ctx context.Context, category *model.Category) (*model.Category, error) {
+	existing, err := u.categoryRepository.GetCategoryByID(ctx, category.CategoryID)
+	if err != nil {
+		return nil, err
+	}
+
+	if category.Name == "" {
+		category.Name = existing.Name
+	}
+	if category.Slug == "" {
+		category.Slug = existing.Slug
+	}
+	if category.Description == "" {
+		category.Description = existing.Description
+	}
+
+	if err := u.validateCategory(ctx, category); err != nil {
+		return nil, err
+	}
+
+	return u.categoryRepository.UpdateCategory(ctx, category)
+}
+
+func (u *CategoryUsecase) DeleteCategory(ctx context.Context, categoryID uuid.UUID) error {
+	return u.categoryRepository.DeleteCategory(ctx, categoryID)
+}
+
+// validateCategory trims category, derives a missing slug from the name and
+// checks that no other category uses the slug.
+func (u *CategoryUsecase) validateCategory(ctx context.Context, category *model.Category) error {
+	var e []string
+
+	category.Name = strings.TrimSpace(category.Name)
+	category.Description = strings.TrimSpace(category.Description)
+	category.Slug = strings.TrimSpace(category.Slug)
+	if category.Slug == "" {
+		category.Slug = model.Slugify(category.Name)
+	}
+
+	if category.Name == "" {
+		e = append(e, "Name is required")
+	}
+	if len(category.Name) > 100 {
+		e = append(e, "Name must be at most 100 characters")
+	}
+	if !model.IsValidSlug(category.Slug) {
+		e = append(e, "Slug must be lowercase letters, digits and dashes, at most 64 characters")
+	}
+
+	if len(e) > 0 {
+		return errors.New(strings.Join(e, ", "))
+	}
+
+	other, err := u.categoryRepository.GetCategoryBySlug(ctx, category.Slug)
+	if err == nil && other.CategoryID != category.CategoryID {
+		return ErrCategorySlugTaken
+	}
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	return nil
+}
@@ -3,17 +3,35 @@ package usecase
 import (
 	"context"
 	"errors"
+	"fmt"
+	"log"
 	"strings"
 
 	"institution-service/model"
 	"institution-service/repository"
+
+	"github.com/google/uuid"
 )
 
 type IFundCollectUsecase interface {
-	CreateFundCollect(ctx context.Context, fundCollect *model.FundCollect) (*model.FundCollect, error)
 	GetFundCollectByPostID(ctx context.Context, postID string) ([]model.FundCollect, error)
+	ApplyDonationSettled(ctx context.Context, event model.DonationSettledEvent) error
+	ApplyDonationRefunded(ctx context.Context, event model.DonationRefundedEvent) error
+	GetFundCollectByID(ctx context.Context, fundCollectID uuid.UUID) (*model.FundCollect, error)
+	GetPostSupporters(ctx context.Context, postID uuid.UUID, cursor string, limit int) (*model.FundCollectPage, error)
+	SetMessageHidden(ctx context.Context, fundCollectID uuid.UUID, hidden bool) (*model.FundCollect, error)
 }
 
+// ErrInvalidDonationEvent marks events that can never be applied, so the
+// consumer drops them instead of redelivering.
+var ErrInvalidDonationEvent = errors.New("invalid donation event")
+
+const (
+	DefaultSupporterPageSize = 20
+	MaxSupporterPageSize     = 100
+	maxDonationMessageLength = 280
+)
+
 type FundCollectUsecase struct {
 	fundCollectRepository repository.IFundCollectRepository
 }
@@ -24,29 +42,174 @@ func NewFundCollectUsecase(fundCollectRepository repository.IFundCollectReposito
 	}
 }
 
-func (u *FundCollectUsecase) CreateFundCollect(ctx context.Context, fundCollect *model.FundCollect) (*model.FundCollect, error) {
+func (u *FundCollectUsecase) GetFundCollectByPostID(ctx context.Context, postID string) ([]model.FundCollect, error) {
+	return u.fundCollectRepository.GetFundCollectByPostID(ctx, postID)
+}
+
+// ApplyDonationSettled records the fund collect of a settled donation and
+// credits its post. Redelivered events are ignored.
+func (u *FundCollectUsecase) ApplyDonationSettled(ctx context.Context, event model.DonationSettledEvent) error {
 	var e []string
 
-	if fundCollect.PostID.String() == "00000000-0000-0000-0000-000000000000" {
-		e = append(e, "Post ID is required")
+	postID, err := uuid.Parse(event.PostID)
+	if err != nil {
+		e = append(e, "Post ID is invalid")
 	}
-	if fundCollect.UserID == "00000000-0000-0000-0000-000000000000" {
+	if event.TransactionID == "" {
+		e = append(e, "Transaction ID is required")
+	}
+	if event.UserID == "" {
 		e = append(e, "User ID is required")
 	}
-	if fundCollect.UserName == "" {
+	if event.UserName == "" {
 		e = append(e, "User Name is required")
 	}
-	if fundCollect.Amount <= 0 {
+	if err := event.Amount.Validate(); err != nil {
+		e = append(e, "Amount currency is not supported")
+	} else if event.Amount.Amount <= 0 {
 		e = append(e, "Amount must be greater than 0")
 	}
 
+	if len(e) > 0 {
+		return fmt.Errorf("%w: %s", ErrInvalidDonationEvent, strings.Join(e, ", "))
+	}
+
+	// Events raised before donors chose a name carry their email, which
+	// must not reach public donor lists.
+	userName := event.UserName
+	if strings.Contains(userName, "@") {
+		userName = model.AnonymousDonorName
+	}
+
+	fundCollect := &model.FundCollect{
+		PostID:        postID,
+		UserID:        event.UserID,
+		UserName:      userName,
+		Amount:        event.Amount,
+		TransactionID: event.TransactionID,
+		Message:       truncateMessage(strings.TrimSpace(event.Message)),
+	}
+	if event.OriginalAmount != nil {
+		fundCollect.OriginalAmount = *event.OriginalAmount
+	}
+
+	fundAchieved, credited, err := u.fundCollectRepository.CreditFundCollect(ctx, fundCollect)
+	if err != nil {
+		return fmt.Errorf("failed to credit fund collect: %v", err)
+	}
+	if !credited {
+		log.Printf("Transaction %s was already credited, skipping event %s", event.TransactionID, event.EventID)
+		return nil
+	}
+
+	log.Printf("Credited transaction %s, post %s has raised %s", event.TransactionID, event.PostID, fundAchieved)
+
+	return nil
+}
+
+// ApplyDonationRefunded takes a refund back off the fund collect and post of
+// its donation. Redelivered events are ignored.
+func (u *FundCollectUsecase) ApplyDonationRefunded(ctx context.Context, event model.DonationRefundedEvent) error {
+	var e []string
+
+	postID, err := uuid.Parse(event.PostID)
+	if err != nil {
+		e = append(e, "Post ID is invalid")
+	}
+	if event.TransactionID == "" {
+		e = append(e, "Transaction ID is required")
+	}
+	if event.RefundID == "" {
+		e = append(e, "Refund ID is required")
+	}
+	if err := event.Amount.Validate(); err != nil {
+		e = append(e, "Amount currency is not supported")
+	} else if event.Amount.Amount < 0 {
+		e = append(e, "Amount must not be negative")
+	}
+
+	if len(e) > 0 {
+		return fmt.Errorf("%w: %s", ErrInvalidDonationEvent, strings.Join(e, ", "))
+	}
+
+	refund := &model.FundCollectRefund{
+		RefundID:      event.RefundID,
+		TransactionID: event.TransactionID,
+		Amount:        event.Amount,
+	}
+
+	fundAchieved, debited, err := u.fundCollectRepository.DebitFundCollect(ctx, postID, refund)
+	if err != nil {
+		return fmt.Errorf("failed to debit fund collect: %v", err)
+	}
+	if !debited {
+		log.Printf("Refund %s was already taken off, skipping event %s", event.RefundID, event.EventID)
+		return nil
+	}
+
+	log.Printf("Took refund %s off transaction %s, post %s has raised %s", event.RefundID, event.TransactionID, event.PostID, fundAchieved)
+
+	return nil
+}
+
+func (u *FundCollectUsecase) GetFundCollectByID(ctx context.Context, fundCollectID uuid.UUID) (*model.FundCollect, error) {
+	return u.fundCollectRepository.GetFundCollectByID(ctx, fundCollectID)
+}
+
+// GetPostSupporters pages through the post's supporters wall, newest first.
+func (u *FundCollectUsecase) GetPostSupporters(ctx context.Context, postID uuid.UUID, cursor string, limit int) (*model.FundCollectPage, error) {
+	var e []string
+
+	var cursorID uuid.UUID
+	if cursor != "" {
+		var err error
+		if cursorID, err = uuid.Parse(cursor); err != nil {
+			e = append(e, "Cursor is invalid")
+		}
+	}
+	if limit < 0 {
+		e = append(e, "Limit must not be negative")
+	}
+
 	if len(e) > 0 {
 		return nil, errors.New(strings.Join(e, ", "))
 	}
 
-	return u.fundCollectRepository.CreateFundCollect(ctx, fundCollect)
+	if limit == 0 {
+		limit = DefaultSupporterPageSize
+	}
+	if limit > MaxSupporterPageSize {
+		limit = MaxSupporterPageSize
+	}
+
+	// Fetch one extra row to learn whether another page follows.
+	fundCollects, err := u.fundCollectRepository.GetSupporters(ctx, postID, cursorID, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &model.FundCollectPage{FundCollects: fundCollects}
+	if len(fundCollects) > limit {
+		page.FundCollects = fundCollects[:limit]
+		page.NextCursor = page.FundCollects[limit-1].FundCollectID.String()
+	}
+
+	return page, nil
 }
 
-func (u *FundCollectUsecase) GetFundCollectByPostID(ctx context.Context, postID string) ([]model.FundCollect, error) {
-	return u.fundCollectRepository.GetFundCollectByPostID(ctx, postID)
+// SetMessageHidden hides or shows a donation's message on the supporters
+// wall. The donation itself stays listed.
+func (u *FundCollectUsecase) SetMessageHidden(ctx context.Context, fundCollectID uuid.UUID, hidden bool) (*model.FundCollect, error) {
+	return u.fundCollectRepository.SetMessageHidden(ctx, fundCollectID, hidden)
+}
+
+// truncateMessage cuts a message to the column size. transaction-service
+// already rejects longer ones; a donation is never dropped over its message.
+func truncateMessage(message string) string {
+	runes := []rune(message)
+	if len(runes) <= maxDonationMessageLength {
+		return message
+	}
+
+	return string(runes[:maxDonationMessageLength])
 }
@@ -62,6 +62,10 @@ func (u *InstitutionUsecase) RegisterInstitution(ctx context.Context, institutio
 		return nil, errors.New(strings.Join(e, ", "))
 	}
 
+	// Posts of new institutions stay drafts until their documents are
+	// verified, see VerificationUsecase.
+	institution.VerificationStatus = model.VerificationStatusUnverified
+
 	institution, err := u.institutionRepository.RegisterInstitution(ctx, institution)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,101 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"institution-service/model"
+	"institution-service/repository"
+
+	"github.com/google/uuid"
+)
+
+type IModerationUsecase interface {
+	GetModerationQueue(ctx context.Context) ([]model.Post, error)
+	ModeratePost(ctx context.Context, moderation *model.PostModeration) (*model.PostModeration, error)
+	GetModerationHistory(ctx context.Context, post_id uuid.UUID) ([]model.PostModeration, error)
+}
+
+var (
+	ErrInvalidModerationDecision = errors.New("decision must be APPROVE, REJECT or REQUEST_CHANGES")
+	ErrModerationReasonRequired  = errors.New("reason is required unless the post is approved")
+)
+
+type ModerationUsecase struct {
+	moderationRepository  repository.IModerationRepository
+	postRepository        repository.IPostRepository
+	institutionRepository repository.IInstitutionRepository
+}
+
+func NewModerationUsecase(moderationRepository repository.IModerationRepository, postRepository repository.IPostRepository, institutionRepository repository.IInstitutionRepository) *ModerationUsecase {
+	return &ModerationUsecase{
+		moderationRepository:  moderationRepository,
+		postRepository:        postRepository,
+		institutionRepository: institutionRepository,
+	}
+}
+
+func (u *ModerationUsecase) GetModerationQueue(ctx context.Context) ([]model.Post, error) {
+	return u.moderationRepository.GetModerationQueue(ctx)
+}
+
+// ModeratePost applies the decision of a moderator to a submitted post and
+// records it. The returned moderation carries the updated post and its
+// institution, to be notified of the decision.
+func (u *ModerationUsecase) ModeratePost(ctx context.Context, moderation *model.PostModeration) (*model.PostModeration, error) {
+	if !moderation.Decision.IsValid() {
+		return nil, ErrInvalidModerationDecision
+	}
+
+	moderation.Reason = strings.TrimSpace(moderation.Reason)
+	if moderation.Decision.RequiresReason() && moderation.Reason == "" {
+		return nil, ErrModerationReasonRequired
+	}
+
+	post, err := u.postRepository.GetPostByID(ctx, moderation.PostID)
+	if err != nil {
+		return nil, err
+	}
+
+	status := moderation.Decision.Status()
+	if !model.CanTransitionPost(post.Status, status, model.PostActorModerator) {
+		return nil, fmt.Errorf("%w: moderators cannot move a %s post to %s",
+			ErrPostTransitionRefused, post.Status, status)
+	}
+	if status == model.PostStatusPublished && post.HasEnded(time.Now()) {
+		return nil, fmt.Errorf("%w: the fundraising period has already ended", ErrPostTransitionRefused)
+	}
+
+	institution, err := u.institutionRepository.GetInstitutionByID(ctx, post.InstitutionID)
+	if err != nil {
+		return nil, err
+	}
+	if status == model.PostStatusPublished && !institution.IsVerified() {
+		return nil, ErrInstitutionNotVerified
+	}
+
+	moderation.FromStatus = post.Status
+	moderation.ToStatus = status
+
+	moderation, err = u.moderationRepository.CreateModeration(ctx, moderation)
+	if err != nil {
+		return nil, err
+	}
+
+	post, err = u.postRepository.GetPostByID(ctx, moderation.PostID)
+	if err != nil {
+		return nil, err
+	}
+
+	post.Institution = *institution
+	moderation.Post = post
+
+	return moderation, nil
+}
+
+func (u *ModerationUsecase) GetModerationHistory(ctx context.Context, post_id uuid.UUID) ([]model.PostModeration, error) {
+	return u.moderationRepository.GetModerationHistory(ctx, post_id)
+}
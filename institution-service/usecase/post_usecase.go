@@ -3,31 +3,51 @@ package usecase
 import (
 	"context"
 	"errors"
+	"fmt"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"institution-service/model"
+	"institution-service/repository"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type IPostUsecase interface {
 	CreatePost(ctx context.Context, post *model.Post) (*model.Post, error)
-	GetAllPost(ctx context.Context) ([]model.Post, error)
+	GetAllPost(ctx context.Context, filter model.PostFilter) (*model.PostPage, error)
 	GetPostByID(ctx context.Context, post_id uuid.UUID) (*model.Post, error)
+	LookupPost(ctx context.Context, post_id uuid.UUID) (*model.Post, error)
 	GetAllPostByInstitutionID(ctx context.Context, institutionID uuid.UUID) ([]model.Post, error)
 	UpdatePost(ctx context.Context, post *model.Post) (*model.Post, error)
 	DeletePost(ctx context.Context, post_id uuid.UUID) error
-	AddPostFundAchieved(ctx context.Context, post_id uuid.UUID, amount float64) (*model.Post, error)
+	ChangePostStatus(ctx context.Context, post_id uuid.UUID, status model.PostStatus, actor model.PostActor) (*model.Post, error)
 }
 
+var (
+	ErrInvalidPostStatus     = errors.New("status must be DRAFT, SUBMITTED, PUBLISHED, REJECTED, CLOSED, COMPLETED or ARCHIVED")
+	ErrPostTransitionRefused = errors.New("post status change not allowed")
+	ErrPostNotEditable       = errors.New("only DRAFT and REJECTED posts can be edited")
+)
+
+const (
+	DefaultPostPageSize = 20
+	MaxPostPageSize     = 100
+)
+
 type PostUsecase struct {
-	postRepository IPostUsecase
+	postRepository        repository.IPostRepository
+	categoryRepository    repository.ICategoryRepository
+	institutionRepository repository.IInstitutionRepository
 }
 
-func NewPostUsecase(postRepository IPostUsecase) *PostUsecase {
+func NewPostUsecase(postRepository repository.IPostRepository, categoryRepository repository.ICategoryRepository, institutionRepository repository.IInstitutionRepository) *PostUsecase {
 	return &PostUsecase{
-		postRepository: postRepository,
+		postRepository:        postRepository,
+		categoryRepository:    categoryRepository,
+		institutionRepository: institutionRepository,
 	}
 }
 
@@ -61,40 +81,192 @@ func (u *PostUsecase) CreatePost(ctx context.Context, post *model.Post) (*model.
 		e = append(e, "Fundraising period cannot be more than 6 months")
 	}
 
-	if post.FundTarget <= 0 {
+	if err := post.FundTarget.Validate(); err != nil {
+		e = append(e, "Fund Target currency is not supported")
+	} else if post.FundTarget.Amount <= 0 {
 		e = append(e, "Fund Target must be greater than 0")
 	}
 
+	if post.OverflowPolicy == "" {
+		post.OverflowPolicy = model.OverflowPolicyAccept
+	}
+	if !post.OverflowPolicy.IsValid() {
+		e = append(e, "Overflow Policy must be ACCEPT, CAP or REJECT")
+	}
+
+	taxonomyErrors, err := u.validateTaxonomy(ctx, post)
+	if err != nil {
+		return nil, err
+	}
+	e = append(e, taxonomyErrors...)
+
 	if len(e) > 0 {
 		return nil, errors.New(strings.Join(e, ", "))
 	}
 
+	// Posts are published only after moderation, see ModeratePost.
+	post.Status = model.PostStatusDraft
+
 	return u.postRepository.CreatePost(ctx, post)
 }
 
-func (u *PostUsecase) GetAllPost(ctx context.Context) ([]model.Post, error) {
-	return u.postRepository.GetAllPost(ctx)
+// validateTaxonomy checks that the category of post exists and normalizes
+// its tags, dropping duplicates.
+func (u *PostUsecase) validateTaxonomy(ctx context.Context, post *model.Post) ([]string, error) {
+	var e []string
+
+	if post.CategoryID != nil {
+		_, err := u.categoryRepository.GetCategoryByID(ctx, *post.CategoryID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			e = append(e, "Category not found")
+		} else if err != nil {
+			return nil, err
+		}
+	}
+
+	if post.Tags == nil {
+		return e, nil
+	}
+
+	tags := make([]model.Tag, 0, len(post.Tags))
+	seen := make(map[string]bool, len(post.Tags))
+	for _, tag := range post.Tags {
+		name := model.NormalizeTagName(tag.Name)
+		if name == "" || seen[name] {
+			continue
+		}
+		if utf8.RuneCountInString(name) > model.MaxTagNameLength {
+			e = append(e, fmt.Sprintf("Tag %q must be at most %d characters", name, model.MaxTagNameLength))
+		}
+		seen[name] = true
+		tags = append(tags, model.Tag{Name: name})
+	}
+	if len(tags) > model.MaxPostTags {
+		e = append(e, fmt.Sprintf("A post can have at most %d tags", model.MaxPostTags))
+	}
+	post.Tags = tags
+
+	return e, nil
+}
+
+// GetAllPost returns one page of the public post listing, newest first unless
+// filter.Sort says otherwise.
+func (u *PostUsecase) GetAllPost(ctx context.Context, filter model.PostFilter) (*model.PostPage, error) {
+	var e []string
+
+	if filter.Sort == "" {
+		filter.Sort = model.PostSortNewest
+	}
+	if !filter.Sort.IsValid() {
+		e = append(e, "Sort must be NEWEST, ENDING_SOON, MOST_FUNDED or PERCENT_FUNDED")
+	}
+	if filter.Status != "" && !filter.Status.IsValid() {
+		e = append(e, "Status must be UPCOMING, ACTIVE, ENDED or FUNDED")
+	}
+	if filter.Cursor != nil && filter.Cursor.Sort != filter.Sort {
+		e = append(e, "Cursor belongs to another sort")
+	}
+	if !filter.DateFrom.IsZero() && !filter.DateTo.IsZero() && !filter.DateFrom.Before(filter.DateTo) {
+		e = append(e, "Date from must be before date to")
+	}
+	for _, target := range []*model.Money{filter.TargetMin, filter.TargetMax} {
+		if target != nil && target.Validate() != nil {
+			e = append(e, "Target currency is not supported")
+		}
+	}
+	if filter.TargetMin != nil && filter.TargetMax != nil {
+		if filter.TargetMin.Currency != filter.TargetMax.Currency {
+			e = append(e, "Target min and max must be in the same currency")
+		} else if filter.TargetMin.Amount > filter.TargetMax.Amount {
+			e = append(e, "Target min must not be greater than target max")
+		}
+	}
+	if filter.Limit < 0 {
+		e = append(e, "Limit must not be negative")
+	}
+
+	var tags []string
+	for _, tag := range filter.Tags {
+		if name := model.NormalizeTagName(tag); name != "" {
+			tags = append(tags, name)
+		}
+	}
+	filter.Tags = tags
+
+	if len(e) > 0 {
+		return nil, errors.New(strings.Join(e, ", "))
+	}
+
+	limit := filter.Limit
+	if limit == 0 {
+		limit = DefaultPostPageSize
+	}
+	if limit > MaxPostPageSize {
+		limit = MaxPostPageSize
+	}
+
+	// Fetch one extra row to learn whether another page follows.
+	filter.Limit = limit + 1
+
+	posts, err := u.postRepository.GetAllPost(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &model.PostPage{Posts: posts}
+	if len(posts) > limit {
+		page.Posts = posts[:limit]
+		page.NextCursor = model.NewPostCursor(filter.Sort, &page.Posts[limit-1]).Encode()
+	}
+
+	return page, nil
 }
 
 func (u *PostUsecase) GetPostByID(ctx context.Context, post_id uuid.UUID) (*model.Post, error) {
 	return u.postRepository.GetPostByID(ctx, post_id)
 }
 
+// LookupPost finds a post whatever its status, even once deleted, for the
+// services that settle and refund its donations.
+func (u *PostUsecase) LookupPost(ctx context.Context, post_id uuid.UUID) (*model.Post, error) {
+	return u.postRepository.GetPostByIDWithDeleted(ctx, post_id)
+}
+
 func (u *PostUsecase) GetAllPostByInstitutionID(ctx context.Context, institutionID uuid.UUID) ([]model.Post, error) {
 	return u.postRepository.GetAllPostByInstitutionID(ctx, institutionID)
 }
 
+// UpdatePost edits a post that has not been submitted for review or has been
+// rejected. Posts under review or approved must be withdrawn to DRAFT first,
+// so every change goes through moderation.
 func (u *PostUsecase) UpdatePost(ctx context.Context, post *model.Post) (*model.Post, error) {
 
 	getPost, err := u.postRepository.GetPostByID(ctx, post.PostID)
 	if err != nil {
 		return nil, err
 	}
+	if !getPost.Status.IsEditable() {
+		return nil, fmt.Errorf("%w: post is %s", ErrPostNotEditable, getPost.Status)
+	}
 
-	if post.FundTarget == 0 {
+	if post.FundTarget.IsZero() {
 		post.FundTarget = getPost.FundTarget
-	} else if post.FundTarget < 0 {
+	} else if post.FundTarget.Amount < 0 {
 		return nil, errors.New("fund Target must be greater than 0")
+	} else if post.FundTarget.Currency != getPost.FundTarget.Currency {
+		return nil, errors.New("fund Target currency cannot be changed")
+	}
+
+	if post.OverflowPolicy != "" && !post.OverflowPolicy.IsValid() {
+		return nil, errors.New("overflow Policy must be ACCEPT, CAP or REJECT")
+	}
+
+	e, err := u.validateTaxonomy(ctx, post)
+	if err != nil {
+		return nil, err
+	}
+	if len(e) > 0 {
+		return nil, errors.New(strings.Join(e, ", "))
 	}
 
 	return u.postRepository.UpdatePost(ctx, post)
@@ -104,6 +276,43 @@ func (u *PostUsecase) DeletePost(ctx context.Context, post_id uuid.UUID) error {
 	return u.postRepository.DeletePost(ctx, post_id)
 }
 
-func (u *PostUsecase) AddPostFundAchieved(ctx context.Context, post_id uuid.UUID, amount float64) (*model.Post, error) {
-	return u.postRepository.AddPostFundAchieved(ctx, post_id, amount)
+// ChangePostStatus moves a post along its lifecycle on behalf of actor.
+// Besides the transitions model.CanTransitionPost allows, a post can only be
+// submitted before its end by a verified institution, and completed once it
+// has ended or is funded.
+func (u *PostUsecase) ChangePostStatus(ctx context.Context, post_id uuid.UUID, status model.PostStatus, actor model.PostActor) (*model.Post, error) {
+	if !status.IsValid() {
+		return nil, ErrInvalidPostStatus
+	}
+
+	post, err := u.postRepository.GetPostByID(ctx, post_id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !model.CanTransitionPost(post.Status, status, actor) {
+		return nil, fmt.Errorf("%w: %s cannot move a %s post to %s",
+			ErrPostTransitionRefused, strings.ToLower(string(actor)), post.Status, status)
+	}
+
+	now := time.Now()
+	switch status {
+	case model.PostStatusSubmitted:
+		if post.HasEnded(now) {
+			return nil, fmt.Errorf("%w: the fundraising period has already ended", ErrPostTransitionRefused)
+		}
+		institution, err := u.institutionRepository.GetInstitutionByID(ctx, post.InstitutionID)
+		if err != nil {
+			return nil, err
+		}
+		if !institution.IsVerified() {
+			return nil, ErrInstitutionNotVerified
+		}
+	case model.PostStatusCompleted:
+		if !post.HasEnded(now) && !post.IsFunded() {
+			return nil, fmt.Errorf("%w: the fundraising is still running, close it instead", ErrPostTransitionRefused)
+		}
+	}
+
+	return u.postRepository.UpdatePostStatus(ctx, post_id, post.Status, status)
 }
@@ -0,0 +1,105 @@
+package tests
+
+import (
+	"context"
+	"institution-service/mocks"
+	"institution-service/model"
+	"institution-service/usecase"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestCreateCategory(t *testing.T) {
+	t.Run("success - slug derived from the name", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockCategoryRepo := mocks.NewMockICategoryRepository(ctrl)
+		categoryUsecase := usecase.NewCategoryUsecase(mockCategoryRepo)
+
+		category := &model.Category{Name: " Teacher Training "}
+
+		mockCategoryRepo.EXPECT().
+			GetCategoryBySlug(gomock.Any(), "teacher-training").
+			Return(nil, gorm.ErrRecordNotFound)
+		mockCategoryRepo.EXPECT().
+			CreateCategory(gomock.Any(), category).
+			Return(category, nil)
+
+		result, err := categoryUsecase.CreateCategory(context.Background(), category)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Teacher Training", result.Name)
+		assert.Equal(t, "teacher-training", result.Slug)
+	})
+
+	t.Run("failed - slug taken", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockCategoryRepo := mocks.NewMockICategoryRepository(ctrl)
+		categoryUsecase := usecase.NewCategoryUsecase(mockCategoryRepo)
+
+		mockCategoryRepo.EXPECT().
+			GetCategoryBySlug(gomock.Any(), "books").
+			Return(&model.Category{CategoryID: uuid.New(), Slug: "books"}, nil)
+
+		result, err := categoryUsecase.CreateCategory(context.Background(), &model.Category{Name: "Books"})
+
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, usecase.ErrCategorySlugTaken)
+	})
+
+	t.Run("failed - invalid category", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		categoryUsecase := usecase.NewCategoryUsecase(mocks.NewMockICategoryRepository(ctrl))
+
+		result, err := categoryUsecase.CreateCategory(context.Background(), &model.Category{Slug: "Not A Slug"})
+
+		assert.Nil(t, result)
+		assert.EqualError(t, err, "Name is required, Slug must be lowercase letters, digits and dashes, at most 64 characters")
+	})
+}
+
+func TestGetAllCategories(t *testing.T) {
+	t.Run("success - aggregates per currency", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockCategoryRepo := mocks.NewMockICategoryRepository(ctrl)
+		categoryUsecase := usecase.NewCategoryUsecase(mockCategoryRepo)
+
+		books := model.Category{CategoryID: uuid.New(), Slug: "books", Name: "Books"}
+		scholarships := model.Category{CategoryID: uuid.New(), Slug: "scholarships", Name: "Scholarships"}
+
+		mockCategoryRepo.EXPECT().
+			GetAllCategories(gomock.Any()).
+			Return([]model.Category{books, scholarships}, nil)
+		mockCategoryRepo.EXPECT().
+			GetCategoryStats(gomock.Any()).
+			Return([]model.CategoryStat{
+				{CategoryID: scholarships.CategoryID, Currency: "IDR", PostCount: 3, ActivePostCount: 2, FundTargetMinor: 300000000, FundAchievedMinor: 120000000},
+				{CategoryID: scholarships.CategoryID, Currency: "USD", PostCount: 1, FundTargetMinor: 500000, FundAchievedMinor: 500000},
+			}, nil)
+
+		summaries, err := categoryUsecase.GetAllCategories(context.Background())
+
+		assert.NoError(t, err)
+		assert.Equal(t, []model.CategorySummary{
+			{Category: books},
+			{
+				Category:        scholarships,
+				PostCount:       4,
+				ActivePostCount: 2,
+				FundTarget:      []model.Money{model.IDR(3000000), model.NewMoney(500000, "USD")},
+				FundAchieved:    []model.Money{model.IDR(1200000), model.NewMoney(500000, "USD")},
+			},
+		}, summaries)
+	})
+}
@@ -0,0 +1,259 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"institution-service/mocks"
+	"institution-service/model"
+	"institution-service/usecase"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func newDonationSettledEvent() model.DonationSettledEvent {
+	return model.DonationSettledEvent{
+		EventID:       uuid.New().String(),
+		TransactionID: "65f1c0a2b3c4d5e6f7a8b9c0",
+		PostID:        uuid.New().String(),
+		UserID:        uuid.New().String(),
+		UserName:      "Budi Santoso",
+		Amount:        model.IDR(50000),
+		PaidAt:        time.Now(),
+	}
+}
+
+func TestApplyDonationSettled(t *testing.T) {
+	t.Run("success - credits the fund collect", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockFundCollectRepo := mocks.NewMockIFundCollectRepository(ctrl)
+		fundCollectUsecase := usecase.NewFundCollectUsecase(mockFundCollectRepo)
+
+		event := newDonationSettledEvent()
+		original := model.NewMoney(325, "USD")
+		event.OriginalAmount = &original
+		event.Message = " Semoga lekas pulih "
+
+		mockFundCollectRepo.EXPECT().
+			CreditFundCollect(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, fundCollect *model.FundCollect) (model.Money, bool, error) {
+				assert.Equal(t, event.PostID, fundCollect.PostID.String())
+				assert.Equal(t, event.TransactionID, fundCollect.TransactionID)
+				assert.Equal(t, event.UserName, fundCollect.UserName)
+				assert.Equal(t, event.Amount, fundCollect.Amount)
+				assert.Equal(t, original, fundCollect.OriginalAmount)
+				assert.Equal(t, "Semoga lekas pulih", fundCollect.Message)
+				return fundCollect.Amount, true, nil
+			})
+
+		err := fundCollectUsecase.ApplyDonationSettled(context.Background(), event)
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("success - email of an older event is anonymized", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockFundCollectRepo := mocks.NewMockIFundCollectRepository(ctrl)
+		fundCollectUsecase := usecase.NewFundCollectUsecase(mockFundCollectRepo)
+
+		event := newDonationSettledEvent()
+		event.UserName = "donor@email.com"
+
+		mockFundCollectRepo.EXPECT().
+			CreditFundCollect(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, fundCollect *model.FundCollect) (model.Money, bool, error) {
+				assert.Equal(t, model.AnonymousDonorName, fundCollect.UserName)
+				return fundCollect.Amount, true, nil
+			})
+
+		err := fundCollectUsecase.ApplyDonationSettled(context.Background(), event)
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("success - redelivered event is ignored", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockFundCollectRepo := mocks.NewMockIFundCollectRepository(ctrl)
+		fundCollectUsecase := usecase.NewFundCollectUsecase(mockFundCollectRepo)
+
+		mockFundCollectRepo.EXPECT().
+			CreditFundCollect(gomock.Any(), gomock.Any()).
+			Return(model.Money{}, false, nil)
+
+		err := fundCollectUsecase.ApplyDonationSettled(context.Background(), newDonationSettledEvent())
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("failed - invalid event", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockFundCollectRepo := mocks.NewMockIFundCollectRepository(ctrl)
+		fundCollectUsecase := usecase.NewFundCollectUsecase(mockFundCollectRepo)
+
+		event := newDonationSettledEvent()
+		event.PostID = "invalid"
+		event.Amount = model.IDR(0)
+
+		err := fundCollectUsecase.ApplyDonationSettled(context.Background(), event)
+
+		assert.ErrorIs(t, err, usecase.ErrInvalidDonationEvent)
+		assert.EqualError(t, err, "invalid donation event: Post ID is invalid, Amount must be greater than 0")
+	})
+
+	t.Run("failed - database error can be retried", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockFundCollectRepo := mocks.NewMockIFundCollectRepository(ctrl)
+		fundCollectUsecase := usecase.NewFundCollectUsecase(mockFundCollectRepo)
+
+		mockFundCollectRepo.EXPECT().
+			CreditFundCollect(gomock.Any(), gomock.Any()).
+			Return(model.Money{}, false, errors.New("database error"))
+
+		err := fundCollectUsecase.ApplyDonationSettled(context.Background(), newDonationSettledEvent())
+
+		assert.Error(t, err)
+		assert.NotErrorIs(t, err, usecase.ErrInvalidDonationEvent)
+	})
+}
+
+func TestApplyDonationRefunded(t *testing.T) {
+	newDonationRefundedEvent := func() model.DonationRefundedEvent {
+		return model.DonationRefundedEvent{
+			EventID:       uuid.New().String(),
+			TransactionID: "65f1c0a2b3c4d5e6f7a8b9c0",
+			PostID:        uuid.New().String(),
+			RefundID:      "rfd-1",
+			Amount:        model.IDR(20000),
+			RefundedAt:    time.Now(),
+		}
+	}
+
+	t.Run("success - debits the fund collect", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockFundCollectRepo := mocks.NewMockIFundCollectRepository(ctrl)
+		fundCollectUsecase := usecase.NewFundCollectUsecase(mockFundCollectRepo)
+
+		event := newDonationRefundedEvent()
+
+		mockFundCollectRepo.EXPECT().
+			DebitFundCollect(gomock.Any(), uuid.MustParse(event.PostID), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, postID uuid.UUID, refund *model.FundCollectRefund) (model.Money, bool, error) {
+				assert.Equal(t, event.RefundID, refund.RefundID)
+				assert.Equal(t, event.TransactionID, refund.TransactionID)
+				assert.Equal(t, event.Amount, refund.Amount)
+				return model.IDR(0), true, nil
+			})
+
+		err := fundCollectUsecase.ApplyDonationRefunded(context.Background(), event)
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("success - redelivered refund is ignored", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockFundCollectRepo := mocks.NewMockIFundCollectRepository(ctrl)
+		fundCollectUsecase := usecase.NewFundCollectUsecase(mockFundCollectRepo)
+
+		mockFundCollectRepo.EXPECT().
+			DebitFundCollect(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(model.Money{}, false, nil)
+
+		err := fundCollectUsecase.ApplyDonationRefunded(context.Background(), newDonationRefundedEvent())
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("failed - refund ID is required", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockFundCollectRepo := mocks.NewMockIFundCollectRepository(ctrl)
+		fundCollectUsecase := usecase.NewFundCollectUsecase(mockFundCollectRepo)
+
+		event := newDonationRefundedEvent()
+		event.RefundID = ""
+
+		err := fundCollectUsecase.ApplyDonationRefunded(context.Background(), event)
+
+		assert.ErrorIs(t, err, usecase.ErrInvalidDonationEvent)
+		assert.EqualError(t, err, "invalid donation event: Refund ID is required")
+	})
+}
+
+func TestGetPostSupporters(t *testing.T) {
+	t.Run("success - last page has no cursor", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockFundCollectRepo := mocks.NewMockIFundCollectRepository(ctrl)
+		fundCollectUsecase := usecase.NewFundCollectUsecase(mockFundCollectRepo)
+
+		postID := uuid.New()
+		fundCollects := []model.FundCollect{{FundCollectID: uuid.New(), PostID: postID}}
+
+		mockFundCollectRepo.EXPECT().
+			GetSupporters(gomock.Any(), postID, uuid.Nil, usecase.DefaultSupporterPageSize+1).
+			Return(fundCollects, nil)
+
+		page, err := fundCollectUsecase.GetPostSupporters(context.Background(), postID, "", 0)
+
+		assert.NoError(t, err)
+		assert.Len(t, page.FundCollects, 1)
+		assert.Empty(t, page.NextCursor)
+	})
+
+	t.Run("success - full page returns the next cursor", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockFundCollectRepo := mocks.NewMockIFundCollectRepository(ctrl)
+		fundCollectUsecase := usecase.NewFundCollectUsecase(mockFundCollectRepo)
+
+		postID := uuid.New()
+		cursor := uuid.New()
+		fundCollects := []model.FundCollect{
+			{FundCollectID: uuid.New(), PostID: postID},
+			{FundCollectID: uuid.New(), PostID: postID},
+			{FundCollectID: uuid.New(), PostID: postID},
+		}
+
+		mockFundCollectRepo.EXPECT().
+			GetSupporters(gomock.Any(), postID, cursor, 3).
+			Return(fundCollects, nil)
+
+		page, err := fundCollectUsecase.GetPostSupporters(context.Background(), postID, cursor.String(), 2)
+
+		assert.NoError(t, err)
+		assert.Len(t, page.FundCollects, 2)
+		assert.Equal(t, fundCollects[1].FundCollectID.String(), page.NextCursor)
+	})
+
+	t.Run("failed - invalid cursor", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		fundCollectUsecase := usecase.NewFundCollectUsecase(mocks.NewMockIFundCollectRepository(ctrl))
+
+		page, err := fundCollectUsecase.GetPostSupporters(context.Background(), uuid.New(), "not-a-cursor", -1)
+
+		assert.Nil(t, page)
+		assert.EqualError(t, err, "Cursor is invalid, Limit must not be negative")
+	})
+}
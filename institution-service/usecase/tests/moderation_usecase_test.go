@@ -0,0 +1,178 @@
+package tests
+
+import (
+	"context"
+	"institution-service/mocks"
+	"institution-service/model"
+	"institution-service/usecase"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestModeratePost(t *testing.T) {
+	t.Run("success - approve publishes the post", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockModerationRepo := mocks.NewMockIModerationRepository(ctrl)
+		mockPostRepo := mocks.NewMockIPostRepository(ctrl)
+		mockInstitutionRepo := mocks.NewMockIInstitutionRepository(ctrl)
+		moderationUsecase := usecase.NewModerationUsecase(mockModerationRepo, mockPostRepo, mockInstitutionRepo)
+
+		post := newPost()
+		post.PostID = uuid.New()
+		post.Status = model.PostStatusSubmitted
+		published := *post
+		published.Status = model.PostStatusPublished
+		institution := &model.Institution{
+			InstitutionID:      post.InstitutionID,
+			Name:               "Yayasan Pesisir",
+			Email:              "yayasan@email.com",
+			VerificationStatus: model.VerificationStatusVerified,
+		}
+
+		moderation := &model.PostModeration{
+			PostID:         post.PostID,
+			Decision:       model.ModerationDecisionApprove,
+			ModeratorEmail: "moderator@email.com",
+		}
+
+		gomock.InOrder(
+			mockPostRepo.EXPECT().GetPostByID(gomock.Any(), post.PostID).Return(post, nil),
+			mockInstitutionRepo.EXPECT().GetInstitutionByID(gomock.Any(), post.InstitutionID).Return(institution, nil),
+			mockModerationRepo.EXPECT().
+				CreateModeration(gomock.Any(), moderation).
+				DoAndReturn(func(ctx context.Context, moderation *model.PostModeration) (*model.PostModeration, error) {
+					assert.Equal(t, model.PostStatusSubmitted, moderation.FromStatus)
+					assert.Equal(t, model.PostStatusPublished, moderation.ToStatus)
+					return moderation, nil
+				}),
+			mockPostRepo.EXPECT().GetPostByID(gomock.Any(), post.PostID).Return(&published, nil),
+		)
+
+		result, err := moderationUsecase.ModeratePost(context.Background(), moderation)
+
+		assert.NoError(t, err)
+		assert.Equal(t, model.PostStatusPublished, result.Post.Status)
+		assert.Equal(t, "yayasan@email.com", result.Post.Institution.Email)
+	})
+
+	t.Run("failed - reject without a reason", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		moderationUsecase := usecase.NewModerationUsecase(mocks.NewMockIModerationRepository(ctrl),
+			mocks.NewMockIPostRepository(ctrl), mocks.NewMockIInstitutionRepository(ctrl))
+
+		result, err := moderationUsecase.ModeratePost(context.Background(), &model.PostModeration{
+			PostID:         uuid.New(),
+			Decision:       model.ModerationDecisionReject,
+			Reason:         "  ",
+			ModeratorEmail: "moderator@email.com",
+		})
+
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, usecase.ErrModerationReasonRequired)
+	})
+
+	t.Run("failed - unknown decision", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		moderationUsecase := usecase.NewModerationUsecase(mocks.NewMockIModerationRepository(ctrl),
+			mocks.NewMockIPostRepository(ctrl), mocks.NewMockIInstitutionRepository(ctrl))
+
+		result, err := moderationUsecase.ModeratePost(context.Background(), &model.PostModeration{
+			PostID:   uuid.New(),
+			Decision: "PUBLISH",
+		})
+
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, usecase.ErrInvalidModerationDecision)
+	})
+
+	t.Run("failed - post is not submitted", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockPostRepo := mocks.NewMockIPostRepository(ctrl)
+		moderationUsecase := usecase.NewModerationUsecase(mocks.NewMockIModerationRepository(ctrl),
+			mockPostRepo, mocks.NewMockIInstitutionRepository(ctrl))
+
+		post := newPost()
+		post.PostID = uuid.New()
+		post.Status = model.PostStatusDraft
+
+		mockPostRepo.EXPECT().GetPostByID(gomock.Any(), post.PostID).Return(post, nil)
+
+		result, err := moderationUsecase.ModeratePost(context.Background(), &model.PostModeration{
+			PostID:         post.PostID,
+			Decision:       model.ModerationDecisionRequestChanges,
+			Reason:         "Add the budget",
+			ModeratorEmail: "moderator@email.com",
+		})
+
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, usecase.ErrPostTransitionRefused)
+		assert.EqualError(t, err, "post status change not allowed: moderators cannot move a DRAFT post to DRAFT")
+	})
+
+	t.Run("failed - ended fundraising cannot be approved", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockPostRepo := mocks.NewMockIPostRepository(ctrl)
+		moderationUsecase := usecase.NewModerationUsecase(mocks.NewMockIModerationRepository(ctrl),
+			mockPostRepo, mocks.NewMockIInstitutionRepository(ctrl))
+
+		post := newPost()
+		post.PostID = uuid.New()
+		post.Status = model.PostStatusSubmitted
+		post.DateStart = time.Now().AddDate(0, -2, 0)
+		post.DateEnd = time.Now().AddDate(0, 0, -1)
+
+		mockPostRepo.EXPECT().GetPostByID(gomock.Any(), post.PostID).Return(post, nil)
+
+		result, err := moderationUsecase.ModeratePost(context.Background(), &model.PostModeration{
+			PostID:         post.PostID,
+			Decision:       model.ModerationDecisionApprove,
+			ModeratorEmail: "moderator@email.com",
+		})
+
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, usecase.ErrPostTransitionRefused)
+	})
+
+	t.Run("failed - posts of unverified institutions cannot be approved", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockPostRepo := mocks.NewMockIPostRepository(ctrl)
+		mockInstitutionRepo := mocks.NewMockIInstitutionRepository(ctrl)
+		moderationUsecase := usecase.NewModerationUsecase(mocks.NewMockIModerationRepository(ctrl),
+			mockPostRepo, mockInstitutionRepo)
+
+		post := newPost()
+		post.PostID = uuid.New()
+		post.Status = model.PostStatusSubmitted
+
+		mockPostRepo.EXPECT().GetPostByID(gomock.Any(), post.PostID).Return(post, nil)
+		mockInstitutionRepo.EXPECT().GetInstitutionByID(gomock.Any(), post.InstitutionID).Return(&model.Institution{
+			InstitutionID:      post.InstitutionID,
+			VerificationStatus: model.VerificationStatusPending,
+		}, nil)
+
+		result, err := moderationUsecase.ModeratePost(context.Background(), &model.PostModeration{
+			PostID:         post.PostID,
+			Decision:       model.ModerationDecisionApprove,
+			ModeratorEmail: "moderator@email.com",
+		})
+
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, usecase.ErrInstitutionNotVerified)
+	})
+}
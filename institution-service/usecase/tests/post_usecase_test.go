@@ -0,0 +1,443 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"institution-service/mocks"
+	"institution-service/model"
+	"institution-service/usecase"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func newPost() *model.Post {
+	return &model.Post{
+		InstitutionID: uuid.New(),
+		Title:         "School library",
+		Body:          "Help us build a library",
+		DateStart:     time.Now(),
+		DateEnd:       time.Now().Add(30 * 24 * time.Hour),
+		FundTarget:    model.IDR(1000000),
+	}
+}
+
+func TestCreatePostOverflowPolicy(t *testing.T) {
+	t.Run("success - defaults to accept", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockPostRepo := mocks.NewMockIPostRepository(ctrl)
+		postUsecase := usecase.NewPostUsecase(mockPostRepo, mocks.NewMockICategoryRepository(ctrl), mocks.NewMockIInstitutionRepository(ctrl))
+
+		post := newPost()
+
+		mockPostRepo.EXPECT().
+			CreatePost(gomock.Any(), post).
+			Return(post, nil)
+
+		ctx := context.Background()
+		result, err := postUsecase.CreatePost(ctx, post)
+
+		assert.NoError(t, err)
+		assert.Equal(t, model.OverflowPolicyAccept, result.OverflowPolicy)
+		assert.Equal(t, model.PostStatusDraft, result.Status)
+	})
+
+	t.Run("success - cap", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockPostRepo := mocks.NewMockIPostRepository(ctrl)
+		postUsecase := usecase.NewPostUsecase(mockPostRepo, mocks.NewMockICategoryRepository(ctrl), mocks.NewMockIInstitutionRepository(ctrl))
+
+		post := newPost()
+		post.OverflowPolicy = model.OverflowPolicyCap
+
+		mockPostRepo.EXPECT().
+			CreatePost(gomock.Any(), post).
+			Return(post, nil)
+
+		ctx := context.Background()
+		result, err := postUsecase.CreatePost(ctx, post)
+
+		assert.NoError(t, err)
+		assert.Equal(t, model.OverflowPolicyCap, result.OverflowPolicy)
+	})
+
+	t.Run("failed - unknown policy", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockPostRepo := mocks.NewMockIPostRepository(ctrl)
+		postUsecase := usecase.NewPostUsecase(mockPostRepo, mocks.NewMockICategoryRepository(ctrl), mocks.NewMockIInstitutionRepository(ctrl))
+
+		post := newPost()
+		post.OverflowPolicy = "OVERFLOW"
+
+		ctx := context.Background()
+		result, err := postUsecase.CreatePost(ctx, post)
+
+		assert.Nil(t, result)
+		assert.EqualError(t, err, "Overflow Policy must be ACCEPT, CAP or REJECT")
+	})
+}
+
+func TestUpdatePostOverflowPolicy(t *testing.T) {
+	t.Run("failed - unknown policy", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockPostRepo := mocks.NewMockIPostRepository(ctrl)
+		postUsecase := usecase.NewPostUsecase(mockPostRepo, mocks.NewMockICategoryRepository(ctrl), mocks.NewMockIInstitutionRepository(ctrl))
+
+		post := newPost()
+		post.PostID = uuid.New()
+		post.Status = model.PostStatusDraft
+
+		mockPostRepo.EXPECT().
+			GetPostByID(gomock.Any(), post.PostID).
+			Return(post, nil)
+
+		ctx := context.Background()
+		result, err := postUsecase.UpdatePost(ctx, &model.Post{PostID: post.PostID, OverflowPolicy: "OVERFLOW"})
+
+		assert.Nil(t, result)
+		assert.Error(t, err)
+	})
+
+	t.Run("failed - fund target currency cannot change", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockPostRepo := mocks.NewMockIPostRepository(ctrl)
+		postUsecase := usecase.NewPostUsecase(mockPostRepo, mocks.NewMockICategoryRepository(ctrl), mocks.NewMockIInstitutionRepository(ctrl))
+
+		post := newPost()
+		post.PostID = uuid.New()
+		post.Status = model.PostStatusDraft
+
+		mockPostRepo.EXPECT().
+			GetPostByID(gomock.Any(), post.PostID).
+			Return(post, nil)
+
+		ctx := context.Background()
+		result, err := postUsecase.UpdatePost(ctx, &model.Post{PostID: post.PostID, FundTarget: model.NewMoney(100000, "USD")})
+
+		assert.Nil(t, result)
+		assert.EqualError(t, err, "fund Target currency cannot be changed")
+	})
+}
+
+func TestUpdatePostStatus(t *testing.T) {
+	t.Run("success - rejected post is edited", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockPostRepo := mocks.NewMockIPostRepository(ctrl)
+		postUsecase := usecase.NewPostUsecase(mockPostRepo, mocks.NewMockICategoryRepository(ctrl), mocks.NewMockIInstitutionRepository(ctrl))
+
+		post := newPost()
+		post.PostID = uuid.New()
+		post.Status = model.PostStatusRejected
+		update := &model.Post{PostID: post.PostID, Title: "School library and reading room", FundTarget: model.IDR(2000000)}
+
+		mockPostRepo.EXPECT().
+			GetPostByID(gomock.Any(), post.PostID).
+			Return(post, nil)
+		mockPostRepo.EXPECT().
+			UpdatePost(gomock.Any(), update).
+			Return(post, nil)
+
+		ctx := context.Background()
+		result, err := postUsecase.UpdatePost(ctx, update)
+
+		assert.NoError(t, err)
+		assert.NotNil(t, result)
+	})
+
+	for _, status := range []model.PostStatus{model.PostStatusSubmitted, model.PostStatusPublished, model.PostStatusClosed} {
+		t.Run("failed - "+string(status)+" post cannot be edited", func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockPostRepo := mocks.NewMockIPostRepository(ctrl)
+			postUsecase := usecase.NewPostUsecase(mockPostRepo, mocks.NewMockICategoryRepository(ctrl), mocks.NewMockIInstitutionRepository(ctrl))
+
+			post := newPost()
+			post.PostID = uuid.New()
+			post.Status = status
+
+			mockPostRepo.EXPECT().
+				GetPostByID(gomock.Any(), post.PostID).
+				Return(post, nil)
+
+			ctx := context.Background()
+			result, err := postUsecase.UpdatePost(ctx, &model.Post{PostID: post.PostID, Title: "A different campaign"})
+
+			assert.Nil(t, result)
+			assert.ErrorIs(t, err, usecase.ErrPostNotEditable)
+		})
+	}
+}
+
+func TestCreatePostFundTarget(t *testing.T) {
+	t.Run("failed - unsupported currency", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		postUsecase := usecase.NewPostUsecase(mocks.NewMockIPostRepository(ctrl), mocks.NewMockICategoryRepository(ctrl), mocks.NewMockIInstitutionRepository(ctrl))
+
+		post := newPost()
+		post.FundTarget = model.NewMoney(100000, "XYZ")
+
+		ctx := context.Background()
+		result, err := postUsecase.CreatePost(ctx, post)
+
+		assert.Nil(t, result)
+		assert.EqualError(t, err, "Fund Target currency is not supported")
+	})
+}
+
+func TestGetAllPost(t *testing.T) {
+	t.Run("success - newest first with a cursor to the next page", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockPostRepo := mocks.NewMockIPostRepository(ctrl)
+		postUsecase := usecase.NewPostUsecase(mockPostRepo, mocks.NewMockICategoryRepository(ctrl), mocks.NewMockIInstitutionRepository(ctrl))
+
+		posts := make([]model.Post, 3)
+		for i := range posts {
+			posts[i] = *newPost()
+			posts[i].PostID = uuid.New()
+			posts[i].CreatedAt = time.Now().Add(-time.Duration(i) * time.Hour)
+		}
+
+		mockPostRepo.EXPECT().
+			GetAllPost(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, filter model.PostFilter) ([]model.Post, error) {
+				assert.Equal(t, model.PostSortNewest, filter.Sort)
+				assert.Equal(t, 3, filter.Limit)
+				return posts, nil
+			})
+
+		page, err := postUsecase.GetAllPost(context.Background(), model.PostFilter{Limit: 2})
+
+		assert.NoError(t, err)
+		assert.Len(t, page.Posts, 2)
+
+		cursor, err := model.DecodePostCursor(page.NextCursor)
+		assert.NoError(t, err)
+		assert.Equal(t, model.NewPostCursor(model.PostSortNewest, &posts[1]), *cursor)
+	})
+
+	t.Run("success - last page has no cursor", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockPostRepo := mocks.NewMockIPostRepository(ctrl)
+		postUsecase := usecase.NewPostUsecase(mockPostRepo, mocks.NewMockICategoryRepository(ctrl), mocks.NewMockIInstitutionRepository(ctrl))
+
+		mockPostRepo.EXPECT().
+			GetAllPost(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, filter model.PostFilter) ([]model.Post, error) {
+				assert.Equal(t, usecase.MaxPostPageSize+1, filter.Limit)
+				return []model.Post{*newPost()}, nil
+			})
+
+		page, err := postUsecase.GetAllPost(context.Background(), model.PostFilter{Sort: model.PostSortMostFunded, Limit: 500})
+
+		assert.NoError(t, err)
+		assert.Len(t, page.Posts, 1)
+		assert.Empty(t, page.NextCursor)
+	})
+
+	t.Run("failed - invalid filter", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		postUsecase := usecase.NewPostUsecase(mocks.NewMockIPostRepository(ctrl), mocks.NewMockICategoryRepository(ctrl), mocks.NewMockIInstitutionRepository(ctrl))
+
+		targetMin, targetMax := model.IDR(500), model.NewMoney(100, "USD")
+		page, err := postUsecase.GetAllPost(context.Background(), model.PostFilter{
+			Status:    "CLOSED",
+			Sort:      model.PostSortEndingSoon,
+			Cursor:    &model.PostCursor{Sort: model.PostSortNewest, PostID: uuid.New()},
+			TargetMin: &targetMin,
+			TargetMax: &targetMax,
+		})
+
+		assert.Nil(t, page)
+		assert.EqualError(t, err, "Status must be UPCOMING, ACTIVE, ENDED or FUNDED, Cursor belongs to another sort, Target min and max must be in the same currency")
+	})
+}
+
+func TestCreatePostTaxonomy(t *testing.T) {
+	t.Run("success - tags normalized and deduplicated", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockPostRepo := mocks.NewMockIPostRepository(ctrl)
+		mockCategoryRepo := mocks.NewMockICategoryRepository(ctrl)
+		postUsecase := usecase.NewPostUsecase(mockPostRepo, mockCategoryRepo, mocks.NewMockIInstitutionRepository(ctrl))
+
+		categoryID := uuid.New()
+		post := newPost()
+		post.CategoryID = &categoryID
+		post.Tags = []model.Tag{{Name: " Papua "}, {Name: "papua"}, {Name: "Sekolah  Dasar"}, {Name: " "}}
+
+		mockCategoryRepo.EXPECT().
+			GetCategoryByID(gomock.Any(), categoryID).
+			Return(&model.Category{CategoryID: categoryID}, nil)
+		mockPostRepo.EXPECT().
+			CreatePost(gomock.Any(), post).
+			Return(post, nil)
+
+		result, err := postUsecase.CreatePost(context.Background(), post)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []model.Tag{{Name: "papua"}, {Name: "sekolah dasar"}}, result.Tags)
+	})
+
+	t.Run("failed - unknown category and too many tags", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockCategoryRepo := mocks.NewMockICategoryRepository(ctrl)
+		postUsecase := usecase.NewPostUsecase(mocks.NewMockIPostRepository(ctrl), mockCategoryRepo, mocks.NewMockIInstitutionRepository(ctrl))
+
+		categoryID := uuid.New()
+		post := newPost()
+		post.CategoryID = &categoryID
+		for i := 0; i <= model.MaxPostTags; i++ {
+			post.Tags = append(post.Tags, model.Tag{Name: fmt.Sprintf("tag %d", i)})
+		}
+
+		mockCategoryRepo.EXPECT().
+			GetCategoryByID(gomock.Any(), categoryID).
+			Return(nil, gorm.ErrRecordNotFound)
+
+		result, err := postUsecase.CreatePost(context.Background(), post)
+
+		assert.Nil(t, result)
+		assert.EqualError(t, err, "Category not found, A post can have at most 10 tags")
+	})
+}
+
+func TestChangePostStatus(t *testing.T) {
+	t.Run("success - institution submits a draft", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockPostRepo := mocks.NewMockIPostRepository(ctrl)
+		mockInstitutionRepo := mocks.NewMockIInstitutionRepository(ctrl)
+		postUsecase := usecase.NewPostUsecase(mockPostRepo, mocks.NewMockICategoryRepository(ctrl), mockInstitutionRepo)
+
+		post := newPost()
+		post.PostID = uuid.New()
+		post.Status = model.PostStatusDraft
+		submitted := *post
+		submitted.Status = model.PostStatusSubmitted
+
+		mockPostRepo.EXPECT().
+			GetPostByID(gomock.Any(), post.PostID).
+			Return(post, nil)
+		mockInstitutionRepo.EXPECT().
+			GetInstitutionByID(gomock.Any(), post.InstitutionID).
+			Return(&model.Institution{InstitutionID: post.InstitutionID, VerificationStatus: model.VerificationStatusVerified}, nil)
+		mockPostRepo.EXPECT().
+			UpdatePostStatus(gomock.Any(), post.PostID, model.PostStatusDraft, model.PostStatusSubmitted).
+			Return(&submitted, nil)
+
+		result, err := postUsecase.ChangePostStatus(context.Background(), post.PostID, model.PostStatusSubmitted, model.PostActorInstitution)
+
+		assert.NoError(t, err)
+		assert.Equal(t, model.PostStatusSubmitted, result.Status)
+	})
+
+	t.Run("failed - unverified institution keeps its posts as drafts", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockPostRepo := mocks.NewMockIPostRepository(ctrl)
+		mockInstitutionRepo := mocks.NewMockIInstitutionRepository(ctrl)
+		postUsecase := usecase.NewPostUsecase(mockPostRepo, mocks.NewMockICategoryRepository(ctrl), mockInstitutionRepo)
+
+		post := newPost()
+		post.PostID = uuid.New()
+		post.Status = model.PostStatusDraft
+
+		mockPostRepo.EXPECT().
+			GetPostByID(gomock.Any(), post.PostID).
+			Return(post, nil)
+		mockInstitutionRepo.EXPECT().
+			GetInstitutionByID(gomock.Any(), post.InstitutionID).
+			Return(&model.Institution{InstitutionID: post.InstitutionID, VerificationStatus: model.VerificationStatusPending}, nil)
+
+		result, err := postUsecase.ChangePostStatus(context.Background(), post.PostID, model.PostStatusSubmitted, model.PostActorInstitution)
+
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, usecase.ErrInstitutionNotVerified)
+	})
+
+	t.Run("failed - institution cannot publish its own post", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockPostRepo := mocks.NewMockIPostRepository(ctrl)
+		postUsecase := usecase.NewPostUsecase(mockPostRepo, mocks.NewMockICategoryRepository(ctrl), mocks.NewMockIInstitutionRepository(ctrl))
+
+		post := newPost()
+		post.PostID = uuid.New()
+		post.Status = model.PostStatusSubmitted
+
+		mockPostRepo.EXPECT().
+			GetPostByID(gomock.Any(), post.PostID).
+			Return(post, nil)
+
+		result, err := postUsecase.ChangePostStatus(context.Background(), post.PostID, model.PostStatusPublished, model.PostActorInstitution)
+
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, usecase.ErrPostTransitionRefused)
+		assert.EqualError(t, err, "post status change not allowed: institution cannot move a SUBMITTED post to PUBLISHED")
+	})
+
+	t.Run("failed - running fundraising cannot be completed", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockPostRepo := mocks.NewMockIPostRepository(ctrl)
+		postUsecase := usecase.NewPostUsecase(mockPostRepo, mocks.NewMockICategoryRepository(ctrl), mocks.NewMockIInstitutionRepository(ctrl))
+
+		post := newPost()
+		post.PostID = uuid.New()
+		post.Status = model.PostStatusPublished
+		post.FundAchieved = model.IDR(500000)
+
+		mockPostRepo.EXPECT().
+			GetPostByID(gomock.Any(), post.PostID).
+			Return(post, nil)
+
+		result, err := postUsecase.ChangePostStatus(context.Background(), post.PostID, model.PostStatusCompleted, model.PostActorInstitution)
+
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, usecase.ErrPostTransitionRefused)
+	})
+
+	t.Run("failed - unknown status", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		postUsecase := usecase.NewPostUsecase(mocks.NewMockIPostRepository(ctrl), mocks.NewMockICategoryRepository(ctrl), mocks.NewMockIInstitutionRepository(ctrl))
+
+		result, err := postUsecase.ChangePostStatus(context.Background(), uuid.New(), "LIVE", model.PostActorAdmin)
+
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, usecase.ErrInvalidPostStatus)
+	})
+}
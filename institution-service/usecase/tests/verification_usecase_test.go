@@ -0,0 +1,221 @@
+package tests
+
+import (
+	"context"
+	"institution-service/mocks"
+	"institution-service/model"
+	"institution-service/usecase"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+var pdfContent = []byte("%PDF-1.4\n1 0 obj\n<< /Type /Catalog >>\nendobj\n")
+
+func TestUploadDocument(t *testing.T) {
+	t.Run("success - replaces the document of the same type", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockVerificationRepo := mocks.NewMockIVerificationRepository(ctrl)
+		mockInstitutionRepo := mocks.NewMockIInstitutionRepository(ctrl)
+		mockStorage := mocks.NewMockIStorage(ctrl)
+		verificationUsecase := usecase.NewVerificationUsecase(mockVerificationRepo, mockInstitutionRepo, mockStorage)
+
+		institutionID := uuid.New()
+		replaced := &model.InstitutionDocument{StorageKey: "institutions/old.pdf"}
+
+		var storageKey string
+		gomock.InOrder(
+			mockInstitutionRepo.EXPECT().GetInstitutionByID(gomock.Any(), institutionID).Return(&model.Institution{
+				InstitutionID:      institutionID,
+				VerificationStatus: model.VerificationStatusRejected,
+			}, nil),
+			mockStorage.EXPECT().Put(gomock.Any(), gomock.Any(), pdfContent).
+				DoAndReturn(func(ctx context.Context, key string, content []byte) error {
+					storageKey = key
+					return nil
+				}),
+			mockVerificationRepo.EXPECT().SaveDocument(gomock.Any(), gomock.Any()).Return(replaced, nil),
+			mockStorage.EXPECT().Delete(gomock.Any(), "institutions/old.pdf").Return(nil),
+		)
+
+		result, err := verificationUsecase.UploadDocument(context.Background(), &model.InstitutionDocument{
+			InstitutionID: institutionID,
+			Type:          model.DocumentTypeTaxID,
+			Filename:      "../../npwp.pdf",
+		}, pdfContent)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "npwp.pdf", result.Filename)
+		assert.Equal(t, "application/pdf", result.ContentType)
+		assert.Equal(t, int64(len(pdfContent)), result.Size)
+		assert.Equal(t, result.StorageKey, storageKey)
+		assert.True(t, strings.HasPrefix(storageKey, "institutions/"+institutionID.String()+"/"))
+		assert.True(t, strings.HasSuffix(storageKey, ".pdf"))
+	})
+
+	t.Run("failed - invalid type and content", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		verificationUsecase := usecase.NewVerificationUsecase(mocks.NewMockIVerificationRepository(ctrl),
+			mocks.NewMockIInstitutionRepository(ctrl), mocks.NewMockIStorage(ctrl))
+
+		result, err := verificationUsecase.UploadDocument(context.Background(), &model.InstitutionDocument{
+			InstitutionID: uuid.New(),
+			Type:          "KTP",
+			Filename:      "script.sh",
+		}, []byte("#!/bin/sh\necho hello\n"))
+
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, usecase.ErrInvalidDocument)
+		assert.ErrorContains(t, err, "Type must be")
+		assert.ErrorContains(t, err, "File must be a PDF, JPEG or PNG")
+	})
+
+	t.Run("failed - documents are locked while under review", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockInstitutionRepo := mocks.NewMockIInstitutionRepository(ctrl)
+		verificationUsecase := usecase.NewVerificationUsecase(mocks.NewMockIVerificationRepository(ctrl),
+			mockInstitutionRepo, mocks.NewMockIStorage(ctrl))
+
+		institutionID := uuid.New()
+		mockInstitutionRepo.EXPECT().GetInstitutionByID(gomock.Any(), institutionID).Return(&model.Institution{
+			InstitutionID:      institutionID,
+			VerificationStatus: model.VerificationStatusPending,
+		}, nil)
+
+		result, err := verificationUsecase.UploadDocument(context.Background(), &model.InstitutionDocument{
+			InstitutionID: institutionID,
+			Type:          model.DocumentTypeRegistrationCertificate,
+			Filename:      "akta.pdf",
+		}, pdfContent)
+
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, usecase.ErrVerificationRefused)
+	})
+}
+
+func TestSubmitVerification(t *testing.T) {
+	t.Run("success - all documents uploaded", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockVerificationRepo := mocks.NewMockIVerificationRepository(ctrl)
+		mockInstitutionRepo := mocks.NewMockIInstitutionRepository(ctrl)
+		verificationUsecase := usecase.NewVerificationUsecase(mockVerificationRepo, mockInstitutionRepo, mocks.NewMockIStorage(ctrl))
+
+		institutionID := uuid.New()
+		documents := make([]model.InstitutionDocument, 0, len(model.RequiredDocumentTypes))
+		for _, documentType := range model.RequiredDocumentTypes {
+			documents = append(documents, model.InstitutionDocument{InstitutionID: institutionID, Type: documentType})
+		}
+		pending := &model.Institution{InstitutionID: institutionID, VerificationStatus: model.VerificationStatusPending}
+
+		gomock.InOrder(
+			mockInstitutionRepo.EXPECT().GetInstitutionByID(gomock.Any(), institutionID).Return(&model.Institution{
+				InstitutionID:      institutionID,
+				VerificationStatus: model.VerificationStatusUnverified,
+			}, nil),
+			mockVerificationRepo.EXPECT().GetDocuments(gomock.Any(), institutionID).Return(documents, nil),
+			mockVerificationRepo.EXPECT().UpdateVerificationStatus(gomock.Any(), institutionID,
+				model.VerificationStatusUnverified, model.VerificationStatusPending, "").Return(pending, nil),
+		)
+
+		result, err := verificationUsecase.SubmitVerification(context.Background(), institutionID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, model.VerificationStatusPending, result.VerificationStatus)
+	})
+
+	t.Run("failed - missing documents", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockVerificationRepo := mocks.NewMockIVerificationRepository(ctrl)
+		mockInstitutionRepo := mocks.NewMockIInstitutionRepository(ctrl)
+		verificationUsecase := usecase.NewVerificationUsecase(mockVerificationRepo, mockInstitutionRepo, mocks.NewMockIStorage(ctrl))
+
+		institutionID := uuid.New()
+		mockInstitutionRepo.EXPECT().GetInstitutionByID(gomock.Any(), institutionID).Return(&model.Institution{
+			InstitutionID:      institutionID,
+			VerificationStatus: model.VerificationStatusUnverified,
+		}, nil)
+		mockVerificationRepo.EXPECT().GetDocuments(gomock.Any(), institutionID).Return([]model.InstitutionDocument{
+			{InstitutionID: institutionID, Type: model.DocumentTypeTaxID},
+		}, nil)
+
+		result, err := verificationUsecase.SubmitVerification(context.Background(), institutionID)
+
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, usecase.ErrVerificationRefused)
+		assert.ErrorContains(t, err, "missing REGISTRATION_CERTIFICATE, BANK_ACCOUNT_PROOF")
+	})
+}
+
+func TestReviewVerification(t *testing.T) {
+	t.Run("success - approve verifies the institution", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockVerificationRepo := mocks.NewMockIVerificationRepository(ctrl)
+		mockInstitutionRepo := mocks.NewMockIInstitutionRepository(ctrl)
+		verificationUsecase := usecase.NewVerificationUsecase(mockVerificationRepo, mockInstitutionRepo, mocks.NewMockIStorage(ctrl))
+
+		institutionID := uuid.New()
+		verified := &model.Institution{InstitutionID: institutionID, VerificationStatus: model.VerificationStatusVerified}
+
+		gomock.InOrder(
+			mockInstitutionRepo.EXPECT().GetInstitutionByID(gomock.Any(), institutionID).Return(&model.Institution{
+				InstitutionID:      institutionID,
+				VerificationStatus: model.VerificationStatusPending,
+			}, nil),
+			mockVerificationRepo.EXPECT().UpdateVerificationStatus(gomock.Any(), institutionID,
+				model.VerificationStatusPending, model.VerificationStatusVerified, "").Return(verified, nil),
+		)
+
+		result, err := verificationUsecase.ReviewVerification(context.Background(), institutionID, model.VerificationDecisionApprove, " ")
+
+		assert.NoError(t, err)
+		assert.True(t, result.IsVerified())
+	})
+
+	t.Run("failed - reject without a reason", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		verificationUsecase := usecase.NewVerificationUsecase(mocks.NewMockIVerificationRepository(ctrl),
+			mocks.NewMockIInstitutionRepository(ctrl), mocks.NewMockIStorage(ctrl))
+
+		result, err := verificationUsecase.ReviewVerification(context.Background(), uuid.New(), model.VerificationDecisionReject, "")
+
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, usecase.ErrVerificationReasonRequired)
+	})
+
+	t.Run("failed - institution did not submit its documents", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockInstitutionRepo := mocks.NewMockIInstitutionRepository(ctrl)
+		verificationUsecase := usecase.NewVerificationUsecase(mocks.NewMockIVerificationRepository(ctrl),
+			mockInstitutionRepo, mocks.NewMockIStorage(ctrl))
+
+		institutionID := uuid.New()
+		mockInstitutionRepo.EXPECT().GetInstitutionByID(gomock.Any(), institutionID).Return(&model.Institution{
+			InstitutionID:      institutionID,
+			VerificationStatus: model.VerificationStatusUnverified,
+		}, nil)
+
+		result, err := verificationUsecase.ReviewVerification(context.Background(), institutionID, model.VerificationDecisionApprove, "")
+
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, usecase.ErrVerificationRefused)
+	})
+}
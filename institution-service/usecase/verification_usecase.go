@@ -0,0 +1,198 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"institution-service/model"
+	"institution-service/repository"
+	"institution-service/storage"
+
+	"github.com/google/uuid"
+)
+
+type IVerificationUsecase interface {
+	UploadDocument(ctx context.Context, document *model.InstitutionDocument, content []byte) (*model.InstitutionDocument, error)
+	GetDocuments(ctx context.Context, institution_id uuid.UUID) ([]model.InstitutionDocument, error)
+	GetDocumentContent(ctx context.Context, institution_id, document_id uuid.UUID) (*model.InstitutionDocument, []byte, error)
+	SubmitVerification(ctx context.Context, institution_id uuid.UUID) (*model.Institution, error)
+	GetPendingVerifications(ctx context.Context) ([]model.Institution, error)
+	ReviewVerification(ctx context.Context, institution_id uuid.UUID, decision model.VerificationDecision, reason string) (*model.Institution, error)
+}
+
+var (
+	ErrInvalidDocument             = errors.New("invalid document")
+	ErrVerificationRefused         = errors.New("verification change not allowed")
+	ErrInvalidVerificationDecision = errors.New("decision must be APPROVE or REJECT")
+	ErrVerificationReasonRequired  = errors.New("reason is required to reject")
+	ErrInstitutionNotVerified      = errors.New("institution is not verified, its posts can only be drafts")
+)
+
+type VerificationUsecase struct {
+	verificationRepository repository.IVerificationRepository
+	institutionRepository  repository.IInstitutionRepository
+	storage                storage.IStorage
+}
+
+func NewVerificationUsecase(verificationRepository repository.IVerificationRepository, institutionRepository repository.IInstitutionRepository, storage storage.IStorage) *VerificationUsecase {
+	return &VerificationUsecase{
+		verificationRepository: verificationRepository,
+		institutionRepository:  institutionRepository,
+		storage:                storage,
+	}
+}
+
+// UploadDocument stores content and records it as the institution's document
+// of its type, replacing the previous one. The content type is detected from
+// content rather than trusted from the upload.
+func (u *VerificationUsecase) UploadDocument(ctx context.Context, document *model.InstitutionDocument, content []byte) (*model.InstitutionDocument, error) {
+	var e []string
+
+	document.Filename = filepath.Base(strings.TrimSpace(document.Filename))
+	if utf8.RuneCountInString(document.Filename) > 255 {
+		e = append(e, "Filename must be at most 255 characters")
+	}
+	if !document.Type.IsValid() {
+		e = append(e, "Type must be REGISTRATION_CERTIFICATE, TAX_ID or BANK_ACCOUNT_PROOF")
+	}
+	if len(content) == 0 {
+		e = append(e, "File is required")
+	} else if len(content) > model.MaxDocumentSize {
+		e = append(e, fmt.Sprintf("File must be at most %d MB", model.MaxDocumentSize>>20))
+	}
+
+	contentType := http.DetectContentType(content)
+	extension, ok := model.DocumentExtension(contentType)
+	if len(content) > 0 && !ok {
+		e = append(e, "File must be a PDF, JPEG or PNG")
+	}
+
+	if len(e) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidDocument, strings.Join(e, ", "))
+	}
+
+	institution, err := u.institutionRepository.GetInstitutionByID(ctx, document.InstitutionID)
+	if err != nil {
+		return nil, err
+	}
+	if !institution.VerificationStatus.AcceptsDocuments() {
+		return nil, fmt.Errorf("%w: documents cannot be changed while %s", ErrVerificationRefused, institution.VerificationStatus)
+	}
+
+	document.DocumentID = uuid.New()
+	if document.Filename == "." || document.Filename == "/" {
+		document.Filename = string(document.Type) + extension
+	}
+	document.ContentType = contentType
+	document.Size = int64(len(content))
+	document.StorageKey = fmt.Sprintf("institutions/%s/%s%s", document.InstitutionID, document.DocumentID, extension)
+
+	if err := u.storage.Put(ctx, document.StorageKey, content); err != nil {
+		return nil, err
+	}
+
+	replaced, err := u.verificationRepository.SaveDocument(ctx, document)
+	if err != nil {
+		if err := u.storage.Delete(ctx, document.StorageKey); err != nil {
+			log.Printf("Failed to remove unsaved document %s: %v", document.StorageKey, err)
+		}
+		return nil, err
+	}
+
+	if replaced != nil {
+		if err := u.storage.Delete(ctx, replaced.StorageKey); err != nil {
+			log.Printf("Failed to remove replaced document %s: %v", replaced.StorageKey, err)
+		}
+	}
+
+	return document, nil
+}
+
+func (u *VerificationUsecase) GetDocuments(ctx context.Context, institution_id uuid.UUID) ([]model.InstitutionDocument, error) {
+	return u.verificationRepository.GetDocuments(ctx, institution_id)
+}
+
+func (u *VerificationUsecase) GetDocumentContent(ctx context.Context, institution_id, document_id uuid.UUID) (*model.InstitutionDocument, []byte, error) {
+	document, err := u.verificationRepository.GetDocumentByID(ctx, institution_id, document_id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	content, err := u.storage.Get(ctx, document.StorageKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return document, content, nil
+}
+
+// SubmitVerification puts the institution in the review queue once it has
+// uploaded every required document.
+func (u *VerificationUsecase) SubmitVerification(ctx context.Context, institution_id uuid.UUID) (*model.Institution, error) {
+	institution, err := u.institutionRepository.GetInstitutionByID(ctx, institution_id)
+	if err != nil {
+		return nil, err
+	}
+	if !institution.VerificationStatus.AcceptsDocuments() {
+		return nil, fmt.Errorf("%w: the institution is already %s", ErrVerificationRefused, institution.VerificationStatus)
+	}
+
+	documents, err := u.verificationRepository.GetDocuments(ctx, institution_id)
+	if err != nil {
+		return nil, err
+	}
+
+	uploaded := make(map[model.DocumentType]bool, len(documents))
+	for _, document := range documents {
+		uploaded[document.Type] = true
+	}
+
+	var missing []string
+	for _, documentType := range model.RequiredDocumentTypes {
+		if !uploaded[documentType] {
+			missing = append(missing, string(documentType))
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("%w: missing %s", ErrVerificationRefused, strings.Join(missing, ", "))
+	}
+
+	return u.verificationRepository.UpdateVerificationStatus(ctx, institution_id,
+		institution.VerificationStatus, model.VerificationStatusPending, "")
+}
+
+func (u *VerificationUsecase) GetPendingVerifications(ctx context.Context) ([]model.Institution, error) {
+	return u.verificationRepository.GetInstitutionsByVerificationStatus(ctx, model.VerificationStatusPending)
+}
+
+// ReviewVerification verifies or rejects the submitted documents of an
+// institution. A rejected institution can upload new documents and submit
+// them again.
+func (u *VerificationUsecase) ReviewVerification(ctx context.Context, institution_id uuid.UUID, decision model.VerificationDecision, reason string) (*model.Institution, error) {
+	status := decision.Status()
+	if status == "" {
+		return nil, ErrInvalidVerificationDecision
+	}
+
+	reason = strings.TrimSpace(reason)
+	if status == model.VerificationStatusRejected && reason == "" {
+		return nil, ErrVerificationReasonRequired
+	}
+
+	institution, err := u.institutionRepository.GetInstitutionByID(ctx, institution_id)
+	if err != nil {
+		return nil, err
+	}
+	if institution.VerificationStatus != model.VerificationStatusPending {
+		return nil, fmt.Errorf("%w: the institution is %s, not PENDING", ErrVerificationRefused, institution.VerificationStatus)
+	}
+
+	return u.verificationRepository.UpdateVerificationStatus(ctx, institution_id,
+		model.VerificationStatusPending, status, reason)
+}
@@ -0,0 +1,32 @@
+package utils
+
+import (
+	"os"
+	"strings"
+)
+
+// IsAdmin reports whether email is one of the comma-separated ADMIN_EMAILS,
+// the user accounts allowed to use the admin endpoints.
+func IsAdmin(email string) bool {
+	return listedIn("ADMIN_EMAILS", email)
+}
+
+// IsModerator reports whether email may review submitted posts: those in the
+// comma-separated MODERATOR_EMAILS, and admins.
+func IsModerator(email string) bool {
+	return listedIn("MODERATOR_EMAILS", email) || IsAdmin(email)
+}
+
+func listedIn(env, email string) bool {
+	if email == "" {
+		return false
+	}
+
+	for _, listed := range strings.Split(os.Getenv(env), ",") {
+		if strings.EqualFold(strings.TrimSpace(listed), email) {
+			return true
+		}
+	}
+
+	return false
+}
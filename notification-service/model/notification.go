@@ -9,4 +9,14 @@ type Notification struct {
 	Message        string `gorm:"not null"`
 	Status         string `gorm:"default:'pending'"`
 	CreatedAt      time.Time
+	// Attachments are sent with the email but not stored.
+	Attachments []Attachment `gorm:"-"`
+}
+
+// Attachment is a file sent with an email. Content is base64 encoded in the
+// queue message.
+type Attachment struct {
+	Filename    string `json:"filename"`
+	ContentType string `json:"content_type"`
+	Content     []byte `json:"content"`
 }
@@ -2,14 +2,16 @@ package service
 
 import (
 	"fmt"
+	"io"
 	"log"
+	"notification_service/model"
 	"os"
 
 	"github.com/joho/godotenv"
 	"gopkg.in/gomail.v2"
 )
 
-func SendEmail(to, subject, body string) error {
+func SendEmail(to, subject, body string, attachments ...model.Attachment) error {
 
 	goenvload := godotenv.Load()
 	if goenvload != nil {
@@ -21,6 +23,16 @@ func SendEmail(to, subject, body string) error {
 	mailer.SetHeader("To", to)
 	mailer.SetHeader("Subject", subject)
 	mailer.SetBody("text/html", body)
+	for _, attachment := range attachments {
+		content := attachment.Content
+		mailer.Attach(attachment.Filename,
+			gomail.SetHeader(map[string][]string{"Content-Type": {attachment.ContentType}}),
+			gomail.SetCopyFunc(func(w io.Writer) error {
+				_, err := w.Write(content)
+				return err
+			}),
+		)
+	}
 
 	dialer := gomail.NewDialer(os.Getenv("EMAIL_HOST"), 587, os.Getenv("EMAIL_USERNAME"), os.Getenv("EMAIL_PASSWORD"))
 
@@ -38,7 +38,7 @@ func (u *notificationUsecase) SendNotification(notification model.Notification)
 		return err
 	}
 
-	err = service.SendEmail(notification.Email, notification.Subject, notification.Message)
+	err = service.SendEmail(notification.Email, notification.Subject, notification.Message, notification.Attachments...)
 	if err != nil {
 		u.logger.WithFields(logrus.Fields{
 			"email": notification.Email,
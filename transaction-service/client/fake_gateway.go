@@ -0,0 +1,196 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type FakeOutcome string
+
+const (
+	FakeOutcomePending FakeOutcome = "PENDING"
+	FakeOutcomePaid    FakeOutcome = "PAID"
+	FakeOutcomeExpired FakeOutcome = "EXPIRED"
+	FakeOutcomeError   FakeOutcome = "ERROR"
+)
+
+var ErrFakeGateway = errors.New("fake gateway error")
+
+// FakeGateway is an in-process PaymentGateway. Each created invoice is
+// assigned an outcome, taken from the Script queue or else the default, and
+// GetInvoice reports that outcome as the invoice status. FakeOutcomeError
+// makes CreateInvoice itself fail.
+type FakeGateway struct {
+	BaseURL string
+
+	mu             sync.Mutex
+	defaultOutcome FakeOutcome
+	script         []FakeOutcome
+	invoices       map[string]*InvoiceResponse
+	outcomes       map[string]FakeOutcome
+	refunded       map[string]float64
+}
+
+func NewFakeGateway() *FakeGateway {
+	return &FakeGateway{
+		BaseURL:        "https://checkout.fake-gateway.local",
+		defaultOutcome: FakeOutcomePaid,
+		invoices:       map[string]*InvoiceResponse{},
+		outcomes:       map[string]FakeOutcome{},
+		refunded:       map[string]float64{},
+	}
+}
+
+func (g *FakeGateway) SetDefaultOutcome(outcome FakeOutcome) error {
+	switch outcome {
+	case FakeOutcomePending, FakeOutcomePaid, FakeOutcomeExpired, FakeOutcomeError:
+	default:
+		return fmt.Errorf("unknown fake gateway outcome %q", outcome)
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.defaultOutcome = outcome
+
+	return nil
+}
+
+// Script queues outcomes for the next invoices, in creation order.
+func (g *FakeGateway) Script(outcomes ...FakeOutcome) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.script = append(g.script, outcomes...)
+}
+
+// SetInvoiceStatus overrides what GetInvoice reports for an existing invoice.
+func (g *FakeGateway) SetInvoiceStatus(invoiceID string, outcome FakeOutcome) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if _, ok := g.invoices[invoiceID]; !ok {
+		return fmt.Errorf("%w: invoice %s not found", ErrFakeGateway, invoiceID)
+	}
+	g.outcomes[invoiceID] = outcome
+
+	return nil
+}
+
+func (g *FakeGateway) CreateInvoice(req CreateInvoiceRequest) (*InvoiceResponse, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	outcome := g.defaultOutcome
+	if len(g.script) > 0 {
+		outcome = g.script[0]
+		g.script = g.script[1:]
+	}
+
+	if outcome == FakeOutcomeError {
+		return nil, fmt.Errorf("%w: create invoice for %s", ErrFakeGateway, req.ExternalID)
+	}
+
+	duration := time.Duration(req.InvoiceDuration) * time.Second
+	if duration == 0 {
+		duration = 24 * time.Hour
+	}
+
+	id := uuid.New().String()
+	invoice := &InvoiceResponse{
+		ID:          id,
+		ExternalID:  req.ExternalID,
+		Status:      string(FakeOutcomePending),
+		Amount:      req.Amount,
+		Currency:    req.Currency,
+		PayerEmail:  req.PayerEmail,
+		Description: req.Description,
+		InvoiceURL:  fmt.Sprintf("%s/invoices/%s", g.BaseURL, id),
+		ExpiryDate:  time.Now().Add(duration),
+	}
+
+	g.invoices[id] = invoice
+	g.outcomes[id] = outcome
+
+	response := *invoice
+	return &response, nil
+}
+
+func (g *FakeGateway) GetInvoice(invoiceID string) (*InvoiceResponse, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	invoice, ok := g.invoices[invoiceID]
+	if !ok {
+		return nil, fmt.Errorf("%w: invoice %s not found", ErrFakeGateway, invoiceID)
+	}
+
+	switch g.outcomes[invoiceID] {
+	case FakeOutcomeError:
+		return nil, fmt.Errorf("%w: get invoice %s", ErrFakeGateway, invoiceID)
+	case FakeOutcomePaid:
+		if invoice.Status != string(FakeOutcomePaid) {
+			invoice.Status = string(FakeOutcomePaid)
+			invoice.PaymentMethod = "FAKE"
+			invoice.PaidAt = time.Now()
+		}
+	case FakeOutcomeExpired:
+		invoice.Status = string(FakeOutcomeExpired)
+	}
+
+	response := *invoice
+	return &response, nil
+}
+
+func (g *FakeGateway) ExpireInvoice(invoiceID string) (*InvoiceResponse, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	invoice, ok := g.invoices[invoiceID]
+	if !ok {
+		return nil, fmt.Errorf("%w: invoice %s not found", ErrFakeGateway, invoiceID)
+	}
+	if invoice.Status == string(FakeOutcomePaid) {
+		return nil, fmt.Errorf("%w: invoice %s is already paid", ErrFakeGateway, invoiceID)
+	}
+
+	invoice.Status = string(FakeOutcomeExpired)
+	g.outcomes[invoiceID] = FakeOutcomeExpired
+
+	response := *invoice
+	return &response, nil
+}
+
+func (g *FakeGateway) Refund(req RefundRequest) (*RefundResponse, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	invoice, ok := g.invoices[req.InvoiceID]
+	if !ok {
+		return nil, fmt.Errorf("%w: invoice %s not found", ErrFakeGateway, req.InvoiceID)
+	}
+	if g.outcomes[req.InvoiceID] == FakeOutcomeError {
+		return nil, fmt.Errorf("%w: refund invoice %s", ErrFakeGateway, req.InvoiceID)
+	}
+	if invoice.Status != string(FakeOutcomePaid) {
+		return nil, fmt.Errorf("%w: invoice %s is not paid", ErrFakeGateway, req.InvoiceID)
+	}
+	if req.Amount <= 0 || g.refunded[req.InvoiceID]+req.Amount > invoice.Amount {
+		return nil, fmt.Errorf("%w: refund amount exceeds paid amount", ErrFakeGateway)
+	}
+
+	g.refunded[req.InvoiceID] += req.Amount
+
+	return &RefundResponse{
+		ID:          uuid.New().String(),
+		InvoiceID:   req.InvoiceID,
+		ReferenceID: req.ReferenceID,
+		Amount:      req.Amount,
+		Currency:    req.Currency,
+		Status:      "SUCCEEDED",
+		Reason:      req.Reason,
+		Created:     time.Now(),
+	}, nil
+}
@@ -0,0 +1,36 @@
+package client
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// PaymentGateway is what the transaction service needs from a payment
+// provider. XenditClient is the production implementation.
+type PaymentGateway interface {
+	CreateInvoice(req CreateInvoiceRequest) (*InvoiceResponse, error)
+	GetInvoice(invoiceID string) (*InvoiceResponse, error)
+	ExpireInvoice(invoiceID string) (*InvoiceResponse, error)
+	Refund(req RefundRequest) (*RefundResponse, error)
+}
+
+// NewPaymentGateway picks the gateway named by PAYMENT_GATEWAY, defaulting to
+// Xendit. PAYMENT_GATEWAY=fake runs without Xendit credentials, settling every
+// invoice with the outcome in FAKE_GATEWAY_OUTCOME (PAID when unset).
+func NewPaymentGateway() (PaymentGateway, error) {
+	switch strings.ToLower(os.Getenv("PAYMENT_GATEWAY")) {
+	case "", "xendit":
+		return NewXenditClient(), nil
+	case "fake":
+		gateway := NewFakeGateway()
+		if outcome := os.Getenv("FAKE_GATEWAY_OUTCOME"); outcome != "" {
+			if err := gateway.SetDefaultOutcome(FakeOutcome(strings.ToUpper(outcome))); err != nil {
+				return nil, err
+			}
+		}
+		return gateway, nil
+	default:
+		return nil, fmt.Errorf("unknown payment gateway %q", os.Getenv("PAYMENT_GATEWAY"))
+	}
+}
@@ -0,0 +1,182 @@
+package tests
+
+import (
+	"testing"
+
+	"transaction-service/client"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newInvoiceRequest(externalID string) client.CreateInvoiceRequest {
+	return client.CreateInvoiceRequest{
+		ExternalID:      externalID,
+		Amount:          50000,
+		PayerEmail:      "donor@email.com",
+		Description:     "Fund contribution",
+		InvoiceDuration: 3600,
+	}
+}
+
+func TestFakeGateway(t *testing.T) {
+	t.Run("success - invoice is pending until checked, then paid", func(t *testing.T) {
+		gateway := client.NewFakeGateway()
+
+		invoice, err := gateway.CreateInvoice(newInvoiceRequest("tx-1"))
+		assert.NoError(t, err)
+		assert.Equal(t, "PENDING", invoice.Status)
+		assert.Equal(t, "tx-1", invoice.ExternalID)
+		assert.Contains(t, invoice.InvoiceURL, invoice.ID)
+
+		checked, err := gateway.GetInvoice(invoice.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, "PAID", checked.Status)
+		assert.False(t, checked.PaidAt.IsZero())
+	})
+
+	t.Run("success - scripted outcomes apply in creation order", func(t *testing.T) {
+		gateway := client.NewFakeGateway()
+		gateway.Script(client.FakeOutcomeExpired, client.FakeOutcomeError, client.FakeOutcomePending)
+
+		expired, err := gateway.CreateInvoice(newInvoiceRequest("tx-1"))
+		assert.NoError(t, err)
+
+		_, err = gateway.CreateInvoice(newInvoiceRequest("tx-2"))
+		assert.ErrorIs(t, err, client.ErrFakeGateway)
+
+		pending, err := gateway.CreateInvoice(newInvoiceRequest("tx-3"))
+		assert.NoError(t, err)
+
+		paid, err := gateway.CreateInvoice(newInvoiceRequest("tx-4"))
+		assert.NoError(t, err)
+
+		for id, status := range map[string]string{expired.ID: "EXPIRED", pending.ID: "PENDING", paid.ID: "PAID"} {
+			invoice, err := gateway.GetInvoice(id)
+			assert.NoError(t, err)
+			assert.Equal(t, status, invoice.Status)
+		}
+	})
+
+	t.Run("success - status can be changed after creation", func(t *testing.T) {
+		gateway := client.NewFakeGateway()
+		assert.NoError(t, gateway.SetDefaultOutcome(client.FakeOutcomePending))
+
+		invoice, err := gateway.CreateInvoice(newInvoiceRequest("tx-1"))
+		assert.NoError(t, err)
+
+		assert.NoError(t, gateway.SetInvoiceStatus(invoice.ID, client.FakeOutcomeError))
+		_, err = gateway.GetInvoice(invoice.ID)
+		assert.ErrorIs(t, err, client.ErrFakeGateway)
+
+		assert.NoError(t, gateway.SetInvoiceStatus(invoice.ID, client.FakeOutcomePaid))
+		checked, err := gateway.GetInvoice(invoice.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, "PAID", checked.Status)
+	})
+
+	t.Run("success - expire pending invoice", func(t *testing.T) {
+		gateway := client.NewFakeGateway()
+		gateway.Script(client.FakeOutcomePending)
+
+		invoice, err := gateway.CreateInvoice(newInvoiceRequest("tx-1"))
+		assert.NoError(t, err)
+
+		expired, err := gateway.ExpireInvoice(invoice.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, "EXPIRED", expired.Status)
+
+		checked, err := gateway.GetInvoice(invoice.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, "EXPIRED", checked.Status)
+	})
+
+	t.Run("success - partial refunds up to the paid amount", func(t *testing.T) {
+		gateway := client.NewFakeGateway()
+
+		invoice, err := gateway.CreateInvoice(newInvoiceRequest("tx-1"))
+		assert.NoError(t, err)
+		_, err = gateway.GetInvoice(invoice.ID)
+		assert.NoError(t, err)
+
+		refund, err := gateway.Refund(client.RefundRequest{InvoiceID: invoice.ID, ReferenceID: "r-1", Amount: 30000})
+		assert.NoError(t, err)
+		assert.Equal(t, "SUCCEEDED", refund.Status)
+
+		_, err = gateway.Refund(client.RefundRequest{InvoiceID: invoice.ID, ReferenceID: "r-2", Amount: 30000})
+		assert.ErrorIs(t, err, client.ErrFakeGateway)
+
+		_, err = gateway.Refund(client.RefundRequest{InvoiceID: invoice.ID, ReferenceID: "r-3", Amount: 20000})
+		assert.NoError(t, err)
+	})
+
+	t.Run("failed - expire paid invoice", func(t *testing.T) {
+		gateway := client.NewFakeGateway()
+
+		invoice, err := gateway.CreateInvoice(newInvoiceRequest("tx-1"))
+		assert.NoError(t, err)
+		_, err = gateway.GetInvoice(invoice.ID)
+		assert.NoError(t, err)
+
+		_, err = gateway.ExpireInvoice(invoice.ID)
+		assert.ErrorIs(t, err, client.ErrFakeGateway)
+	})
+
+	t.Run("failed - refund unpaid invoice", func(t *testing.T) {
+		gateway := client.NewFakeGateway()
+		gateway.Script(client.FakeOutcomePending)
+
+		invoice, err := gateway.CreateInvoice(newInvoiceRequest("tx-1"))
+		assert.NoError(t, err)
+
+		_, err = gateway.Refund(client.RefundRequest{InvoiceID: invoice.ID, Amount: 1000})
+		assert.ErrorIs(t, err, client.ErrFakeGateway)
+	})
+
+	t.Run("failed - unknown invoice", func(t *testing.T) {
+		gateway := client.NewFakeGateway()
+
+		_, err := gateway.GetInvoice("missing")
+		assert.ErrorIs(t, err, client.ErrFakeGateway)
+	})
+
+	t.Run("failed - unknown default outcome", func(t *testing.T) {
+		gateway := client.NewFakeGateway()
+
+		err := gateway.SetDefaultOutcome("REFUNDED")
+		assert.Error(t, err)
+	})
+}
+
+func TestNewPaymentGateway(t *testing.T) {
+	t.Run("success - defaults to xendit", func(t *testing.T) {
+		t.Setenv("PAYMENT_GATEWAY", "")
+
+		gateway, err := client.NewPaymentGateway()
+
+		assert.NoError(t, err)
+		assert.IsType(t, &client.XenditClient{}, gateway)
+	})
+
+	t.Run("success - fake with scripted default outcome", func(t *testing.T) {
+		t.Setenv("PAYMENT_GATEWAY", "fake")
+		t.Setenv("FAKE_GATEWAY_OUTCOME", "expired")
+
+		gateway, err := client.NewPaymentGateway()
+		assert.NoError(t, err)
+		assert.IsType(t, &client.FakeGateway{}, gateway)
+
+		invoice, err := gateway.CreateInvoice(newInvoiceRequest("tx-1"))
+		assert.NoError(t, err)
+		checked, err := gateway.GetInvoice(invoice.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, "EXPIRED", checked.Status)
+	})
+
+	t.Run("failed - unknown gateway", func(t *testing.T) {
+		t.Setenv("PAYMENT_GATEWAY", "paypal")
+
+		_, err := client.NewPaymentGateway()
+
+		assert.Error(t, err)
+	})
+}
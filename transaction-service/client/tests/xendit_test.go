@@ -0,0 +1,73 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"transaction-service/client"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestXenditClient(t *testing.T, handler http.HandlerFunc) *client.XenditClient {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	return &client.XenditClient{
+		APIKey:     "xnd_test",
+		BaseURL:    server.URL,
+		HTTPClient: server.Client(),
+	}
+}
+
+func TestXenditClient(t *testing.T) {
+	t.Run("success - expire invoice", func(t *testing.T) {
+		xenditClient := newTestXenditClient(t, func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodPost, r.Method)
+			assert.Equal(t, "/invoices/inv-1/expire!", r.URL.Path)
+			username, _, _ := r.BasicAuth()
+			assert.Equal(t, "xnd_test", username)
+
+			json.NewEncoder(w).Encode(client.InvoiceResponse{ID: "inv-1", Status: "EXPIRED"})
+		})
+
+		invoice, err := xenditClient.ExpireInvoice("inv-1")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "EXPIRED", invoice.Status)
+	})
+
+	t.Run("success - refund", func(t *testing.T) {
+		xenditClient := newTestXenditClient(t, func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodPost, r.Method)
+			assert.Equal(t, "/refunds", r.URL.Path)
+
+			var req client.RefundRequest
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			assert.Equal(t, "inv-1", req.InvoiceID)
+			assert.Equal(t, float64(20000), req.Amount)
+
+			json.NewEncoder(w).Encode(client.RefundResponse{ID: "rfd-1", InvoiceID: req.InvoiceID, Amount: req.Amount, Status: "SUCCEEDED"})
+		})
+
+		refund, err := xenditClient.Refund(client.RefundRequest{InvoiceID: "inv-1", ReferenceID: "ref-1", Amount: 20000, Reason: "REQUESTED_BY_CUSTOMER"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "rfd-1", refund.ID)
+		assert.Equal(t, "SUCCEEDED", refund.Status)
+	})
+
+	t.Run("failed - api error", func(t *testing.T) {
+		xenditClient := newTestXenditClient(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(map[string]string{"error_code": "INVOICE_NOT_FOUND_ERROR"})
+		})
+
+		invoice, err := xenditClient.GetInvoice("missing")
+
+		assert.ErrorContains(t, err, "INVOICE_NOT_FOUND_ERROR")
+		assert.Nil(t, invoice)
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"os"
 	"time"
@@ -18,6 +19,7 @@ type XenditClient struct {
 type CreateInvoiceRequest struct {
 	ExternalID         string  `json:"external_id"`
 	Amount             float64 `json:"amount"`
+	Currency           string  `json:"currency,omitempty"`
 	PayerEmail         string  `json:"payer_email,omitempty"`
 	Description        string  `json:"description"`
 	InvoiceDuration    int     `json:"invoice_duration,omitempty"`
@@ -34,16 +36,38 @@ type InvoiceResponse struct {
 	Status                  string         `json:"status"`
 	MerchantName            string         `json:"merchant_name"`
 	Amount                  float64        `json:"amount"`
+	Currency                string         `json:"currency,omitempty"`
 	PayerEmail              string         `json:"payer_email"`
 	Description             string         `json:"description"`
 	InvoiceURL              string         `json:"invoice_url"`
 	ExpiryDate              time.Time      `json:"expiry_date"`
+	PaidAt                  time.Time      `json:"paid_at"`
+	PaymentMethod           string         `json:"payment_method"`
 	AvailableBanks          []Bank         `json:"available_banks"`
 	AvailableRetailOutlets  []RetailOutlet `json:"available_retail_outlets"`
 	AvailableEWallets       []EWallet      `json:"available_ewallets"`
 	ShouldExcludeCreditCard bool           `json:"should_exclude_credit_card"`
 }
 
+type RefundRequest struct {
+	InvoiceID   string  `json:"invoice_id"`
+	ReferenceID string  `json:"reference_id"`
+	Amount      float64 `json:"amount"`
+	Currency    string  `json:"currency,omitempty"`
+	Reason      string  `json:"reason"`
+}
+
+type RefundResponse struct {
+	ID          string    `json:"id"`
+	InvoiceID   string    `json:"invoice_id"`
+	ReferenceID string    `json:"reference_id"`
+	Amount      float64   `json:"amount"`
+	Currency    string    `json:"currency"`
+	Status      string    `json:"status"`
+	Reason      string    `json:"reason"`
+	Created     time.Time `json:"created"`
+}
+
 type Bank struct {
 	BankCode          string  `json:"bank_code"`
 	CollectionType    string  `json:"collection_type"`
@@ -77,45 +101,54 @@ func NewXenditClient() *XenditClient {
 }
 
 func (c *XenditClient) CreateInvoice(req CreateInvoiceRequest) (*InvoiceResponse, error) {
-	payload, err := json.Marshal(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %v", err)
-	}
-
-	request, err := http.NewRequest("POST", c.BaseURL+"/invoices", bytes.NewBuffer(payload))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %v", err)
+	var invoiceResponse InvoiceResponse
+	if err := c.do("POST", "/invoices", req, &invoiceResponse); err != nil {
+		return nil, err
 	}
 
-	request.SetBasicAuth(c.APIKey, "")
-	request.Header.Set("Content-Type", "application/json")
+	return &invoiceResponse, nil
+}
 
-	response, err := c.HTTPClient.Do(request)
-	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %v", err)
+func (c *XenditClient) GetInvoice(invoiceID string) (*InvoiceResponse, error) {
+	var invoiceResponse InvoiceResponse
+	if err := c.do("GET", fmt.Sprintf("/invoices/%s", invoiceID), nil, &invoiceResponse); err != nil {
+		return nil, err
 	}
-	defer response.Body.Close()
 
-	if response.StatusCode != http.StatusOK {
-		var errorResponse map[string]interface{}
-		if err := json.NewDecoder(response.Body).Decode(&errorResponse); err != nil {
-			return nil, fmt.Errorf("failed to decode error response: %v", err)
-		}
-		return nil, fmt.Errorf("xendit API error: %v", errorResponse)
-	}
+	return &invoiceResponse, nil
+}
 
+func (c *XenditClient) ExpireInvoice(invoiceID string) (*InvoiceResponse, error) {
 	var invoiceResponse InvoiceResponse
-	if err := json.NewDecoder(response.Body).Decode(&invoiceResponse); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %v", err)
+	if err := c.do("POST", fmt.Sprintf("/invoices/%s/expire!", invoiceID), nil, &invoiceResponse); err != nil {
+		return nil, err
 	}
 
 	return &invoiceResponse, nil
 }
 
-func (c *XenditClient) GetInvoice(invoiceID string) (*InvoiceResponse, error) {
-	request, err := http.NewRequest("GET", fmt.Sprintf("%s/invoices/%s", c.BaseURL, invoiceID), nil)
+func (c *XenditClient) Refund(req RefundRequest) (*RefundResponse, error) {
+	var refundResponse RefundResponse
+	if err := c.do("POST", "/refunds", req, &refundResponse); err != nil {
+		return nil, err
+	}
+
+	return &refundResponse, nil
+}
+
+func (c *XenditClient) do(method, path string, body interface{}, out interface{}) error {
+	var payload io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %v", err)
+		}
+		payload = bytes.NewBuffer(b)
+	}
+
+	request, err := http.NewRequest(method, c.BaseURL+path, payload)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %v", err)
+		return fmt.Errorf("failed to create request: %v", err)
 	}
 
 	request.SetBasicAuth(c.APIKey, "")
@@ -123,22 +156,21 @@ func (c *XenditClient) GetInvoice(invoiceID string) (*InvoiceResponse, error) {
 
 	response, err := c.HTTPClient.Do(request)
 	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %v", err)
+		return fmt.Errorf("failed to send request: %v", err)
 	}
 	defer response.Body.Close()
 
-	if response.StatusCode != http.StatusOK {
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
 		var errorResponse map[string]interface{}
 		if err := json.NewDecoder(response.Body).Decode(&errorResponse); err != nil {
-			return nil, fmt.Errorf("failed to decode error response: %v", err)
+			return fmt.Errorf("failed to decode error response: %v", err)
 		}
-		return nil, fmt.Errorf("xendit API error: %v", errorResponse)
+		return fmt.Errorf("xendit API error: %v", errorResponse)
 	}
 
-	var invoiceResponse InvoiceResponse
-	if err := json.NewDecoder(response.Body).Decode(&invoiceResponse); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %v", err)
+	if err := json.NewDecoder(response.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %v", err)
 	}
 
-	return &invoiceResponse, nil
+	return nil
 }
@@ -0,0 +1,346 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config holds the per-environment settings of transaction-service. Values
+// are read from the YAML file named by CONFIG_FILE, if any, and environment
+// variables override the file.
+type Config struct {
+	// PublicBaseURL is where Xendit and donors' browsers reach this service,
+	// e.g. https://transaction.staging.edu-connect.id.
+	PublicBaseURL string `yaml:"public_base_url"`
+	// FrontendSuccessURL and FrontendFailureURL are the frontend pages donors
+	// land on after paying or abandoning an invoice.
+	FrontendSuccessURL string        `yaml:"frontend_success_url"`
+	FrontendFailureURL string        `yaml:"frontend_failure_url"`
+	WebhookPath        string        `yaml:"webhook_path"`
+	InvoiceDuration    time.Duration `yaml:"invoice_duration"`
+	// CallbackToken is the verification token Xendit sends in the
+	// x-callback-token header of invoice callbacks.
+	CallbackToken string `yaml:"callback_token"`
+	// SwaggerHost defaults to the host of PublicBaseURL.
+	SwaggerHost string `yaml:"swagger_host"`
+	// MinDonationAmount and MaxDonationAmount bound every donation, in IDR.
+	MinDonationAmount float64 `yaml:"min_donation_amount"`
+	MaxDonationAmount float64 `yaml:"max_donation_amount"`
+	// AdminEmails are the donor accounts allowed to use the admin endpoints,
+	// such as setting FX rates.
+	AdminEmails []string `yaml:"admin_emails"`
+
+	// Settings of the background workers.
+	Reconciler            ReconcilerConfig            `yaml:"reconciler"`
+	OutboxRelay           WorkerConfig                `yaml:"outbox_relay"`
+	SubscriptionScheduler SubscriptionSchedulerConfig `yaml:"subscription_scheduler"`
+	ReceiptSender         WorkerConfig                `yaml:"receipt_sender"`
+	TaxStatementSender    WorkerConfig                `yaml:"tax_statement_sender"`
+}
+
+// WorkerConfig sets how often a background worker runs and how many records it
+// handles per pass.
+type WorkerConfig struct {
+	Interval  time.Duration `yaml:"interval"`
+	BatchSize int           `yaml:"batch_size"`
+}
+
+// ReconcilerConfig also sets how long a transaction stays PENDING before the
+// reconciler asks the payment gateway about it.
+type ReconcilerConfig struct {
+	Interval   time.Duration `yaml:"interval"`
+	BatchSize  int           `yaml:"batch_size"`
+	StaleAfter time.Duration `yaml:"stale_after"`
+}
+
+// SubscriptionSchedulerConfig also sets how long before each recurring charge
+// the donor is reminded.
+type SubscriptionSchedulerConfig struct {
+	Interval     time.Duration `yaml:"interval"`
+	BatchSize    int           `yaml:"batch_size"`
+	RemindBefore time.Duration `yaml:"remind_before"`
+}
+
+const SuccessRedirectPath = "/payment/success"
+
+func Default() Config {
+	return Config{
+		WebhookPath:       "/payment/callback",
+		InvoiceDuration:   24 * time.Hour,
+		MinDonationAmount: 10000,
+		MaxDonationAmount: 100000000,
+		Reconciler: ReconcilerConfig{
+			Interval:   5 * time.Minute,
+			BatchSize:  50,
+			StaleAfter: 15 * time.Minute,
+		},
+		OutboxRelay: WorkerConfig{Interval: 5 * time.Second, BatchSize: 100},
+		SubscriptionScheduler: SubscriptionSchedulerConfig{
+			Interval:     time.Hour,
+			BatchSize:    50,
+			RemindBefore: 72 * time.Hour,
+		},
+		ReceiptSender:      WorkerConfig{Interval: time.Minute, BatchSize: 50},
+		TaxStatementSender: WorkerConfig{Interval: time.Hour, BatchSize: 50},
+	}
+}
+
+// Load reads CONFIG_FILE and then the PUBLIC_BASE_URL, FRONTEND_SUCCESS_URL,
+// FRONTEND_FAILURE_URL, WEBHOOK_PATH, INVOICE_DURATION, XENDIT_CALLBACK_TOKEN,
+// SWAGGER_HOST, MIN_DONATION_AMOUNT, MAX_DONATION_AMOUNT and ADMIN_EMAILS
+// (comma-separated) environment variables, then the worker settings RECONCILER_INTERVAL,
+// RECONCILER_BATCH_SIZE, RECONCILER_STALE_AFTER, OUTBOX_RELAY_INTERVAL,
+// OUTBOX_RELAY_BATCH_SIZE, SUBSCRIPTION_SCHEDULER_INTERVAL,
+// SUBSCRIPTION_BATCH_SIZE, SUBSCRIPTION_REMINDER_BEFORE,
+// RECEIPT_SENDER_INTERVAL, RECEIPT_SENDER_BATCH_SIZE,
+// TAX_STATEMENT_SENDER_INTERVAL and TAX_STATEMENT_SENDER_BATCH_SIZE, and
+// validates the result.
+func Load() (*Config, error) {
+	config := Default()
+
+	if path := os.Getenv("CONFIG_FILE"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read config file: %v", err)
+		}
+		if err := yaml.Unmarshal(data, &config); err != nil {
+			return nil, fmt.Errorf("failed to parse config file %s: %v", path, err)
+		}
+	}
+
+	var e []string
+
+	overrideString(&config.PublicBaseURL, "PUBLIC_BASE_URL")
+	overrideString(&config.FrontendSuccessURL, "FRONTEND_SUCCESS_URL")
+	overrideString(&config.FrontendFailureURL, "FRONTEND_FAILURE_URL")
+	overrideString(&config.WebhookPath, "WEBHOOK_PATH")
+	overrideString(&config.CallbackToken, "XENDIT_CALLBACK_TOKEN")
+	overrideString(&config.SwaggerHost, "SWAGGER_HOST")
+	if duration := os.Getenv("INVOICE_DURATION"); duration != "" {
+		d, err := time.ParseDuration(duration)
+		if err != nil {
+			e = append(e, "INVOICE_DURATION must be a duration such as 24h")
+		}
+		config.InvoiceDuration = d
+	}
+	if amount := os.Getenv("MIN_DONATION_AMOUNT"); amount != "" {
+		n, err := strconv.ParseFloat(amount, 64)
+		if err != nil {
+			e = append(e, "MIN_DONATION_AMOUNT must be a number")
+		}
+		config.MinDonationAmount = n
+	}
+	if amount := os.Getenv("MAX_DONATION_AMOUNT"); amount != "" {
+		n, err := strconv.ParseFloat(amount, 64)
+		if err != nil {
+			e = append(e, "MAX_DONATION_AMOUNT must be a number")
+		}
+		config.MaxDonationAmount = n
+	}
+
+	if emails := os.Getenv("ADMIN_EMAILS"); emails != "" {
+		config.AdminEmails = nil
+		for _, email := range strings.Split(emails, ",") {
+			if email = strings.TrimSpace(email); email != "" {
+				config.AdminEmails = append(config.AdminEmails, email)
+			}
+		}
+	}
+
+	overrideDuration(&config.Reconciler.Interval, "RECONCILER_INTERVAL", &e)
+	overrideInt(&config.Reconciler.BatchSize, "RECONCILER_BATCH_SIZE", &e)
+	overrideDuration(&config.Reconciler.StaleAfter, "RECONCILER_STALE_AFTER", &e)
+	overrideDuration(&config.OutboxRelay.Interval, "OUTBOX_RELAY_INTERVAL", &e)
+	overrideInt(&config.OutboxRelay.BatchSize, "OUTBOX_RELAY_BATCH_SIZE", &e)
+	overrideDuration(&config.SubscriptionScheduler.Interval, "SUBSCRIPTION_SCHEDULER_INTERVAL", &e)
+	overrideInt(&config.SubscriptionScheduler.BatchSize, "SUBSCRIPTION_BATCH_SIZE", &e)
+	overrideDuration(&config.SubscriptionScheduler.RemindBefore, "SUBSCRIPTION_REMINDER_BEFORE", &e)
+	overrideDuration(&config.ReceiptSender.Interval, "RECEIPT_SENDER_INTERVAL", &e)
+	overrideInt(&config.ReceiptSender.BatchSize, "RECEIPT_SENDER_BATCH_SIZE", &e)
+	overrideDuration(&config.TaxStatementSender.Interval, "TAX_STATEMENT_SENDER_INTERVAL", &e)
+	overrideInt(&config.TaxStatementSender.BatchSize, "TAX_STATEMENT_SENDER_BATCH_SIZE", &e)
+
+	if len(e) > 0 {
+		return nil, errors.New(strings.Join(e, ", "))
+	}
+
+	config.PublicBaseURL = strings.TrimRight(config.PublicBaseURL, "/")
+	if config.SwaggerHost == "" {
+		if base, err := url.Parse(config.PublicBaseURL); err == nil {
+			config.SwaggerHost = base.Host
+		}
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &config, nil
+}
+
+func (c *Config) Validate() error {
+	var e []string
+
+	if err := validateAbsoluteURL(c.PublicBaseURL); err != nil {
+		e = append(e, fmt.Sprintf("Public base URL %v", err))
+	}
+	if err := validateAbsoluteURL(c.FrontendSuccessURL); err != nil {
+		e = append(e, fmt.Sprintf("Frontend success URL %v", err))
+	}
+	if err := validateAbsoluteURL(c.FrontendFailureURL); err != nil {
+		e = append(e, fmt.Sprintf("Frontend failure URL %v", err))
+	}
+	if !strings.HasPrefix(c.WebhookPath, "/") {
+		e = append(e, "Webhook path must start with /")
+	}
+	if c.WebhookPath == SuccessRedirectPath {
+		e = append(e, "Webhook path must not be "+SuccessRedirectPath)
+	}
+	if c.CallbackToken == "" {
+		e = append(e, "Xendit callback token is required")
+	}
+	if c.InvoiceDuration < time.Second {
+		e = append(e, "Invoice duration must be at least 1s")
+	}
+	if c.SwaggerHost == "" {
+		e = append(e, "Swagger host is required")
+	}
+	if c.MinDonationAmount <= 0 || c.MinDonationAmount != math.Trunc(c.MinDonationAmount) {
+		e = append(e, "Minimum donation amount must be a positive whole number of IDR")
+	}
+	if c.MaxDonationAmount < c.MinDonationAmount || c.MaxDonationAmount != math.Trunc(c.MaxDonationAmount) {
+		e = append(e, "Maximum donation amount must be a whole number of IDR no less than the minimum")
+	}
+
+	workers := []struct {
+		name      string
+		interval  time.Duration
+		batchSize int
+	}{
+		{"Reconciler", c.Reconciler.Interval, c.Reconciler.BatchSize},
+		{"Outbox relay", c.OutboxRelay.Interval, c.OutboxRelay.BatchSize},
+		{"Subscription scheduler", c.SubscriptionScheduler.Interval, c.SubscriptionScheduler.BatchSize},
+		{"Receipt sender", c.ReceiptSender.Interval, c.ReceiptSender.BatchSize},
+		{"Tax statement sender", c.TaxStatementSender.Interval, c.TaxStatementSender.BatchSize},
+	}
+	for _, worker := range workers {
+		if worker.interval <= 0 {
+			e = append(e, worker.name+" interval must be positive")
+		}
+		if worker.batchSize <= 0 {
+			e = append(e, worker.name+" batch size must be positive")
+		}
+	}
+	if c.Reconciler.StaleAfter < 0 {
+		e = append(e, "Reconciler staleness threshold must not be negative")
+	}
+	if c.SubscriptionScheduler.RemindBefore < 0 {
+		e = append(e, "Subscription reminder lead time must not be negative")
+	}
+
+	if len(e) > 0 {
+		return errors.New(strings.Join(e, ", "))
+	}
+
+	return nil
+}
+
+// CallbackURL is the webhook URL given to the payment gateway.
+func (c *Config) CallbackURL() string {
+	return c.PublicBaseURL + c.WebhookPath
+}
+
+// SuccessRedirectURL sends the donor back through this service, which settles
+// the transaction if the gateway reports it paid before forwarding to the
+// frontend success page.
+func (c *Config) SuccessRedirectURL(transactionID string) string {
+	return c.PublicBaseURL + SuccessRedirectPath + "?external_id=" + url.QueryEscape(transactionID)
+}
+
+func (c *Config) FrontendSuccessRedirect(transactionID string) string {
+	return withTransactionID(c.FrontendSuccessURL, transactionID)
+}
+
+func (c *Config) FailureRedirectURL(transactionID string) string {
+	return withTransactionID(c.FrontendFailureURL, transactionID)
+}
+
+func (c *Config) IsAdmin(email string) bool {
+	for _, admin := range c.AdminEmails {
+		if email != "" && strings.EqualFold(admin, email) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// SwaggerScheme is the scheme of PublicBaseURL.
+func (c *Config) SwaggerScheme() string {
+	base, err := url.Parse(c.PublicBaseURL)
+	if err != nil || base.Scheme == "" {
+		return "https"
+	}
+
+	return base.Scheme
+}
+
+func overrideString(value *string, key string) {
+	if env := os.Getenv(key); env != "" {
+		*value = env
+	}
+}
+
+func overrideDuration(value *time.Duration, key string, e *[]string) {
+	if env := os.Getenv(key); env != "" {
+		d, err := time.ParseDuration(env)
+		if err != nil {
+			*e = append(*e, key+" must be a duration such as 5m")
+		}
+		*value = d
+	}
+}
+
+func overrideInt(value *int, key string, e *[]string) {
+	if env := os.Getenv(key); env != "" {
+		n, err := strconv.Atoi(env)
+		if err != nil {
+			*e = append(*e, key+" must be an integer")
+		}
+		*value = n
+	}
+}
+
+func validateAbsoluteURL(value string) error {
+	if value == "" {
+		return errors.New("is required")
+	}
+
+	parsed, err := url.Parse(value)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return errors.New("must be an absolute http or https URL")
+	}
+
+	return nil
+}
+
+func withTransactionID(page, transactionID string) string {
+	parsed, err := url.Parse(page)
+	if err != nil {
+		return page
+	}
+
+	query := parsed.Query()
+	query.Set("transaction_id", transactionID)
+	parsed.RawQuery = query.Encode()
+
+	return parsed.String()
+}
@@ -0,0 +1,202 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"transaction-service/config"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func setConfigEnv(t *testing.T, env map[string]string) {
+	for _, key := range []string{"CONFIG_FILE", "PUBLIC_BASE_URL", "FRONTEND_SUCCESS_URL", "FRONTEND_FAILURE_URL", "WEBHOOK_PATH", "INVOICE_DURATION", "XENDIT_CALLBACK_TOKEN", "SWAGGER_HOST", "MIN_DONATION_AMOUNT", "MAX_DONATION_AMOUNT", "ADMIN_EMAILS", "RECONCILER_INTERVAL", "RECONCILER_BATCH_SIZE", "RECONCILER_STALE_AFTER", "OUTBOX_RELAY_INTERVAL", "OUTBOX_RELAY_BATCH_SIZE", "SUBSCRIPTION_SCHEDULER_INTERVAL", "SUBSCRIPTION_BATCH_SIZE", "SUBSCRIPTION_REMINDER_BEFORE", "RECEIPT_SENDER_INTERVAL", "RECEIPT_SENDER_BATCH_SIZE", "TAX_STATEMENT_SENDER_INTERVAL", "TAX_STATEMENT_SENDER_BATCH_SIZE"} {
+		t.Setenv(key, env[key])
+	}
+}
+
+func TestLoad(t *testing.T) {
+	t.Run("success - from env with defaults", func(t *testing.T) {
+		setConfigEnv(t, map[string]string{
+			"PUBLIC_BASE_URL":       "https://transaction.staging.edu-connect.id/",
+			"FRONTEND_SUCCESS_URL":  "https://staging.edu-connect.id/payment/success",
+			"FRONTEND_FAILURE_URL":  "https://staging.edu-connect.id/payment/failed?source=xendit",
+			"XENDIT_CALLBACK_TOKEN": "callback-token",
+		})
+
+		cfg, err := config.Load()
+
+		assert.NoError(t, err)
+		assert.Equal(t, 24*time.Hour, cfg.InvoiceDuration)
+		assert.Equal(t, "callback-token", cfg.CallbackToken)
+		assert.Equal(t, float64(10000), cfg.MinDonationAmount)
+		assert.Equal(t, float64(100000000), cfg.MaxDonationAmount)
+		assert.Equal(t, "transaction.staging.edu-connect.id", cfg.SwaggerHost)
+		assert.Equal(t, "https", cfg.SwaggerScheme())
+		assert.Equal(t, "https://transaction.staging.edu-connect.id/payment/callback", cfg.CallbackURL())
+		assert.Equal(t, "https://transaction.staging.edu-connect.id/payment/success?external_id=abc", cfg.SuccessRedirectURL("abc"))
+		assert.Equal(t, "https://staging.edu-connect.id/payment/success?transaction_id=abc", cfg.FrontendSuccessRedirect("abc"))
+		assert.Equal(t, "https://staging.edu-connect.id/payment/failed?source=xendit&transaction_id=abc", cfg.FailureRedirectURL("abc"))
+		assert.Equal(t, config.ReconcilerConfig{Interval: 5 * time.Minute, BatchSize: 50, StaleAfter: 15 * time.Minute}, cfg.Reconciler)
+		assert.Equal(t, config.WorkerConfig{Interval: 5 * time.Second, BatchSize: 100}, cfg.OutboxRelay)
+		assert.Equal(t, config.SubscriptionSchedulerConfig{Interval: time.Hour, BatchSize: 50, RemindBefore: 72 * time.Hour}, cfg.SubscriptionScheduler)
+		assert.Equal(t, config.WorkerConfig{Interval: time.Minute, BatchSize: 50}, cfg.ReceiptSender)
+		assert.Equal(t, config.WorkerConfig{Interval: time.Hour, BatchSize: 50}, cfg.TaxStatementSender)
+	})
+
+	t.Run("success - env overrides yaml file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		err := os.WriteFile(path, []byte(`
+public_base_url: http://localhost:8082
+frontend_success_url: http://localhost:3000/success
+frontend_failure_url: http://localhost:3000/failed
+webhook_path: /xendit/invoice
+invoice_duration: 2h
+callback_token: yaml-token
+swagger_host: docs.local:8082
+`), 0o600)
+		assert.NoError(t, err)
+
+		setConfigEnv(t, map[string]string{
+			"CONFIG_FILE":      path,
+			"INVOICE_DURATION": "30m",
+			"ADMIN_EMAILS":     " admin@edu-connect.id, ops@edu-connect.id,",
+		})
+
+		cfg, err := config.Load()
+
+		assert.NoError(t, err)
+		assert.Equal(t, 30*time.Minute, cfg.InvoiceDuration)
+		assert.Equal(t, "/xendit/invoice", cfg.WebhookPath)
+		assert.Equal(t, "yaml-token", cfg.CallbackToken)
+		assert.Equal(t, "docs.local:8082", cfg.SwaggerHost)
+		assert.Equal(t, "http", cfg.SwaggerScheme())
+		assert.Equal(t, "http://localhost:8082/xendit/invoice", cfg.CallbackURL())
+		assert.Equal(t, []string{"admin@edu-connect.id", "ops@edu-connect.id"}, cfg.AdminEmails)
+		assert.True(t, cfg.IsAdmin("Admin@Edu-Connect.id"))
+		assert.False(t, cfg.IsAdmin("donor@email.com"))
+	})
+
+	t.Run("success - worker settings from yaml file and env", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		err := os.WriteFile(path, []byte(`
+public_base_url: http://localhost:8082
+frontend_success_url: http://localhost:3000/success
+frontend_failure_url: http://localhost:3000/failed
+callback_token: yaml-token
+reconciler:
+  interval: 1m
+  stale_after: 30m
+subscription_scheduler:
+  remind_before: 24h
+receipt_sender:
+  batch_size: 20
+`), 0o600)
+		assert.NoError(t, err)
+
+		setConfigEnv(t, map[string]string{
+			"CONFIG_FILE":             path,
+			"RECONCILER_BATCH_SIZE":   "200",
+			"OUTBOX_RELAY_INTERVAL":   "10s",
+			"SUBSCRIPTION_BATCH_SIZE": "25",
+		})
+
+		cfg, err := config.Load()
+
+		assert.NoError(t, err)
+		assert.Equal(t, config.ReconcilerConfig{Interval: time.Minute, BatchSize: 200, StaleAfter: 30 * time.Minute}, cfg.Reconciler)
+		assert.Equal(t, config.WorkerConfig{Interval: 10 * time.Second, BatchSize: 100}, cfg.OutboxRelay)
+		assert.Equal(t, config.SubscriptionSchedulerConfig{Interval: time.Hour, BatchSize: 25, RemindBefore: 24 * time.Hour}, cfg.SubscriptionScheduler)
+		assert.Equal(t, config.WorkerConfig{Interval: time.Minute, BatchSize: 20}, cfg.ReceiptSender)
+	})
+
+	t.Run("failed - missing and invalid values", func(t *testing.T) {
+		setConfigEnv(t, map[string]string{
+			"FRONTEND_SUCCESS_URL": "/payment/success",
+			"FRONTEND_FAILURE_URL": "https://staging.edu-connect.id/payment/failed",
+			"WEBHOOK_PATH":         "payment/callback",
+		})
+
+		cfg, err := config.Load()
+
+		assert.Nil(t, cfg)
+		assert.ErrorContains(t, err, "Public base URL is required")
+		assert.ErrorContains(t, err, "Frontend success URL must be an absolute http or https URL")
+		assert.ErrorContains(t, err, "Webhook path must start with /")
+		assert.ErrorContains(t, err, "Xendit callback token is required")
+		assert.ErrorContains(t, err, "Swagger host is required")
+	})
+
+	t.Run("failed - invalid invoice duration", func(t *testing.T) {
+		setConfigEnv(t, map[string]string{
+			"PUBLIC_BASE_URL":      "http://localhost:8082",
+			"FRONTEND_SUCCESS_URL": "http://localhost:3000/success",
+			"FRONTEND_FAILURE_URL": "http://localhost:3000/failed",
+			"INVOICE_DURATION":     "one day",
+		})
+
+		cfg, err := config.Load()
+
+		assert.Nil(t, cfg)
+		assert.ErrorContains(t, err, "INVOICE_DURATION must be a duration")
+	})
+
+	t.Run("failed - invalid donation limits", func(t *testing.T) {
+		setConfigEnv(t, map[string]string{
+			"PUBLIC_BASE_URL":      "http://localhost:8082",
+			"FRONTEND_SUCCESS_URL": "http://localhost:3000/success",
+			"FRONTEND_FAILURE_URL": "http://localhost:3000/failed",
+			"MIN_DONATION_AMOUNT":  "50000",
+			"MAX_DONATION_AMOUNT":  "20000",
+		})
+
+		cfg, err := config.Load()
+
+		assert.Nil(t, cfg)
+		assert.ErrorContains(t, err, "Maximum donation amount must be a whole number of IDR no less than the minimum")
+	})
+
+	t.Run("failed - invalid worker settings", func(t *testing.T) {
+		setConfigEnv(t, map[string]string{
+			"PUBLIC_BASE_URL":              "http://localhost:8082",
+			"FRONTEND_SUCCESS_URL":         "http://localhost:3000/success",
+			"FRONTEND_FAILURE_URL":         "http://localhost:3000/failed",
+			"RECONCILER_INTERVAL":          "soon",
+			"OUTBOX_RELAY_BATCH_SIZE":      "many",
+			"RECEIPT_SENDER_INTERVAL":      "0s",
+			"SUBSCRIPTION_REMINDER_BEFORE": "-1h",
+		})
+
+		cfg, err := config.Load()
+
+		assert.Nil(t, cfg)
+		assert.EqualError(t, err, "RECONCILER_INTERVAL must be a duration such as 5m, OUTBOX_RELAY_BATCH_SIZE must be an integer")
+
+		setConfigEnv(t, map[string]string{
+			"PUBLIC_BASE_URL":              "http://localhost:8082",
+			"FRONTEND_SUCCESS_URL":         "http://localhost:3000/success",
+			"FRONTEND_FAILURE_URL":         "http://localhost:3000/failed",
+			"RECEIPT_SENDER_INTERVAL":      "0s",
+			"SUBSCRIPTION_REMINDER_BEFORE": "-1h",
+		})
+
+		cfg, err = config.Load()
+
+		assert.Nil(t, cfg)
+		assert.ErrorContains(t, err, "Receipt sender interval must be positive")
+		assert.ErrorContains(t, err, "Subscription reminder lead time must not be negative")
+	})
+
+	t.Run("failed - unreadable config file", func(t *testing.T) {
+		setConfigEnv(t, map[string]string{
+			"CONFIG_FILE": filepath.Join(t.TempDir(), "missing.yaml"),
+		})
+
+		cfg, err := config.Load()
+
+		assert.Nil(t, cfg)
+		assert.ErrorContains(t, err, "failed to read config file")
+	})
+}
@@ -0,0 +1,137 @@
+package database
+
+import (
+	"context"
+
+	"transaction-service/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// BackfillTransactionMoney copies the float amount, refunded_amount and refund
+// amounts of transactions created before amounts were stored in minor units
+// into amount_v2, refunded_amount_v2 and refunds.amount_v2. Those transactions
+// were all in IDR. It only touches documents without amount_v2, so it is safe
+// to run on every start.
+func BackfillTransactionMoney(ctx context.Context, db *mongo.Database) (int64, error) {
+	filter := bson.D{{Key: "amount_v2", Value: bson.D{{Key: "$exists", Value: false}}}}
+
+	update := mongo.Pipeline{
+		{{Key: "$set", Value: bson.D{
+			{Key: "amount_v2", Value: idrMinorUnits("$amount")},
+			{Key: "refunded_amount_v2", Value: idrMinorUnits("$refunded_amount")},
+			{Key: "refunds", Value: bson.D{{Key: "$map", Value: bson.D{
+				{Key: "input", Value: bson.D{{Key: "$ifNull", Value: bson.A{"$refunds", bson.A{}}}}},
+				{Key: "as", Value: "refund"},
+				{Key: "in", Value: bson.D{{Key: "$mergeObjects", Value: bson.A{
+					"$$refund",
+					bson.D{{Key: "amount_v2", Value: idrMinorUnits("$$refund.amount")}},
+				}}}},
+			}}}},
+		}}},
+	}
+
+	result, err := db.Collection("transactions").UpdateMany(ctx, filter, update)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.ModifiedCount, nil
+}
+
+// BackfillPaymentStatus rewrites payment statuses stored before they were
+// typed, such as "paid" or Xendit's "SETTLED", to the PaymentStatus they stand
+// for, so that filters by status match those transactions. Values that are no
+// known status are left as they are. It only reads transactions whose status
+// is not already a known one, so it is safe to run on every start.
+func BackfillPaymentStatus(ctx context.Context, db *mongo.Database) (int64, error) {
+	transactions := db.Collection("transactions")
+
+	filter := bson.D{{Key: "payment_status", Value: bson.D{{Key: "$nin", Value: bson.A{
+		model.PaymentStatusCreated,
+		model.PaymentStatusPending,
+		model.PaymentStatusPaid,
+		model.PaymentStatusExpired,
+		model.PaymentStatusFailed,
+		model.PaymentStatusRefunded,
+	}}}}}
+	opts := options.Find().SetProjection(bson.D{{Key: "payment_status", Value: 1}})
+
+	cursor, err := transactions.Find(ctx, filter, opts)
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var backfilled int64
+	for cursor.Next(ctx) {
+		var doc struct {
+			ID            primitive.ObjectID `bson:"_id"`
+			PaymentStatus string             `bson:"payment_status"`
+		}
+		if err := cursor.Decode(&doc); err != nil {
+			return backfilled, err
+		}
+
+		status, ok := model.ParsePaymentStatus(doc.PaymentStatus)
+		if !ok {
+			continue
+		}
+
+		result, err := transactions.UpdateOne(ctx,
+			bson.D{{Key: "_id", Value: doc.ID}, {Key: "payment_status", Value: doc.PaymentStatus}},
+			bson.D{{Key: "$set", Value: bson.D{{Key: "payment_status", Value: status}}}},
+		)
+		if err != nil {
+			return backfilled, err
+		}
+		backfilled += result.ModifiedCount
+	}
+
+	return backfilled, cursor.Err()
+}
+
+// transactionDateFields were stored as RFC3339 strings in the server's local
+// offset, which do not compare correctly across offsets.
+var transactionDateFields = []string{"created_at", "updated_at", "paid_at", "expires_at"}
+
+// BackfillTransactionDates converts the dates of transactions stored before
+// they were BSON dates, so that date filters and sorts compare instants. It
+// only touches transactions with a string date, so it is safe to run on every
+// start.
+func BackfillTransactionDates(ctx context.Context, db *mongo.Database) (int64, error) {
+	var filter bson.A
+	set := bson.D{}
+	for _, field := range transactionDateFields {
+		filter = append(filter, bson.D{{Key: field, Value: bson.D{{Key: "$type", Value: "string"}}}})
+		set = append(set, bson.E{Key: field, Value: bson.D{{Key: "$cond", Value: bson.A{
+			bson.D{{Key: "$eq", Value: bson.A{bson.D{{Key: "$type", Value: "$" + field}}, "string"}}},
+			bson.D{{Key: "$toDate", Value: "$" + field}},
+			"$" + field,
+		}}}})
+	}
+
+	update := mongo.Pipeline{{{Key: "$set", Value: set}}}
+
+	result, err := db.Collection("transactions").UpdateMany(ctx, bson.D{{Key: "$or", Value: filter}}, update)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.ModifiedCount, nil
+}
+
+// idrMinorUnits is an aggregation expression turning a float rupiah field into
+// a Money document in sen. A missing field becomes zero.
+func idrMinorUnits(field string) bson.D {
+	return bson.D{
+		{Key: "amount", Value: bson.D{{Key: "$toLong", Value: bson.D{{Key: "$round", Value: bson.A{
+			bson.D{{Key: "$multiply", Value: bson.A{bson.D{{Key: "$ifNull", Value: bson.A{field, 0}}}, 100}}},
+			0,
+		}}}}}},
+		{Key: "currency", Value: "IDR"},
+	}
+}
@@ -0,0 +1,37 @@
+package database
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/rabbitmq/amqp091-go"
+)
+
+// InitRabbitMQ connects to the broker shared with notification-service. It
+// returns nil without error when MQHOST is not set, so the service can run
+// locally without RabbitMQ.
+func InitRabbitMQ() (*amqp091.Connection, *amqp091.Channel, error) {
+	host := os.Getenv("MQHOST")
+	if host == "" {
+		return nil, nil, nil
+	}
+
+	conStr := fmt.Sprintf("amqp://%s:%s@%s:%s/%s",
+		os.Getenv("MQUSER"), os.Getenv("MQPASS"), host, os.Getenv("MQPORT"), os.Getenv("MQVHOST"),
+	)
+
+	conn, err := amqp091.Dial(conStr)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to RabbitMQ: %v", err)
+	}
+
+	ch, err := conn.Channel()
+	if err != nil {
+		conn.Close()
+		return nil, nil, fmt.Errorf("failed to open RabbitMQ channel: %v", err)
+	}
+
+	log.Info("Connected to RabbitMQ")
+
+	return conn, ch, nil
+}
@@ -0,0 +1,98 @@
+package document
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"transaction-service/model"
+
+	"github.com/go-pdf/fpdf"
+)
+
+const dateLayout = "02 January 2006"
+
+// RenderReceiptPDF renders the donation receipt of a paid transaction.
+func RenderReceiptPDF(data model.ReceiptData) ([]byte, error) {
+	transaction := data.Transaction
+
+	pdf := newDocument("Donation Receipt", data.IssuedAt)
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+
+	pdf.SetFont("Helvetica", "B", 18)
+	pdf.CellFormat(0, 10, "Donation Receipt", "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 10)
+	pdf.CellFormat(0, 6, tr("Issued "+data.IssuedAt.Format(dateLayout)), "", 1, "L", false, 0, "")
+	pdf.Ln(6)
+
+	section(pdf, "Recipient")
+	row(pdf, tr, "Institution", data.Institution.Name)
+	row(pdf, tr, "Address", data.Institution.Address)
+	if data.Institution.Website != "" {
+		row(pdf, tr, "Website", data.Institution.Website)
+	}
+	row(pdf, tr, "Fundraising", data.Post.Title)
+	pdf.Ln(4)
+
+	section(pdf, "Donor")
+	row(pdf, tr, "Name", transaction.AccountName)
+	row(pdf, tr, "Email", transaction.UserEmail)
+	pdf.Ln(4)
+
+	section(pdf, "Donation")
+	row(pdf, tr, "Transaction ID", transaction.TransactionID.Hex())
+	row(pdf, tr, "Payment date", transaction.PaidAt.Format(dateLayout))
+	if transaction.PaymentMethod != "" {
+		row(pdf, tr, "Payment method", transaction.PaymentMethod)
+	}
+	row(pdf, tr, "Amount", transaction.Amount.String())
+	if transaction.FXRate != nil {
+		row(pdf, tr, "Credited as", transaction.CampaignAmount().String())
+	}
+	if !transaction.RefundedAmount.IsZero() {
+		row(pdf, tr, "Refunded", transaction.RefundedAmount.String())
+		row(pdf, tr, "Net donation", transaction.RefundableAmount().String())
+	}
+	pdf.Ln(8)
+
+	pdf.SetFont("Helvetica", "I", 9)
+	pdf.MultiCell(0, 5, tr(fmt.Sprintf(
+		"This receipt confirms that %s received the donation above through EduConnect. Keep it for your tax deduction claim.",
+		data.Institution.Name,
+	)), "", "L", false)
+
+	return output(pdf)
+}
+
+func newDocument(title string, createdAt time.Time) *fpdf.Fpdf {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetTitle(title, true)
+	pdf.SetAuthor("EduConnect", true)
+	pdf.SetCreationDate(createdAt)
+	pdf.SetModificationDate(createdAt)
+	pdf.SetMargins(20, 20, 20)
+	pdf.AddPage()
+
+	return pdf
+}
+
+func section(pdf *fpdf.Fpdf, title string) {
+	pdf.SetFont("Helvetica", "B", 12)
+	pdf.CellFormat(0, 8, title, "B", 1, "L", false, 0, "")
+	pdf.Ln(1)
+}
+
+func row(pdf *fpdf.Fpdf, tr func(string) string, label, value string) {
+	pdf.SetFont("Helvetica", "", 10)
+	pdf.CellFormat(45, 6, label, "", 0, "L", false, 0, "")
+	pdf.MultiCell(0, 6, tr(value), "", "L", false)
+}
+
+func output(pdf *fpdf.Fpdf) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, fmt.Errorf("failed to render PDF: %v", err)
+	}
+
+	return buf.Bytes(), nil
+}
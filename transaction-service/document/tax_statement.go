@@ -0,0 +1,163 @@
+package document
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"strings"
+
+	"transaction-service/model"
+
+	"github.com/go-pdf/fpdf"
+)
+
+// Column widths of the donation table, filling the 170 mm between margins.
+var taxStatementColumns = []struct {
+	title string
+	width float64
+	align string
+}{
+	{"Date", 28, "L"},
+	{"Fundraising", 62, "L"},
+	{"Transaction ID", 46, "L"},
+	{"Net amount", 34, "R"},
+}
+
+// RenderTaxStatementPDF renders a donor's annual statement with one table of
+// donations per institution.
+func RenderTaxStatementPDF(statement *model.TaxStatement) ([]byte, error) {
+	title := fmt.Sprintf("Annual Donation Statement %d", statement.Year)
+	from, to := model.TaxYearRange(statement.Year)
+
+	pdf := newDocument(title, statement.GeneratedAt)
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+
+	pdf.SetFont("Helvetica", "B", 18)
+	pdf.CellFormat(0, 10, title, "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 10)
+	pdf.CellFormat(0, 6, tr("Generated "+statement.GeneratedAt.Format(dateLayout)), "", 1, "L", false, 0, "")
+	pdf.Ln(6)
+
+	section(pdf, "Donor")
+	row(pdf, tr, "Name", statement.DonorName)
+	row(pdf, tr, "Email", statement.UserEmail)
+	row(pdf, tr, "Period", from.Format(dateLayout)+" - "+to.AddDate(0, 0, -1).Format(dateLayout))
+	pdf.Ln(4)
+
+	for _, group := range statement.Institutions {
+		section(pdf, tr(group.Institution.Name))
+		row(pdf, tr, "Address", group.Institution.Address)
+		if group.Institution.Website != "" {
+			row(pdf, tr, "Website", group.Institution.Website)
+		}
+		pdf.Ln(2)
+
+		pdf.SetFont("Helvetica", "B", 9)
+		for _, column := range taxStatementColumns {
+			pdf.CellFormat(column.width, 6, column.title, "B", 0, column.align, false, 0, "")
+		}
+		pdf.Ln(-1)
+
+		pdf.SetFont("Helvetica", "", 9)
+		for _, donation := range group.Donations {
+			values := []string{
+				donation.PaidAt.In(model.TaxYearLocation).Format("02 Jan 2006"),
+				donation.PostTitle,
+				donation.TransactionID.Hex(),
+				donation.Net.String(),
+			}
+			for i, column := range taxStatementColumns {
+				pdf.CellFormat(column.width, 6, fit(pdf, tr(values[i]), column.width), "", 0, column.align, false, 0, "")
+			}
+			pdf.Ln(-1)
+		}
+
+		totals(pdf, "Total", group.Totals)
+		pdf.Ln(4)
+	}
+
+	section(pdf, "Total donations")
+	totals(pdf, fmt.Sprintf("Total %d", statement.Year), statement.Totals)
+	pdf.Ln(8)
+
+	pdf.SetFont("Helvetica", "I", 9)
+	pdf.MultiCell(0, 5, tr(
+		"This statement lists the donations paid through EduConnect in the period above, net of refunds. Keep it with the receipts of each donation for your zakat or donation deduction claim.",
+	), "", "L", false)
+
+	return output(pdf)
+}
+
+// totals prints one right-aligned line per currency under the amount column.
+func totals(pdf *fpdf.Fpdf, label string, amounts []model.Money) {
+	labelWidth := 0.0
+	for _, column := range taxStatementColumns[:len(taxStatementColumns)-1] {
+		labelWidth += column.width
+	}
+	amountWidth := taxStatementColumns[len(taxStatementColumns)-1].width
+
+	pdf.SetFont("Helvetica", "B", 9)
+	for _, amount := range amounts {
+		pdf.CellFormat(labelWidth, 6, label, "T", 0, "R", false, 0, "")
+		pdf.CellFormat(amountWidth, 6, amount.String(), "T", 1, "R", false, 0, "")
+	}
+}
+
+// fit shortens text with an ellipsis so it stays within width.
+func fit(pdf *fpdf.Fpdf, text string, width float64) string {
+	width -= 2 * pdf.GetCellMargin()
+	if pdf.GetStringWidth(text) <= width {
+		return text
+	}
+
+	runes := []rune(text)
+	for len(runes) > 0 && pdf.GetStringWidth(string(runes)+"...") > width {
+		runes = runes[:len(runes)-1]
+	}
+
+	return strings.TrimSpace(string(runes)) + "..."
+}
+
+// RenderTaxStatementCSV renders the statement as one row per donation, with
+// amounts in major units so spreadsheets can sum them.
+func RenderTaxStatementCSV(statement *model.TaxStatement) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+
+	records := [][]string{{
+		"year",
+		"institution",
+		"institution_address",
+		"transaction_id",
+		"fundraising",
+		"paid_at",
+		"payment_method",
+		"currency",
+		"amount",
+		"refunded",
+		"net",
+	}}
+	for _, group := range statement.Institutions {
+		for _, donation := range group.Donations {
+			records = append(records, []string{
+				fmt.Sprint(statement.Year),
+				group.Institution.Name,
+				group.Institution.Address,
+				donation.TransactionID.Hex(),
+				donation.PostTitle,
+				donation.PaidAt.In(model.TaxYearLocation).Format("2006-01-02"),
+				donation.PaymentMethod,
+				donation.Amount.Currency,
+				donation.Amount.Decimal(),
+				donation.Amount.Sub(donation.Net).Decimal(),
+				donation.Net.Decimal(),
+			})
+		}
+	}
+
+	if err := writer.WriteAll(records); err != nil {
+		return nil, fmt.Errorf("failed to render CSV: %v", err)
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,95 @@
+package tests
+
+import (
+	"bytes"
+	"compress/zlib"
+	"io"
+	"regexp"
+	"testing"
+	"time"
+
+	"transaction-service/document"
+	"transaction-service/model"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var streamPattern = regexp.MustCompile(`(?s)stream\n(.*?)endstream`)
+
+// pdfText inflates the content streams of a PDF so tests can look for the
+// text drawn on its pages.
+func pdfText(t *testing.T, pdf []byte) string {
+	var text bytes.Buffer
+	for _, match := range streamPattern.FindAllSubmatch(pdf, -1) {
+		reader, err := zlib.NewReader(bytes.NewReader(match[1]))
+		if err != nil {
+			continue
+		}
+		content, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		text.Write(content)
+	}
+
+	return text.String()
+}
+
+func newReceiptData() model.ReceiptData {
+	return model.ReceiptData{
+		Transaction: &model.Transaction{
+			TransactionID: primitive.NewObjectID(),
+			UserEmail:     "donor@email.com",
+			PaymentStatus: model.PaymentStatusPaid,
+			PaymentMethod: "BANK_TRANSFER",
+			PaidAt:        time.Date(2024, time.March, 5, 10, 0, 0, 0, time.UTC),
+			Amount:        model.IDR(150000),
+			AccountName:   "Budi Santoso",
+		},
+		Post: &model.Post{PostID: uuid.New(), Title: "Beasiswa Anak Pesisir"},
+		Institution: &model.Institution{
+			InstitutionID: uuid.New(),
+			Name:          "Yayasan Pendidikan Nusantara",
+			Address:       "Jl. Merdeka No. 1, Jakarta",
+		},
+		IssuedAt: time.Date(2024, time.March, 5, 10, 5, 0, 0, time.UTC),
+	}
+}
+
+func TestRenderReceiptPDF(t *testing.T) {
+	t.Run("success - receipt shows the donation details", func(t *testing.T) {
+		data := newReceiptData()
+
+		pdf, err := document.RenderReceiptPDF(data)
+
+		require.NoError(t, err)
+		assert.True(t, bytes.HasPrefix(pdf, []byte("%PDF-")))
+
+		text := pdfText(t, pdf)
+		for _, want := range []string{
+			data.Transaction.TransactionID.Hex(),
+			"Budi Santoso",
+			"Yayasan Pendidikan Nusantara",
+			"Jl. Merdeka No. 1, Jakarta",
+			"Beasiswa Anak Pesisir",
+			"IDR 150000.00",
+			"05 March 2024",
+		} {
+			assert.Contains(t, text, want)
+		}
+		assert.NotContains(t, text, "Net donation")
+	})
+
+	t.Run("success - partial refund shows the net donation", func(t *testing.T) {
+		data := newReceiptData()
+		data.Transaction.RefundedAmount = model.IDR(50000)
+
+		pdf, err := document.RenderReceiptPDF(data)
+
+		require.NoError(t, err)
+		text := pdfText(t, pdf)
+		assert.Contains(t, text, "IDR 50000.00")
+		assert.Contains(t, text, "IDR 100000.00")
+	})
+}
@@ -0,0 +1,92 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+	"time"
+
+	"transaction-service/document"
+	"transaction-service/model"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func newTaxStatement() *model.TaxStatement {
+	return &model.TaxStatement{
+		UserID:    uuid.New().String(),
+		UserEmail: "donor@email.com",
+		DonorName: "Budi Santoso",
+		Year:      2024,
+		Institutions: []model.TaxStatementInstitution{{
+			Institution: model.Institution{
+				InstitutionID: uuid.New(),
+				Name:          "Yayasan Pendidikan Nusantara",
+				Address:       "Jl. Merdeka No. 1, Jakarta",
+			},
+			Donations: []model.TaxStatementDonation{{
+				TransactionID: primitive.NewObjectID(),
+				PostTitle:     "Beasiswa Anak Pesisir",
+				PaidAt:        time.Date(2024, time.June, 30, 20, 0, 0, 0, time.UTC),
+				PaymentMethod: "BANK_TRANSFER",
+				Amount:        model.IDR(150000),
+				Net:           model.IDR(100000),
+			}},
+			Totals: []model.Money{model.IDR(100000)},
+		}},
+		Totals:      []model.Money{model.IDR(100000)},
+		GeneratedAt: time.Date(2025, time.January, 2, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestRenderTaxStatementPDF(t *testing.T) {
+	t.Run("success - statement lists donations per institution", func(t *testing.T) {
+		statement := newTaxStatement()
+
+		pdf, err := document.RenderTaxStatementPDF(statement)
+
+		require.NoError(t, err)
+		assert.True(t, bytes.HasPrefix(pdf, []byte("%PDF-")))
+
+		text := pdfText(t, pdf)
+		for _, want := range []string{
+			"Annual Donation Statement 2024",
+			"Budi Santoso",
+			"01 January 2024 - 31 December 2024",
+			"Yayasan Pendidikan Nusantara",
+			statement.Institutions[0].Donations[0].TransactionID.Hex(),
+			"IDR 100000.00",
+		} {
+			assert.Contains(t, text, want)
+		}
+	})
+}
+
+func TestRenderTaxStatementCSV(t *testing.T) {
+	t.Run("success - one row per donation in Western Indonesian Time", func(t *testing.T) {
+		statement := newTaxStatement()
+
+		content, err := document.RenderTaxStatementCSV(statement)
+		require.NoError(t, err)
+
+		records, err := csv.NewReader(bytes.NewReader(content)).ReadAll()
+		require.NoError(t, err)
+		require.Len(t, records, 2)
+		assert.Equal(t, []string{
+			"2024",
+			"Yayasan Pendidikan Nusantara",
+			"Jl. Merdeka No. 1, Jakarta",
+			statement.Institutions[0].Donations[0].TransactionID.Hex(),
+			"Beasiswa Anak Pesisir",
+			"2024-07-01",
+			"BANK_TRANSFER",
+			"IDR",
+			"150000.00",
+			"50000.00",
+			"100000.00",
+		}, records[1])
+	})
+}
@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"transaction-service/middlewares"
+	pbReceipt "transaction-service/pb/receipt"
+	"transaction-service/usecase"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type IReceiptHandler interface {
+	GetTransactionReceipt(ctx context.Context, req *pbReceipt.GetTransactionReceiptRequest) (*pbReceipt.ReceiptResponse, error)
+}
+
+type ReceiptServer struct {
+	pbReceipt.UnimplementedReceiptServiceServer
+	receiptUsecase usecase.IReceiptUsecase
+}
+
+func NewReceiptHandler(receiptUsecase usecase.IReceiptUsecase) *ReceiptServer {
+	return &ReceiptServer{
+		receiptUsecase: receiptUsecase,
+	}
+}
+
+func (s *ReceiptServer) GetTransactionReceipt(ctx context.Context, req *pbReceipt.GetTransactionReceiptRequest) (*pbReceipt.ReceiptResponse, error) {
+	email, ok := ctx.Value(middlewares.EmailKey).(string)
+	if !ok || email == "" {
+		return nil, status.Errorf(codes.Unauthenticated, "failed to get authenticated user email from context")
+	}
+
+	transactionID, err := primitive.ObjectIDFromHex(req.TransactionId)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid transaction ID format: %v", err)
+	}
+
+	receipt, err := s.receiptUsecase.GetUserReceipt(ctx, email, transactionID)
+	switch {
+	case errors.Is(err, usecase.ErrTransactionNotFound):
+		return nil, status.Errorf(codes.NotFound, "transaction not found")
+	case errors.Is(err, usecase.ErrReceiptNotAvailable):
+		return nil, status.Errorf(codes.FailedPrecondition, "%v", err)
+	case err != nil:
+		return nil, status.Errorf(codes.Internal, "failed to get receipt: %v", err)
+	}
+
+	return &pbReceipt.ReceiptResponse{
+		Filename:    receipt.Filename(),
+		ContentType: "application/pdf",
+		Content:     receipt.PDF,
+		IssuedAt:    receipt.IssuedAt.Format(time.RFC3339),
+	}, nil
+}
@@ -0,0 +1,173 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"transaction-service/middlewares"
+	"transaction-service/model"
+	pbSubscription "transaction-service/pb/subscription"
+	"transaction-service/usecase"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type ISubscriptionHandler interface {
+	CreateSubscription(ctx context.Context, req *pbSubscription.CreateSubscriptionRequest) (*pbSubscription.SubscriptionResponse, error)
+	GetSubscriptionByID(ctx context.Context, req *pbSubscription.SubscriptionIDRequest) (*pbSubscription.SubscriptionResponse, error)
+	GetMySubscriptions(ctx context.Context, req *pbSubscription.GetMySubscriptionsRequest) (*pbSubscription.GetSubscriptionsResponse, error)
+	PauseSubscription(ctx context.Context, req *pbSubscription.SubscriptionIDRequest) (*pbSubscription.SubscriptionResponse, error)
+	ResumeSubscription(ctx context.Context, req *pbSubscription.SubscriptionIDRequest) (*pbSubscription.SubscriptionResponse, error)
+	CancelSubscription(ctx context.Context, req *pbSubscription.SubscriptionIDRequest) (*pbSubscription.SubscriptionResponse, error)
+}
+
+type SubscriptionServer struct {
+	pbSubscription.UnimplementedSubscriptionServiceServer
+	subscriptionUsecase usecase.ISubscriptionUsecase
+	transactionUsecase  usecase.ITransactionUsecase
+}
+
+func NewSubscriptionHandler(subscriptionUsecase usecase.ISubscriptionUsecase, transactionUsecase usecase.ITransactionUsecase) *SubscriptionServer {
+	return &SubscriptionServer{
+		subscriptionUsecase: subscriptionUsecase,
+		transactionUsecase:  transactionUsecase,
+	}
+}
+
+func (s *SubscriptionServer) CreateSubscription(ctx context.Context, req *pbSubscription.CreateSubscriptionRequest) (*pbSubscription.SubscriptionResponse, error) {
+	email, ok := ctx.Value(middlewares.EmailKey).(string)
+	if !ok || email == "" {
+		return nil, status.Errorf(codes.Unauthenticated, "failed to get authenticated user email from context")
+	}
+
+	userID, _ := ctx.Value(middlewares.UserIDKey).(string)
+	if userID == "" {
+		user, err := s.transactionUsecase.GetUserByEmail(ctx, email)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to get user by email: %v", err)
+		}
+		userID = user.UserID
+	}
+
+	subscription := &model.Subscription{
+		UserID:              userID,
+		UserEmail:           email,
+		PostID:              req.PostId,
+		InstitutionID:       req.InstitutionId,
+		Cadence:             model.SubscriptionCadence(strings.ToUpper(req.Cadence)),
+		AccountNumber:       req.AccountNumber,
+		AccountName:         req.AccountName,
+		DonorNameVisibility: model.DonorNameVisibility(strings.ToUpper(req.DonorNameVisibility)),
+		DonorName:           req.DisplayName,
+	}
+	if req.Amount != nil {
+		subscription.Amount = model.NewMoney(req.Amount.Amount, req.Amount.Currency)
+	}
+
+	created, err := s.subscriptionUsecase.CreateSubscription(ctx, subscription)
+	switch {
+	case errors.Is(err, usecase.ErrPostNotFound):
+		return nil, status.Errorf(codes.NotFound, "post not found")
+	case errors.Is(err, usecase.ErrPostEnded), errors.Is(err, usecase.ErrPostNotPublished):
+		return nil, status.Errorf(codes.FailedPrecondition, "%v", err)
+	case err != nil:
+		return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+	}
+
+	return toSubscriptionResponse(created), nil
+}
+
+func (s *SubscriptionServer) GetSubscriptionByID(ctx context.Context, req *pbSubscription.SubscriptionIDRequest) (*pbSubscription.SubscriptionResponse, error) {
+	return s.withUserSubscription(ctx, req, s.subscriptionUsecase.GetUserSubscriptionByID)
+}
+
+func (s *SubscriptionServer) GetMySubscriptions(ctx context.Context, req *pbSubscription.GetMySubscriptionsRequest) (*pbSubscription.GetSubscriptionsResponse, error) {
+	email, ok := ctx.Value(middlewares.EmailKey).(string)
+	if !ok || email == "" {
+		return nil, status.Errorf(codes.Unauthenticated, "failed to get authenticated user email from context")
+	}
+
+	subscriptions, err := s.subscriptionUsecase.GetUserSubscriptions(ctx, email)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get subscriptions: %v", err)
+	}
+
+	res := &pbSubscription.GetSubscriptionsResponse{
+		Subscriptions: make([]*pbSubscription.SubscriptionResponse, 0, len(subscriptions)),
+	}
+	for i := range subscriptions {
+		res.Subscriptions = append(res.Subscriptions, toSubscriptionResponse(&subscriptions[i]))
+	}
+
+	return res, nil
+}
+
+func (s *SubscriptionServer) PauseSubscription(ctx context.Context, req *pbSubscription.SubscriptionIDRequest) (*pbSubscription.SubscriptionResponse, error) {
+	return s.withUserSubscription(ctx, req, s.subscriptionUsecase.PauseSubscription)
+}
+
+func (s *SubscriptionServer) ResumeSubscription(ctx context.Context, req *pbSubscription.SubscriptionIDRequest) (*pbSubscription.SubscriptionResponse, error) {
+	return s.withUserSubscription(ctx, req, s.subscriptionUsecase.ResumeSubscription)
+}
+
+func (s *SubscriptionServer) CancelSubscription(ctx context.Context, req *pbSubscription.SubscriptionIDRequest) (*pbSubscription.SubscriptionResponse, error) {
+	return s.withUserSubscription(ctx, req, s.subscriptionUsecase.CancelSubscription)
+}
+
+// withUserSubscription runs action on one of the authenticated user's
+// subscriptions and maps its errors to gRPC statuses.
+func (s *SubscriptionServer) withUserSubscription(
+	ctx context.Context,
+	req *pbSubscription.SubscriptionIDRequest,
+	action func(ctx context.Context, email string, subscriptionID primitive.ObjectID) (*model.Subscription, error),
+) (*pbSubscription.SubscriptionResponse, error) {
+	email, ok := ctx.Value(middlewares.EmailKey).(string)
+	if !ok || email == "" {
+		return nil, status.Errorf(codes.Unauthenticated, "failed to get authenticated user email from context")
+	}
+
+	subscriptionID, err := primitive.ObjectIDFromHex(req.SubscriptionId)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid subscription ID format: %v", err)
+	}
+
+	subscription, err := action(ctx, email, subscriptionID)
+	switch {
+	case errors.Is(err, usecase.ErrSubscriptionNotFound):
+		return nil, status.Errorf(codes.NotFound, "subscription not found")
+	case errors.Is(err, usecase.ErrInvalidSubscriptionTransition), errors.Is(err, usecase.ErrSubscriptionChanged):
+		return nil, status.Errorf(codes.FailedPrecondition, "%v", err)
+	case err != nil:
+		return nil, status.Errorf(codes.Internal, "failed to update subscription: %v", err)
+	}
+
+	return toSubscriptionResponse(subscription), nil
+}
+
+func toSubscriptionResponse(subscription *model.Subscription) *pbSubscription.SubscriptionResponse {
+	res := &pbSubscription.SubscriptionResponse{
+		SubscriptionId: subscription.SubscriptionID.Hex(),
+		PostId:         subscription.PostID,
+		InstitutionId:  subscription.InstitutionID,
+		Amount: &pbSubscription.Money{
+			Amount:   subscription.Amount.Amount,
+			Currency: subscription.Amount.Currency,
+		},
+		Cadence:           string(subscription.Cadence),
+		Status:            string(subscription.Status),
+		AccountNumber:     subscription.AccountNumber,
+		AccountName:       subscription.AccountName,
+		DonorName:         subscription.DonorName,
+		LastTransactionId: subscription.LastTransactionID,
+		CreatedAt:         subscription.CreatedAt.Format(time.RFC3339),
+	}
+	if subscription.Status != model.SubscriptionStatusCancelled {
+		res.NextChargeAt = subscription.NextChargeAt.Format(time.RFC3339)
+	}
+
+	return res
+}
@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"context"
+	"errors"
+
+	"transaction-service/middlewares"
+	"transaction-service/model"
+	pbTaxStatement "transaction-service/pb/tax_statement"
+	"transaction-service/usecase"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type ITaxStatementHandler interface {
+	GetTaxStatement(ctx context.Context, req *pbTaxStatement.GetTaxStatementRequest) (*pbTaxStatement.TaxStatementResponse, error)
+}
+
+type TaxStatementServer struct {
+	pbTaxStatement.UnimplementedTaxStatementServiceServer
+	taxStatementUsecase usecase.ITaxStatementUsecase
+	transactionUsecase  usecase.ITransactionUsecase
+}
+
+func NewTaxStatementHandler(taxStatementUsecase usecase.ITaxStatementUsecase, transactionUsecase usecase.ITransactionUsecase) *TaxStatementServer {
+	return &TaxStatementServer{
+		taxStatementUsecase: taxStatementUsecase,
+		transactionUsecase:  transactionUsecase,
+	}
+}
+
+func (s *TaxStatementServer) GetTaxStatement(ctx context.Context, req *pbTaxStatement.GetTaxStatementRequest) (*pbTaxStatement.TaxStatementResponse, error) {
+	email, ok := ctx.Value(middlewares.EmailKey).(string)
+	if !ok || email == "" {
+		return nil, status.Errorf(codes.Unauthenticated, "failed to get authenticated user email from context")
+	}
+
+	format := model.TaxStatementFormat(req.Format)
+	if format == "" {
+		format = model.TaxStatementFormatPDF
+	}
+	if !format.IsValid() {
+		return nil, status.Errorf(codes.InvalidArgument, "%v", usecase.ErrInvalidTaxStatementFormat)
+	}
+
+	userID, _ := ctx.Value(middlewares.UserIDKey).(string)
+	if userID == "" {
+		user, err := s.transactionUsecase.GetUserByEmail(ctx, email)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to get user by email: %v", err)
+		}
+		userID = user.UserID
+	}
+
+	statement, err := s.taxStatementUsecase.BuildTaxStatement(ctx, userID, int(req.Year))
+	switch {
+	case errors.Is(err, usecase.ErrInvalidTaxYear):
+		return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+	case errors.Is(err, usecase.ErrNoDonationsInTaxYear):
+		return nil, status.Errorf(codes.NotFound, "%v", err)
+	case err != nil:
+		return nil, status.Errorf(codes.Internal, "failed to build tax statement: %v", err)
+	}
+
+	file, err := s.taxStatementUsecase.RenderTaxStatement(statement, format)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to render tax statement: %v", err)
+	}
+
+	return &pbTaxStatement.TaxStatementResponse{
+		Filename:    file.Filename,
+		ContentType: file.ContentType,
+		Content:     file.Content,
+	}, nil
+}
@@ -0,0 +1,1060 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"transaction-service/client"
+	"transaction-service/config"
+	"transaction-service/handler"
+	"transaction-service/middlewares"
+	"transaction-service/mocks"
+	"transaction-service/model"
+	pbTransaction "transaction-service/pb/transaction"
+	"transaction-service/queue"
+	"transaction-service/usecase"
+	"transaction-service/worker"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+)
+
+// donationStore backs the mocked repository with an in-memory transaction
+// and post so the real usecase can run the whole donation flow.
+type donationStore struct {
+	post         model.Post
+	transactions map[primitive.ObjectID]*model.Transaction
+	credited     map[string]bool
+}
+
+func newDonationStore(t *testing.T, mockTransactionRepo *mocks.MockITransactionRepository) *donationStore {
+	store := &donationStore{
+		post: model.Post{
+			PostID:        uuid.New(),
+			InstitutionID: uuid.New(),
+			Title:         "School library",
+			Status:        model.PostStatusPublished,
+			DateStart:     time.Now().Add(-24 * time.Hour),
+			DateEnd:       time.Now().Add(24 * time.Hour),
+			FundTarget:    model.IDR(1000000),
+			FundAchieved:  model.IDR(0),
+		},
+		transactions: map[primitive.ObjectID]*model.Transaction{},
+		credited:     map[string]bool{},
+	}
+
+	mockTransactionRepo.EXPECT().
+		GetUserByEmail(gomock.Any(), gomock.Any()).
+		Return(&model.User{UserID: uuid.New().String(), Email: "donor@email.com"}, nil).
+		AnyTimes()
+
+	mockTransactionRepo.EXPECT().
+		GetPostByID(gomock.Any(), store.post.PostID).
+		Return(&store.post, nil).
+		AnyTimes()
+
+	mockTransactionRepo.EXPECT().
+		CreateTransaction(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, transaction *model.Transaction) (*model.Transaction, error) {
+			transaction.TransactionID = primitive.NewObjectID()
+			transaction.CreatedAt = time.Now()
+			stored := *transaction
+			store.transactions[transaction.TransactionID] = &stored
+			return transaction, nil
+		}).
+		AnyTimes()
+
+	updateStatus := func(ctx context.Context, transaction *model.Transaction, transition model.StatusTransition) (bool, error) {
+		stored := store.transactions[transaction.TransactionID]
+		if stored.PaymentStatus != transition.From {
+			return false, nil
+		}
+		stored.PaymentID = transaction.PaymentID
+		stored.PaymentURL = transaction.PaymentURL
+		stored.PaymentMethod = transaction.PaymentMethod
+		stored.ExpiresAt = transaction.ExpiresAt
+		stored.PreviousPaymentIDs = transaction.PreviousPaymentIDs
+		stored.PaymentStatus = transition.To
+		stored.StatusHistory = append(stored.StatusHistory, transition)
+		return true, nil
+	}
+
+	mockTransactionRepo.EXPECT().
+		UpdateTransactionStatus(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(updateStatus).
+		AnyTimes()
+
+	// The settled event is applied to the post straight away, standing in for
+	// the outbox relay and the institution-service consumer.
+	mockTransactionRepo.EXPECT().
+		UpdateTransactionStatusWithEvent(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, transaction *model.Transaction, transition model.StatusTransition, event model.OutboxEvent) (bool, error) {
+			updated, err := updateStatus(ctx, transaction, transition)
+			if !updated || err != nil {
+				return updated, err
+			}
+
+			var settled model.DonationSettledEvent
+			assert.NoError(t, json.Unmarshal(event.Payload, &settled))
+			if !store.credited[settled.TransactionID] {
+				store.credited[settled.TransactionID] = true
+				store.post.FundAchieved = store.post.FundAchieved.Add(settled.Amount)
+			}
+			return true, nil
+		}).
+		AnyTimes()
+
+	mockTransactionRepo.EXPECT().
+		GetPendingTransactionsBefore(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, createdBefore time.Time, limit int64) ([]model.Transaction, error) {
+			var transactions []model.Transaction
+			for _, transaction := range store.transactions {
+				if transaction.PaymentStatus == model.PaymentStatusPending {
+					transactions = append(transactions, *transaction)
+				}
+			}
+			return transactions, nil
+		}).
+		AnyTimes()
+
+	mockTransactionRepo.EXPECT().
+		GetTransactionByID(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, transactionID primitive.ObjectID) (*model.Transaction, error) {
+			stored, ok := store.transactions[transactionID]
+			if !ok {
+				return nil, mongo.ErrNoDocuments
+			}
+			loaded := *stored
+			return &loaded, nil
+		}).
+		AnyTimes()
+
+	mockTransactionRepo.EXPECT().
+		GetTransactions(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, filter model.TransactionFilter) ([]model.Transaction, error) {
+			var transactions []model.Transaction
+			for _, transaction := range store.transactions {
+				if transaction.PostID == filter.PostID && (filter.Status == "" || transaction.PaymentStatus == filter.Status) {
+					transactions = append(transactions, *transaction)
+				}
+			}
+			return transactions, nil
+		}).
+		AnyTimes()
+
+	mockTransactionRepo.EXPECT().
+		AddPendingRefund(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, transaction *model.Transaction, refund model.Refund) (bool, error) {
+			stored := store.transactions[transaction.TransactionID]
+			if stored.PaymentStatus != model.PaymentStatusPaid || stored.RefundedAmount.Amount != transaction.RefundedAmount.Amount || stored.PendingRefund() != nil {
+				return false, nil
+			}
+			stored.Refunds = append(stored.Refunds, refund)
+			return true, nil
+		}).
+		AnyTimes()
+
+	mockTransactionRepo.EXPECT().
+		FailRefund(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, transactionID primitive.ObjectID, referenceID string) error {
+			if pending := store.transactions[transactionID].PendingRefund(); pending != nil && pending.ReferenceID == referenceID {
+				pending.Status = model.RefundStatusFailed
+			}
+			return nil
+		}).
+		AnyTimes()
+
+	mockTransactionRepo.EXPECT().
+		RecordRefund(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, transaction *model.Transaction, refund model.Refund, transition *model.StatusTransition, event model.OutboxEvent) (bool, error) {
+			stored := store.transactions[transaction.TransactionID]
+			pending := stored.PendingRefund()
+			if stored.PaymentStatus != model.PaymentStatusPaid || stored.RefundedAmount.Amount != transaction.RefundedAmount.Amount || pending == nil || pending.ReferenceID != refund.ReferenceID {
+				return false, nil
+			}
+			stored.RefundedAmount = stored.RefundedAmount.Add(refund.Amount)
+			*pending = refund
+			if transition != nil {
+				stored.PaymentStatus = transition.To
+				stored.StatusHistory = append(stored.StatusHistory, *transition)
+			}
+
+			var refunded model.DonationRefundedEvent
+			assert.NoError(t, json.Unmarshal(event.Payload, &refunded))
+			store.post.FundAchieved = store.post.FundAchieved.Sub(refunded.Amount)
+			return true, nil
+		}).
+		AnyTimes()
+
+	return store
+}
+
+// donate creates a transaction through the handler and settles it with the
+// reconciler, as if the donor paid the invoice.
+func donate(t *testing.T, transactionServer *handler.TransactionServer, transactionUsecase usecase.ITransactionUsecase, gateway *client.FakeGateway, store *donationStore, amount float32) string {
+	res, err := transactionServer.CreateTransaction(donorContext(), &pbTransaction.CreateTransactionRequest{
+		PostId:        store.post.PostID.String(),
+		Amount:        amount,
+		AccountNumber: "1234567890",
+		AccountName:   "Donor",
+	})
+	assert.NoError(t, err)
+
+	reconciler := worker.NewReconciler(transactionUsecase, gateway, config.ReconcilerConfig{Interval: time.Minute, BatchSize: 10})
+	assert.NoError(t, reconciler.ReconcileOnce(context.Background()))
+
+	return res.TransactionId
+}
+
+var testDonationLimits = usecase.DonationLimits{MinAmount: model.IDR(10000), MaxAmount: model.IDR(100000000)}
+
+func testConfig() *config.Config {
+	cfg := config.Default()
+	cfg.PublicBaseURL = "http://localhost:8082"
+	cfg.FrontendSuccessURL = "http://localhost:3000/payment/success"
+	cfg.FrontendFailureURL = "http://localhost:3000/payment/failed"
+	cfg.SwaggerHost = "localhost:8082"
+
+	return &cfg
+}
+
+func institutionContext(institutionID uuid.UUID) context.Context {
+	return context.WithValue(context.Background(), middlewares.InstitutionIDKey, institutionID.String())
+}
+
+func (s *donationStore) only(t *testing.T) *model.Transaction {
+	assert.Len(t, s.transactions, 1)
+	for _, transaction := range s.transactions {
+		return transaction
+	}
+	return nil
+}
+
+func donorContext() context.Context {
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("email", "donor@email.com"))
+	return context.WithValue(ctx, middlewares.EmailKey, "donor@email.com")
+}
+
+func TestDonationFlowWithFakeGateway(t *testing.T) {
+	reconcilerConfig := config.ReconcilerConfig{Interval: time.Minute, BatchSize: 10}
+
+	t.Run("success - paid invoice is credited by the reconciler", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransactionRepo := mocks.NewMockITransactionRepository(ctrl)
+		store := newDonationStore(t, mockTransactionRepo)
+		transactionUsecase := usecase.NewTransactionUsecase(mockTransactionRepo, testDonationLimits)
+
+		gateway := client.NewFakeGateway()
+		gateway.Script(client.FakeOutcomePaid)
+
+		transactionServer := handler.NewTransactionHandler(transactionUsecase, nil, gateway, queue.LogEmailPublisher{}, testConfig())
+
+		res, err := transactionServer.CreateTransaction(donorContext(), &pbTransaction.CreateTransactionRequest{
+			PostId:        store.post.PostID.String(),
+			AmountV2:      &pbTransaction.Money{Amount: 5000000, Currency: "IDR"},
+			AccountNumber: "1234567890",
+			AccountName:   "Donor",
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, string(model.PaymentStatusPending), res.Status)
+		assert.Equal(t, int64(5000000), res.AmountV2.Amount)
+		assert.Equal(t, float32(50000), res.Amount)
+		assert.NotEmpty(t, res.PaymentUrl)
+
+		reconciler := worker.NewReconciler(transactionUsecase, gateway, reconcilerConfig)
+		assert.NoError(t, reconciler.ReconcileOnce(context.Background()))
+		assert.NoError(t, reconciler.ReconcileOnce(context.Background()))
+
+		transaction := store.only(t)
+		assert.Equal(t, model.PaymentStatusPaid, transaction.PaymentStatus)
+		assert.Equal(t, "FAKE", transaction.PaymentMethod)
+		assert.Equal(t, model.IDR(50000), store.post.FundAchieved)
+		assert.Len(t, transaction.StatusHistory, 3)
+		assert.Equal(t, model.TransitionSourceReconciler, transaction.StatusHistory[2].Source)
+	})
+
+	t.Run("success - expired invoice is not credited", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransactionRepo := mocks.NewMockITransactionRepository(ctrl)
+		store := newDonationStore(t, mockTransactionRepo)
+		transactionUsecase := usecase.NewTransactionUsecase(mockTransactionRepo, testDonationLimits)
+
+		gateway := client.NewFakeGateway()
+		gateway.Script(client.FakeOutcomeExpired)
+
+		transactionServer := handler.NewTransactionHandler(transactionUsecase, nil, gateway, queue.LogEmailPublisher{}, testConfig())
+
+		_, err := transactionServer.CreateTransaction(donorContext(), &pbTransaction.CreateTransactionRequest{
+			PostId:        store.post.PostID.String(),
+			Amount:        50000,
+			AccountNumber: "1234567890",
+			AccountName:   "Donor",
+		})
+		assert.NoError(t, err)
+
+		reconciler := worker.NewReconciler(transactionUsecase, gateway, reconcilerConfig)
+		assert.NoError(t, reconciler.ReconcileOnce(context.Background()))
+
+		transaction := store.only(t)
+		assert.Equal(t, model.PaymentStatusExpired, transaction.PaymentStatus)
+		assert.Equal(t, model.IDR(0), store.post.FundAchieved)
+	})
+
+	t.Run("success - donations stay open on the last day of the post", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransactionRepo := mocks.NewMockITransactionRepository(ctrl)
+		store := newDonationStore(t, mockTransactionRepo)
+		transactionUsecase := usecase.NewTransactionUsecase(mockTransactionRepo, testDonationLimits)
+		transactionServer := handler.NewTransactionHandler(transactionUsecase, nil, client.NewFakeGateway(), queue.LogEmailPublisher{}, testConfig())
+
+		// institution-service sends date_end as a date, which parses to
+		// midnight at the start of the last day.
+		today := time.Now().UTC()
+		store.post.DateEnd = time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.UTC)
+
+		res, err := transactionServer.CreateTransaction(donorContext(), &pbTransaction.CreateTransactionRequest{
+			PostId:        store.post.PostID.String(),
+			Amount:        50000,
+			AccountNumber: "1234567890",
+			AccountName:   "Donor",
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, string(model.PaymentStatusPending), res.Status)
+
+		store.post.DateEnd = store.post.DateEnd.AddDate(0, 0, -1)
+		_, err = transactionServer.CreateTransaction(donorContext(), &pbTransaction.CreateTransactionRequest{
+			PostId:        store.post.PostID.String(),
+			Amount:        50000,
+			AccountNumber: "1234567890",
+			AccountName:   "Donor",
+		})
+		assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	})
+
+	t.Run("success - capped post charges only the remaining target", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransactionRepo := mocks.NewMockITransactionRepository(ctrl)
+		store := newDonationStore(t, mockTransactionRepo)
+		store.post.FundAchieved = model.IDR(980000)
+		store.post.OverflowPolicy = model.OverflowPolicyCap
+		transactionUsecase := usecase.NewTransactionUsecase(mockTransactionRepo, testDonationLimits)
+		gateway := client.NewFakeGateway()
+		transactionServer := handler.NewTransactionHandler(transactionUsecase, nil, gateway, queue.LogEmailPublisher{}, testConfig())
+
+		res, err := transactionServer.CreateTransaction(donorContext(), &pbTransaction.CreateTransactionRequest{
+			PostId:        store.post.PostID.String(),
+			Amount:        50000,
+			AccountNumber: "1234567890",
+			AccountName:   "Donor",
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, float32(20000), res.Amount)
+
+		invoice, err := gateway.GetInvoice(res.PaymentId)
+		assert.NoError(t, err)
+		assert.Equal(t, float64(20000), invoice.Amount)
+	})
+
+	t.Run("failed - rejecting post refuses an overshooting donation", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransactionRepo := mocks.NewMockITransactionRepository(ctrl)
+		store := newDonationStore(t, mockTransactionRepo)
+		store.post.FundAchieved = model.IDR(980000)
+		store.post.OverflowPolicy = model.OverflowPolicyReject
+		transactionUsecase := usecase.NewTransactionUsecase(mockTransactionRepo, testDonationLimits)
+		transactionServer := handler.NewTransactionHandler(transactionUsecase, nil, client.NewFakeGateway(), queue.LogEmailPublisher{}, testConfig())
+
+		res, err := transactionServer.CreateTransaction(donorContext(), &pbTransaction.CreateTransactionRequest{
+			PostId:        store.post.PostID.String(),
+			Amount:        50000,
+			AccountNumber: "1234567890",
+			AccountName:   "Donor",
+		})
+
+		assert.Nil(t, res)
+		assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+		assert.Empty(t, store.transactions)
+	})
+
+	t.Run("failed - gateway error marks the transaction failed", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransactionRepo := mocks.NewMockITransactionRepository(ctrl)
+		store := newDonationStore(t, mockTransactionRepo)
+		transactionUsecase := usecase.NewTransactionUsecase(mockTransactionRepo, testDonationLimits)
+
+		gateway := client.NewFakeGateway()
+		gateway.Script(client.FakeOutcomeError)
+
+		transactionServer := handler.NewTransactionHandler(transactionUsecase, nil, gateway, queue.LogEmailPublisher{}, testConfig())
+
+		res, err := transactionServer.CreateTransaction(donorContext(), &pbTransaction.CreateTransactionRequest{
+			PostId:        store.post.PostID.String(),
+			Amount:        50000,
+			AccountNumber: "1234567890",
+			AccountName:   "Donor",
+		})
+		assert.Nil(t, res)
+		assert.Equal(t, codes.Internal, status.Code(err))
+
+		transaction := store.only(t)
+		assert.Equal(t, model.PaymentStatusFailed, transaction.PaymentStatus)
+		assert.Equal(t, model.IDR(0), store.post.FundAchieved)
+	})
+}
+
+func TestMultiCurrencyDonationWithFakeGateway(t *testing.T) {
+	usdToIDR := &model.FXRate{FXRateID: uuid.New(), BaseCurrency: "USD", QuoteCurrency: "IDR", Rate: "15500", CreatedAt: time.Now()}
+
+	t.Run("success - USD donation is credited to an IDR post after conversion", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransactionRepo := mocks.NewMockITransactionRepository(ctrl)
+		store := newDonationStore(t, mockTransactionRepo)
+		transactionUsecase := usecase.NewTransactionUsecase(mockTransactionRepo, testDonationLimits)
+		gateway := client.NewFakeGateway()
+		transactionServer := handler.NewTransactionHandler(transactionUsecase, nil, gateway, queue.LogEmailPublisher{}, testConfig())
+
+		mockTransactionRepo.EXPECT().
+			GetLatestFXRate(gomock.Any(), "USD", "IDR").
+			Return(usdToIDR, nil)
+
+		res, err := transactionServer.CreateTransaction(donorContext(), &pbTransaction.CreateTransactionRequest{
+			PostId:        store.post.PostID.String(),
+			AmountV2:      &pbTransaction.Money{Amount: 1000, Currency: "USD"},
+			AccountNumber: "1234567890",
+			AccountName:   "Donor",
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "USD", res.AmountV2.Currency)
+		assert.Equal(t, int64(15500000), res.ConvertedAmount.Amount)
+
+		invoice, err := gateway.GetInvoice(res.PaymentId)
+		assert.NoError(t, err)
+		assert.Equal(t, "USD", invoice.Currency)
+		assert.Equal(t, float64(10), invoice.Amount)
+
+		reconciler := worker.NewReconciler(transactionUsecase, gateway, config.ReconcilerConfig{Interval: time.Minute, BatchSize: 10})
+		assert.NoError(t, reconciler.ReconcileOnce(context.Background()))
+
+		transaction := store.only(t)
+		assert.Equal(t, model.PaymentStatusPaid, transaction.PaymentStatus)
+		assert.Equal(t, model.IDR(155000), store.post.FundAchieved)
+
+		refunded, err := transactionServer.RefundTransaction(institutionContext(store.post.InstitutionID), &pbTransaction.RefundTransactionRequest{
+			TransactionId: res.TransactionId,
+			AmountV2:      &pbTransaction.Money{Amount: 500, Currency: "USD"},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "15500", refunded.FxRate.Rate)
+		assert.Equal(t, model.IDR(77500), store.post.FundAchieved)
+	})
+
+	t.Run("failed - no rate for the donor's currency", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransactionRepo := mocks.NewMockITransactionRepository(ctrl)
+		store := newDonationStore(t, mockTransactionRepo)
+		transactionUsecase := usecase.NewTransactionUsecase(mockTransactionRepo, testDonationLimits)
+		transactionServer := handler.NewTransactionHandler(transactionUsecase, nil, client.NewFakeGateway(), queue.LogEmailPublisher{}, testConfig())
+
+		mockTransactionRepo.EXPECT().
+			GetLatestFXRate(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(nil, gorm.ErrRecordNotFound).
+			Times(2)
+
+		res, err := transactionServer.CreateTransaction(donorContext(), &pbTransaction.CreateTransactionRequest{
+			PostId:        store.post.PostID.String(),
+			AmountV2:      &pbTransaction.Money{Amount: 1000, Currency: "SGD"},
+			AccountNumber: "1234567890",
+			AccountName:   "Donor",
+		})
+
+		assert.Nil(t, res)
+		assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+		assert.Empty(t, store.transactions)
+	})
+}
+
+func TestFXRateAdmin(t *testing.T) {
+	adminContext := func(email string) context.Context {
+		return context.WithValue(context.Background(), middlewares.EmailKey, email)
+	}
+
+	t.Run("success - admin sets a rate", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransactionRepo := mocks.NewMockITransactionRepository(ctrl)
+		transactionUsecase := usecase.NewTransactionUsecase(mockTransactionRepo, testDonationLimits)
+		cfg := testConfig()
+		cfg.AdminEmails = []string{"admin@edu-connect.id"}
+		transactionServer := handler.NewTransactionHandler(transactionUsecase, nil, client.NewFakeGateway(), queue.LogEmailPublisher{}, cfg)
+
+		mockTransactionRepo.EXPECT().
+			CreateFXRate(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, rate *model.FXRate) (*model.FXRate, error) {
+				assert.Equal(t, "admin@edu-connect.id", rate.SetBy)
+				rate.FXRateID = uuid.New()
+				rate.CreatedAt = time.Now()
+				return rate, nil
+			})
+
+		res, err := transactionServer.SetFXRate(adminContext("admin@edu-connect.id"), &pbTransaction.SetFXRateRequest{
+			BaseCurrency:  "SGD",
+			QuoteCurrency: "IDR",
+			Rate:          "11500",
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "SGD", res.BaseCurrency)
+		assert.Equal(t, "11500", res.Rate)
+	})
+
+	t.Run("failed - donors cannot set rates", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		transactionUsecase := usecase.NewTransactionUsecase(mocks.NewMockITransactionRepository(ctrl), testDonationLimits)
+		transactionServer := handler.NewTransactionHandler(transactionUsecase, nil, client.NewFakeGateway(), queue.LogEmailPublisher{}, testConfig())
+
+		res, err := transactionServer.SetFXRate(adminContext("donor@email.com"), &pbTransaction.SetFXRateRequest{
+			BaseCurrency:  "SGD",
+			QuoteCurrency: "IDR",
+			Rate:          "11500",
+		})
+
+		assert.Nil(t, res)
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	})
+}
+
+func TestTransactionQueries(t *testing.T) {
+	t.Run("success - my transactions parse filters", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransactionUsecase := mocks.NewMockITransactionUsecase(ctrl)
+		transactionServer := handler.NewTransactionHandler(mockTransactionUsecase, nil, client.NewFakeGateway(), queue.LogEmailPublisher{}, testConfig())
+
+		cursor := primitive.NewObjectID()
+		transaction := model.Transaction{
+			TransactionID: primitive.NewObjectID(),
+			UserEmail:     "donor@email.com",
+			PaymentStatus: model.PaymentStatusPaid,
+			Amount:        model.IDR(50000),
+			CreatedAt:     time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC),
+		}
+
+		mockTransactionUsecase.EXPECT().
+			GetUserTransactions(gomock.Any(), "donor@email.com", gomock.Any()).
+			DoAndReturn(func(ctx context.Context, email string, filter model.TransactionFilter) (*model.TransactionPage, error) {
+				assert.Equal(t, model.PaymentStatusPaid, filter.Status)
+				assert.Equal(t, "2025-03-01", filter.DateFrom.Format("2006-01-02"))
+				assert.Equal(t, "2025-04-01", filter.DateTo.Format("2006-01-02"))
+				assert.Equal(t, cursor, filter.Cursor)
+				assert.Equal(t, 10, filter.Limit)
+				return &model.TransactionPage{Transactions: []model.Transaction{transaction}, NextCursor: transaction.TransactionID.Hex()}, nil
+			})
+
+		res, err := transactionServer.GetMyTransactions(donorContext(), &pbTransaction.GetMyTransactionsRequest{
+			Status:   "paid",
+			DateFrom: "2025-03-01",
+			DateTo:   "2025-03-31",
+			Cursor:   cursor.Hex(),
+			Limit:    10,
+		})
+
+		assert.NoError(t, err)
+		assert.Len(t, res.Transactions, 1)
+		assert.Equal(t, transaction.TransactionID.Hex(), res.Transactions[0].TransactionId)
+		assert.Equal(t, "2025-03-01T10:00:00Z", res.Transactions[0].CreatedAt)
+		assert.Equal(t, transaction.TransactionID.Hex(), res.NextCursor)
+	})
+
+	t.Run("failed - my transactions with invalid cursor", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransactionUsecase := mocks.NewMockITransactionUsecase(ctrl)
+		transactionServer := handler.NewTransactionHandler(mockTransactionUsecase, nil, client.NewFakeGateway(), queue.LogEmailPublisher{}, testConfig())
+
+		res, err := transactionServer.GetMyTransactions(donorContext(), &pbTransaction.GetMyTransactionsRequest{Cursor: "nope"})
+
+		assert.Nil(t, res)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("failed - transaction of another user is not found", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransactionUsecase := mocks.NewMockITransactionUsecase(ctrl)
+		transactionServer := handler.NewTransactionHandler(mockTransactionUsecase, nil, client.NewFakeGateway(), queue.LogEmailPublisher{}, testConfig())
+
+		mockTransactionUsecase.EXPECT().
+			GetUserTransactionByID(gomock.Any(), "donor@email.com", gomock.Any()).
+			Return(nil, usecase.ErrTransactionNotFound)
+
+		res, err := transactionServer.GetTransactionByID(donorContext(), &pbTransaction.GetTransactionByIDRequest{
+			TransactionId: primitive.NewObjectID().Hex(),
+		})
+
+		assert.Nil(t, res)
+		assert.Equal(t, codes.NotFound, status.Code(err))
+	})
+
+	t.Run("failed - donors cannot list post transactions", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransactionUsecase := mocks.NewMockITransactionUsecase(ctrl)
+		transactionServer := handler.NewTransactionHandler(mockTransactionUsecase, nil, client.NewFakeGateway(), queue.LogEmailPublisher{}, testConfig())
+
+		res, err := transactionServer.GetPostTransactions(donorContext(), &pbTransaction.GetPostTransactionsRequest{
+			PostId: uuid.New().String(),
+		})
+
+		assert.Nil(t, res)
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	})
+
+	t.Run("failed - institution cannot list another institution's post", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransactionUsecase := mocks.NewMockITransactionUsecase(ctrl)
+		transactionServer := handler.NewTransactionHandler(mockTransactionUsecase, nil, client.NewFakeGateway(), queue.LogEmailPublisher{}, testConfig())
+
+		institutionID := uuid.New()
+		mockTransactionUsecase.EXPECT().
+			GetPostTransactions(gomock.Any(), institutionID, gomock.Any()).
+			Return(nil, usecase.ErrPostAccessDenied)
+
+		ctx := context.WithValue(context.Background(), middlewares.InstitutionIDKey, institutionID.String())
+		res, err := transactionServer.GetPostTransactions(ctx, &pbTransaction.GetPostTransactionsRequest{
+			PostId: uuid.New().String(),
+		})
+
+		assert.Nil(t, res)
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	})
+}
+
+func TestRefundWithFakeGateway(t *testing.T) {
+	t.Run("success - partial then full refund", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransactionRepo := mocks.NewMockITransactionRepository(ctrl)
+		mockEmailPublisher := mocks.NewMockIEmailPublisher(ctrl)
+		store := newDonationStore(t, mockTransactionRepo)
+		transactionUsecase := usecase.NewTransactionUsecase(mockTransactionRepo, testDonationLimits)
+		gateway := client.NewFakeGateway()
+		transactionServer := handler.NewTransactionHandler(transactionUsecase, nil, gateway, mockEmailPublisher, testConfig())
+
+		transactionID := donate(t, transactionServer, transactionUsecase, gateway, store, 50000)
+		assert.Equal(t, model.IDR(50000), store.post.FundAchieved)
+
+		mockEmailPublisher.EXPECT().
+			PublishRefundNotification(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(transaction *model.Transaction, refund model.Refund) error {
+				assert.Equal(t, "donor@email.com", transaction.UserEmail)
+				return nil
+			}).
+			Times(2)
+
+		ctx := institutionContext(store.post.InstitutionID)
+		res, err := transactionServer.RefundTransaction(ctx, &pbTransaction.RefundTransactionRequest{
+			TransactionId: transactionID,
+			Amount:        20000,
+			Reason:        "Campaign scope reduced",
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, string(model.PaymentStatusPaid), res.Status)
+		assert.Equal(t, float32(20000), res.RefundedAmount)
+		assert.Equal(t, int64(2000000), res.RefundedAmountV2.Amount)
+		assert.Equal(t, model.IDR(30000), store.post.FundAchieved)
+
+		res, err = transactionServer.RefundTransaction(ctx, &pbTransaction.RefundTransactionRequest{
+			TransactionId: transactionID,
+			Reason:        "Campaign cancelled",
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, string(model.PaymentStatusRefunded), res.Status)
+		assert.Equal(t, float32(50000), res.RefundedAmount)
+		assert.Equal(t, model.IDR(0), store.post.FundAchieved)
+
+		_, err = transactionServer.RefundTransaction(ctx, &pbTransaction.RefundTransactionRequest{
+			TransactionId: transactionID,
+		})
+		assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	})
+
+	t.Run("success - refund every paid transaction of a post", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransactionRepo := mocks.NewMockITransactionRepository(ctrl)
+		mockEmailPublisher := mocks.NewMockIEmailPublisher(ctrl)
+		store := newDonationStore(t, mockTransactionRepo)
+		transactionUsecase := usecase.NewTransactionUsecase(mockTransactionRepo, testDonationLimits)
+		gateway := client.NewFakeGateway()
+		transactionServer := handler.NewTransactionHandler(transactionUsecase, nil, gateway, mockEmailPublisher, testConfig())
+
+		donate(t, transactionServer, transactionUsecase, gateway, store, 50000)
+		donate(t, transactionServer, transactionUsecase, gateway, store, 25000)
+		assert.Equal(t, model.IDR(75000), store.post.FundAchieved)
+
+		mockEmailPublisher.EXPECT().
+			PublishRefundNotification(gomock.Any(), gomock.Any()).
+			Return(errors.New("broker down")).
+			Times(2)
+
+		res, err := transactionServer.RefundPostTransactions(institutionContext(store.post.InstitutionID), &pbTransaction.RefundPostTransactionsRequest{
+			PostId: store.post.PostID.String(),
+			Reason: "Campaign cancelled",
+		})
+
+		assert.NoError(t, err)
+		assert.Len(t, res.Refunded, 2)
+		assert.Empty(t, res.Failed)
+		assert.Equal(t, model.IDR(0), store.post.FundAchieved)
+	})
+
+	t.Run("success - refund a donation to a deleted post", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransactionRepo := mocks.NewMockITransactionRepository(ctrl)
+		mockEmailPublisher := mocks.NewMockIEmailPublisher(ctrl)
+		store := newDonationStore(t, mockTransactionRepo)
+		transactionUsecase := usecase.NewTransactionUsecase(mockTransactionRepo, testDonationLimits)
+		gateway := client.NewFakeGateway()
+		transactionServer := handler.NewTransactionHandler(transactionUsecase, nil, gateway, mockEmailPublisher, testConfig())
+
+		transactionID := donate(t, transactionServer, transactionUsecase, gateway, store, 50000)
+		donate(t, transactionServer, transactionUsecase, gateway, store, 25000)
+		store.post.Deleted = true
+
+		mockEmailPublisher.EXPECT().
+			PublishRefundNotification(gomock.Any(), gomock.Any()).
+			Return(nil).
+			Times(2)
+
+		ctx := institutionContext(store.post.InstitutionID)
+		res, err := transactionServer.RefundTransaction(ctx, &pbTransaction.RefundTransactionRequest{
+			TransactionId: transactionID,
+			Reason:        "Campaign deleted",
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, string(model.PaymentStatusRefunded), res.Status)
+
+		refunded, err := transactionServer.RefundPostTransactions(ctx, &pbTransaction.RefundPostTransactionsRequest{
+			PostId: store.post.PostID.String(),
+			Reason: "Campaign deleted",
+		})
+		assert.NoError(t, err)
+		assert.Len(t, refunded.Refunded, 1)
+		assert.Empty(t, refunded.Failed)
+		assert.Equal(t, model.IDR(0), store.post.FundAchieved)
+
+		_, err = transactionServer.CreateTransaction(donorContext(), &pbTransaction.CreateTransactionRequest{
+			PostId:        store.post.PostID.String(),
+			Amount:        50000,
+			AccountNumber: "1234567890",
+			AccountName:   "Donor",
+		})
+		assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	})
+
+	t.Run("success - refund the gateway rejects is kept as failed and can be retried", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransactionRepo := mocks.NewMockITransactionRepository(ctrl)
+		mockEmailPublisher := mocks.NewMockIEmailPublisher(ctrl)
+		store := newDonationStore(t, mockTransactionRepo)
+		transactionUsecase := usecase.NewTransactionUsecase(mockTransactionRepo, testDonationLimits)
+		gateway := client.NewFakeGateway()
+		transactionServer := handler.NewTransactionHandler(transactionUsecase, nil, gateway, mockEmailPublisher, testConfig())
+
+		transactionID := donate(t, transactionServer, transactionUsecase, gateway, store, 50000)
+		objectID, _ := primitive.ObjectIDFromHex(transactionID)
+		stored := store.transactions[objectID]
+		invoiceID := stored.PaymentID
+		stored.PaymentID = "unknown-invoice"
+
+		ctx := institutionContext(store.post.InstitutionID)
+		_, err := transactionServer.RefundTransaction(ctx, &pbTransaction.RefundTransactionRequest{
+			TransactionId: transactionID,
+			Reason:        "Campaign cancelled",
+		})
+		assert.Equal(t, codes.Internal, status.Code(err))
+		assert.Len(t, stored.Refunds, 1)
+		assert.Equal(t, model.RefundStatusFailed, stored.Refunds[0].Status)
+		assert.Equal(t, transactionID+"-refund-1", stored.Refunds[0].ReferenceID)
+		assert.Equal(t, model.IDR(50000), store.post.FundAchieved)
+
+		stored.PaymentID = invoiceID
+		mockEmailPublisher.EXPECT().PublishRefundNotification(gomock.Any(), gomock.Any()).Return(nil)
+
+		res, err := transactionServer.RefundTransaction(ctx, &pbTransaction.RefundTransactionRequest{
+			TransactionId: transactionID,
+			Reason:        "Campaign cancelled",
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, string(model.PaymentStatusRefunded), res.Status)
+		assert.Len(t, stored.Refunds, 2)
+		assert.Equal(t, model.RefundStatusSucceeded, stored.Refunds[1].Status)
+		assert.Equal(t, transactionID+"-refund-2", stored.Refunds[1].ReferenceID)
+		assert.NotEmpty(t, stored.Refunds[1].RefundID)
+		assert.Equal(t, model.IDR(0), store.post.FundAchieved)
+	})
+
+	t.Run("failed - another institution cannot refund", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransactionRepo := mocks.NewMockITransactionRepository(ctrl)
+		mockEmailPublisher := mocks.NewMockIEmailPublisher(ctrl)
+		store := newDonationStore(t, mockTransactionRepo)
+		transactionUsecase := usecase.NewTransactionUsecase(mockTransactionRepo, testDonationLimits)
+		gateway := client.NewFakeGateway()
+		transactionServer := handler.NewTransactionHandler(transactionUsecase, nil, gateway, mockEmailPublisher, testConfig())
+
+		transactionID := donate(t, transactionServer, transactionUsecase, gateway, store, 50000)
+
+		res, err := transactionServer.RefundTransaction(institutionContext(uuid.New()), &pbTransaction.RefundTransactionRequest{
+			TransactionId: transactionID,
+		})
+
+		assert.Nil(t, res)
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+		assert.Equal(t, model.IDR(50000), store.post.FundAchieved)
+	})
+
+	t.Run("failed - donors cannot refund", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransactionUsecase := mocks.NewMockITransactionUsecase(ctrl)
+		transactionServer := handler.NewTransactionHandler(mockTransactionUsecase, nil, client.NewFakeGateway(), queue.LogEmailPublisher{}, testConfig())
+
+		res, err := transactionServer.RefundTransaction(donorContext(), &pbTransaction.RefundTransactionRequest{
+			TransactionId: primitive.NewObjectID().Hex(),
+		})
+
+		assert.Nil(t, res)
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	})
+}
+
+func TestRetryPaymentWithFakeGateway(t *testing.T) {
+	t.Run("success - expired donation is paid on a fresh invoice", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransactionRepo := mocks.NewMockITransactionRepository(ctrl)
+		store := newDonationStore(t, mockTransactionRepo)
+		transactionUsecase := usecase.NewTransactionUsecase(mockTransactionRepo, testDonationLimits)
+		gateway := client.NewFakeGateway()
+		gateway.Script(client.FakeOutcomeExpired)
+		transactionServer := handler.NewTransactionHandler(transactionUsecase, nil, gateway, queue.LogEmailPublisher{}, testConfig())
+		reconciler := worker.NewReconciler(transactionUsecase, gateway, config.ReconcilerConfig{Interval: time.Minute, BatchSize: 10})
+
+		created, err := transactionServer.CreateTransaction(donorContext(), &pbTransaction.CreateTransactionRequest{
+			PostId:        store.post.PostID.String(),
+			Amount:        50000,
+			AccountNumber: "1234567890",
+			AccountName:   "Donor",
+		})
+		assert.NoError(t, err)
+
+		assert.NoError(t, reconciler.ReconcileOnce(context.Background()))
+		transactionID, _ := primitive.ObjectIDFromHex(created.TransactionId)
+		assert.Equal(t, model.PaymentStatusExpired, store.transactions[transactionID].PaymentStatus)
+
+		retried, err := transactionServer.RetryPayment(donorContext(), &pbTransaction.RetryPaymentRequest{
+			TransactionId: created.TransactionId,
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, created.TransactionId, retried.TransactionId)
+		assert.NotEqual(t, created.PaymentId, retried.PaymentId)
+		assert.Equal(t, string(model.PaymentStatusPending), retried.Status)
+
+		assert.NoError(t, reconciler.ReconcileOnce(context.Background()))
+
+		assert.Len(t, store.transactions, 1)
+		stored := store.transactions[transactionID]
+		assert.Equal(t, model.PaymentStatusPaid, stored.PaymentStatus)
+		assert.Equal(t, retried.PaymentId, stored.PaymentID)
+		assert.Equal(t, []string{created.PaymentId}, stored.PreviousPaymentIDs)
+		assert.False(t, stored.ExpiresAt.IsZero())
+		assert.Equal(t, model.IDR(50000), store.post.FundAchieved)
+	})
+
+	t.Run("success - pending donation with a lapsed invoice is expired first", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransactionRepo := mocks.NewMockITransactionRepository(ctrl)
+		store := newDonationStore(t, mockTransactionRepo)
+		transactionUsecase := usecase.NewTransactionUsecase(mockTransactionRepo, testDonationLimits)
+		gateway := client.NewFakeGateway()
+		gateway.Script(client.FakeOutcomePending)
+		transactionServer := handler.NewTransactionHandler(transactionUsecase, nil, gateway, queue.LogEmailPublisher{}, testConfig())
+
+		created, err := transactionServer.CreateTransaction(donorContext(), &pbTransaction.CreateTransactionRequest{
+			PostId:        store.post.PostID.String(),
+			Amount:        50000,
+			AccountNumber: "1234567890",
+			AccountName:   "Donor",
+		})
+		assert.NoError(t, err)
+
+		_, err = transactionServer.RetryPayment(donorContext(), &pbTransaction.RetryPaymentRequest{
+			TransactionId: created.TransactionId,
+		})
+		assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+		_, err = gateway.ExpireInvoice(created.PaymentId)
+		assert.NoError(t, err)
+
+		retried, err := transactionServer.RetryPayment(donorContext(), &pbTransaction.RetryPaymentRequest{
+			TransactionId: created.TransactionId,
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, string(model.PaymentStatusPending), retried.Status)
+
+		transactionID, _ := primitive.ObjectIDFromHex(created.TransactionId)
+		history := store.transactions[transactionID].StatusHistory
+		assert.Equal(t, model.PaymentStatusExpired, history[len(history)-2].To)
+		assert.Equal(t, model.PaymentStatusPending, history[len(history)-1].To)
+	})
+
+	t.Run("failed - paid donation cannot be retried", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransactionRepo := mocks.NewMockITransactionRepository(ctrl)
+		store := newDonationStore(t, mockTransactionRepo)
+		transactionUsecase := usecase.NewTransactionUsecase(mockTransactionRepo, testDonationLimits)
+		gateway := client.NewFakeGateway()
+		transactionServer := handler.NewTransactionHandler(transactionUsecase, nil, gateway, queue.LogEmailPublisher{}, testConfig())
+
+		transactionID := donate(t, transactionServer, transactionUsecase, gateway, store, 50000)
+
+		res, err := transactionServer.RetryPayment(donorContext(), &pbTransaction.RetryPaymentRequest{
+			TransactionId: transactionID,
+		})
+
+		assert.Nil(t, res)
+		assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	})
+
+	t.Run("failed - another donor's transaction", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransactionRepo := mocks.NewMockITransactionRepository(ctrl)
+		store := newDonationStore(t, mockTransactionRepo)
+		transactionUsecase := usecase.NewTransactionUsecase(mockTransactionRepo, testDonationLimits)
+		gateway := client.NewFakeGateway()
+		gateway.Script(client.FakeOutcomeExpired)
+		transactionServer := handler.NewTransactionHandler(transactionUsecase, nil, gateway, queue.LogEmailPublisher{}, testConfig())
+
+		transactionID := donate(t, transactionServer, transactionUsecase, gateway, store, 50000)
+
+		ctx := context.WithValue(context.Background(), middlewares.EmailKey, "other@email.com")
+		res, err := transactionServer.RetryPayment(ctx, &pbTransaction.RetryPaymentRequest{
+			TransactionId: transactionID,
+		})
+
+		assert.Nil(t, res)
+		assert.Equal(t, codes.NotFound, status.Code(err))
+	})
+}
+
+func TestSuccessRedirect(t *testing.T) {
+	redirect := func(callbackHandler *handler.PaymentCallbackHandler, transactionID string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		callbackHandler.HandleSuccessRedirect(recorder, httptest.NewRequest(http.MethodGet, config.SuccessRedirectPath+"?external_id="+transactionID, nil))
+		return recorder
+	}
+
+	t.Run("success - unpaid invoice is only redirected", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransactionRepo := mocks.NewMockITransactionRepository(ctrl)
+		store := newDonationStore(t, mockTransactionRepo)
+		transactionUsecase := usecase.NewTransactionUsecase(mockTransactionRepo, testDonationLimits)
+
+		gateway := client.NewFakeGateway()
+		gateway.Script(client.FakeOutcomePending)
+
+		cfg := testConfig()
+		transactionServer := handler.NewTransactionHandler(transactionUsecase, nil, gateway, queue.LogEmailPublisher{}, cfg)
+		transactionID := donate(t, transactionServer, transactionUsecase, gateway, store, 50000)
+
+		callbackHandler := handler.NewPaymentCallbackHandler(transactionUsecase, nil, gateway, cfg)
+		recorder := redirect(callbackHandler, transactionID)
+
+		assert.Equal(t, http.StatusSeeOther, recorder.Code)
+		assert.Equal(t, cfg.FrontendSuccessRedirect(transactionID), recorder.Header().Get("Location"))
+		assert.Equal(t, model.PaymentStatusPending, store.only(t).PaymentStatus)
+		assert.Equal(t, model.IDR(0), store.post.FundAchieved)
+	})
+
+	t.Run("success - paid invoice is settled before redirecting", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransactionRepo := mocks.NewMockITransactionRepository(ctrl)
+		store := newDonationStore(t, mockTransactionRepo)
+		transactionUsecase := usecase.NewTransactionUsecase(mockTransactionRepo, testDonationLimits)
+
+		gateway := client.NewFakeGateway()
+		gateway.Script(client.FakeOutcomePending)
+
+		cfg := testConfig()
+		transactionServer := handler.NewTransactionHandler(transactionUsecase, nil, gateway, queue.LogEmailPublisher{}, cfg)
+		transactionID := donate(t, transactionServer, transactionUsecase, gateway, store, 50000)
+		assert.NoError(t, gateway.SetInvoiceStatus(store.only(t).PaymentID, client.FakeOutcomePaid))
+
+		callbackHandler := handler.NewPaymentCallbackHandler(transactionUsecase, nil, gateway, cfg)
+		recorder := redirect(callbackHandler, transactionID)
+
+		transaction := store.only(t)
+		assert.Equal(t, http.StatusSeeOther, recorder.Code)
+		assert.Equal(t, model.PaymentStatusPaid, transaction.PaymentStatus)
+		assert.Equal(t, model.TransitionSourceRedirect, transaction.StatusHistory[len(transaction.StatusHistory)-1].Source)
+		assert.Equal(t, model.IDR(50000), store.post.FundAchieved)
+	})
+}
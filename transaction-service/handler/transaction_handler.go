@@ -2,19 +2,23 @@ package handler
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"strings"
 	"time"
 
 	"transaction-service/client"
+	"transaction-service/config"
 	"transaction-service/middlewares"
 	"transaction-service/model"
-	pbFuncCollect "transaction-service/pb/fund_collect"
 	pbTransaction "transaction-service/pb/transaction"
 	pbUser "transaction-service/pb/user"
+	"transaction-service/queue"
 	"transaction-service/usecase"
 
 	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
@@ -22,26 +26,38 @@ import (
 
 type ITransactionHandler interface {
 	CreateTransaction(ctx context.Context, req *pbTransaction.CreateTransactionRequest) (*pbTransaction.CreateTransactionResponse, error)
+	GetTransactionByID(ctx context.Context, req *pbTransaction.GetTransactionByIDRequest) (*pbTransaction.TransactionResponse, error)
+	GetMyTransactions(ctx context.Context, req *pbTransaction.GetMyTransactionsRequest) (*pbTransaction.GetTransactionsResponse, error)
+	GetPostTransactions(ctx context.Context, req *pbTransaction.GetPostTransactionsRequest) (*pbTransaction.GetTransactionsResponse, error)
+	RefundTransaction(ctx context.Context, req *pbTransaction.RefundTransactionRequest) (*pbTransaction.TransactionResponse, error)
+	RefundPostTransactions(ctx context.Context, req *pbTransaction.RefundPostTransactionsRequest) (*pbTransaction.RefundPostTransactionsResponse, error)
+	RetryPayment(ctx context.Context, req *pbTransaction.RetryPaymentRequest) (*pbTransaction.CreateTransactionResponse, error)
+	SetFXRate(ctx context.Context, req *pbTransaction.SetFXRateRequest) (*pbTransaction.FXRate, error)
+	GetFXRates(ctx context.Context, req *pbTransaction.GetFXRatesRequest) (*pbTransaction.GetFXRatesResponse, error)
 }
 
 type TransactionServer struct {
 	pbTransaction.UnimplementedTransactionServiceServer
 	transactionUsecase usecase.ITransactionUsecase
 	userClient         pbUser.UserServiceClient
-	fundCollectClient  pbFuncCollect.FundCollectServiceClient
-	xenditClient       *client.XenditClient
+	paymentGateway     client.PaymentGateway
+	emailPublisher     queue.IEmailPublisher
+	config             *config.Config
 }
 
 func NewTransactionHandler(
 	transactionUsecase usecase.ITransactionUsecase,
 	userClient pbUser.UserServiceClient,
-	fundCollectClient pbFuncCollect.FundCollectServiceClient,
+	paymentGateway client.PaymentGateway,
+	emailPublisher queue.IEmailPublisher,
+	config *config.Config,
 ) *TransactionServer {
 	return &TransactionServer{
 		transactionUsecase: transactionUsecase,
 		userClient:         userClient,
-		fundCollectClient:  fundCollectClient,
-		xenditClient:       client.NewXenditClient(),
+		paymentGateway:     paymentGateway,
+		emailPublisher:     emailPublisher,
+		config:             config,
 	}
 }
 
@@ -61,35 +77,60 @@ func (s *TransactionServer) CreateTransaction(ctx context.Context, req *pbTransa
 		return nil, status.Errorf(codes.Internal, "failed to get authenticated user email from context")
 	}
 
-	getUser, err := s.transactionUsecase.GetUserByEmail(ctx, email)
-	if err != nil {
-		log.Printf("Failed to get user by email: %v", err)
+	// The user ID comes with the token or the gateway metadata when they carry
+	// one; otherwise user-service resolves it from the email.
+	authenticatedUserID, _ := ctx.Value(middlewares.UserIDKey).(string)
+	if authenticatedUserID == "" {
+		user, err := s.transactionUsecase.GetUserByEmail(ctx, email)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to get user by email: %v", err)
+		}
+		authenticatedUserID = user.UserID
 	}
 
-	authenticatedUserID := getUser.UserID
-
 	postID, err := uuid.Parse(req.PostId)
 	if err != nil {
 		return nil, status.Errorf(codes.InvalidArgument, "invalid post ID format: %v", err)
 	}
 
 	post, err := s.transactionUsecase.GetPostByID(ctx, postID)
+	if status.Code(err) == codes.NotFound {
+		return nil, status.Errorf(codes.NotFound, "post not found")
+	}
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to get post: %v", err)
 	}
 
-	if post.DateEnd.Before(time.Now()) {
+	if !post.IsPublished() {
+		return nil, status.Errorf(codes.FailedPrecondition, "this fundraising is not published, cannot accept new transactions")
+	}
+	if post.HasEnded(time.Now()) {
 		return nil, status.Errorf(codes.FailedPrecondition, "this fundraising has ended, cannot accept new transactions")
 	}
 
+	quote, err := s.transactionUsecase.QuoteDonation(ctx, post, moneyFromRequest(req.AmountV2, req.Amount))
+	if errors.Is(err, usecase.ErrUnsupportedCurrency) {
+		return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "%v", err)
+	}
+
 	transaction_model := &model.Transaction{
-		UserID:        authenticatedUserID,
-		PostID:        req.PostId,
-		UserEmail:     email,
-		PaymentID:     "pending",
-		Amount:        float64(req.Amount),
-		AccountNumber: req.AccountNumber,
-		AccountName:   req.AccountName,
+		UserID:              authenticatedUserID,
+		PostID:              req.PostId,
+		PostTitle:           post.Title,
+		InstitutionID:       post.InstitutionID.String(),
+		UserEmail:           email,
+		PaymentID:           "pending",
+		Amount:              quote.Amount,
+		ConvertedAmount:     quote.ConvertedAmount,
+		FXRate:              quote.FXRate,
+		AccountNumber:       req.AccountNumber,
+		AccountName:         req.AccountName,
+		DonorNameVisibility: model.DonorNameVisibility(strings.ToUpper(req.DonorNameVisibility)),
+		DonorName:           req.DisplayName,
+		Message:             req.Message,
 	}
 
 	transaction, err := s.transactionUsecase.CreateTransaction(ctx, transaction_model)
@@ -99,39 +140,533 @@ func (s *TransactionServer) CreateTransaction(ctx context.Context, req *pbTransa
 
 	transactionIDStr := transaction.TransactionID.Hex()
 
-	invoiceReq := client.CreateInvoiceRequest{
-		ExternalID:         transactionIDStr,
-		Amount:             transaction.Amount,
-		PayerEmail:         email,
-		Description:        fmt.Sprintf("Fund contribution for %s", post.Title),
-		CustomerName:       "anonymous",
-		InvoiceDuration:    86400, // 24 hours
-		SuccessRedirectURL: fmt.Sprintf("https://transaction-service-1011483964797.asia-southeast2.run.app/payment/success?external_id=%s", transactionIDStr),
-		FailureRedirectURL: "https://edu-connect.example.com/payment/failed",
-		CallbackURL:        "https://edu-connect.example.com/api/payment/callback",
-	}
-
-	invoice, err := s.xenditClient.CreateInvoice(invoiceReq)
+	invoice, err := s.paymentGateway.CreateInvoice(s.newInvoiceRequest(transaction, post))
 	if err != nil {
+		if _, transitionErr := s.transactionUsecase.TransitionTransaction(ctx, transaction, model.PaymentStatusFailed, model.TransitionSourceAPI); transitionErr != nil {
+			log.Printf("Failed to mark transaction %s as failed: %v", transactionIDStr, transitionErr)
+		}
 		return nil, status.Errorf(codes.Internal, "failed to create payment invoice: %v", err)
 	}
 
 	transaction.PaymentID = invoice.ID
 	transaction.PaymentURL = invoice.InvoiceURL
-	transaction.PaymentStatus = "PENDING"
+	transaction.ExpiresAt = invoice.ExpiryDate
+
+	_, err = s.transactionUsecase.TransitionTransaction(ctx, transaction, model.PaymentStatusPending, model.TransitionSourceAPI)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to update transaction with payment details: %v", err)
+	}
+
+	return &pbTransaction.CreateTransactionResponse{
+		TransactionId:   transaction.TransactionID.Hex(),
+		PaymentId:       transaction.PaymentID,
+		Amount:          float32(transaction.Amount.Major()),
+		AmountV2:        toMoneyResponse(transaction.Amount),
+		ConvertedAmount: toMoneyResponse(transaction.CampaignAmount()),
+		AccountNumber:   transaction.AccountNumber,
+		AccountName:     transaction.AccountName,
+		PaymentUrl:      invoice.InvoiceURL,
+		Status:          string(transaction.PaymentStatus),
+		DonorName:       transaction.PublicDonorName(),
+		Message:         transaction.Message,
+	}, nil
+}
+
+func (s *TransactionServer) GetTransactionByID(ctx context.Context, req *pbTransaction.GetTransactionByIDRequest) (*pbTransaction.TransactionResponse, error) {
+	email, ok := ctx.Value(middlewares.EmailKey).(string)
+	if !ok || email == "" {
+		return nil, status.Errorf(codes.Unauthenticated, "failed to get authenticated user email from context")
+	}
+
+	transactionID, err := primitive.ObjectIDFromHex(req.TransactionId)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid transaction ID format: %v", err)
+	}
+
+	transaction, err := s.transactionUsecase.GetUserTransactionByID(ctx, email, transactionID)
+	if errors.Is(err, usecase.ErrTransactionNotFound) {
+		return nil, status.Errorf(codes.NotFound, "transaction not found")
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get transaction: %v", err)
+	}
+
+	return toTransactionResponse(transaction), nil
+}
+
+func (s *TransactionServer) GetMyTransactions(ctx context.Context, req *pbTransaction.GetMyTransactionsRequest) (*pbTransaction.GetTransactionsResponse, error) {
+	email, ok := ctx.Value(middlewares.EmailKey).(string)
+	if !ok || email == "" {
+		return nil, status.Errorf(codes.Unauthenticated, "failed to get authenticated user email from context")
+	}
+
+	filter, err := parseTransactionFilter(req.Status, req.DateFrom, req.DateTo, req.Cursor, req.Limit)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+	}
+
+	page, err := s.transactionUsecase.GetUserTransactions(ctx, email, filter)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "failed to get transactions: %v", err)
+	}
+
+	return toTransactionsResponse(page), nil
+}
+
+func (s *TransactionServer) GetPostTransactions(ctx context.Context, req *pbTransaction.GetPostTransactionsRequest) (*pbTransaction.GetTransactionsResponse, error) {
+	institutionID, err := authenticatedInstitutionID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	filter, err := parseTransactionFilter(req.Status, req.DateFrom, req.DateTo, req.Cursor, req.Limit)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+	}
+	filter.PostID = req.PostId
+
+	page, err := s.transactionUsecase.GetPostTransactions(ctx, institutionID, filter)
+	switch {
+	case errors.Is(err, usecase.ErrPostNotFound):
+		return nil, status.Errorf(codes.NotFound, "post not found")
+	case errors.Is(err, usecase.ErrPostAccessDenied):
+		return nil, status.Errorf(codes.PermissionDenied, "%v", err)
+	case err != nil:
+		return nil, status.Errorf(codes.InvalidArgument, "failed to get transactions: %v", err)
+	}
+
+	return toTransactionsResponse(page), nil
+}
+
+func (s *TransactionServer) RefundTransaction(ctx context.Context, req *pbTransaction.RefundTransactionRequest) (*pbTransaction.TransactionResponse, error) {
+	institutionID, err := authenticatedInstitutionID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	transactionID, err := primitive.ObjectIDFromHex(req.TransactionId)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid transaction ID format: %v", err)
+	}
+
+	transaction, err := s.transactionUsecase.GetInstitutionTransactionByID(ctx, institutionID, transactionID)
+	switch {
+	case errors.Is(err, usecase.ErrTransactionNotFound), errors.Is(err, usecase.ErrPostNotFound):
+		return nil, status.Errorf(codes.NotFound, "transaction not found")
+	case errors.Is(err, usecase.ErrPostAccessDenied):
+		return nil, status.Errorf(codes.PermissionDenied, "%v", err)
+	case err != nil:
+		return nil, status.Errorf(codes.Internal, "failed to get transaction: %v", err)
+	}
+
+	amount, err := s.transactionUsecase.ResolveRefundAmount(transaction, moneyFromRequest(req.AmountV2, req.Amount))
+	if err != nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "%v", err)
+	}
+
+	refunded, err := s.refund(ctx, transaction, amount, req.Reason)
+	if err != nil {
+		return nil, err
+	}
+
+	return toTransactionResponse(refunded), nil
+}
+
+// RefundPostTransactions fully refunds every PAID transaction of a post, for
+// campaigns that are cancelled or deleted. Transactions that fail are reported
+// by ID so the call can be repeated.
+func (s *TransactionServer) RefundPostTransactions(ctx context.Context, req *pbTransaction.RefundPostTransactionsRequest) (*pbTransaction.RefundPostTransactionsResponse, error) {
+	institutionID, err := authenticatedInstitutionID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &pbTransaction.RefundPostTransactionsResponse{
+		Refunded: []*pbTransaction.TransactionResponse{},
+		Failed:   []string{},
+	}
+
+	filter := model.TransactionFilter{
+		PostID: req.PostId,
+		Status: model.PaymentStatusPaid,
+		Limit:  usecase.MaxTransactionPageSize,
+	}
+
+	for {
+		page, err := s.transactionUsecase.GetPostTransactions(ctx, institutionID, filter)
+		switch {
+		case errors.Is(err, usecase.ErrPostNotFound):
+			return nil, status.Errorf(codes.NotFound, "post not found")
+		case errors.Is(err, usecase.ErrPostAccessDenied):
+			return nil, status.Errorf(codes.PermissionDenied, "%v", err)
+		case err != nil:
+			return nil, status.Errorf(codes.InvalidArgument, "failed to get transactions: %v", err)
+		}
+
+		for i := range page.Transactions {
+			transaction := &page.Transactions[i]
+
+			refunded, err := s.refund(ctx, transaction, transaction.RefundableAmount(), req.Reason)
+			if err != nil {
+				log.Printf("Failed to refund transaction %s: %v", transaction.TransactionID.Hex(), err)
+				res.Failed = append(res.Failed, transaction.TransactionID.Hex())
+				continue
+			}
+			res.Refunded = append(res.Refunded, toTransactionResponse(refunded))
+		}
+
+		if page.NextCursor == "" {
+			return res, nil
+		}
+		filter.Cursor, _ = primitive.ObjectIDFromHex(page.NextCursor)
+	}
+}
+
+// RetryPayment issues a fresh invoice for one of the donor's expired or failed
+// transactions. The transaction keeps its ID, so no second donation record is
+// created. A PENDING transaction whose invoice has expired at the gateway is
+// marked EXPIRED first.
+func (s *TransactionServer) RetryPayment(ctx context.Context, req *pbTransaction.RetryPaymentRequest) (*pbTransaction.CreateTransactionResponse, error) {
+	email, ok := ctx.Value(middlewares.EmailKey).(string)
+	if !ok || email == "" {
+		return nil, status.Errorf(codes.Unauthenticated, "failed to get authenticated user email from context")
+	}
+
+	transactionID, err := primitive.ObjectIDFromHex(req.TransactionId)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid transaction ID format: %v", err)
+	}
+
+	transaction, err := s.transactionUsecase.GetUserTransactionByID(ctx, email, transactionID)
+	if errors.Is(err, usecase.ErrTransactionNotFound) {
+		return nil, status.Errorf(codes.NotFound, "transaction not found")
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get transaction: %v", err)
+	}
+
+	if transaction.PaymentStatus == model.PaymentStatusPending {
+		if err := s.expireLapsedInvoice(ctx, transaction); err != nil {
+			return nil, err
+		}
+	}
+
+	if !transaction.CanRetryPayment() {
+		return nil, status.Errorf(codes.FailedPrecondition, "%v: transaction is %s", usecase.ErrRetryNotAllowed, transaction.PaymentStatus)
+	}
+
+	postID, err := uuid.Parse(transaction.PostID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "invalid post ID on transaction: %v", err)
+	}
+
+	post, err := s.transactionUsecase.GetPostByID(ctx, postID)
+	if status.Code(err) == codes.NotFound {
+		return nil, status.Errorf(codes.NotFound, "post not found")
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get post: %v", err)
+	}
+
+	if !post.IsPublished() {
+		return nil, status.Errorf(codes.FailedPrecondition, "this fundraising is not published, cannot accept new transactions")
+	}
+	if post.HasEnded(time.Now()) {
+		return nil, status.Errorf(codes.FailedPrecondition, "this fundraising has ended, cannot accept new transactions")
+	}
+
+	invoice, err := s.paymentGateway.CreateInvoice(s.newInvoiceRequest(transaction, post))
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to create payment invoice: %v", err)
+	}
 
-	_, err = s.transactionUsecase.UpdateTransaction(ctx, transaction)
+	retried, err := s.transactionUsecase.RetryTransaction(ctx, transaction, invoice.ID, invoice.InvoiceURL, invoice.ExpiryDate)
 	if err != nil {
+		// Another retry won the race; do not leave a second payable invoice.
+		if _, expireErr := s.paymentGateway.ExpireInvoice(invoice.ID); expireErr != nil {
+			log.Printf("Failed to expire unused invoice %s for transaction %s: %v", invoice.ID, req.TransactionId, expireErr)
+		}
+		if errors.Is(err, usecase.ErrRetryNotAllowed) || errors.Is(err, usecase.ErrInvalidStatusTransition) {
+			return nil, status.Errorf(codes.FailedPrecondition, "%v", err)
+		}
 		return nil, status.Errorf(codes.Internal, "failed to update transaction with payment details: %v", err)
 	}
 
 	return &pbTransaction.CreateTransactionResponse{
-		TransactionId: transaction.TransactionID.String(),
-		PaymentId:     transaction.PaymentID,
-		Amount:        float32(transaction.Amount),
-		AccountNumber: transaction.AccountNumber,
-		AccountName:   transaction.AccountName,
-		PaymentUrl:    invoice.InvoiceURL,
-		Status:        "PENDING",
+		TransactionId:   retried.TransactionID.Hex(),
+		PaymentId:       retried.PaymentID,
+		Amount:          float32(retried.Amount.Major()),
+		AmountV2:        toMoneyResponse(retried.Amount),
+		ConvertedAmount: toMoneyResponse(retried.CampaignAmount()),
+		AccountNumber:   retried.AccountNumber,
+		AccountName:     retried.AccountName,
+		PaymentUrl:      retried.PaymentURL,
+		Status:          string(retried.PaymentStatus),
 	}, nil
 }
+
+// expireLapsedInvoice marks a PENDING transaction EXPIRED when the gateway
+// reports its invoice expired but neither the callback nor the reconciler has
+// caught up yet.
+func (s *TransactionServer) expireLapsedInvoice(ctx context.Context, transaction *model.Transaction) error {
+	invoice, err := s.paymentGateway.GetInvoice(transaction.PaymentID)
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to check payment invoice: %v", err)
+	}
+	if !strings.EqualFold(invoice.Status, string(model.PaymentStatusExpired)) {
+		return nil
+	}
+
+	_, err = s.transactionUsecase.TransitionTransaction(ctx, transaction, model.PaymentStatusExpired, model.TransitionSourceAPI)
+	if errors.Is(err, usecase.ErrInvalidStatusTransition) {
+		return status.Errorf(codes.FailedPrecondition, "%v", err)
+	}
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to expire transaction: %v", err)
+	}
+
+	return nil
+}
+
+// SetFXRate records a new exchange rate. Only accounts listed in the admin
+// emails config may set rates.
+func (s *TransactionServer) SetFXRate(ctx context.Context, req *pbTransaction.SetFXRateRequest) (*pbTransaction.FXRate, error) {
+	email, ok := ctx.Value(middlewares.EmailKey).(string)
+	if !ok || email == "" {
+		return nil, status.Errorf(codes.Unauthenticated, "failed to get authenticated user email from context")
+	}
+	if !s.config.IsAdmin(email) {
+		return nil, status.Errorf(codes.PermissionDenied, "only admins can set exchange rates")
+	}
+
+	rate, err := s.transactionUsecase.SetFXRate(ctx, &model.FXRate{
+		BaseCurrency:  req.BaseCurrency,
+		QuoteCurrency: req.QuoteCurrency,
+		Rate:          req.Rate,
+		SetBy:         email,
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "failed to set exchange rate: %v", err)
+	}
+
+	return toFXRateResponse(rate), nil
+}
+
+func (s *TransactionServer) GetFXRates(ctx context.Context, req *pbTransaction.GetFXRatesRequest) (*pbTransaction.GetFXRatesResponse, error) {
+	rates, err := s.transactionUsecase.GetFXRates(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get exchange rates: %v", err)
+	}
+
+	res := &pbTransaction.GetFXRatesResponse{Rates: make([]*pbTransaction.FXRate, 0, len(rates))}
+	for i := range rates {
+		res.Rates = append(res.Rates, toFXRateResponse(&rates[i]))
+	}
+
+	return res, nil
+}
+
+func (s *TransactionServer) refund(ctx context.Context, transaction *model.Transaction, amount model.Money, reason string) (*model.Transaction, error) {
+	transactionIDStr := transaction.TransactionID.Hex()
+
+	refund, err := s.transactionUsecase.StartRefund(ctx, transaction, model.Refund{
+		Amount: amount,
+		Reason: reason,
+		Source: model.TransitionSourceInstitution,
+	})
+	if errors.Is(err, usecase.ErrRefundNotAllowed) {
+		return nil, status.Errorf(codes.FailedPrecondition, "%v", err)
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to start refund: %v", err)
+	}
+
+	gatewayRefund, err := s.paymentGateway.Refund(client.RefundRequest{
+		InvoiceID:   transaction.PaymentID,
+		ReferenceID: refund.ReferenceID,
+		Amount:      amount.Major(),
+		Currency:    amount.Currency,
+		Reason:      "CANCELLATION",
+	})
+	if err != nil {
+		if failErr := s.transactionUsecase.FailRefund(ctx, transaction, refund); failErr != nil {
+			log.Printf("Failed to mark refund %s as failed: %v", refund.ReferenceID, failErr)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to refund payment: %v", err)
+	}
+
+	refund.RefundID = gatewayRefund.ID
+
+	refunded, err := s.transactionUsecase.RefundTransaction(ctx, transaction, refund)
+	if errors.Is(err, usecase.ErrRefundNotAllowed) {
+		return nil, status.Errorf(codes.FailedPrecondition, "%v", err)
+	}
+	if err != nil {
+		// The refund stays pending under its reference ID until it is settled
+		// by hand against the gateway.
+		return nil, status.Errorf(codes.Internal, "failed to record refund %s of pending refund %s: %v", gatewayRefund.ID, refund.ReferenceID, err)
+	}
+
+	if err := s.emailPublisher.PublishRefundNotification(refunded, refund); err != nil {
+		log.Printf("Failed to notify donor of refund %s for transaction %s: %v", refund.RefundID, transactionIDStr, err)
+	}
+
+	return refunded, nil
+}
+
+func (s *TransactionServer) newInvoiceRequest(transaction *model.Transaction, post *model.Post) client.CreateInvoiceRequest {
+	transactionIDStr := transaction.TransactionID.Hex()
+
+	return client.CreateInvoiceRequest{
+		ExternalID:         transactionIDStr,
+		Amount:             transaction.Amount.Major(),
+		Currency:           transaction.Amount.Currency,
+		PayerEmail:         transaction.UserEmail,
+		Description:        fmt.Sprintf("Fund contribution for %s", post.Title),
+		CustomerName:       transaction.PublicDonorName(),
+		InvoiceDuration:    int(s.config.InvoiceDuration.Seconds()),
+		SuccessRedirectURL: s.config.SuccessRedirectURL(transactionIDStr),
+		FailureRedirectURL: s.config.FailureRedirectURL(transactionIDStr),
+		CallbackURL:        s.config.CallbackURL(),
+	}
+}
+
+func authenticatedInstitutionID(ctx context.Context) (uuid.UUID, error) {
+	authenticatedInstitutionID, ok := ctx.Value(middlewares.InstitutionIDKey).(string)
+	if !ok || authenticatedInstitutionID == "" {
+		return uuid.Nil, status.Errorf(codes.PermissionDenied, "only institutions can access post transactions")
+	}
+
+	institutionID, err := uuid.Parse(authenticatedInstitutionID)
+	if err != nil {
+		return uuid.Nil, status.Errorf(codes.Internal, "failed to parse authenticated institution ID: %v", err)
+	}
+
+	return institutionID, nil
+}
+
+func parseTransactionFilter(paymentStatus, dateFrom, dateTo, cursor string, limit int32) (model.TransactionFilter, error) {
+	filter := model.TransactionFilter{
+		Status: model.PaymentStatus(strings.ToUpper(paymentStatus)),
+		Limit:  int(limit),
+	}
+
+	var err error
+	if dateFrom != "" {
+		if filter.DateFrom, err = parseFilterDate(dateFrom, false); err != nil {
+			return filter, fmt.Errorf("invalid date_from format, expected YYYY-MM-DD or RFC3339: %v", err)
+		}
+	}
+	if dateTo != "" {
+		if filter.DateTo, err = parseFilterDate(dateTo, true); err != nil {
+			return filter, fmt.Errorf("invalid date_to format, expected YYYY-MM-DD or RFC3339: %v", err)
+		}
+	}
+	if cursor != "" {
+		if filter.Cursor, err = primitive.ObjectIDFromHex(cursor); err != nil {
+			return filter, fmt.Errorf("invalid cursor: %v", err)
+		}
+	}
+
+	return filter, nil
+}
+
+// parseFilterDate accepts RFC3339 or YYYY-MM-DD. A plain date used as the end
+// of a range includes that whole day.
+func parseFilterDate(value string, endOfRange bool) (time.Time, error) {
+	date, err := time.Parse(time.RFC3339, value)
+	if err == nil {
+		return date, nil
+	}
+
+	date, err = time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if endOfRange {
+		date = date.AddDate(0, 0, 1)
+	}
+
+	return date, nil
+}
+
+func toTransactionResponse(transaction *model.Transaction) *pbTransaction.TransactionResponse {
+	res := &pbTransaction.TransactionResponse{
+		TransactionId:    transaction.TransactionID.Hex(),
+		UserId:           transaction.UserID,
+		PostId:           transaction.PostID,
+		UserEmail:        transaction.UserEmail,
+		PaymentId:        transaction.PaymentID,
+		PaymentUrl:       transaction.PaymentURL,
+		Status:           string(transaction.PaymentStatus),
+		PaymentMethod:    transaction.PaymentMethod,
+		Amount:           float32(transaction.Amount.Major()),
+		AmountV2:         toMoneyResponse(transaction.Amount),
+		AccountNumber:    transaction.AccountNumber,
+		AccountName:      transaction.AccountName,
+		RefundedAmount:   float32(transaction.RefundedAmount.Major()),
+		RefundedAmountV2: toMoneyResponse(transaction.RefundedAmount),
+		ConvertedAmount:  toMoneyResponse(transaction.CampaignAmount()),
+		DonorName:        transaction.PublicDonorName(),
+		Message:          transaction.Message,
+	}
+	if transaction.FXRate != nil {
+		res.FxRate = &pbTransaction.FXRate{
+			FxRateId:      transaction.FXRate.FXRateID,
+			BaseCurrency:  transaction.FXRate.BaseCurrency,
+			QuoteCurrency: transaction.FXRate.QuoteCurrency,
+			Rate:          transaction.FXRate.Rate,
+			AsOf:          transaction.FXRate.AsOf.Format(time.RFC3339),
+		}
+	}
+	if !transaction.CreatedAt.IsZero() {
+		res.CreatedAt = transaction.CreatedAt.Format(time.RFC3339)
+	}
+	if !transaction.PaidAt.IsZero() {
+		res.PaidAt = transaction.PaidAt.Format(time.RFC3339)
+	}
+	if !transaction.ExpiresAt.IsZero() {
+		res.ExpiresAt = transaction.ExpiresAt.Format(time.RFC3339)
+	}
+
+	return res
+}
+
+func toTransactionsResponse(page *model.TransactionPage) *pbTransaction.GetTransactionsResponse {
+	transactions := make([]*pbTransaction.TransactionResponse, 0, len(page.Transactions))
+	for i := range page.Transactions {
+		transactions = append(transactions, toTransactionResponse(&page.Transactions[i]))
+	}
+
+	return &pbTransaction.GetTransactionsResponse{
+		Transactions: transactions,
+		NextCursor:   page.NextCursor,
+	}
+}
+
+// moneyFromRequest prefers the Money field of a request and falls back to the
+// deprecated float field, which is always in IDR.
+func moneyFromRequest(amount *pbTransaction.Money, legacy float32) model.Money {
+	if amount != nil {
+		return model.NewMoney(amount.Amount, amount.Currency)
+	}
+
+	return model.MoneyFromMajor(float64(legacy), model.CurrencyIDR)
+}
+
+func toMoneyResponse(amount model.Money) *pbTransaction.Money {
+	return &pbTransaction.Money{
+		Amount:   amount.Amount,
+		Currency: amount.Currency,
+	}
+}
+
+func toFXRateResponse(rate *model.FXRate) *pbTransaction.FXRate {
+	return &pbTransaction.FXRate{
+		FxRateId:      rate.FXRateID.String(),
+		BaseCurrency:  rate.BaseCurrency,
+		QuoteCurrency: rate.QuoteCurrency,
+		Rate:          rate.Rate,
+		AsOf:          rate.CreatedAt.Format(time.RFC3339),
+	}
+}
@@ -1,45 +1,72 @@
 package handler
 
 import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
+	"strings"
+	"time"
 
+	"transaction-service/client"
+	"transaction-service/config"
 	"transaction-service/model"
-	pbFuncCollect "transaction-service/pb/fund_collect"
 	pbUser "transaction-service/pb/user"
 	"transaction-service/usecase"
 
-	"github.com/google/uuid"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"google.golang.org/grpc/metadata"
 )
 
 type PaymentCallbackHandler struct {
 	transactionUsecase usecase.ITransactionUsecase
 	userClient         pbUser.UserServiceClient
-	fundCollectClient  pbFuncCollect.FundCollectServiceClient
+	paymentGateway     client.PaymentGateway
+	config             *config.Config
 }
 
+// XenditCallbackPayload is the body Xendit posts to the invoice callback URL.
 type XenditCallbackPayload struct {
-	PaymentID     string  `json:"payment_id"`
-	TransactionID string  `json:"transaction_id"`
-	Status        string  `json:"status"`
-	Amount        float64 `json:"amount"`
+	ID                 string    `json:"id"`
+	ExternalID         string    `json:"external_id"`
+	UserID             string    `json:"user_id"`
+	IsHigh             bool      `json:"is_high"`
+	Status             string    `json:"status"`
+	MerchantName       string    `json:"merchant_name"`
+	Amount             float64   `json:"amount"`
+	PaidAmount         float64   `json:"paid_amount"`
+	PayerEmail         string    `json:"payer_email"`
+	Description        string    `json:"description"`
+	PaymentMethod      string    `json:"payment_method"`
+	PaymentChannel     string    `json:"payment_channel"`
+	PaymentDestination string    `json:"payment_destination"`
+	BankCode           string    `json:"bank_code"`
+	Currency           string    `json:"currency"`
+	PaidAt             time.Time `json:"paid_at"`
+	Created            time.Time `json:"created"`
+	Updated            time.Time `json:"updated"`
 }
 
 func NewPaymentCallbackHandler(
 	transactionUsecase usecase.ITransactionUsecase,
 	userClient pbUser.UserServiceClient,
-	fundCollectClient pbFuncCollect.FundCollectServiceClient,
+	paymentGateway client.PaymentGateway,
+	config *config.Config,
 ) *PaymentCallbackHandler {
 	return &PaymentCallbackHandler{
 		transactionUsecase: transactionUsecase,
 		userClient:         userClient,
-		fundCollectClient:  fundCollectClient,
+		paymentGateway:     paymentGateway,
+		config:             config,
 	}
 }
 
+// HandleSuccessRedirect forwards the donor to the frontend success page. The
+// redirect itself proves nothing, since anyone can open it; the transaction is
+// only settled here when the gateway reports its invoice paid, so the donor
+// does not wait for the callback to see the donation go through.
 func (h *PaymentCallbackHandler) HandleSuccessRedirect(w http.ResponseWriter, r *http.Request) {
 	transactionID := r.URL.Query().Get("external_id")
 	if transactionID == "" {
@@ -53,65 +80,126 @@ func (h *PaymentCallbackHandler) HandleSuccessRedirect(w http.ResponseWriter, r
 		return
 	}
 
-	transaction, err := h.transactionUsecase.GetTransactionByID(r.Context(), objectID)
+	if err := h.settlePaidInvoice(r.Context(), objectID); err != nil {
+		// The callback or the reconciler settles it later.
+		log.Printf("Not settling transaction %s on redirect: %v", transactionID, err)
+	}
+
+	http.Redirect(w, r, h.config.FrontendSuccessRedirect(transactionID), http.StatusSeeOther)
+}
+
+func (h *PaymentCallbackHandler) settlePaidInvoice(ctx context.Context, transactionID primitive.ObjectID) error {
+	transaction, err := h.transactionUsecase.GetTransactionByID(ctx, transactionID)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Transaction not found: %v", err), http.StatusNotFound)
-		return
+		return err
+	}
+	if transaction.PaymentStatus != model.PaymentStatusPending {
+		return nil
 	}
 
-	transaction.PaymentStatus = "PAID"
+	invoice, err := h.paymentGateway.GetInvoice(transaction.PaymentID)
+	if err != nil {
+		return fmt.Errorf("failed to check payment invoice: %v", err)
+	}
+	if invoice.ExternalID != transaction.TransactionID.Hex() {
+		return errors.New("invoice does not belong to this transaction")
+	}
+	if model.MoneyFromMajor(invoice.Amount, transaction.Amount.Currency) != transaction.Amount {
+		return errors.New("invoice amount does not match transaction")
+	}
+	if status := strings.ToUpper(invoice.Status); status != "PAID" && status != "SETTLED" {
+		return fmt.Errorf("invoice is %s", invoice.Status)
+	}
 
-	log.Printf("Processing successful payment for transaction %s", transaction.TransactionID)
+	log.Printf("Processing successful payment for transaction %s", transaction.TransactionID.Hex())
 
-	outCtx := r.Context()
+	transaction.PaymentMethod = invoice.PaymentMethod
+	transaction.PaidAt = invoice.PaidAt
+	_, err = h.transactionUsecase.SettleTransaction(ctx, transaction, model.TransitionSourceRedirect)
+	return err
+}
 
-	token := r.Header.Get("Authorization")
-	if token == "" {
-		token = "Bearer your-service-token-here"
+// HandleInvoiceCallback receives Xendit invoice status notifications. The
+// request is authenticated with the x-callback-token header, which Xendit
+// sets to the verification token configured in its dashboard.
+func (h *PaymentCallbackHandler) HandleInvoiceCallback(w http.ResponseWriter, r *http.Request) {
+	if !h.validCallbackToken(r.Header.Get("x-callback-token")) {
+		http.Error(w, "Invalid callback token", http.StatusUnauthorized)
+		return
 	}
 
-	md := metadata.Pairs("authorization", token)
-	authCtx := metadata.NewOutgoingContext(outCtx, md)
-
-	var userName string
-	if email := transaction.UserEmail; email != "" {
-		userName = email
-	} else {
-		userName = "Anonymous User"
-		log.Printf("Warning: User email not found for transaction %s", transaction.TransactionID)
+	var payload XenditCallbackPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, fmt.Sprintf("Invalid callback payload: %v", err), http.StatusBadRequest)
+		return
 	}
 
-	postUUID, err := uuid.Parse(transaction.PostID)
+	objectID, err := primitive.ObjectIDFromHex(payload.ExternalID)
 	if err != nil {
-		log.Printf("Failed to parse PostID as UUID: %v", err)
-		http.Error(w, fmt.Sprintf("Invalid PostID format: %v", err), http.StatusInternalServerError)
+		http.Error(w, fmt.Sprintf("Invalid transaction ID format: %v", err), http.StatusBadRequest)
 		return
 	}
 
-	_, err = h.transactionUsecase.CreateFundCollect(authCtx, &model.FundCollect{
-		PostID:        postUUID,
-		UserID:        transaction.UserID,
-		UserName:      userName,
-		Amount:        float64(transaction.Amount),
-		TransactionID: transaction.TransactionID.Hex(),
-	})
+	transaction, err := h.transactionUsecase.GetTransactionByID(r.Context(), objectID)
 	if err != nil {
-		log.Printf("Failed to create fund collect: %v", err)
+		http.Error(w, fmt.Sprintf("Transaction not found: %v", err), http.StatusNotFound)
+		return
 	}
 
-	_, err = h.transactionUsecase.AddPostFundAchieved(r.Context(), postUUID, transaction.Amount)
-	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to update post fund achieved: %v", err), http.StatusInternalServerError)
+	if transaction.PaymentID != payload.ID && transaction.IsPreviousPayment(payload.ID) {
+		// The donor retried with a fresh invoice; this one has been replaced.
+		log.Printf("Ignoring %s callback for replaced invoice %s of transaction %s", payload.Status, payload.ID, transaction.TransactionID.Hex())
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]string{
+			"message": "Callback ignored",
+		})
 		return
 	}
 
-	_, err = h.transactionUsecase.UpdateTransaction(r.Context(), transaction)
-	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to update transaction: %v", err), http.StatusInternalServerError)
+	if transaction.PaymentID != payload.ID {
+		http.Error(w, "Invoice does not belong to this transaction", http.StatusBadRequest)
+		return
+	}
+
+	if model.MoneyFromMajor(payload.Amount, transaction.Amount.Currency) != transaction.Amount {
+		http.Error(w, "Invoice amount does not match transaction", http.StatusBadRequest)
+		return
+	}
+
+	log.Printf("Received invoice callback for transaction %s with status %s", transaction.TransactionID.Hex(), payload.Status)
+
+	transaction.PaymentMethod = payload.PaymentMethod
+
+	switch strings.ToUpper(payload.Status) {
+	case "PAID", "SETTLED":
+		transaction.PaidAt = payload.PaidAt
+		_, err = h.transactionUsecase.SettleTransaction(r.Context(), transaction, model.TransitionSourceWebhook)
+	case "EXPIRED":
+		_, err = h.transactionUsecase.TransitionTransaction(r.Context(), transaction, model.PaymentStatusExpired, model.TransitionSourceWebhook)
+	case "FAILED":
+		_, err = h.transactionUsecase.TransitionTransaction(r.Context(), transaction, model.PaymentStatusFailed, model.TransitionSourceWebhook)
+	default:
+		log.Printf("Ignoring invoice callback with unhandled status %s", payload.Status)
+	}
+
+	message := "Callback processed"
+	if errors.Is(err, usecase.ErrInvalidStatusTransition) {
+		// Acknowledge so Xendit stops retrying a callback we will never apply.
+		log.Printf("Ignoring invoice callback for transaction %s: %v", transaction.TransactionID.Hex(), err)
+		message = "Callback ignored"
+	} else if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	w.Header().Set("Content-Type", "text/html")
+	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	fmt.Fprintf(w, "<html><body><h1>Payment Successful</h1><p>Thank you for your contribution!</p></body></html>")
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": message,
+	})
+}
+
+func (h *PaymentCallbackHandler) validCallbackToken(token string) bool {
+	return h.config.CallbackToken != "" && subtle.ConstantTimeCompare([]byte(token), []byte(h.config.CallbackToken)) == 1
 }
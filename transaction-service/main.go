@@ -10,16 +10,25 @@ import (
 	"os/signal"
 	"syscall"
 
+	"transaction-service/client"
+	"transaction-service/config"
 	"transaction-service/database"
 	"transaction-service/docs"
 	"transaction-service/handler"
 	"transaction-service/middlewares"
-	pbFuncCollect "transaction-service/pb/fund_collect"
+	"transaction-service/model"
+	pbInstitution "transaction-service/pb/institution"
+	pbPost "transaction-service/pb/post"
+	pbReceipt "transaction-service/pb/receipt"
+	pbSubscription "transaction-service/pb/subscription"
+	pbTaxStatement "transaction-service/pb/tax_statement"
 	"transaction-service/pb/transaction"
 	pbUser "transaction-service/pb/user"
+	"transaction-service/queue"
 	"transaction-service/repository"
 	"transaction-service/routes"
 	"transaction-service/usecase"
+	"transaction-service/worker"
 
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
@@ -47,6 +56,11 @@ func main() {
 		TimestampFormat: "2006-01-02 15:04:05",
 	})
 
+	cfg, err := config.Load()
+	if err != nil {
+		logger.Fatalf("Invalid config: %v", err)
+	}
+
 	ctx := context.Background()
 	dbMongo := database.GetMongoDatabase()
 	defer func() {
@@ -55,6 +69,30 @@ func main() {
 		}
 	}()
 
+	backfilled, err := database.BackfillTransactionMoney(ctx, dbMongo)
+	if err != nil {
+		logger.Fatalf("Failed to backfill transaction amounts: %v", err)
+	}
+	if backfilled > 0 {
+		logger.Infof("Backfilled minor-unit amounts of %d transactions", backfilled)
+	}
+
+	backfilled, err = database.BackfillPaymentStatus(ctx, dbMongo)
+	if err != nil {
+		logger.Fatalf("Failed to backfill payment statuses: %v", err)
+	}
+	if backfilled > 0 {
+		logger.Infof("Backfilled payment statuses of %d transactions", backfilled)
+	}
+
+	backfilled, err = database.BackfillTransactionDates(ctx, dbMongo)
+	if err != nil {
+		logger.Fatalf("Failed to backfill transaction dates: %v", err)
+	}
+	if backfilled > 0 {
+		logger.Infof("Backfilled dates of %d transactions", backfilled)
+	}
+
 	initDB := database.GetDB()
 	if initDB == nil {
 		fmt.Println("Failed to initialize database")
@@ -68,6 +106,10 @@ func main() {
 		panic("failed to connect to database!")
 	}
 
+	if err := dbPostgre.AutoMigrate(&model.FXRate{}); err != nil {
+		logger.Fatalf("Failed to migrate fx_rates: %v", err)
+	}
+
 	sigChan := make(chan os.Signal, 1)
 	errChan := make(chan error, 1)
 	quitChan := make(chan bool, 1)
@@ -101,19 +143,91 @@ func main() {
 		grpcPort = "50053"
 	}
 
-	transactionRepo := repository.NewTransactionRepository(dbMongo, dbPostgre)
-	transactionUsecase := usecase.NewTransactionUsecase(transactionRepo)
+	userConn, institutionConn := getServiceConnections()
+
+	// institutionConn is institution-service, which serves posts and
+	// institutions.
+	postClient := pbPost.NewPostServiceClient(institutionConn)
+	institutionClient := pbInstitution.NewInstitutionServiceClient(institutionConn)
+	userClient := pbUser.NewUserServiceClient(userConn)
+
+	transactionRepo := repository.NewTransactionRepository(dbMongo, dbPostgre, postClient, userClient)
+	transactionUsecase := usecase.NewTransactionUsecase(transactionRepo, usecase.DonationLimits{
+		MinAmount: model.MoneyFromMajor(cfg.MinDonationAmount, model.CurrencyIDR),
+		MaxAmount: model.MoneyFromMajor(cfg.MaxDonationAmount, model.CurrencyIDR),
+	})
+
+	subscriptionRepo := repository.NewSubscriptionRepository(dbMongo)
+	subscriptionUsecase := usecase.NewSubscriptionUsecase(subscriptionRepo, transactionRepo)
+
+	receiptRepo := repository.NewReceiptRepository(dbMongo, institutionClient)
+	receiptUsecase := usecase.NewReceiptUsecase(receiptRepo, transactionRepo)
+
+	taxStatementRepo := repository.NewTaxStatementRepository(dbMongo, institutionClient)
+	taxStatementUsecase := usecase.NewTaxStatementUsecase(taxStatementRepo, transactionRepo)
+
+	paymentGateway, err := client.NewPaymentGateway()
+	if err != nil {
+		logger.Fatalf("Invalid payment gateway: %v", err)
+	}
+
+	var emailPublisher queue.IEmailPublisher = queue.LogEmailPublisher{}
+	var eventPublisher queue.IEventPublisher
+	rabbitConn, rabbitChannel, err := database.InitRabbitMQ()
+	if err != nil {
+		logger.Fatalf("Failed to initialize RabbitMQ: %v", err)
+	}
+	if rabbitConn != nil {
+		defer rabbitConn.Close()
+
+		emailPublisher, err = queue.NewEmailPublisher(rabbitChannel, "email")
+		if err != nil {
+			logger.Fatalf("Failed to declare email queue: %v", err)
+		}
+
+		// Publisher confirms apply to the whole channel, so events get their own.
+		eventChannel, err := rabbitConn.Channel()
+		if err != nil {
+			logger.Fatalf("Failed to open RabbitMQ channel for donation events: %v", err)
+		}
+		eventPublisher, err = queue.NewEventPublisher(eventChannel, queue.DonationEventsQueue)
+		if err != nil {
+			logger.Fatalf("Failed to declare donation events queue: %v", err)
+		}
+	} else {
+		logger.Warn("MQHOST is not set, email notifications will only be logged and donation events will stay in the outbox")
+	}
+
+	reconcilerCtx, stopReconciler := context.WithCancel(ctx)
+	reconciler := worker.NewReconciler(transactionUsecase, paymentGateway, cfg.Reconciler)
+	go reconciler.Start(reconcilerCtx)
 
-	userConn, fundCollectConn := getServiceConnections()
+	subscriptionScheduler := worker.NewSubscriptionScheduler(subscriptionUsecase, transactionUsecase, paymentGateway, emailPublisher, cfg)
+	go subscriptionScheduler.Start(reconcilerCtx)
 
-	go InitHTTPServer(errChan, port, grpcEndpoint, grpcPort, transactionUsecase, userConn, fundCollectConn)
-	go InitGRPCServer(dbMongo, errChan, grpcEndpoint, grpcPort, transactionUsecase, userConn, fundCollectConn)
+	if rabbitConn != nil {
+		receiptSender := worker.NewReceiptSender(receiptUsecase, emailPublisher, cfg.ReceiptSender)
+		go receiptSender.Start(reconcilerCtx)
+
+		taxStatementSender := worker.NewTaxStatementSender(taxStatementUsecase, emailPublisher, cfg.TaxStatementSender)
+		go taxStatementSender.Start(reconcilerCtx)
+	}
+
+	if eventPublisher != nil {
+		outboxRelay := worker.NewOutboxRelay(transactionUsecase, eventPublisher, cfg.OutboxRelay)
+		go outboxRelay.Start(reconcilerCtx)
+	}
+
+	go InitHTTPServer(errChan, port, grpcEndpoint, grpcPort, cfg, transactionUsecase, paymentGateway, userConn)
+	go InitGRPCServer(dbMongo, errChan, grpcEndpoint, grpcPort, cfg, transactionUsecase, subscriptionUsecase, receiptUsecase, taxStatementUsecase, paymentGateway, emailPublisher, userConn)
 
 	<-quitChan
 	logger.Info("Shutting down...")
 
+	stopReconciler()
+
 	userConn.Close()
-	fundCollectConn.Close()
+	institutionConn.Close()
 }
 
 func getServiceConnections() (*grpc.ClientConn, *grpc.ClientConn) {
@@ -146,14 +260,14 @@ func getServiceConnections() (*grpc.ClientConn, *grpc.ClientConn) {
 		grpcInstitutionPort = "50052"
 	}
 
-	fundCollectConn, err := grpc.Dial(grpcInstitutionEndpoint+":"+grpcInstitutionPort,
+	institutionConn, err := grpc.Dial(grpcInstitutionEndpoint+":"+grpcInstitutionPort,
 		grpc.WithTransportCredentials(creds),
 	)
 	if err != nil {
-		log.Fatalf("Failed to connect to fund collect service: %v", err)
+		log.Fatalf("Failed to connect to institution service: %v", err)
 	}
 
-	return userConn, fundCollectConn
+	return userConn, institutionConn
 }
 
 func InitHTTPServer(
@@ -161,9 +275,10 @@ func InitHTTPServer(
 	port,
 	grpcEndpoint,
 	grpcPort string,
+	cfg *config.Config,
 	transactionUsecase usecase.ITransactionUsecase,
+	paymentGateway client.PaymentGateway,
 	userConn *grpc.ClientConn,
-	fundCollectConn *grpc.ClientConn,
 ) {
 	var opts []grpc.DialOption
 
@@ -183,7 +298,9 @@ func InitHTTPServer(
 	defer conn.Close()
 
 	transactionClient := transaction.NewTransactionServiceClient(conn)
-	fundCollectClient := pbFuncCollect.NewFundCollectServiceClient(fundCollectConn)
+	subscriptionClient := pbSubscription.NewSubscriptionServiceClient(conn)
+	receiptClient := pbReceipt.NewReceiptServiceClient(conn)
+	taxStatementClient := pbTaxStatement.NewTaxStatementServiceClient(conn)
 
 	e := echo.New()
 
@@ -198,21 +315,34 @@ func InitHTTPServer(
 	docs.SwaggerInfo.Title = "EduConnect - Transaction Service API Contract"
 	docs.SwaggerInfo.Description = "This is a documentation EduConnect - Transaction Service API Contract."
 	docs.SwaggerInfo.Version = "1.0"
-	docs.SwaggerInfo.Host = "transaction-service-1011483964797.asia-southeast2.run.app"
+	docs.SwaggerInfo.Host = cfg.SwaggerHost
 	docs.SwaggerInfo.BasePath = "/"
-	docs.SwaggerInfo.Schemes = []string{"https"}
+	docs.SwaggerInfo.Schemes = []string{cfg.SwaggerScheme()}
 	e.GET("/swagger/*", echoSwagger.WrapHandler)
 
 	userClient := pbUser.NewUserServiceClient(userConn)
-	paymentCallbackHandler := handler.NewPaymentCallbackHandler(transactionUsecase, userClient, fundCollectClient)
-	e.GET("/payment/success", func(c echo.Context) error {
+	paymentCallbackHandler := handler.NewPaymentCallbackHandler(transactionUsecase, userClient, paymentGateway, cfg)
+	e.GET(config.SuccessRedirectPath, func(c echo.Context) error {
 		paymentCallbackHandler.HandleSuccessRedirect(c.Response().Writer, c.Request())
 		return nil
 	})
+	e.POST(cfg.WebhookPath, func(c echo.Context) error {
+		paymentCallbackHandler.HandleInvoiceCallback(c.Response().Writer, c.Request())
+		return nil
+	})
 
 	transactionRoutes := routes.NewTransactionHTTPHandler(transactionClient)
 	transactionRoutes.Routes(e)
 
+	subscriptionRoutes := routes.NewSubscriptionHTTPHandler(subscriptionClient)
+	subscriptionRoutes.Routes(e)
+
+	receiptRoutes := routes.NewReceiptHTTPHandler(receiptClient)
+	receiptRoutes.Routes(e)
+
+	taxStatementRoutes := routes.NewTaxStatementHTTPHandler(taxStatementClient)
+	taxStatementRoutes.Routes(e)
+
 	log.Info("Starting HTTP Server at port: ", port)
 	errChan <- e.Start(":" + port)
 }
@@ -222,9 +352,14 @@ func InitGRPCServer(
 	errChan chan error,
 	grpcEndpoint,
 	grpcPort string,
+	cfg *config.Config,
 	transactionUsecase usecase.ITransactionUsecase,
+	subscriptionUsecase usecase.ISubscriptionUsecase,
+	receiptUsecase usecase.IReceiptUsecase,
+	taxStatementUsecase usecase.ITaxStatementUsecase,
+	paymentGateway client.PaymentGateway,
+	emailPublisher queue.IEmailPublisher,
 	userConn *grpc.ClientConn,
-	fundCollectConn *grpc.ClientConn,
 ) {
 	listener, err := net.Listen("tcp", fmt.Sprintf("%s:%s", grpcEndpoint, grpcPort))
 	if err != nil {
@@ -240,13 +375,19 @@ func InitGRPCServer(
 	opts = append(opts, grpc.UnaryInterceptor(middlewares.AuthGRPCInterceptor2))
 
 	userClient := pbUser.NewUserServiceClient(userConn)
-	fundCollectClient := pbFuncCollect.NewFundCollectServiceClient(fundCollectConn)
 
-	transactionHandler := handler.NewTransactionHandler(transactionUsecase, userClient, fundCollectClient)
+	transactionHandler := handler.NewTransactionHandler(transactionUsecase, userClient, paymentGateway, emailPublisher, cfg)
+
+	subscriptionHandler := handler.NewSubscriptionHandler(subscriptionUsecase, transactionUsecase)
+	receiptHandler := handler.NewReceiptHandler(receiptUsecase)
+	taxStatementHandler := handler.NewTaxStatementHandler(taxStatementUsecase, transactionUsecase)
 
 	transactionServer := grpc.NewServer(opts...)
 
 	transaction.RegisterTransactionServiceServer(transactionServer, transactionHandler)
+	pbSubscription.RegisterSubscriptionServiceServer(transactionServer, subscriptionHandler)
+	pbReceipt.RegisterReceiptServiceServer(transactionServer, receiptHandler)
+	pbTaxStatement.RegisterTaxStatementServiceServer(transactionServer, taxStatementHandler)
 
 	log.Info("Starting gRPC Server at", grpcEndpoint, ":", grpcPort)
 	if err := transactionServer.Serve(listener); err != nil {
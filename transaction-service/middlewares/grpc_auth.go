@@ -15,8 +15,9 @@ import (
 type ContextKey string
 
 const (
-	UserIDKey ContextKey = "id"
-	EmailKey  ContextKey = "email"
+	UserIDKey        ContextKey = "id"
+	EmailKey         ContextKey = "email"
+	InstitutionIDKey ContextKey = "institution_id"
 )
 
 func AuthGRPCInterceptor2(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
@@ -47,7 +48,13 @@ func AuthGRPCInterceptor2(ctx context.Context, req interface{}, info *grpc.Unary
 		tokenString := tokenParts[1]
 		userID, email, err := utils.ValidateJWT(tokenString)
 		if err != nil {
-			return nil, status.Errorf(codes.Unauthenticated, "invalid token: %v", err)
+			// Institution tokens carry an institution_id instead of an email.
+			institutionID, institutionErr := utils.ValidateInstitutionJWT(tokenString)
+			if institutionErr != nil {
+				return nil, status.Errorf(codes.Unauthenticated, "invalid token: %v", err)
+			}
+
+			return handler(context.WithValue(newCtx, InstitutionIDKey, institutionID), req)
 		}
 
 		newCtx = context.WithValue(newCtx, EmailKey, email)
@@ -0,0 +1,21 @@
+package model
+
+import "time"
+
+// MaxDeliveryAttempts is how many times the senders try to email a receipt
+// or a tax statement before giving up on it.
+const MaxDeliveryAttempts = 5
+
+// maxDeliveryRetryDelay caps the backoff between two attempts.
+const maxDeliveryRetryDelay = 6 * time.Hour
+
+// DeliveryRetryAt is when an email that has failed attempts times is tried
+// again: a minute after the first failure, doubling with each one after.
+func DeliveryRetryAt(attempts int, now time.Time) time.Time {
+	delay := time.Minute
+	for i := 1; i < attempts && delay < maxDeliveryRetryDelay; i++ {
+		delay *= 2
+	}
+
+	return now.Add(min(delay, maxDeliveryRetryDelay))
+}
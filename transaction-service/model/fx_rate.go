@@ -0,0 +1,140 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// FXRate says that one unit of BaseCurrency is worth Rate units of
+// QuoteCurrency. Rates are never updated in place: setting a rate inserts a
+// new row and the latest row of a pair is the current rate.
+type FXRate struct {
+	FXRateID      uuid.UUID `json:"fx_rate_id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	BaseCurrency  string    `json:"base_currency" gorm:"type:varchar(3); not null; index:idx_fx_rates_pair"`
+	QuoteCurrency string    `json:"quote_currency" gorm:"type:varchar(3); not null; index:idx_fx_rates_pair"`
+	// Rate is a decimal string so conversions are exact.
+	Rate      string    `json:"rate" gorm:"type:numeric(24,12); not null"`
+	SetBy     string    `json:"set_by" gorm:"type:varchar(255); not null"`
+	CreatedAt time.Time `json:"created_at" gorm:"type:timestamp; not null; autoCreateTime"`
+}
+
+func (FXRate) TableName() string {
+	return "fx_rates"
+}
+
+func (r *FXRate) Validate() error {
+	var e []string
+
+	if _, ok := CurrencyExponent(r.BaseCurrency); !ok {
+		e = append(e, "Base Currency is not supported")
+	}
+	if _, ok := CurrencyExponent(r.QuoteCurrency); !ok {
+		e = append(e, "Quote Currency is not supported")
+	}
+	if strings.EqualFold(r.BaseCurrency, r.QuoteCurrency) {
+		e = append(e, "Base Currency and Quote Currency must differ")
+	}
+	if rate, ok := new(big.Rat).SetString(r.Rate); !ok || rate.Sign() <= 0 {
+		e = append(e, "Rate must be a positive decimal number")
+	}
+
+	if len(e) > 0 {
+		return errors.New(strings.Join(e, ", "))
+	}
+
+	return nil
+}
+
+// Snapshot is the copy of the rate kept on a transaction.
+func (r *FXRate) Snapshot() *FXRateSnapshot {
+	return &FXRateSnapshot{
+		FXRateID:      r.FXRateID.String(),
+		BaseCurrency:  r.BaseCurrency,
+		QuoteCurrency: r.QuoteCurrency,
+		Rate:          r.Rate,
+		AsOf:          r.CreatedAt,
+	}
+}
+
+// FXRateSnapshot is the rate a transaction was converted with, kept so the
+// conversion can be repeated for refunds and reports after the rate changes.
+type FXRateSnapshot struct {
+	FXRateID      string    `json:"fx_rate_id" bson:"fx_rate_id"`
+	BaseCurrency  string    `json:"base_currency" bson:"base_currency"`
+	QuoteCurrency string    `json:"quote_currency" bson:"quote_currency"`
+	Rate          string    `json:"rate" bson:"rate"`
+	AsOf          time.Time `json:"as_of" bson:"as_of"`
+}
+
+// Convert turns an amount in BaseCurrency into QuoteCurrency, rounding half
+// away from zero to the quote currency's minor unit.
+func (s *FXRateSnapshot) Convert(amount Money) (Money, error) {
+	if amount.Currency != s.BaseCurrency {
+		return Money{}, fmt.Errorf("cannot convert %s with a %s/%s rate", amount.Currency, s.BaseCurrency, s.QuoteCurrency)
+	}
+
+	rate, ok := new(big.Rat).SetString(s.Rate)
+	if !ok {
+		return Money{}, fmt.Errorf("invalid rate %q", s.Rate)
+	}
+
+	baseExponent, _ := CurrencyExponent(s.BaseCurrency)
+	quoteExponent, _ := CurrencyExponent(s.QuoteCurrency)
+
+	converted := new(big.Rat).SetInt64(amount.Amount)
+	converted.Mul(converted, rate)
+	converted.Mul(converted, new(big.Rat).SetFrac(pow10(quoteExponent), pow10(baseExponent)))
+
+	return Money{Amount: roundRat(converted), Currency: s.QuoteCurrency}, nil
+}
+
+// Invert is the same rate read from QuoteCurrency to BaseCurrency.
+func (s *FXRateSnapshot) Invert() *FXRateSnapshot {
+	inverted := *s
+	inverted.BaseCurrency, inverted.QuoteCurrency = s.QuoteCurrency, s.BaseCurrency
+	if rate, ok := new(big.Rat).SetString(s.Rate); ok && rate.Sign() != 0 {
+		inverted.Rate = new(big.Rat).Inv(rate).FloatString(12)
+	}
+
+	return &inverted
+}
+
+func pow10(exponent int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(exponent)), nil)
+}
+
+func roundRat(value *big.Rat) int64 {
+	num := new(big.Int).Abs(value.Num())
+	quotient, remainder := new(big.Int).QuoRem(num, value.Denom(), new(big.Int))
+	if new(big.Int).Mul(remainder, big.NewInt(2)).Cmp(value.Denom()) >= 0 {
+		quotient.Add(quotient, big.NewInt(1))
+	}
+	if value.Sign() < 0 {
+		quotient.Neg(quotient)
+	}
+
+	return quotient.Int64()
+}
+
+// DonationQuote is what a donor is charged for a donation and what it credits
+// to the post after conversion and the post's overflow policy.
+type DonationQuote struct {
+	Amount          Money
+	ConvertedAmount Money
+	FXRate          *FXRateSnapshot
+}
+
+type FXRateRequest struct {
+	BaseCurrency  string `json:"base_currency"`
+	QuoteCurrency string `json:"quote_currency"`
+	Rate          string `json:"rate"`
+}
+
+type FXRateListResponse struct {
+	Rates []FXRate `json:"rates"`
+}
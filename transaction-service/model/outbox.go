@@ -0,0 +1,108 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	EventTypeDonationSettled  = "DonationSettled"
+	EventTypeDonationRefunded = "DonationRefunded"
+)
+
+// OutboxEvent is an event waiting to be relayed to RabbitMQ. It is stored on
+// the transaction it describes, so it is written in the same document update
+// as the status change that raised it and cannot be lost or raised twice.
+type OutboxEvent struct {
+	EventID     string    `json:"event_id" bson:"event_id"`
+	Type        string    `json:"type" bson:"type"`
+	Payload     []byte    `json:"payload" bson:"payload"`
+	CreatedAt   time.Time `json:"created_at" bson:"created_at"`
+	PublishedAt time.Time `json:"published_at" bson:"published_at,omitempty"`
+}
+
+// PendingOutboxEvent is an unpublished event and the transaction holding it.
+type PendingOutboxEvent struct {
+	TransactionID primitive.ObjectID
+	Event         OutboxEvent
+}
+
+// DonationSettledEvent tells institution-service to record the fund collect of
+// a paid transaction and add Amount to the post. Consumers must apply it once
+// per TransactionID, since the relay delivers at least once.
+type DonationSettledEvent struct {
+	EventID        string    `json:"event_id"`
+	TransactionID  string    `json:"transaction_id"`
+	PostID         string    `json:"post_id"`
+	UserID         string    `json:"user_id"`
+	UserName       string    `json:"user_name"`
+	Amount         Money     `json:"amount"`
+	OriginalAmount *Money    `json:"original_amount,omitempty"`
+	Message        string    `json:"message,omitempty"`
+	PaidAt         time.Time `json:"paid_at"`
+}
+
+// NewDonationSettledEvent builds the outbox event of a transaction being
+// settled, crediting its amount in the post's currency.
+func NewDonationSettledEvent(transaction *Transaction, userName string) (OutboxEvent, error) {
+	settled := DonationSettledEvent{
+		EventID:       uuid.New().String(),
+		TransactionID: transaction.TransactionID.Hex(),
+		PostID:        transaction.PostID,
+		UserID:        transaction.UserID,
+		UserName:      userName,
+		Amount:        transaction.CampaignAmount(),
+		Message:       transaction.Message,
+		PaidAt:        transaction.PaidAt,
+	}
+	if transaction.FXRate != nil {
+		original := transaction.Amount
+		settled.OriginalAmount = &original
+	}
+
+	return newOutboxEvent(settled.EventID, EventTypeDonationSettled, settled)
+}
+
+// DonationRefundedEvent tells institution-service to take Amount back off the
+// fund collect of TransactionID and its post. Consumers must apply it once per
+// RefundID.
+type DonationRefundedEvent struct {
+	EventID       string    `json:"event_id"`
+	TransactionID string    `json:"transaction_id"`
+	PostID        string    `json:"post_id"`
+	RefundID      string    `json:"refund_id"`
+	Amount        Money     `json:"amount"`
+	RefundedAt    time.Time `json:"refunded_at"`
+}
+
+// NewDonationRefundedEvent builds the outbox event of a refund, debiting the
+// given amount in the post's currency.
+func NewDonationRefundedEvent(transaction *Transaction, refund Refund, debit Money) (OutboxEvent, error) {
+	refunded := DonationRefundedEvent{
+		EventID:       uuid.New().String(),
+		TransactionID: transaction.TransactionID.Hex(),
+		PostID:        transaction.PostID,
+		RefundID:      refund.RefundID,
+		Amount:        debit,
+		RefundedAt:    refund.At,
+	}
+
+	return newOutboxEvent(refunded.EventID, EventTypeDonationRefunded, refunded)
+}
+
+func newOutboxEvent(eventID, eventType string, payload interface{}) (OutboxEvent, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return OutboxEvent{}, err
+	}
+
+	return OutboxEvent{
+		EventID:   eventID,
+		Type:      eventType,
+		Payload:   body,
+		CreatedAt: time.Now(),
+	}, nil
+}
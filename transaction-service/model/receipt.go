@@ -0,0 +1,53 @@
+package model
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ReceiptStatus tracks the receipt email of a paid transaction. Transactions
+// paid before receipts existed have none and are never emailed. A receipt
+// that still fails after MaxDeliveryAttempts is FAILED.
+type ReceiptStatus string
+
+const (
+	ReceiptStatusPending ReceiptStatus = "PENDING"
+	ReceiptStatusSent    ReceiptStatus = "SENT"
+	ReceiptStatusFailed  ReceiptStatus = "FAILED"
+)
+
+// Institution is the public profile of an institution as returned by
+// institution-service.
+type Institution struct {
+	InstitutionID uuid.UUID `json:"institution_id"`
+	Name          string    `json:"name"`
+	Address       string    `json:"address"`
+	Website       string    `json:"website"`
+}
+
+// Receipt is the PDF receipt of a paid transaction. It is issued again when
+// the transaction is refunded after it was issued, so RefundedAmount always
+// matches the document.
+type Receipt struct {
+	ReceiptID      primitive.ObjectID `json:"receipt_id" bson:"_id,omitempty"`
+	TransactionID  primitive.ObjectID `json:"transaction_id" bson:"transaction_id"`
+	UserEmail      string             `json:"user_email" bson:"user_email"`
+	RefundedAmount Money              `json:"refunded_amount" bson:"refunded_amount"`
+	PDF            []byte             `json:"-" bson:"pdf"`
+	IssuedAt       time.Time          `json:"issued_at" bson:"issued_at"`
+}
+
+func (r *Receipt) Filename() string {
+	return fmt.Sprintf("receipt-%s.pdf", r.TransactionID.Hex())
+}
+
+// ReceiptData is everything printed on a receipt.
+type ReceiptData struct {
+	Transaction *Transaction
+	Post        *Post
+	Institution *Institution
+	IssuedAt    time.Time
+}
@@ -0,0 +1,131 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type SubscriptionStatus string
+
+const (
+	SubscriptionStatusActive    SubscriptionStatus = "ACTIVE"
+	SubscriptionStatusPaused    SubscriptionStatus = "PAUSED"
+	SubscriptionStatusCancelled SubscriptionStatus = "CANCELLED"
+)
+
+var subscriptionStatusTransitions = map[SubscriptionStatus][]SubscriptionStatus{
+	SubscriptionStatusActive: {SubscriptionStatusPaused, SubscriptionStatusCancelled},
+	SubscriptionStatusPaused: {SubscriptionStatusActive, SubscriptionStatusCancelled},
+}
+
+func (s SubscriptionStatus) CanTransitionTo(next SubscriptionStatus) bool {
+	for _, status := range subscriptionStatusTransitions[s] {
+		if status == next {
+			return true
+		}
+	}
+
+	return false
+}
+
+type SubscriptionCadence string
+
+const SubscriptionCadenceMonthly SubscriptionCadence = "MONTHLY"
+
+func (c SubscriptionCadence) IsValid() bool {
+	return c == SubscriptionCadenceMonthly
+}
+
+// Subscription is a donor's pledge to give Amount every cycle, either to one
+// post or to whichever open post of an institution ends soonest. Each cycle
+// creates an ordinary Transaction with its own invoice.
+type Subscription struct {
+	SubscriptionID primitive.ObjectID  `json:"subscription_id" bson:"_id,omitempty"`
+	UserID         string              `json:"user_id" bson:"user_id"`
+	UserEmail      string              `json:"user_email" bson:"user_email"`
+	PostID         string              `json:"post_id,omitempty" bson:"post_id,omitempty"`
+	InstitutionID  string              `json:"institution_id,omitempty" bson:"institution_id,omitempty"`
+	Amount         Money               `json:"amount" bson:"amount"`
+	Cadence        SubscriptionCadence `json:"cadence" bson:"cadence"`
+	Status         SubscriptionStatus  `json:"status" bson:"status"`
+	// BillingDay is the day of the month charges fall on, kept apart from
+	// NextChargeAt so a pledge started on the 31st returns to the 31st after
+	// shorter months.
+	BillingDay   int       `json:"billing_day" bson:"billing_day"`
+	NextChargeAt time.Time `json:"next_charge_at" bson:"next_charge_at"`
+	// ReminderSentFor is the NextChargeAt the last reminder email announced.
+	ReminderSentFor time.Time `json:"-" bson:"reminder_sent_for,omitempty"`
+	// ChargingCycle is the charge date of a cycle the scheduler claimed but
+	// has not created a transaction for yet. It is charged again from
+	// ChargeRetryAt, with the same ChargeTransactionID so that a retry never
+	// creates a second transaction for the cycle.
+	ChargingCycle       time.Time           `json:"-" bson:"charging_cycle,omitempty"`
+	ChargeRetryAt       time.Time           `json:"-" bson:"charge_retry_at,omitempty"`
+	ChargeTransactionID primitive.ObjectID  `json:"-" bson:"charge_transaction_id,omitempty"`
+	AccountNumber       string              `json:"account_number" bson:"account_number"`
+	AccountName         string              `json:"account_name" bson:"account_name"`
+	DonorNameVisibility DonorNameVisibility `json:"donor_name_visibility" bson:"donor_name_visibility"`
+	DonorName           string              `json:"donor_name" bson:"donor_name"`
+	LastTransactionID   string              `json:"last_transaction_id,omitempty" bson:"last_transaction_id,omitempty"`
+	CreatedAt           time.Time           `json:"created_at" bson:"created_at"`
+	UpdatedAt           time.Time           `json:"updated_at" bson:"updated_at"`
+	CancelledAt         time.Time           `json:"cancelled_at,omitempty" bson:"cancelled_at,omitempty"`
+}
+
+// ChargeRetryDelay is how long a claimed cycle waits before it is charged
+// again, long enough for the scheduler that claimed it to finish.
+const ChargeRetryDelay = 15 * time.Minute
+
+// ChargeAfter is the first charge date strictly after t, on BillingDay or the
+// last day of shorter months.
+func (s *Subscription) ChargeAfter(t time.Time) time.Time {
+	next := billingDate(t.Year(), t.Month(), s.BillingDay, s.NextChargeAt)
+	for !next.After(t) {
+		next = billingDate(next.Year(), next.Month()+1, s.BillingDay, s.NextChargeAt)
+	}
+
+	return next
+}
+
+// billingDate is day of the given month at the time of day of clock, clamped
+// to the month's last day.
+func billingDate(year int, month time.Month, day int, clock time.Time) time.Time {
+	lastDay := time.Date(year, month+1, 0, 0, 0, 0, 0, clock.Location()).Day()
+	if day > lastDay {
+		day = lastDay
+	}
+
+	return time.Date(year, month, day, clock.Hour(), clock.Minute(), clock.Second(), 0, clock.Location())
+}
+
+type SubscriptionRequest struct {
+	PostID        string `json:"post_id"`
+	InstitutionID string `json:"institution_id"`
+	// Amount is in minor units, e.g. 5000000 for IDR 50,000.
+	Amount              Money  `json:"amount"`
+	Cadence             string `json:"cadence"`
+	AccountNumber       string `json:"account_number"`
+	AccountName         string `json:"account_name"`
+	DonorNameVisibility string `json:"donor_name_visibility"`
+	DisplayName         string `json:"display_name"`
+}
+
+type SubscriptionResponse struct {
+	SubscriptionID    string `json:"subscription_id"`
+	PostID            string `json:"post_id"`
+	InstitutionID     string `json:"institution_id"`
+	Amount            Money  `json:"amount"`
+	Cadence           string `json:"cadence"`
+	Status            string `json:"status"`
+	NextChargeAt      string `json:"next_charge_at"`
+	AccountNumber     string `json:"account_number"`
+	AccountName       string `json:"account_name"`
+	DonorName         string `json:"donor_name"`
+	LastTransactionID string `json:"last_transaction_id"`
+	CreatedAt         string `json:"created_at"`
+}
+
+type SubscriptionListResponse struct {
+	Subscriptions []SubscriptionResponse `json:"subscriptions"`
+}
@@ -0,0 +1,99 @@
+package model
+
+import (
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TaxYearLocation is the time zone calendar years of tax statements are
+// counted in, Western Indonesian Time.
+var TaxYearLocation = time.FixedZone("WIB", 7*60*60)
+
+// TaxYearRange returns the start of year and of the following year.
+func TaxYearRange(year int) (time.Time, time.Time) {
+	from := time.Date(year, time.January, 1, 0, 0, 0, 0, TaxYearLocation)
+	return from, from.AddDate(1, 0, 0)
+}
+
+type TaxStatementFormat string
+
+const (
+	TaxStatementFormatPDF TaxStatementFormat = "pdf"
+	TaxStatementFormatCSV TaxStatementFormat = "csv"
+)
+
+func (f TaxStatementFormat) IsValid() bool {
+	return f == TaxStatementFormatPDF || f == TaxStatementFormatCSV
+}
+
+func (f TaxStatementFormat) ContentType() string {
+	if f == TaxStatementFormatCSV {
+		return "text/csv"
+	}
+
+	return "application/pdf"
+}
+
+// TaxStatementDonation is one paid transaction on a tax statement. Net is
+// what is left after partial refunds, in the currency the donor paid in.
+type TaxStatementDonation struct {
+	TransactionID primitive.ObjectID `json:"transaction_id"`
+	PostTitle     string             `json:"post_title"`
+	PaidAt        time.Time          `json:"paid_at"`
+	PaymentMethod string             `json:"payment_method"`
+	Amount        Money              `json:"amount"`
+	Net           Money              `json:"net"`
+}
+
+// TaxStatementInstitution groups the donations received by one institution.
+// Totals holds one net sum per currency donated in.
+type TaxStatementInstitution struct {
+	Institution Institution            `json:"institution"`
+	Donations   []TaxStatementDonation `json:"donations"`
+	Totals      []Money                `json:"totals"`
+}
+
+// TaxStatement aggregates a donor's paid transactions of a calendar year for
+// zakat and donation deduction claims.
+type TaxStatement struct {
+	UserID       string                    `json:"user_id"`
+	UserEmail    string                    `json:"user_email"`
+	DonorName    string                    `json:"donor_name"`
+	Year         int                       `json:"year"`
+	Institutions []TaxStatementInstitution `json:"institutions"`
+	Totals       []Money                   `json:"totals"`
+	GeneratedAt  time.Time                 `json:"generated_at"`
+}
+
+func (s *TaxStatement) Filename(format TaxStatementFormat) string {
+	return fmt.Sprintf("tax-statement-%d.%s", s.Year, format)
+}
+
+// TaxStatementFile is a tax statement rendered in one format.
+type TaxStatementFile struct {
+	Filename    string
+	ContentType string
+	Content     []byte
+}
+
+// TaxStatementRecipient is a donor with paid transactions in a year whose
+// statement has not been emailed yet. Attempts counts the emails that failed.
+type TaxStatementRecipient struct {
+	UserID    string `bson:"_id"`
+	UserEmail string `bson:"user_email"`
+	Attempts  int    `bson:"attempts"`
+}
+
+// AddTotal adds amount to the total of its currency.
+func AddTotal(totals []Money, amount Money) []Money {
+	for i := range totals {
+		if totals[i].Currency == amount.Currency {
+			totals[i] = totals[i].Add(amount)
+			return totals
+		}
+	}
+
+	return append(totals, amount)
+}
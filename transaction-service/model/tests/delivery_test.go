@@ -0,0 +1,24 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"transaction-service/model"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeliveryRetryAt(t *testing.T) {
+	now := time.Date(2026, time.January, 5, 9, 0, 0, 0, time.UTC)
+
+	t.Run("success - delay doubles after each failure", func(t *testing.T) {
+		assert.Equal(t, now.Add(time.Minute), model.DeliveryRetryAt(1, now))
+		assert.Equal(t, now.Add(2*time.Minute), model.DeliveryRetryAt(2, now))
+		assert.Equal(t, now.Add(16*time.Minute), model.DeliveryRetryAt(5, now))
+	})
+
+	t.Run("success - delay is capped", func(t *testing.T) {
+		assert.Equal(t, now.Add(6*time.Hour), model.DeliveryRetryAt(100, now))
+	})
+}
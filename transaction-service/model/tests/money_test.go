@@ -0,0 +1,111 @@
+package tests
+
+import (
+	"testing"
+
+	"transaction-service/model"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMoney(t *testing.T) {
+	t.Run("success - convert from and to major units", func(t *testing.T) {
+		amount := model.MoneyFromMajor(15000.5, "idr")
+
+		assert.Equal(t, model.NewMoney(1500050, model.CurrencyIDR), amount)
+		assert.Equal(t, 15000.5, amount.Major())
+		assert.False(t, amount.IsWhole())
+		assert.Equal(t, "IDR 15000.50", amount.String())
+	})
+
+	t.Run("success - currencies without minor units", func(t *testing.T) {
+		amount := model.MoneyFromMajor(1200, "JPY")
+
+		assert.Equal(t, int64(1200), amount.Amount)
+		assert.True(t, amount.IsWhole())
+	})
+
+	t.Run("success - sums are exact", func(t *testing.T) {
+		total := model.IDR(0)
+		for i := 0; i < 10; i++ {
+			total = total.Add(model.MoneyFromMajor(0.1, model.CurrencyIDR))
+		}
+
+		assert.Equal(t, model.IDR(1), total)
+	})
+
+	t.Run("success - unset zero takes the other currency", func(t *testing.T) {
+		assert.Equal(t, model.IDR(500), model.IDR(500).Sub(model.Money{}))
+		assert.Equal(t, model.IDR(500), model.Money{}.Add(model.IDR(500)))
+	})
+
+	t.Run("success - round up to a whole major unit", func(t *testing.T) {
+		assert.Equal(t, model.IDR(100001), model.NewMoney(10000050, model.CurrencyIDR).CeilMajor())
+		assert.Equal(t, model.IDR(100000), model.IDR(100000).CeilMajor())
+	})
+
+	t.Run("success - exact decimal without currency", func(t *testing.T) {
+		assert.Equal(t, "15000.05", model.NewMoney(1500005, model.CurrencyIDR).Decimal())
+		assert.Equal(t, "-0.50", model.NewMoney(-50, "USD").Decimal())
+		assert.Equal(t, "1200", model.NewMoney(1200, "JPY").Decimal())
+	})
+
+	t.Run("failed - unsupported currency", func(t *testing.T) {
+		assert.Error(t, model.NewMoney(100, "XYZ").Validate())
+	})
+
+	t.Run("failed - mixing currencies panics", func(t *testing.T) {
+		assert.Panics(t, func() {
+			model.IDR(100).Add(model.NewMoney(100, "USD"))
+		})
+	})
+}
+
+func TestFXRateSnapshot(t *testing.T) {
+	rate := &model.FXRateSnapshot{BaseCurrency: "USD", QuoteCurrency: "IDR", Rate: "15500.25"}
+
+	t.Run("success - convert rounds to the quote minor unit", func(t *testing.T) {
+		converted, err := rate.Convert(model.NewMoney(1, "USD"))
+
+		assert.NoError(t, err)
+		assert.Equal(t, model.NewMoney(15500, "IDR"), converted)
+	})
+
+	t.Run("success - convert between exponents", func(t *testing.T) {
+		jpy := &model.FXRateSnapshot{BaseCurrency: "JPY", QuoteCurrency: "SGD", Rate: "0.009"}
+
+		converted, err := jpy.Convert(model.NewMoney(1000, "JPY"))
+
+		assert.NoError(t, err)
+		assert.Equal(t, model.NewMoney(900, "SGD"), converted)
+	})
+
+	t.Run("success - invert reads the rate the other way", func(t *testing.T) {
+		inverted := rate.Invert()
+		converted, err := inverted.Convert(model.NewMoney(1550025, "IDR"))
+
+		assert.NoError(t, err)
+		assert.Equal(t, "IDR", inverted.BaseCurrency)
+		assert.Equal(t, model.NewMoney(100, "USD"), converted)
+	})
+
+	t.Run("failed - amount in another currency", func(t *testing.T) {
+		_, err := rate.Convert(model.NewMoney(100, "SGD"))
+
+		assert.Error(t, err)
+	})
+}
+
+func TestCampaignShare(t *testing.T) {
+	transaction := &model.Transaction{
+		Amount:          model.NewMoney(1000, "USD"),
+		ConvertedAmount: model.IDR(155000),
+	}
+
+	assert.Equal(t, model.IDR(155000), transaction.CampaignAmount())
+	assert.Equal(t, model.IDR(51615), transaction.CampaignShare(model.NewMoney(333, "USD")))
+	assert.Equal(t, model.IDR(155000), transaction.CampaignShare(model.NewMoney(1000, "USD")))
+
+	legacy := &model.Transaction{Amount: model.IDR(50000)}
+	assert.Equal(t, model.IDR(50000), legacy.CampaignAmount())
+}
@@ -0,0 +1,23 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"transaction-service/model"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPostHasEnded(t *testing.T) {
+	post := model.Post{DateEnd: time.Date(2026, time.March, 31, 0, 0, 0, 0, time.UTC)}
+
+	t.Run("success - open all of the last day", func(t *testing.T) {
+		assert.False(t, post.HasEnded(time.Date(2026, time.March, 31, 0, 0, 0, 0, time.UTC)))
+		assert.False(t, post.HasEnded(time.Date(2026, time.March, 31, 23, 59, 59, 0, time.UTC)))
+	})
+
+	t.Run("success - ended the day after", func(t *testing.T) {
+		assert.True(t, post.HasEnded(time.Date(2026, time.April, 1, 0, 0, 0, 0, time.UTC)))
+	})
+}
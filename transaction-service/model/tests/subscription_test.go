@@ -0,0 +1,38 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"transaction-service/model"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubscriptionChargeAfter(t *testing.T) {
+	at := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 9, 30, 0, 0, time.UTC)
+	}
+
+	t.Run("success - next month on the billing day", func(t *testing.T) {
+		subscription := model.Subscription{BillingDay: 15, NextChargeAt: at(2024, time.March, 15)}
+
+		assert.Equal(t, at(2024, time.April, 15), subscription.ChargeAfter(at(2024, time.March, 15)))
+	})
+
+	t.Run("success - shorter months clamp to their last day and recover", func(t *testing.T) {
+		subscription := model.Subscription{BillingDay: 31, NextChargeAt: at(2024, time.January, 31)}
+
+		february := subscription.ChargeAfter(at(2024, time.January, 31))
+		assert.Equal(t, at(2024, time.February, 29), february)
+
+		subscription.NextChargeAt = february
+		assert.Equal(t, at(2024, time.March, 31), subscription.ChargeAfter(february))
+	})
+
+	t.Run("success - missed cycles are skipped", func(t *testing.T) {
+		subscription := model.Subscription{BillingDay: 10, NextChargeAt: at(2024, time.January, 10)}
+
+		assert.Equal(t, at(2024, time.May, 10), subscription.ChargeAfter(at(2024, time.April, 20)))
+	})
+}
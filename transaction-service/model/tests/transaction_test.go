@@ -0,0 +1,33 @@
+package tests
+
+import (
+	"testing"
+
+	"transaction-service/model"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePaymentStatus(t *testing.T) {
+	t.Run("success - legacy spellings", func(t *testing.T) {
+		for value, want := range map[string]model.PaymentStatus{
+			"PAID":       model.PaymentStatusPaid,
+			"paid":       model.PaymentStatusPaid,
+			" Pending ":  model.PaymentStatusPending,
+			"settled":    model.PaymentStatusPaid,
+			"expired":    model.PaymentStatusExpired,
+			"Refunded\n": model.PaymentStatusRefunded,
+		} {
+			status, ok := model.ParsePaymentStatus(value)
+
+			assert.True(t, ok, value)
+			assert.Equal(t, want, status, value)
+		}
+	})
+
+	t.Run("failed - unknown status", func(t *testing.T) {
+		_, ok := model.ParsePaymentStatus("in progress")
+
+		assert.False(t, ok)
+	})
+}
@@ -1,68 +1,376 @@
 package model
 
 import (
+	"math/big"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+type PaymentStatus string
+
+const (
+	PaymentStatusCreated  PaymentStatus = "CREATED"
+	PaymentStatusPending  PaymentStatus = "PENDING"
+	PaymentStatusPaid     PaymentStatus = "PAID"
+	PaymentStatusExpired  PaymentStatus = "EXPIRED"
+	PaymentStatusFailed   PaymentStatus = "FAILED"
+	PaymentStatusRefunded PaymentStatus = "REFUNDED"
+)
+
+// paymentStatusTransitions lists the statuses each status may move to. A
+// payment the gateway confirms after the invoice was marked expired is still
+// money received, so EXPIRED may move to PAID. EXPIRED and FAILED go back to
+// PENDING when the donor retries with a fresh invoice.
+var paymentStatusTransitions = map[PaymentStatus][]PaymentStatus{
+	PaymentStatusCreated: {PaymentStatusPending, PaymentStatusFailed},
+	PaymentStatusPending: {PaymentStatusPaid, PaymentStatusExpired, PaymentStatusFailed},
+	PaymentStatusPaid:    {PaymentStatusRefunded},
+	PaymentStatusExpired: {PaymentStatusPaid, PaymentStatusPending},
+	PaymentStatusFailed:  {PaymentStatusPending},
+}
+
+func (s PaymentStatus) IsValid() bool {
+	switch s {
+	case PaymentStatusCreated, PaymentStatusPending, PaymentStatusPaid,
+		PaymentStatusExpired, PaymentStatusFailed, PaymentStatusRefunded:
+		return true
+	}
+
+	return false
+}
+
+// ParsePaymentStatus reads a status as stored before statuses were typed,
+// which may be lowercase or padded, or Xendit's SETTLED for a paid invoice. It
+// returns false for a value that is not a known status.
+func ParsePaymentStatus(value string) (PaymentStatus, bool) {
+	status := PaymentStatus(strings.ToUpper(strings.TrimSpace(value)))
+	if status == "SETTLED" {
+		status = PaymentStatusPaid
+	}
+
+	return status, status.IsValid()
+}
+
+func (s PaymentStatus) CanTransitionTo(next PaymentStatus) bool {
+	for _, status := range paymentStatusTransitions[s] {
+		if status == next {
+			return true
+		}
+	}
+
+	return false
+}
+
+type TransitionSource string
+
+const (
+	TransitionSourceAPI         TransitionSource = "api"
+	TransitionSourceWebhook     TransitionSource = "webhook"
+	TransitionSourceRedirect    TransitionSource = "redirect"
+	TransitionSourceReconciler  TransitionSource = "reconciler"
+	TransitionSourceAdmin       TransitionSource = "admin"
+	TransitionSourceInstitution TransitionSource = "institution"
+	TransitionSourceScheduler   TransitionSource = "scheduler"
+)
+
+// DonorNameVisibility is how a donor chose to appear on public donor lists.
+// Donations made before the choice existed have none and appear anonymous.
+type DonorNameVisibility string
+
+const (
+	DonorNameProfile   DonorNameVisibility = "PROFILE_NAME"
+	DonorNameDisplay   DonorNameVisibility = "DISPLAY_NAME"
+	DonorNameAnonymous DonorNameVisibility = "ANONYMOUS"
+)
+
+const AnonymousDonorName = "Anonymous"
+
+func (v DonorNameVisibility) IsValid() bool {
+	switch v {
+	case DonorNameProfile, DonorNameDisplay, DonorNameAnonymous:
+		return true
+	}
+
+	return false
+}
+
+type StatusTransition struct {
+	From   PaymentStatus    `json:"from,omitempty" bson:"from,omitempty"`
+	To     PaymentStatus    `json:"to" bson:"to"`
+	Source TransitionSource `json:"source" bson:"source"`
+	At     time.Time        `json:"at" bson:"at"`
+}
+
+type RefundStatus string
+
+// A refund is recorded as PENDING before the gateway is asked to make it, and
+// settled as SUCCEEDED or FAILED once the gateway answers. Refunds recorded
+// before then have no status, and all succeeded.
+const (
+	RefundStatusPending   RefundStatus = "PENDING"
+	RefundStatusSucceeded RefundStatus = "SUCCEEDED"
+	RefundStatusFailed    RefundStatus = "FAILED"
+)
+
+type Refund struct {
+	// RefundID is the gateway's ID of the refund, set once it is made.
+	RefundID string `json:"refund_id" bson:"refund_id"`
+	// ReferenceID is our ID of the refund, sent to the gateway with it.
+	ReferenceID string           `json:"reference_id" bson:"reference_id,omitempty"`
+	Status      RefundStatus     `json:"status" bson:"status,omitempty"`
+	Amount      Money            `json:"amount" bson:"amount_v2"`
+	Reason      string           `json:"reason" bson:"reason"`
+	Source      TransitionSource `json:"source" bson:"source"`
+	At          time.Time        `json:"at" bson:"at"`
+}
+
 type Transaction struct {
-	TransactionID primitive.ObjectID `json:"transaction_id" bson:"_id,omitempty"`
-	UserID        string             `json:"user_id" bson:"user_id"`
-	PostID        string             `json:"post_id" bson:"post_id"`
-	UserEmail     string             `json:"user_email" bson:"user_email"`
-	PaymentID     string             `json:"payment_id" gorm:"not null"`
-	PaymentURL    string             `json:"payment_url" gorm:""`
-	PaymentStatus string             `json:"payment_status" gorm:"default:'PENDING'"`
-	Amount        float64            `json:"amount" gorm:"not null"`
-	AccountNumber string             `json:"account_number" gorm:"not null"`
-	AccountName   string             `json:"account_name" gorm:"not null"`
-	CreatedAt     time.Time          `json:"created_at" gorm:"default:current_timestamp"`
-	UpdatedAt     time.Time          `json:"updated_at" gorm:"default:current_timestamp"`
+	TransactionID      primitive.ObjectID `json:"transaction_id" bson:"_id,omitempty"`
+	UserID             string             `json:"user_id" bson:"user_id"`
+	PostID             string             `json:"post_id" bson:"post_id"`
+	UserEmail          string             `json:"user_email" bson:"user_email"`
+	PaymentID          string             `json:"payment_id" bson:"payment_id" gorm:"not null"`
+	PaymentURL         string             `json:"payment_url" bson:"payment_url" gorm:""`
+	PaymentStatus      PaymentStatus      `json:"payment_status" bson:"payment_status" gorm:"default:'PENDING'"`
+	PaymentMethod      string             `json:"payment_method" bson:"payment_method"`
+	PaidAt             time.Time          `json:"paid_at" bson:"paid_at,omitempty"`
+	Amount             Money              `json:"amount" bson:"amount_v2"`
+	AccountNumber      string             `json:"account_number" bson:"account_number" gorm:"not null"`
+	AccountName        string             `json:"account_name" bson:"account_name" gorm:"not null"`
+	CreatedAt          time.Time          `json:"created_at" bson:"created_at" gorm:"default:current_timestamp"`
+	UpdatedAt          time.Time          `json:"updated_at" bson:"updated_at" gorm:"default:current_timestamp"`
+	StatusHistory      []StatusTransition `json:"status_history" bson:"status_history"`
+	Refunds            []Refund           `json:"refunds" bson:"refunds,omitempty"`
+	RefundedAmount     Money              `json:"refunded_amount" bson:"refunded_amount_v2"`
+	ExpiresAt          time.Time          `json:"expires_at" bson:"expires_at,omitempty"`
+	PreviousPaymentIDs []string           `json:"previous_payment_ids" bson:"previous_payment_ids,omitempty"`
+	// ConvertedAmount is Amount in the post's campaign currency, converted
+	// with FXRate when the donor paid in another currency.
+	ConvertedAmount Money           `json:"converted_amount" bson:"converted_amount"`
+	FXRate          *FXRateSnapshot `json:"fx_rate,omitempty" bson:"fx_rate,omitempty"`
+	Outbox          []OutboxEvent   `json:"-" bson:"outbox,omitempty"`
+	// DonorName is the public name the donor chose with DonorNameVisibility,
+	// resolved when the transaction is created so later profile changes do
+	// not rename past donations.
+	DonorNameVisibility DonorNameVisibility `json:"donor_name_visibility" bson:"donor_name_visibility,omitempty"`
+	DonorName           string              `json:"donor_name" bson:"donor_name,omitempty"`
+	// Message is an optional note from the donor for the post's supporters
+	// wall.
+	Message string `json:"message" bson:"message,omitempty"`
+	// SubscriptionID is set on donations charged by a recurring subscription.
+	SubscriptionID string        `json:"subscription_id,omitempty" bson:"subscription_id,omitempty"`
+	ReceiptStatus  ReceiptStatus `json:"-" bson:"receipt_status,omitempty"`
+	// ReceiptAttempts counts failed receipt emails; the next one is not tried
+	// before ReceiptRetryAt.
+	ReceiptAttempts int       `json:"-" bson:"receipt_attempts,omitempty"`
+	ReceiptRetryAt  time.Time `json:"-" bson:"receipt_retry_at,omitempty"`
+	// PostTitle and InstitutionID are copied from the post when the
+	// transaction is created, for when the post can no longer be read.
+	PostTitle     string `json:"post_title,omitempty" bson:"post_title,omitempty"`
+	InstitutionID string `json:"institution_id,omitempty" bson:"institution_id,omitempty"`
+}
+
+// CanRetryPayment reports whether the donor may be issued a fresh invoice.
+func (t *Transaction) CanRetryPayment() bool {
+	return t.PaymentStatus == PaymentStatusExpired || t.PaymentStatus == PaymentStatusFailed
+}
+
+// IsPreviousPayment reports whether paymentID is an invoice replaced by a retry.
+func (t *Transaction) IsPreviousPayment(paymentID string) bool {
+	for _, id := range t.PreviousPaymentIDs {
+		if id == paymentID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// PublicDonorName is the name the donation is shown under publicly.
+func (t *Transaction) PublicDonorName() string {
+	if t.DonorName == "" {
+		return AnonymousDonorName
+	}
+
+	return t.DonorName
+}
+
+// RefundableAmount is what is left of a paid transaction after earlier
+// partial refunds.
+func (t *Transaction) RefundableAmount() Money {
+	return t.Amount.Sub(t.RefundedAmount)
+}
+
+// PendingRefund is the refund the gateway has not answered yet, or nil.
+func (t *Transaction) PendingRefund() *Refund {
+	for i := range t.Refunds {
+		if t.Refunds[i].Status == RefundStatusPending {
+			return &t.Refunds[i]
+		}
+	}
+
+	return nil
+}
+
+// CampaignAmount is what the transaction credits to its post. Transactions
+// created before multi-currency donations were always in the post's currency.
+func (t *Transaction) CampaignAmount() Money {
+	if t.ConvertedAmount.Currency == "" {
+		return t.Amount
+	}
+
+	return t.ConvertedAmount
+}
+
+// CampaignShare is the part of CampaignAmount that amount, in the currency the
+// donor paid in, stands for. Shares of running totals telescope, so a series
+// of partial refunds debits exactly CampaignAmount in the end.
+func (t *Transaction) CampaignShare(amount Money) Money {
+	campaign := t.CampaignAmount()
+	if t.Amount.Amount == 0 || amount.Cmp(t.Amount) == 0 {
+		return NewMoney(campaign.Amount*signum(amount.Amount), campaign.Currency)
+	}
+
+	share := new(big.Rat).SetFrac(
+		new(big.Int).Mul(big.NewInt(campaign.Amount), big.NewInt(amount.Amount)),
+		big.NewInt(t.Amount.Amount),
+	)
+
+	return Money{Amount: roundRat(share), Currency: campaign.Currency}
+}
+
+func signum(value int64) int64 {
+	switch {
+	case value > 0:
+		return 1
+	case value < 0:
+		return -1
+	}
+
+	return 0
+}
+
+// TransactionFilter narrows a transaction listing. Results are ordered newest
+// first; Cursor is the ID of the last transaction of the previous page.
+type TransactionFilter struct {
+	UserEmail string
+	PostID    string
+	Status    PaymentStatus
+	DateFrom  time.Time
+	DateTo    time.Time
+	Cursor    primitive.ObjectID
+	Limit     int
+}
+
+type TransactionPage struct {
+	Transactions []Transaction
+	NextCursor   string
+}
+
+type RefundRequest struct {
+	// Amount is deprecated in favour of AmountV2.
+	Amount   float64 `json:"amount"`
+	AmountV2 *Money  `json:"amount_v2"`
+	Reason   string  `json:"reason"`
+}
+
+type RefundPostTransactionsResponse struct {
+	Refunded []TransactionResponse `json:"refunded"`
+	Failed   []string              `json:"failed"`
 }
 
 type TransactionRequest struct {
-	PostID        string  `json:"post_id" bson:"post_id"`
+	PostID string `json:"post_id" bson:"post_id"`
+	// Amount is deprecated in favour of AmountV2.
 	Amount        float64 `json:"amount"`
+	AmountV2      *Money  `json:"amount_v2"`
 	AccountNumber string  `json:"account_number"`
 	AccountName   string  `json:"account_name"`
+	// DonorNameVisibility is PROFILE_NAME, DISPLAY_NAME or ANONYMOUS, the
+	// default. DisplayName is required with DISPLAY_NAME.
+	DonorNameVisibility string `json:"donor_name_visibility"`
+	DisplayName         string `json:"display_name"`
+	// Message is shown on the post's public supporters wall.
+	Message string `json:"message"`
 }
 
 type TransactionResponse struct {
-	TransactionID string  `json:"transaction_id"`
-	UserID        string  `json:"user_id"`
-	PostID        string  `json:"post_id"`
-	UserEmail     string  `json:"user_email"`
-	PaymentID     string  `json:"payment_id"`
-	PaymentURL    string  `json:"payment_url"`
-	PaymentStatus string  `json:"payment_status"`
-	Amount        float64 `json:"amount"`
-	AccountNumber string  `json:"account_number"`
-	AccountName   string  `json:"account_name"`
+	TransactionID    string  `json:"transaction_id"`
+	UserID           string  `json:"user_id"`
+	PostID           string  `json:"post_id"`
+	UserEmail        string  `json:"user_email"`
+	PaymentID        string  `json:"payment_id"`
+	PaymentURL       string  `json:"payment_url"`
+	PaymentStatus    string  `json:"payment_status"`
+	Amount           float64 `json:"amount"`
+	AmountV2         Money   `json:"amount_v2"`
+	RefundedAmount   float64 `json:"refunded_amount"`
+	RefundedAmountV2 Money   `json:"refunded_amount_v2"`
+	AccountNumber    string  `json:"account_number"`
+	AccountName      string  `json:"account_name"`
+	// ConvertedAmount is the amount credited to the post, in its currency.
+	ConvertedAmount Money           `json:"converted_amount"`
+	FXRate          *FXRateSnapshot `json:"fx_rate,omitempty"`
+	DonorName       string          `json:"donor_name"`
+	Message         string          `json:"message"`
 }
 
+type TransactionListResponse struct {
+	Transactions []TransactionResponse `json:"transactions"`
+	NextCursor   string                `json:"next_cursor"`
+}
+
+// User is a donor as returned by user-service.
 type User struct {
-	UserID string `gorm:"primaryKey" json:"user_id"`
-	Name   string `gorm:"type:varchar(50);not null" json:"name"`
-	Email  string `gorm:"type:varchar(100);unique;not null" json:"email"`
+	UserID string `json:"user_id"`
+	Name   string `json:"name"`
+	Email  string `json:"email"`
 }
 
+// OverflowPolicy is set per post by institution-service and decides what
+// happens to a donation larger than what is left of the FundTarget. Posts
+// created before the policy existed have none and behave as ACCEPT.
+type OverflowPolicy string
+
+const (
+	OverflowPolicyAccept OverflowPolicy = "ACCEPT"
+	OverflowPolicyCap    OverflowPolicy = "CAP"
+	OverflowPolicyReject OverflowPolicy = "REJECT"
+)
+
+// PostStatusPublished is the only status in which a post accepts donations.
+const PostStatusPublished = "PUBLISHED"
+
+// Post is a fundraising as returned by institution-service, which owns it.
 type Post struct {
-	PostID       uuid.UUID `json:"post_id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
-	Title        string    `json:"title" gorm:"type:varchar(255); not null"`
-	Body         string    `json:"body" gorm:"type:text; not null"`
-	DateStart    time.Time `json:"date_start" gorm:"type:timestamp; not null"`
-	DateEnd      time.Time `json:"date_end" gorm:"type:timestamp; not null"`
-	FundTarget   float64   `json:"fund_target" gorm:"type:float; not null"`
-	FundAchieved float64   `json:"fund_achieved" gorm:"type:float; default:0"`
-}
-
-type FundCollect struct {
-	FundCollectID uuid.UUID `json:"fund_collect_id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
-	PostID        uuid.UUID `json:"post_id" gorm:"type:uuid; not null"`
-	UserID        string    `json:"user_id" gorm:"type:varchar(255); not null"`
-	UserName      string    `json:"user_name" gorm:"type:varchar(255); not null"`
-	Amount        float64   `json:"amount" gorm:"type:float; not null"`
-	TransactionID string    `json:"transaction_id" gorm:"type:varchar(255); not null"`
+	PostID         uuid.UUID      `json:"post_id"`
+	InstitutionID  uuid.UUID      `json:"institution_id"`
+	Title          string         `json:"title"`
+	Body           string         `json:"body"`
+	DateStart      time.Time      `json:"date_start"`
+	DateEnd        time.Time      `json:"date_end"`
+	FundTarget     Money          `json:"fund_target"`
+	FundAchieved   Money          `json:"fund_achieved"`
+	OverflowPolicy OverflowPolicy `json:"overflow_policy"`
+	Status         string         `json:"status"`
+	// Deleted posts are still found so their donations can be refunded.
+	Deleted bool `json:"deleted"`
+}
+
+func (p *Post) IsPublished() bool {
+	return p.Status == PostStatusPublished && !p.Deleted
+}
+
+// HasEnded reports whether the fundraising period of p is over at now.
+// institution-service only sends the date of DateEnd, so the post stays open
+// until the end of that day.
+func (p *Post) HasEnded(now time.Time) bool {
+	return !now.Before(p.DateEnd.AddDate(0, 0, 1))
+}
+
+func (p *Post) RemainingTarget() Money {
+	return p.FundTarget.Sub(p.FundAchieved)
 }
@@ -0,0 +1,78 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+
+	"transaction-service/model"
+
+	"github.com/rabbitmq/amqp091-go"
+)
+
+const DonationEventsQueue = "donation_events"
+
+type IEventPublisher interface {
+	PublishEvent(ctx context.Context, event model.OutboxEvent) error
+}
+
+// EventPublisher publishes outbox events to the queue consumed by
+// institution-service. It waits for the broker to confirm each message, so an
+// event is only marked published once RabbitMQ has it.
+type EventPublisher struct {
+	channel *amqp091.Channel
+	queue   amqp091.Queue
+}
+
+func NewEventPublisher(channel *amqp091.Channel, queueName string) (*EventPublisher, error) {
+	queue, err := channel.QueueDeclare(
+		queueName,
+		true,
+		false,
+		false,
+		false,
+		nil,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := channel.Confirm(false); err != nil {
+		return nil, fmt.Errorf("failed to enable publisher confirms: %v", err)
+	}
+
+	return &EventPublisher{
+		channel: channel,
+		queue:   queue,
+	}, nil
+}
+
+func (p *EventPublisher) PublishEvent(ctx context.Context, event model.OutboxEvent) error {
+	confirmation, err := p.channel.PublishWithDeferredConfirmWithContext(
+		ctx,
+		"",
+		p.queue.Name,
+		false,
+		false,
+		amqp091.Publishing{
+			ContentType:  "application/json",
+			DeliveryMode: amqp091.Persistent,
+			MessageId:    event.EventID,
+			Type:         event.Type,
+			Timestamp:    event.CreatedAt,
+			Body:         event.Payload,
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	acked, err := confirmation.WaitContext(ctx)
+	if err != nil {
+		return err
+	}
+	if !acked {
+		return fmt.Errorf("broker rejected event %s", event.EventID)
+	}
+
+	return nil
+}
@@ -0,0 +1,206 @@
+package queue
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"transaction-service/model"
+
+	"github.com/rabbitmq/amqp091-go"
+	"github.com/sirupsen/logrus"
+)
+
+type IEmailPublisher interface {
+	PublishRefundNotification(transaction *model.Transaction, refund model.Refund) error
+	PublishSubscriptionReminder(subscription *model.Subscription) error
+	PublishSubscriptionInvoice(subscription *model.Subscription, transaction *model.Transaction) error
+	PublishReceipt(transaction *model.Transaction, receipt *model.Receipt) error
+	PublishTaxStatement(statement *model.TaxStatement, files ...model.TaxStatementFile) error
+}
+
+// Attachment is a file sent along with an email. Content is base64 encoded in
+// the queue message.
+type Attachment struct {
+	Filename    string `json:"filename"`
+	ContentType string `json:"content_type"`
+	Content     []byte `json:"content"`
+}
+
+// EmailPublisher sends messages to the queue consumed by notification-service.
+type EmailPublisher struct {
+	channel *amqp091.Channel
+	queue   amqp091.Queue
+}
+
+func NewEmailPublisher(channel *amqp091.Channel, queueName string) (*EmailPublisher, error) {
+	queue, err := channel.QueueDeclare(
+		queueName,
+		true,
+		false,
+		false,
+		false,
+		nil,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &EmailPublisher{
+		channel: channel,
+		queue:   queue,
+	}, nil
+}
+
+func (p *EmailPublisher) PublishRefundNotification(transaction *model.Transaction, refund model.Refund) error {
+	return p.publish(transaction.UserEmail, "Pengembalian Donasi", refundMessage(transaction, refund))
+}
+
+func (p *EmailPublisher) PublishSubscriptionReminder(subscription *model.Subscription) error {
+	return p.publish(subscription.UserEmail, "Pengingat Donasi Bulanan", subscriptionReminderMessage(subscription))
+}
+
+func (p *EmailPublisher) PublishSubscriptionInvoice(subscription *model.Subscription, transaction *model.Transaction) error {
+	return p.publish(subscription.UserEmail, "Tagihan Donasi Bulanan", subscriptionInvoiceMessage(subscription, transaction))
+}
+
+func (p *EmailPublisher) PublishReceipt(transaction *model.Transaction, receipt *model.Receipt) error {
+	return p.publish(transaction.UserEmail, "Kuitansi Donasi", receiptMessage(transaction), Attachment{
+		Filename:    receipt.Filename(),
+		ContentType: "application/pdf",
+		Content:     receipt.PDF,
+	})
+}
+
+func (p *EmailPublisher) PublishTaxStatement(statement *model.TaxStatement, files ...model.TaxStatementFile) error {
+	attachments := make([]Attachment, 0, len(files))
+	for _, file := range files {
+		attachments = append(attachments, Attachment{
+			Filename:    file.Filename,
+			ContentType: file.ContentType,
+			Content:     file.Content,
+		})
+	}
+
+	subject := fmt.Sprintf("Laporan Donasi Tahunan %d", statement.Year)
+	return p.publish(statement.UserEmail, subject, taxStatementMessage(statement), attachments...)
+}
+
+func (p *EmailPublisher) publish(email, subject, message string, attachments ...Attachment) error {
+	payload := map[string]interface{}{
+		"email":   email,
+		"subject": subject,
+		"message": message,
+	}
+	if len(attachments) > 0 {
+		payload["attachments"] = attachments
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	err = p.channel.Publish(
+		"",
+		p.queue.Name,
+		false,
+		false,
+		amqp091.Publishing{
+			ContentType: "application/json",
+			Body:        body,
+		},
+	)
+	if err != nil {
+		logrus.WithError(err).Errorf("Failed to publish %s email", subject)
+		return err
+	}
+
+	logrus.WithField("email", email).Infof("%s email published", subject)
+	return nil
+}
+
+// LogEmailPublisher logs notifications instead of sending them. It is used
+// when RabbitMQ is not configured.
+type LogEmailPublisher struct{}
+
+func (LogEmailPublisher) PublishRefundNotification(transaction *model.Transaction, refund model.Refund) error {
+	logrus.WithField("email", transaction.UserEmail).Infof("Refund notification not sent, RabbitMQ is not configured: refund %s of %s", refund.RefundID, refund.Amount)
+	return nil
+}
+
+func (LogEmailPublisher) PublishSubscriptionReminder(subscription *model.Subscription) error {
+	logrus.WithField("email", subscription.UserEmail).Infof("Subscription reminder not sent, RabbitMQ is not configured: subscription %s charges %s on %s", subscription.SubscriptionID.Hex(), subscription.Amount, subscription.NextChargeAt.Format("2006-01-02"))
+	return nil
+}
+
+func (LogEmailPublisher) PublishSubscriptionInvoice(subscription *model.Subscription, transaction *model.Transaction) error {
+	logrus.WithField("email", subscription.UserEmail).Infof("Subscription invoice not sent, RabbitMQ is not configured: subscription %s, transaction %s, %s", subscription.SubscriptionID.Hex(), transaction.TransactionID.Hex(), transaction.PaymentURL)
+	return nil
+}
+
+func (LogEmailPublisher) PublishReceipt(transaction *model.Transaction, receipt *model.Receipt) error {
+	logrus.WithField("email", transaction.UserEmail).Infof("Receipt not sent, RabbitMQ is not configured: %s for transaction %s", receipt.Filename(), transaction.TransactionID.Hex())
+	return nil
+}
+
+func (LogEmailPublisher) PublishTaxStatement(statement *model.TaxStatement, files ...model.TaxStatementFile) error {
+	logrus.WithField("email", statement.UserEmail).Infof("Tax statement not sent, RabbitMQ is not configured: %d statement of user %s with %d files", statement.Year, statement.UserID, len(files))
+	return nil
+}
+
+func refundMessage(transaction *model.Transaction, refund model.Refund) string {
+	message := fmt.Sprintf(`
+		<p>Halo %s,</p>
+		<p>Donasi Anda dengan ID transaksi <b>%s</b> telah dikembalikan sebesar <b>%s</b>.</p>
+	`, transaction.AccountName, transaction.TransactionID.Hex(), refund.Amount)
+
+	if refund.Reason != "" {
+		message += fmt.Sprintf("<p>Alasan: %s</p>\n", refund.Reason)
+	}
+	if remaining := transaction.RefundableAmount(); remaining.Amount > 0 {
+		message += fmt.Sprintf("<p>Sisa donasi Anda sebesar %s tetap tersalurkan.</p>\n", remaining)
+	}
+
+	return message + "<p>Terima kasih atas dukungan Anda.</p>\n"
+}
+
+func subscriptionReminderMessage(subscription *model.Subscription) string {
+	return fmt.Sprintf(`
+		<p>Halo %s,</p>
+		<p>Donasi bulanan Anda sebesar <b>%s</b> akan ditagihkan pada <b>%s</b>.</p>
+		<p>Anda dapat menjeda atau membatalkan langganan <b>%s</b> sebelum tanggal tersebut.</p>
+		<p>Terima kasih atas dukungan Anda.</p>
+	`, subscription.AccountName, subscription.Amount, subscription.NextChargeAt.Format("02-01-2006"), subscription.SubscriptionID.Hex())
+}
+
+func subscriptionInvoiceMessage(subscription *model.Subscription, transaction *model.Transaction) string {
+	return fmt.Sprintf(`
+		<p>Halo %s,</p>
+		<p>Tagihan donasi bulanan Anda sebesar <b>%s</b> telah dibuat dengan ID transaksi <b>%s</b>.</p>
+		<p>Silakan selesaikan pembayaran melalui tautan berikut: <a href="%s">%s</a></p>
+		<p>Terima kasih atas dukungan Anda.</p>
+	`, subscription.AccountName, transaction.Amount, transaction.TransactionID.Hex(), transaction.PaymentURL, transaction.PaymentURL)
+}
+
+func receiptMessage(transaction *model.Transaction) string {
+	return fmt.Sprintf(`
+		<p>Halo %s,</p>
+		<p>Terima kasih, donasi Anda sebesar <b>%s</b> dengan ID transaksi <b>%s</b> telah kami terima.</p>
+		<p>Kuitansi donasi terlampir dan dapat digunakan untuk pengajuan pengurangan pajak.</p>
+	`, transaction.AccountName, transaction.Amount, transaction.TransactionID.Hex())
+}
+
+func taxStatementMessage(statement *model.TaxStatement) string {
+	totals := make([]string, 0, len(statement.Totals))
+	for _, total := range statement.Totals {
+		totals = append(totals, total.String())
+	}
+
+	return fmt.Sprintf(`
+		<p>Halo %s,</p>
+		<p>Terlampir laporan donasi Anda selama tahun <b>%d</b> kepada <b>%d</b> lembaga dengan total <b>%s</b>, dalam format PDF dan CSV.</p>
+		<p>Laporan ini dapat digunakan untuk pengajuan pengurangan zakat atau sumbangan pada SPT Tahunan Anda.</p>
+		<p>Terima kasih atas dukungan Anda.</p>
+	`, statement.DonorName, statement.Year, len(statement.Institutions), strings.Join(totals, " + "))
+}
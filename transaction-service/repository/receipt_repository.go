@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"transaction-service/model"
+	pbInstitution "transaction-service/pb/institution"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type IReceiptRepository interface {
+	GetReceiptByTransactionID(ctx context.Context, transactionID primitive.ObjectID) (*model.Receipt, error)
+	SaveReceipt(ctx context.Context, receipt *model.Receipt) (*model.Receipt, error)
+	GetTransactionsAwaitingReceipt(ctx context.Context, limit int64) ([]model.Transaction, error)
+	MarkReceiptSent(ctx context.Context, transactionID primitive.ObjectID) error
+	RecordReceiptFailure(ctx context.Context, transactionID primitive.ObjectID, status model.ReceiptStatus, attempts int, retryAt time.Time) error
+	GetInstitutionByID(ctx context.Context, institutionID uuid.UUID) (*model.Institution, error)
+}
+
+type ReceiptRepository struct {
+	receiptCollection     *mongo.Collection
+	transactionCollection *mongo.Collection
+	institutionClient     pbInstitution.InstitutionServiceClient
+}
+
+func NewReceiptRepository(mongos *mongo.Database, institutionClient pbInstitution.InstitutionServiceClient) *ReceiptRepository {
+	return &ReceiptRepository{
+		receiptCollection:     mongos.Collection("receipts"),
+		transactionCollection: mongos.Collection("transactions"),
+		institutionClient:     institutionClient,
+	}
+}
+
+func (r *ReceiptRepository) GetReceiptByTransactionID(ctx context.Context, transactionID primitive.ObjectID) (*model.Receipt, error) {
+	var receipt model.Receipt
+	if err := r.receiptCollection.FindOne(ctx, bson.M{"transaction_id": transactionID}).Decode(&receipt); err != nil {
+		return nil, err
+	}
+
+	return &receipt, nil
+}
+
+// SaveReceipt stores the receipt, replacing any earlier issue for the same
+// transaction.
+func (r *ReceiptRepository) SaveReceipt(ctx context.Context, receipt *model.Receipt) (*model.Receipt, error) {
+	opts := options.FindOneAndReplace().
+		SetUpsert(true).
+		SetReturnDocument(options.After)
+
+	replacement := *receipt
+	replacement.ReceiptID = primitive.NilObjectID
+
+	var saved model.Receipt
+	err := r.receiptCollection.FindOneAndReplace(ctx, bson.M{"transaction_id": receipt.TransactionID}, replacement, opts).Decode(&saved)
+	if err != nil {
+		return nil, err
+	}
+
+	return &saved, nil
+}
+
+// GetTransactionsAwaitingReceipt returns paid transactions whose receipt has
+// not been emailed yet, oldest payment first. Receipts that failed are left
+// out until their retry time, so they cannot hold up the others.
+func (r *ReceiptRepository) GetTransactionsAwaitingReceipt(ctx context.Context, limit int64) ([]model.Transaction, error) {
+	filter := bson.M{
+		"payment_status": model.PaymentStatusPaid,
+		"receipt_status": model.ReceiptStatusPending,
+		"$or": bson.A{
+			bson.M{"receipt_retry_at": bson.M{"$exists": false}},
+			bson.M{"receipt_retry_at": bson.M{"$lte": time.Now()}},
+		},
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "paid_at", Value: 1}}).
+		SetLimit(limit)
+
+	cursor, err := r.transactionCollection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var transactions []model.Transaction
+	if err := cursor.All(ctx, &transactions); err != nil {
+		return nil, err
+	}
+
+	return transactions, nil
+}
+
+func (r *ReceiptRepository) MarkReceiptSent(ctx context.Context, transactionID primitive.ObjectID) error {
+	_, err := r.transactionCollection.UpdateOne(ctx,
+		bson.M{"_id": transactionID},
+		bson.M{"$set": bson.M{"receipt_status": model.ReceiptStatusSent}},
+	)
+
+	return err
+}
+
+func (r *ReceiptRepository) RecordReceiptFailure(ctx context.Context, transactionID primitive.ObjectID, status model.ReceiptStatus, attempts int, retryAt time.Time) error {
+	_, err := r.transactionCollection.UpdateOne(ctx,
+		bson.M{"_id": transactionID},
+		bson.M{"$set": bson.M{
+			"receipt_status":   status,
+			"receipt_attempts": attempts,
+			"receipt_retry_at": retryAt,
+		}},
+	)
+
+	return err
+}
+
+func (r *ReceiptRepository) GetInstitutionByID(ctx context.Context, institutionID uuid.UUID) (*model.Institution, error) {
+	return getPublicInstitution(ctx, r.institutionClient, institutionID)
+}
+
+func getPublicInstitution(ctx context.Context, institutionClient pbInstitution.InstitutionServiceClient, institutionID uuid.UUID) (*model.Institution, error) {
+	res, err := institutionClient.GetPublicInstitution(ctx, &pbInstitution.GetInstitutionByIDRequest{InstitutionId: institutionID.String()})
+	if err != nil {
+		return nil, err
+	}
+
+	parsedID, err := uuid.Parse(res.InstitutionId)
+	if err != nil {
+		return nil, fmt.Errorf("invalid institution ID %q: %v", res.InstitutionId, err)
+	}
+
+	return &model.Institution{
+		InstitutionID: parsedID,
+		Name:          res.Name,
+		Address:       res.Address,
+		Website:       res.Website,
+	}, nil
+}
@@ -0,0 +1,215 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"transaction-service/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type ISubscriptionRepository interface {
+	CreateSubscription(ctx context.Context, subscription *model.Subscription) (*model.Subscription, error)
+	GetSubscriptionByID(ctx context.Context, subscriptionID primitive.ObjectID) (*model.Subscription, error)
+	GetSubscriptionsByEmail(ctx context.Context, email string) ([]model.Subscription, error)
+	UpdateSubscriptionStatus(ctx context.Context, subscription *model.Subscription, from model.SubscriptionStatus) (bool, error)
+	GetDueSubscriptions(ctx context.Context, dueBefore time.Time, limit int64) ([]model.Subscription, error)
+	GetSubscriptionsToRemind(ctx context.Context, dueBefore time.Time, limit int64) ([]model.Subscription, error)
+	MarkReminderSent(ctx context.Context, subscriptionID primitive.ObjectID, chargeAt time.Time) error
+	AdvanceSubscription(ctx context.Context, subscription *model.Subscription, nextChargeAt, retryAt time.Time, transactionID primitive.ObjectID) (bool, error)
+	RetryCharge(ctx context.Context, subscription *model.Subscription, retryAt time.Time) (bool, error)
+	CompleteCharge(ctx context.Context, subscriptionID primitive.ObjectID, transactionID string) error
+}
+
+type SubscriptionRepository struct {
+	subscriptionCollection *mongo.Collection
+}
+
+func NewSubscriptionRepository(mongos *mongo.Database) *SubscriptionRepository {
+	return &SubscriptionRepository{
+		subscriptionCollection: mongos.Collection("subscriptions"),
+	}
+}
+
+func (r *SubscriptionRepository) CreateSubscription(ctx context.Context, subscription *model.Subscription) (*model.Subscription, error) {
+	result, err := r.subscriptionCollection.InsertOne(ctx, subscription)
+	if err != nil {
+		return nil, err
+	}
+
+	subscription.SubscriptionID = result.InsertedID.(primitive.ObjectID)
+
+	return subscription, nil
+}
+
+func (r *SubscriptionRepository) GetSubscriptionByID(ctx context.Context, subscriptionID primitive.ObjectID) (*model.Subscription, error) {
+	var subscription model.Subscription
+	if err := r.subscriptionCollection.FindOne(ctx, bson.M{"_id": subscriptionID}).Decode(&subscription); err != nil {
+		return nil, err
+	}
+
+	return &subscription, nil
+}
+
+func (r *SubscriptionRepository) GetSubscriptionsByEmail(ctx context.Context, email string) ([]model.Subscription, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: -1}})
+
+	cursor, err := r.subscriptionCollection.Find(ctx, bson.M{"user_email": email}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	subscriptions := []model.Subscription{}
+	if err := cursor.All(ctx, &subscriptions); err != nil {
+		return nil, err
+	}
+
+	return subscriptions, nil
+}
+
+// UpdateSubscriptionStatus writes the subscription's status and next charge
+// date only if it is still in status from. It returns false when a concurrent
+// request changed it first.
+func (r *SubscriptionRepository) UpdateSubscriptionStatus(ctx context.Context, subscription *model.Subscription, from model.SubscriptionStatus) (bool, error) {
+	set := bson.M{
+		"status":         subscription.Status,
+		"next_charge_at": subscription.NextChargeAt,
+		"updated_at":     subscription.UpdatedAt,
+	}
+	if !subscription.CancelledAt.IsZero() {
+		set["cancelled_at"] = subscription.CancelledAt
+	}
+
+	result, err := r.subscriptionCollection.UpdateOne(ctx,
+		bson.M{"_id": subscription.SubscriptionID, "status": from},
+		bson.M{"$set": set},
+	)
+	if err != nil {
+		return false, err
+	}
+
+	return result.MatchedCount > 0, nil
+}
+
+// GetDueSubscriptions returns active subscriptions whose next charge is due by
+// dueBefore, or whose claimed cycle is to be retried by then, oldest first.
+func (r *SubscriptionRepository) GetDueSubscriptions(ctx context.Context, dueBefore time.Time, limit int64) ([]model.Subscription, error) {
+	return r.findActive(ctx, bson.M{"$or": bson.A{
+		bson.M{
+			"charging_cycle": bson.M{"$exists": false},
+			"next_charge_at": bson.M{"$lte": dueBefore},
+		},
+		bson.M{"charge_retry_at": bson.M{"$lte": dueBefore}},
+	}}, limit)
+}
+
+// GetSubscriptionsToRemind returns active subscriptions charging by dueBefore
+// whose donor has not been reminded of that charge yet.
+func (r *SubscriptionRepository) GetSubscriptionsToRemind(ctx context.Context, dueBefore time.Time, limit int64) ([]model.Subscription, error) {
+	return r.findActive(ctx, bson.M{
+		"next_charge_at": bson.M{"$lte": dueBefore},
+		"$expr":          bson.M{"$ne": bson.A{"$reminder_sent_for", "$next_charge_at"}},
+	}, limit)
+}
+
+func (r *SubscriptionRepository) findActive(ctx context.Context, filter bson.M, limit int64) ([]model.Subscription, error) {
+	filter["status"] = model.SubscriptionStatusActive
+	opts := options.Find().
+		SetSort(bson.D{{Key: "next_charge_at", Value: 1}}).
+		SetLimit(limit)
+
+	cursor, err := r.subscriptionCollection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var subscriptions []model.Subscription
+	if err := cursor.All(ctx, &subscriptions); err != nil {
+		return nil, err
+	}
+
+	return subscriptions, nil
+}
+
+func (r *SubscriptionRepository) MarkReminderSent(ctx context.Context, subscriptionID primitive.ObjectID, chargeAt time.Time) error {
+	_, err := r.subscriptionCollection.UpdateOne(ctx,
+		bson.M{"_id": subscriptionID},
+		bson.M{"$set": bson.M{"reminder_sent_for": chargeAt}},
+	)
+
+	return err
+}
+
+// AdvanceSubscription moves an active subscription from its current next
+// charge date to nextChargeAt and records the cycle it leaves as claimed, to
+// be charged as transactionID and retried from retryAt until CompleteCharge.
+// Only one caller can advance a given cycle, so the scheduler claims a cycle
+// with it before charging, and a cycle is never charged twice.
+func (r *SubscriptionRepository) AdvanceSubscription(ctx context.Context, subscription *model.Subscription, nextChargeAt, retryAt time.Time, transactionID primitive.ObjectID) (bool, error) {
+	result, err := r.subscriptionCollection.UpdateOne(ctx,
+		bson.M{
+			"_id":            subscription.SubscriptionID,
+			"status":         model.SubscriptionStatusActive,
+			"next_charge_at": subscription.NextChargeAt,
+			"charging_cycle": bson.M{"$exists": false},
+		},
+		bson.M{"$set": bson.M{
+			"next_charge_at":        nextChargeAt,
+			"charging_cycle":        subscription.NextChargeAt,
+			"charge_retry_at":       retryAt,
+			"charge_transaction_id": transactionID,
+			"updated_at":            time.Now(),
+		}},
+	)
+	if err != nil {
+		return false, err
+	}
+
+	return result.MatchedCount > 0, nil
+}
+
+// RetryCharge claims the retry of the subscription's claimed cycle by moving
+// its retry date to retryAt. It returns false when another caller claimed the
+// retry, or completed the cycle, first.
+func (r *SubscriptionRepository) RetryCharge(ctx context.Context, subscription *model.Subscription, retryAt time.Time) (bool, error) {
+	result, err := r.subscriptionCollection.UpdateOne(ctx,
+		bson.M{
+			"_id":             subscription.SubscriptionID,
+			"status":          model.SubscriptionStatusActive,
+			"charging_cycle":  subscription.ChargingCycle,
+			"charge_retry_at": subscription.ChargeRetryAt,
+		},
+		bson.M{"$set": bson.M{
+			"charge_retry_at": retryAt,
+			"updated_at":      time.Now(),
+		}},
+	)
+	if err != nil {
+		return false, err
+	}
+
+	return result.MatchedCount > 0, nil
+}
+
+// CompleteCharge releases the subscription's claimed cycle, recording
+// transactionID as its last transaction unless the cycle was skipped.
+func (r *SubscriptionRepository) CompleteCharge(ctx context.Context, subscriptionID primitive.ObjectID, transactionID string) error {
+	update := bson.M{"$unset": bson.M{
+		"charging_cycle":        "",
+		"charge_retry_at":       "",
+		"charge_transaction_id": "",
+	}}
+	if transactionID != "" {
+		update["$set"] = bson.M{"last_transaction_id": transactionID}
+	}
+
+	_, err := r.subscriptionCollection.UpdateOne(ctx, bson.M{"_id": subscriptionID}, update)
+
+	return err
+}
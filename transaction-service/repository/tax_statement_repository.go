@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"transaction-service/model"
+	pbInstitution "transaction-service/pb/institution"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type ITaxStatementRepository interface {
+	GetPaidTransactionsByUserID(ctx context.Context, userID string, from, to time.Time) ([]model.Transaction, error)
+	GetTaxStatementRecipients(ctx context.Context, year int, limit int64) ([]model.TaxStatementRecipient, error)
+	MarkTaxStatementSent(ctx context.Context, userID string, year int) error
+	RecordTaxStatementFailure(ctx context.Context, userID string, year int, attempts int, retryAt time.Time) error
+	GetInstitutionByID(ctx context.Context, institutionID uuid.UUID) (*model.Institution, error)
+}
+
+type TaxStatementRepository struct {
+	transactionCollection  *mongo.Collection
+	taxStatementCollection *mongo.Collection
+	institutionClient      pbInstitution.InstitutionServiceClient
+}
+
+func NewTaxStatementRepository(mongos *mongo.Database, institutionClient pbInstitution.InstitutionServiceClient) *TaxStatementRepository {
+	return &TaxStatementRepository{
+		transactionCollection:  mongos.Collection("transactions"),
+		taxStatementCollection: mongos.Collection("tax_statements"),
+		institutionClient:      institutionClient,
+	}
+}
+
+// paidBetween matches paid transactions with from <= paid_at < to.
+func paidBetween(from, to time.Time) bson.M {
+	return bson.M{
+		"payment_status": model.PaymentStatusPaid,
+		"paid_at":        bson.M{"$gte": from, "$lt": to},
+	}
+}
+
+func (r *TaxStatementRepository) GetPaidTransactionsByUserID(ctx context.Context, userID string, from, to time.Time) ([]model.Transaction, error) {
+	filter := paidBetween(from, to)
+	filter["user_id"] = userID
+
+	opts := options.Find().SetSort(bson.D{{Key: "paid_at", Value: 1}})
+
+	cursor, err := r.transactionCollection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var transactions []model.Transaction
+	if err := cursor.All(ctx, &transactions); err != nil {
+		return nil, err
+	}
+
+	return transactions, nil
+}
+
+// GetTaxStatementRecipients returns donors with paid transactions in year
+// whose statement for that year has not been emailed yet. Donors whose
+// statement failed are left out until its retry time, and for good after
+// MaxDeliveryAttempts, so they cannot hold up the others.
+func (r *TaxStatementRepository) GetTaxStatementRecipients(ctx context.Context, year int, limit int64) ([]model.TaxStatementRecipient, error) {
+	from, to := model.TaxYearRange(year)
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: paidBetween(from, to)}},
+		{{Key: "$sort", Value: bson.D{{Key: "paid_at", Value: 1}}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":        "$user_id",
+			"user_email": bson.M{"$last": "$user_email"},
+		}}},
+		{{Key: "$lookup", Value: bson.M{
+			"from": r.taxStatementCollection.Name(),
+			"let":  bson.M{"user_id": "$_id"},
+			"pipeline": bson.A{
+				bson.M{"$match": bson.M{
+					"year":  year,
+					"$expr": bson.M{"$eq": bson.A{"$user_id", "$$user_id"}},
+				}},
+			},
+			"as": "delivery",
+		}}},
+		{{Key: "$match", Value: bson.M{
+			"delivery.sent_at":  bson.M{"$exists": false},
+			"delivery.attempts": bson.M{"$not": bson.M{"$gte": model.MaxDeliveryAttempts}},
+			"$or": bson.A{
+				bson.M{"delivery.retry_at": bson.M{"$exists": false}},
+				bson.M{"delivery.retry_at": bson.M{"$lte": time.Now()}},
+			},
+		}}},
+		{{Key: "$set", Value: bson.M{
+			"attempts": bson.M{"$ifNull": bson.A{bson.M{"$arrayElemAt": bson.A{"$delivery.attempts", 0}}, 0}},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "_id", Value: 1}}}},
+		{{Key: "$limit", Value: limit}},
+	}
+
+	cursor, err := r.transactionCollection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var recipients []model.TaxStatementRecipient
+	if err := cursor.All(ctx, &recipients); err != nil {
+		return nil, err
+	}
+
+	return recipients, nil
+}
+
+func (r *TaxStatementRepository) MarkTaxStatementSent(ctx context.Context, userID string, year int) error {
+	_, err := r.taxStatementCollection.UpdateOne(ctx,
+		bson.M{"user_id": userID, "year": year},
+		bson.M{"$set": bson.M{"sent_at": time.Now()}},
+		options.Update().SetUpsert(true),
+	)
+
+	return err
+}
+
+func (r *TaxStatementRepository) RecordTaxStatementFailure(ctx context.Context, userID string, year int, attempts int, retryAt time.Time) error {
+	_, err := r.taxStatementCollection.UpdateOne(ctx,
+		bson.M{"user_id": userID, "year": year},
+		bson.M{"$set": bson.M{"attempts": attempts, "retry_at": retryAt}},
+		options.Update().SetUpsert(true),
+	)
+
+	return err
+}
+
+func (r *TaxStatementRepository) GetInstitutionByID(ctx context.Context, institutionID uuid.UUID) (*model.Institution, error) {
+	return getPublicInstitution(ctx, r.institutionClient, institutionID)
+}
@@ -0,0 +1,258 @@
+package tests
+
+import (
+	"context"
+	"log"
+	"testing"
+	"time"
+
+	"transaction-service/model"
+	pbPost "transaction-service/pb/post"
+	pbUser "transaction-service/pb/user"
+	"transaction-service/repository"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func NewTransactionMockDB() (*mongo.Database, *gorm.DB, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatalf("An error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{
+		Conn: db,
+	}), &gorm.Config{})
+	if err != nil {
+		log.Fatalf("An error '%s' was not expected when opening gorm database", err)
+	}
+
+	// The client connects lazily, so no MongoDB server is needed for tests
+	// that only touch Postgres.
+	mongoClient, err := mongo.Connect(context.Background(), options.Client().ApplyURI("mongodb://localhost:27017"))
+	if err != nil {
+		log.Fatalf("An error '%s' was not expected when creating mongo client", err)
+	}
+
+	return mongoClient.Database("test"), gormDB, mock
+}
+
+func TestGetLatestFXRate(t *testing.T) {
+	t.Run("success - latest rate of the pair", func(t *testing.T) {
+		mongoDB, db, mock := NewTransactionMockDB()
+		repo := repository.NewTransactionRepository(mongoDB, db, nil, nil)
+
+		rateID := uuid.New()
+		mock.ExpectQuery(`SELECT \* FROM "fx_rates" WHERE base_currency = \$1 AND quote_currency = \$2 ORDER BY created_at DESC,"fx_rates"."fx_rate_id" LIMIT \$3`).
+			WithArgs("USD", "IDR", 1).
+			WillReturnRows(sqlmock.NewRows([]string{"fx_rate_id", "base_currency", "quote_currency", "rate"}).
+				AddRow(rateID, "USD", "IDR", "15500.000000000000"))
+
+		ctx := context.Background()
+		rate, err := repo.GetLatestFXRate(ctx, "USD", "IDR")
+
+		assert.NoError(t, err)
+		assert.Equal(t, rateID, rate.FXRateID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("failed - pair without a rate", func(t *testing.T) {
+		mongoDB, db, mock := NewTransactionMockDB()
+		repo := repository.NewTransactionRepository(mongoDB, db, nil, nil)
+
+		mock.ExpectQuery(`SELECT \* FROM "fx_rates"`).
+			WillReturnRows(sqlmock.NewRows([]string{"fx_rate_id"}))
+
+		ctx := context.Background()
+		rate, err := repo.GetLatestFXRate(ctx, "SGD", "IDR")
+
+		assert.Nil(t, rate)
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+type fakePostClient struct {
+	pbPost.PostServiceClient
+	post          *pbPost.PostResponse
+	pages         map[string]*pbPost.GetAllPostResponse
+	requests      []*pbPost.GetAllPostRequest
+	authorization []string
+	err           error
+}
+
+func (c *fakePostClient) LookupPost(ctx context.Context, req *pbPost.LookupPostRequest, opts ...grpc.CallOption) (*pbPost.PostResponse, error) {
+	md, _ := metadata.FromOutgoingContext(ctx)
+	c.authorization = md["authorization"]
+	return c.post, c.err
+}
+
+// GetAllPost returns the page stored under the request's cursor.
+func (c *fakePostClient) GetAllPost(ctx context.Context, req *pbPost.GetAllPostRequest, opts ...grpc.CallOption) (*pbPost.GetAllPostResponse, error) {
+	c.requests = append(c.requests, req)
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	return c.pages[req.Cursor], nil
+}
+
+type fakeUserClient struct {
+	pbUser.UserServiceClient
+	authorization []string
+}
+
+func (c *fakeUserClient) GetUserByEmail(ctx context.Context, req *pbUser.GetUserByEmailRequest, opts ...grpc.CallOption) (*pbUser.UserResponse, error) {
+	md, _ := metadata.FromOutgoingContext(ctx)
+	c.authorization = md["authorization"]
+	return &pbUser.UserResponse{UserId: "user-1", Name: "Donor", Email: req.Email}, nil
+}
+
+func TestGetPostByID(t *testing.T) {
+	t.Setenv("JWT_SECRET", "test-secret")
+
+	t.Run("success - reads the post from institution-service", func(t *testing.T) {
+		mongoDB, db, _ := NewTransactionMockDB()
+
+		postID, institutionID := uuid.New(), uuid.New()
+		postClient := &fakePostClient{post: &pbPost.PostResponse{
+			PostId:         postID.String(),
+			InstitutionId:  institutionID.String(),
+			Title:          "School library",
+			DateStart:      "2026-01-01",
+			DateEnd:        "2026-02-01",
+			OverflowPolicy: "CAP",
+			FundTargetV2:   &pbPost.Money{Amount: 100000000, Currency: "IDR"},
+			FundAchievedV2: &pbPost.Money{Amount: 2500000, Currency: "IDR"},
+		}}
+		repo := repository.NewTransactionRepository(mongoDB, db, postClient, nil)
+
+		ctx := context.Background()
+		post, err := repo.GetPostByID(ctx, postID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, postID, post.PostID)
+		assert.Equal(t, institutionID, post.InstitutionID)
+		assert.Equal(t, time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC), post.DateEnd)
+		assert.Equal(t, model.IDR(1000000), post.FundTarget)
+		assert.Equal(t, model.IDR(25000), post.FundAchieved)
+		assert.Equal(t, model.OverflowPolicyCap, post.OverflowPolicy)
+	})
+
+	t.Run("success - deleted post is looked up with a service token", func(t *testing.T) {
+		mongoDB, db, _ := NewTransactionMockDB()
+
+		postID := uuid.New()
+		postClient := &fakePostClient{post: &pbPost.PostResponse{
+			PostId:        postID.String(),
+			InstitutionId: uuid.New().String(),
+			DateStart:     "2026-01-01",
+			DateEnd:       "2026-02-01",
+			Status:        model.PostStatusPublished,
+			Deleted:       true,
+		}}
+		repo := repository.NewTransactionRepository(mongoDB, db, postClient, nil)
+
+		ctx := metadata.NewOutgoingContext(context.Background(), metadata.Pairs("authorization", "Bearer donor-token"))
+		post, err := repo.GetPostByID(ctx, postID)
+
+		assert.NoError(t, err)
+		assert.True(t, post.Deleted)
+		assert.False(t, post.IsPublished())
+		assert.Len(t, postClient.authorization, 1)
+		assert.NotEqual(t, "Bearer donor-token", postClient.authorization[0])
+	})
+
+	t.Run("success - legacy float amounts are IDR", func(t *testing.T) {
+		mongoDB, db, _ := NewTransactionMockDB()
+
+		postID := uuid.New()
+		postClient := &fakePostClient{post: &pbPost.PostResponse{
+			PostId:        postID.String(),
+			InstitutionId: uuid.New().String(),
+			DateStart:     "2026-01-01",
+			DateEnd:       "2026-02-01",
+			FundTarget:    1000000,
+			FuncAchieved:  25000,
+		}}
+		repo := repository.NewTransactionRepository(mongoDB, db, postClient, nil)
+
+		ctx := context.Background()
+		post, err := repo.GetPostByID(ctx, postID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, model.IDR(1000000), post.FundTarget)
+		assert.Equal(t, model.IDR(25000), post.FundAchieved)
+	})
+
+	t.Run("failed - missing post keeps the not found status", func(t *testing.T) {
+		mongoDB, db, _ := NewTransactionMockDB()
+
+		postClient := &fakePostClient{err: status.Error(codes.NotFound, "post not found")}
+		repo := repository.NewTransactionRepository(mongoDB, db, postClient, nil)
+
+		ctx := context.Background()
+		post, err := repo.GetPostByID(ctx, uuid.New())
+
+		assert.Nil(t, post)
+		assert.Equal(t, codes.NotFound, status.Code(err))
+	})
+}
+
+func TestGetPostsByInstitutionID(t *testing.T) {
+	t.Run("success - follows the cursor through every page", func(t *testing.T) {
+		mongoDB, db, _ := NewTransactionMockDB()
+
+		institutionID := uuid.New()
+		newPost := func() *pbPost.PostResponse {
+			return &pbPost.PostResponse{
+				PostId:        uuid.New().String(),
+				InstitutionId: institutionID.String(),
+				DateStart:     "2026-01-01",
+				DateEnd:       "2026-02-01",
+				FundTargetV2:  &pbPost.Money{Amount: 100000000, Currency: "IDR"},
+			}
+		}
+		postClient := &fakePostClient{pages: map[string]*pbPost.GetAllPostResponse{
+			"":       {Posts: []*pbPost.PostResponse{newPost(), newPost()}, NextCursor: "page-2"},
+			"page-2": {Posts: []*pbPost.PostResponse{newPost()}},
+		}}
+		repo := repository.NewTransactionRepository(mongoDB, db, postClient, nil)
+
+		posts, err := repo.GetPostsByInstitutionID(context.Background(), institutionID)
+
+		assert.NoError(t, err)
+		assert.Len(t, posts, 3)
+		assert.Len(t, postClient.requests, 2)
+		for _, req := range postClient.requests {
+			assert.Equal(t, institutionID.String(), req.InstitutionId)
+		}
+	})
+}
+
+func TestGetUserByEmail(t *testing.T) {
+	t.Run("success - forwards the caller's token to user-service", func(t *testing.T) {
+		mongoDB, db, _ := NewTransactionMockDB()
+
+		userClient := &fakeUserClient{}
+		repo := repository.NewTransactionRepository(mongoDB, db, nil, userClient)
+
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer token"))
+		user, err := repo.GetUserByEmail(ctx, "donor@email.com")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "user-1", user.UserID)
+		assert.Equal(t, "donor@email.com", user.Email)
+		assert.Equal(t, []string{"Bearer token"}, userClient.authorization)
+	})
+}
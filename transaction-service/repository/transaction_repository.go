@@ -3,41 +3,65 @@ package repository
 import (
 	"context"
 	"fmt"
-	"os"
 	"time"
 
 	"transaction-service/model"
+	pbPost "transaction-service/pb/post"
+	pbUser "transaction-service/pb/user"
+	"transaction-service/utils"
 
 	"github.com/google/uuid"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
-	"gorm.io/driver/postgres"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"google.golang.org/grpc/metadata"
 	"gorm.io/gorm"
 )
 
 type ITransactionRepository interface {
 	CreateTransaction(ctx context.Context, transaction *model.Transaction) (*model.Transaction, error)
-	CreateFundCollect(ctx context.Context, fundCollect *model.FundCollect) (*model.FundCollect, error)
 	GetTransactionByID(ctx context.Context, transactionID primitive.ObjectID) (*model.Transaction, error)
 	GetPostByID(ctx context.Context, postID uuid.UUID) (*model.Post, error)
+	GetPostsByInstitutionID(ctx context.Context, institutionID uuid.UUID) ([]model.Post, error)
 	GetUserByEmail(ctx context.Context, email string) (*model.User, error)
+	GetUserByID(ctx context.Context, userID string) (*model.User, error)
 	UpdateTransaction(ctx context.Context, transaction *model.Transaction) (*model.Transaction, error)
-	AddPostFundAchieved(ctx context.Context, postID uuid.UUID, amount float64) (*model.Post, error)
+	UpdateTransactionStatus(ctx context.Context, transaction *model.Transaction, transition model.StatusTransition) (bool, error)
+	UpdateTransactionStatusWithEvent(ctx context.Context, transaction *model.Transaction, transition model.StatusTransition, event model.OutboxEvent) (bool, error)
+	GetUnpublishedEvents(ctx context.Context, limit int64) ([]model.PendingOutboxEvent, error)
+	MarkEventPublished(ctx context.Context, transactionID primitive.ObjectID, eventID string) error
+	GetPendingTransactionsBefore(ctx context.Context, createdBefore time.Time, limit int64) ([]model.Transaction, error)
+	GetTransactions(ctx context.Context, filter model.TransactionFilter) ([]model.Transaction, error)
+	AddPendingRefund(ctx context.Context, transaction *model.Transaction, refund model.Refund) (bool, error)
+	RecordRefund(ctx context.Context, transaction *model.Transaction, refund model.Refund, transition *model.StatusTransition, event model.OutboxEvent) (bool, error)
+	FailRefund(ctx context.Context, transactionID primitive.ObjectID, referenceID string) error
+	CreateFXRate(ctx context.Context, rate *model.FXRate) (*model.FXRate, error)
+	GetLatestFXRate(ctx context.Context, baseCurrency, quoteCurrency string) (*model.FXRate, error)
+	GetLatestFXRates(ctx context.Context) ([]model.FXRate, error)
 }
 
+// TransactionRepository stores transactions in MongoDB and FX rates in
+// Postgres. Posts and users belong to institution-service and user-service and
+// are only read through their gRPC APIs.
 type TransactionRepository struct {
 	transactionCollection *mongo.Collection
 	gormClient            *gorm.DB
+	postClient            pbPost.PostServiceClient
+	userClient            pbUser.UserServiceClient
 }
 
 func NewTransactionRepository(
 	mongos *mongo.Database,
 	gormClient *gorm.DB,
+	postClient pbPost.PostServiceClient,
+	userClient pbUser.UserServiceClient,
 ) *TransactionRepository {
 	return &TransactionRepository{
 		transactionCollection: mongos.Collection("transactions"),
 		gormClient:            gormClient,
+		postClient:            postClient,
+		userClient:            userClient,
 	}
 }
 
@@ -47,10 +71,40 @@ func (r *TransactionRepository) CreateTransaction(ctx context.Context, transacti
 		{Key: "post_id", Value: transaction.PostID},
 		{Key: "user_email", Value: transaction.UserEmail},
 		{Key: "payment_id", Value: transaction.PaymentID},
-		{Key: "amount", Value: transaction.Amount},
+		{Key: "payment_status", Value: transaction.PaymentStatus},
+		{Key: "amount_v2", Value: transaction.Amount},
+		{Key: "refunded_amount_v2", Value: model.NewMoney(0, transaction.Amount.Currency)},
+		// amount is kept for readers of the old float field.
+		{Key: "amount", Value: transaction.Amount.Major()},
+		{Key: "converted_amount", Value: transaction.ConvertedAmount},
 		{Key: "account_number", Value: transaction.AccountNumber},
 		{Key: "account_name", Value: transaction.AccountName},
-		{Key: "created_at", Value: time.Now().Format(time.RFC3339)},
+		{Key: "created_at", Value: time.Now()},
+		{Key: "status_history", Value: transaction.StatusHistory},
+	}
+	if transaction.FXRate != nil {
+		doc = append(doc, bson.E{Key: "fx_rate", Value: transaction.FXRate})
+	}
+	if transaction.DonorNameVisibility != "" {
+		doc = append(doc,
+			bson.E{Key: "donor_name_visibility", Value: transaction.DonorNameVisibility},
+			bson.E{Key: "donor_name", Value: transaction.DonorName},
+		)
+	}
+	if transaction.Message != "" {
+		doc = append(doc, bson.E{Key: "message", Value: transaction.Message})
+	}
+	if transaction.SubscriptionID != "" {
+		doc = append(doc, bson.E{Key: "subscription_id", Value: transaction.SubscriptionID})
+	}
+	if transaction.PostTitle != "" {
+		doc = append(doc, bson.E{Key: "post_title", Value: transaction.PostTitle})
+	}
+	if transaction.InstitutionID != "" {
+		doc = append(doc, bson.E{Key: "institution_id", Value: transaction.InstitutionID})
+	}
+	if !transaction.TransactionID.IsZero() {
+		doc = append(bson.D{{Key: "_id", Value: transaction.TransactionID}}, doc...)
 	}
 
 	result, err := r.transactionCollection.InsertOne(ctx, doc)
@@ -63,14 +117,6 @@ func (r *TransactionRepository) CreateTransaction(ctx context.Context, transacti
 	return transaction, nil
 }
 
-func (r *TransactionRepository) CreateFundCollect(ctx context.Context, fundCollect *model.FundCollect) (*model.FundCollect, error) {
-	if err := r.gormClient.Create(fundCollect).Error; err != nil {
-		return nil, err
-	}
-
-	return fundCollect, nil
-}
-
 func (r *TransactionRepository) GetTransactionByID(ctx context.Context, transactionID primitive.ObjectID) (*model.Transaction, error) {
 	var transaction model.Transaction
 
@@ -86,35 +132,137 @@ func (r *TransactionRepository) GetTransactionByID(ctx context.Context, transact
 	return &transaction, nil
 }
 
-func (r *TransactionRepository) GetPostByID(ctx context.Context, PostID uuid.UUID) (*model.Post, error) {
-	var post model.Post
-	if err := r.gormClient.Where("post_id = ?", PostID).First(&post).Error; err != nil {
+// GetPostByID returns the gRPC status error of institution-service as is, so
+// a missing post is codes.NotFound. It goes through the service-only
+// LookupPost, which also finds deleted posts and those not published.
+func (r *TransactionRepository) GetPostByID(ctx context.Context, postID uuid.UUID) (*model.Post, error) {
+	ctx, err := utils.WithServiceToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := r.postClient.LookupPost(ctx, &pbPost.LookupPostRequest{PostId: postID.String()})
+	if err != nil {
 		return nil, err
 	}
 
-	return &post, nil
+	return toPost(res)
+}
+
+// postPageSize is the largest page institution-service returns.
+const postPageSize = 100
+
+// GetPostsByInstitutionID pages through the public post listing filtered by
+// institution, since listing by institution in institution-service is only
+// open to that institution.
+func (r *TransactionRepository) GetPostsByInstitutionID(ctx context.Context, institutionID uuid.UUID) ([]model.Post, error) {
+	req := &pbPost.GetAllPostRequest{
+		InstitutionId: institutionID.String(),
+		Limit:         postPageSize,
+	}
+
+	var posts []model.Post
+	for {
+		res, err := r.postClient.GetAllPost(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, postRes := range res.Posts {
+			post, err := toPost(postRes)
+			if err != nil {
+				return nil, err
+			}
+			posts = append(posts, *post)
+		}
+
+		if res.NextCursor == "" {
+			return posts, nil
+		}
+		req.Cursor = res.NextCursor
+	}
+}
+
+func toPost(res *pbPost.PostResponse) (*model.Post, error) {
+	postID, err := uuid.Parse(res.PostId)
+	if err != nil {
+		return nil, fmt.Errorf("invalid post ID %q: %v", res.PostId, err)
+	}
+	institutionID, err := uuid.Parse(res.InstitutionId)
+	if err != nil {
+		return nil, fmt.Errorf("invalid institution ID %q of post %s: %v", res.InstitutionId, postID, err)
+	}
+	dateStart, err := time.Parse("2006-01-02", res.DateStart)
+	if err != nil {
+		return nil, fmt.Errorf("invalid start date of post %s: %v", postID, err)
+	}
+	dateEnd, err := time.Parse("2006-01-02", res.DateEnd)
+	if err != nil {
+		return nil, fmt.Errorf("invalid end date of post %s: %v", postID, err)
+	}
+
+	return &model.Post{
+		PostID:         postID,
+		InstitutionID:  institutionID,
+		Title:          res.Title,
+		Body:           res.Body,
+		DateStart:      dateStart,
+		DateEnd:        dateEnd,
+		FundTarget:     moneyFromPost(res.FundTargetV2, res.FundTarget),
+		FundAchieved:   moneyFromPost(res.FundAchievedV2, res.FuncAchieved),
+		OverflowPolicy: model.OverflowPolicy(res.OverflowPolicy),
+		Status:         res.Status,
+		Deleted:        res.Deleted,
+	}, nil
+}
+
+// moneyFromPost falls back to the deprecated float field, which is always in
+// IDR, for institution-service versions that do not send Money yet.
+func moneyFromPost(amount *pbPost.Money, legacy float32) model.Money {
+	if amount != nil {
+		return model.NewMoney(amount.Amount, amount.Currency)
+	}
+
+	return model.MoneyFromMajor(float64(legacy), model.CurrencyIDR)
 }
 
+// GetUserByEmail asks user-service on behalf of the caller, forwarding the
+// caller's token, which user-service requires.
 func (r *TransactionRepository) GetUserByEmail(ctx context.Context, email string) (*model.User, error) {
-	var user model.User
+	if md, ok := metadata.FromIncomingContext(ctx); ok && len(md["authorization"]) > 0 {
+		ctx = metadata.AppendToOutgoingContext(ctx, "authorization", md["authorization"][0])
+	}
 
-	dsn := os.Getenv("POSTGRES_URI_EXTERNAL")
-	userDB, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	res, err := r.userClient.GetUserByEmail(ctx, &pbUser.GetUserByEmailRequest{Email: email})
 	if err != nil {
-		return nil, fmt.Errorf("failed to connect to user database: %w", err)
+		return nil, fmt.Errorf("error querying user: %w", err)
 	}
 
-	sqlDB, err := userDB.DB()
+	return &model.User{
+		UserID: res.UserId,
+		Name:   res.Name,
+		Email:  res.Email,
+	}, nil
+}
+
+// GetUserByID asks user-service as this service, since it is called from
+// background jobs that have no caller token to forward.
+func (r *TransactionRepository) GetUserByID(ctx context.Context, userID string) (*model.User, error) {
+	ctx, err := utils.WithServiceToken(ctx)
 	if err != nil {
 		return nil, err
 	}
-	defer sqlDB.Close()
 
-	if err := userDB.Table("users").Where("email = ?", email).First(&user).Error; err != nil {
+	res, err := r.userClient.GetUserByID(ctx, &pbUser.GetUserByIDRequest{UserId: userID})
+	if err != nil {
 		return nil, fmt.Errorf("error querying user: %w", err)
 	}
 
-	return &user, nil
+	return &model.User{
+		UserID: res.UserId,
+		Name:   res.Name,
+		Email:  res.Email,
+	}, nil
 }
 
 func (r *TransactionRepository) UpdateTransaction(ctx context.Context, transaction *model.Transaction) (*model.Transaction, error) {
@@ -122,13 +270,24 @@ func (r *TransactionRepository) UpdateTransaction(ctx context.Context, transacti
 		{Key: "_id", Value: transaction.TransactionID},
 	}
 
+	set := bson.D{
+		{Key: "payment_id", Value: transaction.PaymentID},
+		{Key: "payment_url", Value: transaction.PaymentURL},
+		{Key: "payment_method", Value: transaction.PaymentMethod},
+		{Key: "updated_at", Value: time.Now()},
+	}
+	if !transaction.PaidAt.IsZero() {
+		set = append(set, bson.E{Key: "paid_at", Value: transaction.PaidAt})
+	}
+	if !transaction.ExpiresAt.IsZero() {
+		set = append(set, bson.E{Key: "expires_at", Value: transaction.ExpiresAt})
+	}
+	if len(transaction.PreviousPaymentIDs) > 0 {
+		set = append(set, bson.E{Key: "previous_payment_ids", Value: transaction.PreviousPaymentIDs})
+	}
+
 	update := bson.D{
-		{Key: "$set", Value: bson.D{
-			{Key: "payment_id", Value: transaction.PaymentID},
-			{Key: "payment_url", Value: transaction.PaymentURL},
-			{Key: "payment_status", Value: transaction.PaymentStatus},
-			{Key: "updated_at", Value: time.Now().Format(time.RFC3339)},
-		}},
+		{Key: "$set", Value: set},
 	}
 
 	_, err := r.transactionCollection.UpdateOne(ctx, filter, update)
@@ -139,17 +298,326 @@ func (r *TransactionRepository) UpdateTransaction(ctx context.Context, transacti
 	return transaction, nil
 }
 
-func (r *TransactionRepository) AddPostFundAchieved(ctx context.Context, postID uuid.UUID, amount float64) (*model.Post, error) {
-	var post model.Post
-	if err := r.gormClient.Where("post_id = ?", postID).First(&post).Error; err != nil {
+// UpdateTransactionStatus moves the transaction from transition.From to
+// transition.To and appends the transition to its history. It returns false
+// when the stored status is no longer transition.From.
+func (r *TransactionRepository) UpdateTransactionStatus(ctx context.Context, transaction *model.Transaction, transition model.StatusTransition) (bool, error) {
+	return r.updateTransactionStatus(ctx, transaction, transition, nil)
+}
+
+// UpdateTransactionStatusWithEvent is UpdateTransactionStatus that also adds
+// event to the transaction's outbox in the same document update.
+func (r *TransactionRepository) UpdateTransactionStatusWithEvent(ctx context.Context, transaction *model.Transaction, transition model.StatusTransition, event model.OutboxEvent) (bool, error) {
+	return r.updateTransactionStatus(ctx, transaction, transition, &event)
+}
+
+func (r *TransactionRepository) updateTransactionStatus(ctx context.Context, transaction *model.Transaction, transition model.StatusTransition, event *model.OutboxEvent) (bool, error) {
+	filter := bson.D{
+		{Key: "_id", Value: transaction.TransactionID},
+		{Key: "payment_status", Value: transition.From},
+	}
+
+	set := bson.D{
+		{Key: "payment_id", Value: transaction.PaymentID},
+		{Key: "payment_url", Value: transaction.PaymentURL},
+		{Key: "payment_status", Value: transition.To},
+		{Key: "payment_method", Value: transaction.PaymentMethod},
+		{Key: "updated_at", Value: time.Now()},
+	}
+	if !transaction.PaidAt.IsZero() {
+		set = append(set, bson.E{Key: "paid_at", Value: transaction.PaidAt})
+	}
+	if !transaction.ExpiresAt.IsZero() {
+		set = append(set, bson.E{Key: "expires_at", Value: transaction.ExpiresAt})
+	}
+	if len(transaction.PreviousPaymentIDs) > 0 {
+		set = append(set, bson.E{Key: "previous_payment_ids", Value: transaction.PreviousPaymentIDs})
+	}
+	if transition.To == model.PaymentStatusPaid && transaction.ReceiptStatus != "" {
+		set = append(set, bson.E{Key: "receipt_status", Value: transaction.ReceiptStatus})
+	}
+
+	push := bson.D{{Key: "status_history", Value: transition}}
+	if event != nil {
+		push = append(push, bson.E{Key: "outbox", Value: event})
+	}
+
+	update := bson.D{
+		{Key: "$set", Value: set},
+		{Key: "$push", Value: push},
+	}
+
+	result, err := r.transactionCollection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+
+	return result.ModifiedCount > 0, nil
+}
+
+// GetUnpublishedEvents returns the oldest outbox events not yet relayed.
+func (r *TransactionRepository) GetUnpublishedEvents(ctx context.Context, limit int64) ([]model.PendingOutboxEvent, error) {
+	unpublished := bson.D{{Key: "outbox.published_at", Value: bson.D{{Key: "$exists", Value: false}}}}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.D{{Key: "outbox", Value: bson.D{{Key: "$elemMatch", Value: bson.D{
+			{Key: "published_at", Value: bson.D{{Key: "$exists", Value: false}}},
+		}}}}}}},
+		{{Key: "$unwind", Value: "$outbox"}},
+		{{Key: "$match", Value: unpublished}},
+		{{Key: "$sort", Value: bson.D{{Key: "outbox.created_at", Value: 1}}}},
+		{{Key: "$limit", Value: limit}},
+		{{Key: "$project", Value: bson.D{{Key: "outbox", Value: 1}}}},
+	}
+
+	cursor, err := r.transactionCollection.Aggregate(ctx, pipeline)
+	if err != nil {
 		return nil, err
 	}
+	defer cursor.Close(ctx)
 
-	post.FundAchieved += amount
+	var rows []struct {
+		TransactionID primitive.ObjectID `bson:"_id"`
+		Outbox        model.OutboxEvent  `bson:"outbox"`
+	}
+	if err := cursor.All(ctx, &rows); err != nil {
+		return nil, err
+	}
+
+	events := make([]model.PendingOutboxEvent, 0, len(rows))
+	for _, row := range rows {
+		events = append(events, model.PendingOutboxEvent{
+			TransactionID: row.TransactionID,
+			Event:         row.Outbox,
+		})
+	}
+
+	return events, nil
+}
+
+func (r *TransactionRepository) MarkEventPublished(ctx context.Context, transactionID primitive.ObjectID, eventID string) error {
+	filter := bson.D{
+		{Key: "_id", Value: transactionID},
+		{Key: "outbox.event_id", Value: eventID},
+	}
+	update := bson.D{{Key: "$set", Value: bson.D{{Key: "outbox.$.published_at", Value: time.Now()}}}}
 
-	if err := r.gormClient.Save(&post).Error; err != nil {
+	result, err := r.transactionCollection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("event %s not found on transaction %s", eventID, transactionID.Hex())
+	}
+
+	return nil
+}
+
+// GetPendingTransactionsBefore returns the oldest PENDING transactions created
+// before the given time.
+func (r *TransactionRepository) GetPendingTransactionsBefore(ctx context.Context, createdBefore time.Time, limit int64) ([]model.Transaction, error) {
+	filter := bson.D{
+		{Key: "payment_status", Value: model.PaymentStatusPending},
+		{Key: "created_at", Value: bson.D{{Key: "$lt", Value: createdBefore}}},
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: 1}}).
+		SetLimit(limit)
+
+	cursor, err := r.transactionCollection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var transactions []model.Transaction
+	if err := cursor.All(ctx, &transactions); err != nil {
+		return nil, err
+	}
+
+	return transactions, nil
+}
+
+func (r *TransactionRepository) GetTransactions(ctx context.Context, filter model.TransactionFilter) ([]model.Transaction, error) {
+	query := bson.D{}
+	if filter.UserEmail != "" {
+		query = append(query, bson.E{Key: "user_email", Value: filter.UserEmail})
+	}
+	if filter.PostID != "" {
+		query = append(query, bson.E{Key: "post_id", Value: filter.PostID})
+	}
+	if filter.Status != "" {
+		query = append(query, bson.E{Key: "payment_status", Value: filter.Status})
+	}
+
+	createdAt := bson.D{}
+	if !filter.DateFrom.IsZero() {
+		createdAt = append(createdAt, bson.E{Key: "$gte", Value: filter.DateFrom})
+	}
+	if !filter.DateTo.IsZero() {
+		createdAt = append(createdAt, bson.E{Key: "$lt", Value: filter.DateTo})
+	}
+	if len(createdAt) > 0 {
+		query = append(query, bson.E{Key: "created_at", Value: createdAt})
+	}
+
+	if !filter.Cursor.IsZero() {
+		query = append(query, bson.E{Key: "_id", Value: bson.D{{Key: "$lt", Value: filter.Cursor}}})
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "_id", Value: -1}}).
+		SetLimit(int64(filter.Limit))
+
+	cursor, err := r.transactionCollection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var transactions []model.Transaction
+	if err := cursor.All(ctx, &transactions); err != nil {
+		return nil, err
+	}
+
+	return transactions, nil
+}
+
+// AddPendingRefund adds the PENDING refund to the transaction. It returns false
+// when the transaction is no longer PAID, was refunded concurrently or already
+// has a pending refund.
+func (r *TransactionRepository) AddPendingRefund(ctx context.Context, transaction *model.Transaction, refund model.Refund) (bool, error) {
+	filter := append(refundableFilter(transaction),
+		bson.E{Key: "refunds.status", Value: bson.D{{Key: "$ne", Value: model.RefundStatusPending}}},
+	)
+
+	update := bson.D{
+		{Key: "$set", Value: bson.D{{Key: "updated_at", Value: time.Now()}}},
+		{Key: "$push", Value: bson.D{{Key: "refunds", Value: refund}}},
+	}
+
+	result, err := r.transactionCollection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+
+	return result.ModifiedCount > 0, nil
+}
+
+// RecordRefund settles the pending refund with the same ReferenceID as
+// refund, adds its event to the transaction and, when transition is not nil,
+// applies it. It returns false when the transaction is no longer PAID, was
+// refunded concurrently or the refund is no longer pending.
+func (r *TransactionRepository) RecordRefund(ctx context.Context, transaction *model.Transaction, refund model.Refund, transition *model.StatusTransition, event model.OutboxEvent) (bool, error) {
+	filter := append(refundableFilter(transaction), pendingRefundFilter(refund.ReferenceID))
+
+	set := bson.D{
+		{Key: "updated_at", Value: time.Now()},
+		{Key: "refunded_amount_v2.currency", Value: refund.Amount.Currency},
+		{Key: "refunds.$.refund_id", Value: refund.RefundID},
+		{Key: "refunds.$.status", Value: refund.Status},
+		{Key: "refunds.$.at", Value: refund.At},
+	}
+	push := bson.D{
+		{Key: "outbox", Value: event},
+	}
+	if transition != nil {
+		set = append(set, bson.E{Key: "payment_status", Value: transition.To})
+		push = append(push, bson.E{Key: "status_history", Value: *transition})
+	}
+
+	update := bson.D{
+		{Key: "$set", Value: set},
+		{Key: "$inc", Value: bson.D{
+			{Key: "refunded_amount_v2.amount", Value: refund.Amount.Amount},
+			{Key: "refunded_amount", Value: refund.Amount.Major()},
+		}},
+		{Key: "$push", Value: push},
+	}
+
+	result, err := r.transactionCollection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+
+	return result.ModifiedCount > 0, nil
+}
+
+// FailRefund marks the pending refund with referenceID as FAILED.
+func (r *TransactionRepository) FailRefund(ctx context.Context, transactionID primitive.ObjectID, referenceID string) error {
+	filter := bson.D{
+		{Key: "_id", Value: transactionID},
+		pendingRefundFilter(referenceID),
+	}
+
+	update := bson.D{{Key: "$set", Value: bson.D{
+		{Key: "updated_at", Value: time.Now()},
+		{Key: "refunds.$.status", Value: model.RefundStatusFailed},
+	}}}
+
+	_, err := r.transactionCollection.UpdateOne(ctx, filter, update)
+
+	return err
+}
+
+// refundableFilter matches the transaction while it is PAID and refunded no
+// further than the caller has seen.
+func refundableFilter(transaction *model.Transaction) bson.D {
+	refundedAmount := bson.E{Key: "refunded_amount_v2.amount", Value: transaction.RefundedAmount.Amount}
+	if transaction.RefundedAmount.IsZero() {
+		// Transactions paid before refunds existed have no refunded amount.
+		refundedAmount.Value = bson.D{{Key: "$in", Value: bson.A{0, nil}}}
+	}
+
+	return bson.D{
+		{Key: "_id", Value: transaction.TransactionID},
+		{Key: "payment_status", Value: model.PaymentStatusPaid},
+		refundedAmount,
+	}
+}
+
+// pendingRefundFilter matches the pending refund with referenceID, which the
+// positional refunds.$ of the update then refers to.
+func pendingRefundFilter(referenceID string) bson.E {
+	return bson.E{Key: "refunds", Value: bson.D{{Key: "$elemMatch", Value: bson.D{
+		{Key: "reference_id", Value: referenceID},
+		{Key: "status", Value: model.RefundStatusPending},
+	}}}}
+}
+
+func (r *TransactionRepository) CreateFXRate(ctx context.Context, rate *model.FXRate) (*model.FXRate, error) {
+	if err := r.gormClient.WithContext(ctx).Create(rate).Error; err != nil {
+		return nil, err
+	}
+
+	return rate, nil
+}
+
+// GetLatestFXRate returns gorm.ErrRecordNotFound when the pair has no rate.
+func (r *TransactionRepository) GetLatestFXRate(ctx context.Context, baseCurrency, quoteCurrency string) (*model.FXRate, error) {
+	var rate model.FXRate
+	err := r.gormClient.WithContext(ctx).
+		Where("base_currency = ? AND quote_currency = ?", baseCurrency, quoteCurrency).
+		Order("created_at DESC").
+		First(&rate).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &rate, nil
+}
+
+// GetLatestFXRates returns the current rate of every pair.
+func (r *TransactionRepository) GetLatestFXRates(ctx context.Context) ([]model.FXRate, error) {
+	var rates []model.FXRate
+	err := r.gormClient.WithContext(ctx).
+		Raw(`SELECT DISTINCT ON (base_currency, quote_currency) * FROM fx_rates
+			ORDER BY base_currency, quote_currency, created_at DESC`).
+		Scan(&rates).Error
+	if err != nil {
 		return nil, err
 	}
 
-	return &post, nil
+	return rates, nil
 }
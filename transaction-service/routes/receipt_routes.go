@@ -0,0 +1,53 @@
+package routes
+
+import (
+	"fmt"
+	"net/http"
+
+	"transaction-service/httputil"
+	pb "transaction-service/pb/receipt"
+
+	"github.com/labstack/echo/v4"
+	"google.golang.org/grpc/status"
+)
+
+type ReceiptHTTPHandler struct {
+	receiptClient pb.ReceiptServiceClient
+}
+
+func NewReceiptHTTPHandler(receiptClient pb.ReceiptServiceClient) *ReceiptHTTPHandler {
+	return &ReceiptHTTPHandler{
+		receiptClient: receiptClient,
+	}
+}
+
+func (h *ReceiptHTTPHandler) Routes(e *echo.Echo) {
+	e.GET("/v1/transaction/:id/receipt", userAuthMiddleware(h.GetTransactionReceipt))
+}
+
+// GetTransactionReceipt godoc
+// @Summary      Download the receipt of a Transaction.
+// @Description  Download the PDF receipt of one of the authenticated user's paid transactions, for tax deduction claims. It is also emailed once the payment settles.
+// @Tags         Transaction
+// @Produce      application/pdf
+// @Security     BearerAuth
+// @Param        Authorization  header    string  true  "Bearer token"
+// @Param        id             path      string  true  "Transaction ID"
+// @Success      200 {file} file "Receipt PDF"
+// @Failure      401 {object} httputil.HTTPError "Unauthorized"
+// @Failure      404 {object} httputil.HTTPError "Transaction not found"
+// @Failure      409 {object} httputil.HTTPError "Transaction is not paid"
+// @Router       /v1/transaction/{id}/receipt [get]
+func (h *ReceiptHTTPHandler) GetTransactionReceipt(c echo.Context) error {
+	res, err := h.receiptClient.GetTransactionReceipt(c.Request().Context(), &pb.GetTransactionReceiptRequest{
+		TransactionId: c.Param("id"),
+	})
+	if err != nil {
+		return echo.NewHTTPError(httpStatusFromGRPC(err), httputil.HTTPError{
+			Message: status.Convert(err).Message(),
+		})
+	}
+
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", res.Filename))
+	return c.Blob(http.StatusOK, res.ContentType, res.Content)
+}
@@ -0,0 +1,192 @@
+package routes
+
+import (
+	"net/http"
+
+	"transaction-service/httputil"
+	pb "transaction-service/pb/subscription"
+
+	"github.com/labstack/echo/v4"
+	"google.golang.org/grpc/status"
+)
+
+type SubscriptionHTTPHandler struct {
+	subscriptionClient pb.SubscriptionServiceClient
+}
+
+func NewSubscriptionHTTPHandler(subscriptionClient pb.SubscriptionServiceClient) *SubscriptionHTTPHandler {
+	return &SubscriptionHTTPHandler{
+		subscriptionClient: subscriptionClient,
+	}
+}
+
+func (h *SubscriptionHTTPHandler) Routes(e *echo.Echo) {
+	e.POST("/v1/subscription", userAuthMiddleware(h.CreateSubscription))
+	e.GET("/v1/subscriptions", userAuthMiddleware(h.GetMySubscriptions))
+	e.GET("/v1/subscription/:id", userAuthMiddleware(h.GetSubscriptionByID))
+	e.POST("/v1/subscription/:id/pause", userAuthMiddleware(h.PauseSubscription))
+	e.POST("/v1/subscription/:id/resume", userAuthMiddleware(h.ResumeSubscription))
+	e.POST("/v1/subscription/:id/cancel", userAuthMiddleware(h.CancelSubscription))
+}
+
+// CreateSubscription godoc
+// @Summary      Create a recurring donation.
+// @Description  Pledge amount every month to post_id, or to institution_id, whose open fundraising ending soonest then receives each month's donation. amount is in minor units, e.g. 5000000 for IDR 50,000. The first invoice is issued within the scheduler interval and emailed; later months are charged on the same day, and a reminder is emailed a few days before.
+// @Tags         Subscription
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        Authorization  header    string  true  "Bearer token"
+// @Param        request body model.SubscriptionRequest true "Subscription details"
+// @Success      201 {object} model.SubscriptionResponse "Subscription created"
+// @Failure      400 {object} httputil.HTTPError "Invalid request"
+// @Failure      401 {object} httputil.HTTPError "Unauthorized"
+// @Failure      404 {object} httputil.HTTPError "Post not found"
+// @Failure      409 {object} httputil.HTTPError "Fundraising has ended"
+// @Router       /v1/subscription [post]
+func (h *SubscriptionHTTPHandler) CreateSubscription(c echo.Context) error {
+	req := new(pb.CreateSubscriptionRequest)
+	if err := c.Bind(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, httputil.HTTPError{
+			Message: "Invalid request body",
+		})
+	}
+
+	res, err := h.subscriptionClient.CreateSubscription(c.Request().Context(), req)
+	if err != nil {
+		return echo.NewHTTPError(httpStatusFromGRPC(err), httputil.HTTPError{
+			Message: status.Convert(err).Message(),
+		})
+	}
+
+	return c.JSON(http.StatusCreated, res)
+}
+
+// GetMySubscriptions godoc
+// @Summary      Get my recurring donations.
+// @Description  List the authenticated user's subscriptions, newest first.
+// @Tags         Subscription
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        Authorization  header    string  true  "Bearer token"
+// @Success      200 {object} model.SubscriptionListResponse "Success get subscriptions"
+// @Failure      401 {object} httputil.HTTPError "Unauthorized"
+// @Router       /v1/subscriptions [get]
+func (h *SubscriptionHTTPHandler) GetMySubscriptions(c echo.Context) error {
+	res, err := h.subscriptionClient.GetMySubscriptions(c.Request().Context(), &pb.GetMySubscriptionsRequest{})
+	if err != nil {
+		return echo.NewHTTPError(httpStatusFromGRPC(err), httputil.HTTPError{
+			Message: status.Convert(err).Message(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// GetSubscriptionByID godoc
+// @Summary      Get recurring donation by ID.
+// @Description  Get one of the authenticated user's subscriptions.
+// @Tags         Subscription
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        Authorization  header    string  true  "Bearer token"
+// @Param        id             path      string  true  "Subscription ID"
+// @Success      200 {object} model.SubscriptionResponse "Success get subscription"
+// @Failure      401 {object} httputil.HTTPError "Unauthorized"
+// @Failure      404 {object} httputil.HTTPError "Subscription not found"
+// @Router       /v1/subscription/{id} [get]
+func (h *SubscriptionHTTPHandler) GetSubscriptionByID(c echo.Context) error {
+	res, err := h.subscriptionClient.GetSubscriptionByID(c.Request().Context(), &pb.SubscriptionIDRequest{
+		SubscriptionId: c.Param("id"),
+	})
+	if err != nil {
+		return echo.NewHTTPError(httpStatusFromGRPC(err), httputil.HTTPError{
+			Message: status.Convert(err).Message(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// PauseSubscription godoc
+// @Summary      Pause a recurring donation.
+// @Description  Stop charging an active subscription until it is resumed.
+// @Tags         Subscription
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        Authorization  header    string  true  "Bearer token"
+// @Param        id             path      string  true  "Subscription ID"
+// @Success      200 {object} model.SubscriptionResponse "Subscription paused"
+// @Failure      401 {object} httputil.HTTPError "Unauthorized"
+// @Failure      404 {object} httputil.HTTPError "Subscription not found"
+// @Failure      409 {object} httputil.HTTPError "Subscription is not active"
+// @Router       /v1/subscription/{id}/pause [post]
+func (h *SubscriptionHTTPHandler) PauseSubscription(c echo.Context) error {
+	res, err := h.subscriptionClient.PauseSubscription(c.Request().Context(), &pb.SubscriptionIDRequest{
+		SubscriptionId: c.Param("id"),
+	})
+	if err != nil {
+		return echo.NewHTTPError(httpStatusFromGRPC(err), httputil.HTTPError{
+			Message: status.Convert(err).Message(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// ResumeSubscription godoc
+// @Summary      Resume a recurring donation.
+// @Description  Reactivate a paused subscription. Months missed while it was paused are not charged.
+// @Tags         Subscription
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        Authorization  header    string  true  "Bearer token"
+// @Param        id             path      string  true  "Subscription ID"
+// @Success      200 {object} model.SubscriptionResponse "Subscription resumed"
+// @Failure      401 {object} httputil.HTTPError "Unauthorized"
+// @Failure      404 {object} httputil.HTTPError "Subscription not found"
+// @Failure      409 {object} httputil.HTTPError "Subscription is not paused"
+// @Router       /v1/subscription/{id}/resume [post]
+func (h *SubscriptionHTTPHandler) ResumeSubscription(c echo.Context) error {
+	res, err := h.subscriptionClient.ResumeSubscription(c.Request().Context(), &pb.SubscriptionIDRequest{
+		SubscriptionId: c.Param("id"),
+	})
+	if err != nil {
+		return echo.NewHTTPError(httpStatusFromGRPC(err), httputil.HTTPError{
+			Message: status.Convert(err).Message(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// CancelSubscription godoc
+// @Summary      Cancel a recurring donation.
+// @Description  Stop a subscription for good. Invoices already issued stay payable.
+// @Tags         Subscription
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        Authorization  header    string  true  "Bearer token"
+// @Param        id             path      string  true  "Subscription ID"
+// @Success      200 {object} model.SubscriptionResponse "Subscription cancelled"
+// @Failure      401 {object} httputil.HTTPError "Unauthorized"
+// @Failure      404 {object} httputil.HTTPError "Subscription not found"
+// @Failure      409 {object} httputil.HTTPError "Subscription is already cancelled"
+// @Router       /v1/subscription/{id}/cancel [post]
+func (h *SubscriptionHTTPHandler) CancelSubscription(c echo.Context) error {
+	res, err := h.subscriptionClient.CancelSubscription(c.Request().Context(), &pb.SubscriptionIDRequest{
+		SubscriptionId: c.Param("id"),
+	})
+	if err != nil {
+		return echo.NewHTTPError(httpStatusFromGRPC(err), httputil.HTTPError{
+			Message: status.Convert(err).Message(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
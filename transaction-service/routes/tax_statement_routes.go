@@ -0,0 +1,64 @@
+package routes
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"transaction-service/httputil"
+	pb "transaction-service/pb/tax_statement"
+
+	"github.com/labstack/echo/v4"
+	"google.golang.org/grpc/status"
+)
+
+type TaxStatementHTTPHandler struct {
+	taxStatementClient pb.TaxStatementServiceClient
+}
+
+func NewTaxStatementHTTPHandler(taxStatementClient pb.TaxStatementServiceClient) *TaxStatementHTTPHandler {
+	return &TaxStatementHTTPHandler{
+		taxStatementClient: taxStatementClient,
+	}
+}
+
+func (h *TaxStatementHTTPHandler) Routes(e *echo.Echo) {
+	e.GET("/v1/tax-statement/:year", userAuthMiddleware(h.GetTaxStatement))
+}
+
+// GetTaxStatement godoc
+// @Summary      Download the annual tax statement.
+// @Description  Download the authenticated user's paid donations of a calendar year, net of refunds and grouped by institution, for zakat and donation deduction claims. The statement of the previous year is also emailed in January.
+// @Tags         Transaction
+// @Produce      application/pdf
+// @Produce      text/csv
+// @Security     BearerAuth
+// @Param        Authorization  header    string  true   "Bearer token"
+// @Param        year           path      int     true   "Calendar year"
+// @Param        format         query     string  false  "pdf (default) or csv"
+// @Success      200 {file} file "Tax statement"
+// @Failure      400 {object} httputil.HTTPError "Invalid year or format"
+// @Failure      401 {object} httputil.HTTPError "Unauthorized"
+// @Failure      404 {object} httputil.HTTPError "No paid donations in this year"
+// @Router       /v1/tax-statement/{year} [get]
+func (h *TaxStatementHTTPHandler) GetTaxStatement(c echo.Context) error {
+	year, err := strconv.ParseInt(c.Param("year"), 10, 32)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, httputil.HTTPError{
+			Message: "Invalid year",
+		})
+	}
+
+	res, err := h.taxStatementClient.GetTaxStatement(c.Request().Context(), &pb.GetTaxStatementRequest{
+		Year:   int32(year),
+		Format: c.QueryParam("format"),
+	})
+	if err != nil {
+		return echo.NewHTTPError(httpStatusFromGRPC(err), httputil.HTTPError{
+			Message: status.Convert(err).Message(),
+		})
+	}
+
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", res.Filename))
+	return c.Blob(http.StatusOK, res.ContentType, res.Content)
+}
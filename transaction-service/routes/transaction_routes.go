@@ -2,14 +2,18 @@ package routes
 
 import (
 	"net/http"
+	"strconv"
 	"strings"
 
 	"transaction-service/httputil"
+	"transaction-service/model"
 	pb "transaction-service/pb/transaction"
 	"transaction-service/utils"
 
 	"github.com/labstack/echo/v4"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 )
 
 type TransactionHTTPHandler struct {
@@ -24,11 +28,19 @@ func NewTransactionHTTPHandler(transactionClient pb.TransactionServiceClient) *T
 
 func (h *TransactionHTTPHandler) Routes(e *echo.Echo) {
 	e.POST("/v1/transaction", h.authMiddleware2(h.CreateTransaction))
+	e.GET("/v1/transaction/:id", h.authMiddleware2(h.GetTransactionByID))
+	e.POST("/v1/transaction/:id/retry", h.authMiddleware2(h.RetryPayment))
+	e.GET("/v1/transactions", h.authMiddleware2(h.GetMyTransactions))
+	e.GET("/v1/transactions/post/:id", h.institutionAuthMiddleware(h.GetPostTransactions))
+	e.POST("/v1/transaction/:id/refund", h.institutionAuthMiddleware(h.RefundTransaction))
+	e.POST("/v1/transactions/post/:id/refund", h.institutionAuthMiddleware(h.RefundPostTransactions))
+	e.GET("/v1/fx-rates", h.authMiddleware2(h.GetFXRates))
+	e.PUT("/v1/admin/fx-rates", h.authMiddleware2(h.SetFXRate))
 }
 
 // CreateTransaction godoc
 // @Summary      Create a new Transaction.
-// @Description  Create transaction with post id, amount, etc.
+// @Description  Create transaction with post id, amount, etc. amount_v2 is in minor units, e.g. 5000000 for IDR 50,000; the float amount is deprecated. donor_name_visibility is PROFILE_NAME, DISPLAY_NAME with display_name, or ANONYMOUS, the default; it decides the name shown on public donor lists. message is an optional note of at most 280 characters for the post's supporters wall.
 // @Tags         Transaction
 // @Accept       json
 // @Produce      json
@@ -47,10 +59,14 @@ func (h *TransactionHTTPHandler) CreateTransaction(c echo.Context) error {
 	}
 
 	res, err := h.transactionClient.CreateTransaction(c.Request().Context(), &pb.CreateTransactionRequest{
-		PostId:        req.PostId,
-		Amount:        req.Amount,
-		AccountNumber: req.AccountNumber,
-		AccountName:   req.AccountName,
+		PostId:              req.PostId,
+		Amount:              req.Amount,
+		AmountV2:            req.AmountV2,
+		AccountNumber:       req.AccountNumber,
+		AccountName:         req.AccountName,
+		DonorNameVisibility: req.DonorNameVisibility,
+		DisplayName:         req.DisplayName,
+		Message:             req.Message,
 	})
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, httputil.HTTPError{
@@ -61,7 +77,345 @@ func (h *TransactionHTTPHandler) CreateTransaction(c echo.Context) error {
 	return c.JSON(http.StatusCreated, res)
 }
 
+// GetTransactionByID godoc
+// @Summary      Get Transaction by ID.
+// @Description  Get one of the authenticated user's transactions.
+// @Tags         Transaction
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        Authorization  header    string  true  "Bearer token"
+// @Param        id             path      string  true  "Transaction ID"
+// @Success      200 {object} model.TransactionResponse "Success get transaction data"
+// @Failure      401 {object} httputil.HTTPError "Unauthorized"
+// @Failure      404 {object} httputil.HTTPError "Transaction not found"
+// @Router       /v1/transaction/{id} [get]
+func (h *TransactionHTTPHandler) GetTransactionByID(c echo.Context) error {
+	res, err := h.transactionClient.GetTransactionByID(c.Request().Context(), &pb.GetTransactionByIDRequest{
+		TransactionId: c.Param("id"),
+	})
+	if err != nil {
+		return echo.NewHTTPError(httpStatusFromGRPC(err), httputil.HTTPError{
+			Message: status.Convert(err).Message(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// RetryPayment godoc
+// @Summary      Retry payment of a Transaction.
+// @Description  Issue a fresh invoice for one of the authenticated user's expired or failed transactions. The transaction keeps its ID.
+// @Tags         Transaction
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        Authorization  header    string  true  "Bearer token"
+// @Param        id             path      string  true  "Transaction ID"
+// @Success      200 {object} model.TransactionResponse "New invoice issued"
+// @Failure      401 {object} httputil.HTTPError "Unauthorized"
+// @Failure      404 {object} httputil.HTTPError "Transaction not found"
+// @Failure      409 {object} httputil.HTTPError "Transaction cannot be retried"
+// @Router       /v1/transaction/{id}/retry [post]
+func (h *TransactionHTTPHandler) RetryPayment(c echo.Context) error {
+	res, err := h.transactionClient.RetryPayment(c.Request().Context(), &pb.RetryPaymentRequest{
+		TransactionId: c.Param("id"),
+	})
+	if err != nil {
+		return echo.NewHTTPError(httpStatusFromGRPC(err), httputil.HTTPError{
+			Message: status.Convert(err).Message(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// GetMyTransactions godoc
+// @Summary      Get my Transactions.
+// @Description  List the authenticated user's transactions, newest first. Pass next_cursor back as cursor to get the next page.
+// @Tags         Transaction
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        Authorization  header    string  true   "Bearer token"
+// @Param        status         query     string  false  "Payment status"
+// @Param        date_from      query     string  false  "Created on or after, YYYY-MM-DD or RFC3339"
+// @Param        date_to        query     string  false  "Created on or before, YYYY-MM-DD or RFC3339"
+// @Param        cursor         query     string  false  "Cursor from the previous page"
+// @Param        limit          query     int     false  "Page size, at most 100"
+// @Success      200 {object} model.TransactionListResponse "Success get transactions"
+// @Failure      400 {object} httputil.HTTPError "Invalid filter"
+// @Failure      401 {object} httputil.HTTPError "Unauthorized"
+// @Router       /v1/transactions [get]
+func (h *TransactionHTTPHandler) GetMyTransactions(c echo.Context) error {
+	limit, err := queryLimit(c)
+	if err != nil {
+		return err
+	}
+
+	res, err := h.transactionClient.GetMyTransactions(c.Request().Context(), &pb.GetMyTransactionsRequest{
+		Status:   c.QueryParam("status"),
+		DateFrom: c.QueryParam("date_from"),
+		DateTo:   c.QueryParam("date_to"),
+		Cursor:   c.QueryParam("cursor"),
+		Limit:    limit,
+	})
+	if err != nil {
+		return echo.NewHTTPError(httpStatusFromGRPC(err), httputil.HTTPError{
+			Message: status.Convert(err).Message(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// GetPostTransactions godoc
+// @Summary      Get Transactions of a Post.
+// @Description  List the transactions of a post, newest first. Only the institution that owns the post can read them.
+// @Tags         Transaction
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        Authorization  header    string  true   "Institution bearer token"
+// @Param        id             path      string  true   "Post ID"
+// @Param        status         query     string  false  "Payment status"
+// @Param        date_from      query     string  false  "Created on or after, YYYY-MM-DD or RFC3339"
+// @Param        date_to        query     string  false  "Created on or before, YYYY-MM-DD or RFC3339"
+// @Param        cursor         query     string  false  "Cursor from the previous page"
+// @Param        limit          query     int     false  "Page size, at most 100"
+// @Success      200 {object} model.TransactionListResponse "Success get transactions"
+// @Failure      400 {object} httputil.HTTPError "Invalid filter"
+// @Failure      401 {object} httputil.HTTPError "Unauthorized"
+// @Failure      403 {object} httputil.HTTPError "Post belongs to another institution"
+// @Failure      404 {object} httputil.HTTPError "Post not found"
+// @Router       /v1/transactions/post/{id} [get]
+func (h *TransactionHTTPHandler) GetPostTransactions(c echo.Context) error {
+	limit, err := queryLimit(c)
+	if err != nil {
+		return err
+	}
+
+	res, err := h.transactionClient.GetPostTransactions(c.Request().Context(), &pb.GetPostTransactionsRequest{
+		PostId:   c.Param("id"),
+		Status:   c.QueryParam("status"),
+		DateFrom: c.QueryParam("date_from"),
+		DateTo:   c.QueryParam("date_to"),
+		Cursor:   c.QueryParam("cursor"),
+		Limit:    limit,
+	})
+	if err != nil {
+		return echo.NewHTTPError(httpStatusFromGRPC(err), httputil.HTTPError{
+			Message: status.Convert(err).Message(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// RefundTransaction godoc
+// @Summary      Refund a Transaction.
+// @Description  Refund a paid transaction in full or in part. An amount of 0 refunds whatever is left. amount_v2 is in minor units; the float amount is deprecated. Only the institution that owns the post can refund.
+// @Tags         Transaction
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        Authorization  header    string  true  "Institution bearer token"
+// @Param        id             path      string  true  "Transaction ID"
+// @Param        request body model.RefundRequest true "Refund details"
+// @Success      200 {object} model.TransactionResponse "Transaction refunded successfully"
+// @Failure      400 {object} httputil.HTTPError "Invalid request body"
+// @Failure      403 {object} httputil.HTTPError "Post belongs to another institution"
+// @Failure      404 {object} httputil.HTTPError "Transaction not found"
+// @Failure      409 {object} httputil.HTTPError "Transaction cannot be refunded"
+// @Router       /v1/transaction/{id}/refund [post]
+func (h *TransactionHTTPHandler) RefundTransaction(c echo.Context) error {
+	req := new(model.RefundRequest)
+	if err := c.Bind(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, httputil.HTTPError{
+			Message: "Invalid request body",
+		})
+	}
+
+	res, err := h.transactionClient.RefundTransaction(c.Request().Context(), &pb.RefundTransactionRequest{
+		TransactionId: c.Param("id"),
+		Amount:        float32(req.Amount),
+		AmountV2:      toMoneyRequest(req.AmountV2),
+		Reason:        req.Reason,
+	})
+	if err != nil {
+		return echo.NewHTTPError(httpStatusFromGRPC(err), httputil.HTTPError{
+			Message: status.Convert(err).Message(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// RefundPostTransactions godoc
+// @Summary      Refund all Transactions of a Post.
+// @Description  Fully refund every paid transaction of a cancelled or deleted post. Only the institution that owns the post can refund.
+// @Tags         Transaction
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        Authorization  header    string  true  "Institution bearer token"
+// @Param        id             path      string  true  "Post ID"
+// @Param        request body model.RefundRequest true "Refund reason, amount is ignored"
+// @Success      200 {object} model.RefundPostTransactionsResponse "Refunded and failed transactions"
+// @Failure      403 {object} httputil.HTTPError "Post belongs to another institution"
+// @Failure      404 {object} httputil.HTTPError "Post not found"
+// @Router       /v1/transactions/post/{id}/refund [post]
+func (h *TransactionHTTPHandler) RefundPostTransactions(c echo.Context) error {
+	req := new(model.RefundRequest)
+	if err := c.Bind(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, httputil.HTTPError{
+			Message: "Invalid request body",
+		})
+	}
+
+	res, err := h.transactionClient.RefundPostTransactions(c.Request().Context(), &pb.RefundPostTransactionsRequest{
+		PostId: c.Param("id"),
+		Reason: req.Reason,
+	})
+	if err != nil {
+		return echo.NewHTTPError(httpStatusFromGRPC(err), httputil.HTTPError{
+			Message: status.Convert(err).Message(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// GetFXRates godoc
+// @Summary      Get exchange rates.
+// @Description  List the current exchange rate of every currency pair. Donations in another currency than the post are converted with these rates.
+// @Tags         FX Rate
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        Authorization  header    string  true  "Bearer token"
+// @Success      200 {object} model.FXRateListResponse "Current exchange rates"
+// @Failure      401 {object} httputil.HTTPError "Unauthorized"
+// @Router       /v1/fx-rates [get]
+func (h *TransactionHTTPHandler) GetFXRates(c echo.Context) error {
+	res, err := h.transactionClient.GetFXRates(c.Request().Context(), &pb.GetFXRatesRequest{})
+	if err != nil {
+		return echo.NewHTTPError(httpStatusFromGRPC(err), httputil.HTTPError{
+			Message: status.Convert(err).Message(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// SetFXRate godoc
+// @Summary      Set an exchange rate.
+// @Description  Record that one unit of base_currency is worth rate units of quote_currency, e.g. USD to IDR at "15500". Earlier rates are kept for the transactions converted with them. Admins only.
+// @Tags         FX Rate
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        Authorization  header    string  true  "Admin bearer token"
+// @Param        request body model.FXRateRequest true "Exchange rate"
+// @Success      200 {object} model.FXRate "Exchange rate set"
+// @Failure      400 {object} httputil.HTTPError "Invalid exchange rate"
+// @Failure      401 {object} httputil.HTTPError "Unauthorized"
+// @Failure      403 {object} httputil.HTTPError "Not an admin"
+// @Router       /v1/admin/fx-rates [put]
+func (h *TransactionHTTPHandler) SetFXRate(c echo.Context) error {
+	req := new(model.FXRateRequest)
+	if err := c.Bind(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, httputil.HTTPError{
+			Message: "Invalid request body",
+		})
+	}
+
+	res, err := h.transactionClient.SetFXRate(c.Request().Context(), &pb.SetFXRateRequest{
+		BaseCurrency:  req.BaseCurrency,
+		QuoteCurrency: req.QuoteCurrency,
+		Rate:          req.Rate,
+	})
+	if err != nil {
+		return echo.NewHTTPError(httpStatusFromGRPC(err), httputil.HTTPError{
+			Message: status.Convert(err).Message(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+func queryLimit(c echo.Context) (int32, error) {
+	if c.QueryParam("limit") == "" {
+		return 0, nil
+	}
+
+	limit, err := strconv.ParseInt(c.QueryParam("limit"), 10, 32)
+	if err != nil {
+		return 0, echo.NewHTTPError(http.StatusBadRequest, httputil.HTTPError{
+			Message: "Invalid limit",
+		})
+	}
+
+	return int32(limit), nil
+}
+
+func httpStatusFromGRPC(err error) int {
+	switch status.Code(err) {
+	case codes.InvalidArgument:
+		return http.StatusBadRequest
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.FailedPrecondition:
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func (h *TransactionHTTPHandler) institutionAuthMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		token := c.Request().Header.Get("Authorization")
+		if token == "" {
+			return echo.NewHTTPError(http.StatusUnauthorized, httputil.HTTPError{
+				Message: "Unauthorized",
+			})
+		}
+
+		tokenParts := strings.Split(token, " ")
+		if len(tokenParts) != 2 || tokenParts[0] != "Bearer" {
+			return echo.NewHTTPError(http.StatusUnauthorized, httputil.HTTPError{
+				Message: "Invalid token format",
+			})
+		}
+
+		if _, err := utils.ValidateInstitutionJWT(tokenParts[1]); err != nil {
+			return echo.NewHTTPError(http.StatusUnauthorized, httputil.HTTPError{
+				Message: "Invalid token: " + err.Error(),
+			})
+		}
+
+		// The gRPC interceptor reads the institution ID from the token itself.
+		md := metadata.New(map[string]string{
+			"authorization": token,
+		})
+		ctx := metadata.NewOutgoingContext(c.Request().Context(), md)
+		c.SetRequest(c.Request().WithContext(ctx))
+
+		return next(c)
+	}
+}
+
 func (h *TransactionHTTPHandler) authMiddleware2(next echo.HandlerFunc) echo.HandlerFunc {
+	return userAuthMiddleware(next)
+}
+
+// userAuthMiddleware validates the user's JWT and forwards it, with the user's
+// ID and email, to the gRPC server as metadata.
+func userAuthMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
 	return func(c echo.Context) error {
 		token := c.Request().Header.Get("Authorization")
 		if token == "" {
@@ -139,3 +493,11 @@ func (h *TransactionHTTPHandler) authMiddleware2(next echo.HandlerFunc) echo.Han
 // 		return next(c)
 // 	}
 // }
+
+func toMoneyRequest(amount *model.Money) *pb.Money {
+	if amount == nil {
+		return nil
+	}
+
+	return &pb.Money{Amount: amount.Amount, Currency: amount.Currency}
+}
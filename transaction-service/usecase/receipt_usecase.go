@@ -0,0 +1,138 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"transaction-service/document"
+	"transaction-service/model"
+	"transaction-service/repository"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type IReceiptUsecase interface {
+	GetUserReceipt(ctx context.Context, email string, transactionID primitive.ObjectID) (*model.Receipt, error)
+	IssueReceipt(ctx context.Context, transaction *model.Transaction) (*model.Receipt, error)
+	GetTransactionsAwaitingReceipt(ctx context.Context, limit int) ([]model.Transaction, error)
+	MarkReceiptSent(ctx context.Context, transaction *model.Transaction) error
+	RecordReceiptFailure(ctx context.Context, transaction *model.Transaction) error
+}
+
+var ErrReceiptNotAvailable = errors.New("receipts are only available for paid transactions")
+
+type ReceiptUsecase struct {
+	receiptRepository     repository.IReceiptRepository
+	transactionRepository repository.ITransactionRepository
+}
+
+func NewReceiptUsecase(receiptRepository repository.IReceiptRepository, transactionRepository repository.ITransactionRepository) *ReceiptUsecase {
+	return &ReceiptUsecase{
+		receiptRepository:     receiptRepository,
+		transactionRepository: transactionRepository,
+	}
+}
+
+// GetUserReceipt returns the receipt of one of the user's paid transactions,
+// issuing it first if the scheduled issue has not happened yet or a refund
+// has made it outdated.
+func (u *ReceiptUsecase) GetUserReceipt(ctx context.Context, email string, transactionID primitive.ObjectID) (*model.Receipt, error) {
+	transaction, err := u.transactionRepository.GetTransactionByID(ctx, transactionID)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrTransactionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if transaction.UserEmail != email {
+		return nil, ErrTransactionNotFound
+	}
+
+	receipt, err := u.receiptRepository.GetReceiptByTransactionID(ctx, transactionID)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, err
+	}
+	if receipt != nil && receipt.RefundedAmount == transaction.RefundedAmount {
+		return receipt, nil
+	}
+
+	return u.IssueReceipt(ctx, transaction)
+}
+
+// IssueReceipt renders and stores the receipt of a paid transaction.
+func (u *ReceiptUsecase) IssueReceipt(ctx context.Context, transaction *model.Transaction) (*model.Receipt, error) {
+	if transaction.PaymentStatus != model.PaymentStatusPaid {
+		return nil, ErrReceiptNotAvailable
+	}
+
+	postID, err := uuid.Parse(transaction.PostID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid PostID format: %v", err)
+	}
+
+	post, err := u.transactionRepository.GetPostByID(ctx, postID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get post: %v", err)
+	}
+
+	institution, err := u.receiptRepository.GetInstitutionByID(ctx, post.InstitutionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get institution: %v", err)
+	}
+
+	issuedAt := time.Now()
+	pdf, err := document.RenderReceiptPDF(model.ReceiptData{
+		Transaction: transaction,
+		Post:        post,
+		Institution: institution,
+		IssuedAt:    issuedAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return u.receiptRepository.SaveReceipt(ctx, &model.Receipt{
+		TransactionID:  transaction.TransactionID,
+		UserEmail:      transaction.UserEmail,
+		RefundedAmount: transaction.RefundedAmount,
+		PDF:            pdf,
+		IssuedAt:       issuedAt,
+	})
+}
+
+func (u *ReceiptUsecase) GetTransactionsAwaitingReceipt(ctx context.Context, limit int) ([]model.Transaction, error) {
+	return u.receiptRepository.GetTransactionsAwaitingReceipt(ctx, int64(limit))
+}
+
+func (u *ReceiptUsecase) MarkReceiptSent(ctx context.Context, transaction *model.Transaction) error {
+	if err := u.receiptRepository.MarkReceiptSent(ctx, transaction.TransactionID); err != nil {
+		return err
+	}
+
+	transaction.ReceiptStatus = model.ReceiptStatusSent
+	return nil
+}
+
+// RecordReceiptFailure puts the receipt off until its next retry, or marks it
+// FAILED once it has used up MaxDeliveryAttempts.
+func (u *ReceiptUsecase) RecordReceiptFailure(ctx context.Context, transaction *model.Transaction) error {
+	attempts := transaction.ReceiptAttempts + 1
+	receiptStatus := model.ReceiptStatusPending
+	if attempts >= model.MaxDeliveryAttempts {
+		receiptStatus = model.ReceiptStatusFailed
+	}
+	retryAt := model.DeliveryRetryAt(attempts, time.Now())
+
+	if err := u.receiptRepository.RecordReceiptFailure(ctx, transaction.TransactionID, receiptStatus, attempts, retryAt); err != nil {
+		return err
+	}
+
+	transaction.ReceiptStatus = receiptStatus
+	transaction.ReceiptAttempts = attempts
+	transaction.ReceiptRetryAt = retryAt
+	return nil
+}
@@ -0,0 +1,373 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"transaction-service/model"
+	"transaction-service/repository"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type ISubscriptionUsecase interface {
+	CreateSubscription(ctx context.Context, subscription *model.Subscription) (*model.Subscription, error)
+	GetUserSubscriptionByID(ctx context.Context, email string, subscriptionID primitive.ObjectID) (*model.Subscription, error)
+	GetUserSubscriptions(ctx context.Context, email string) ([]model.Subscription, error)
+	PauseSubscription(ctx context.Context, email string, subscriptionID primitive.ObjectID) (*model.Subscription, error)
+	ResumeSubscription(ctx context.Context, email string, subscriptionID primitive.ObjectID) (*model.Subscription, error)
+	CancelSubscription(ctx context.Context, email string, subscriptionID primitive.ObjectID) (*model.Subscription, error)
+	EndSubscription(ctx context.Context, subscription *model.Subscription) error
+	GetDueSubscriptions(ctx context.Context, limit int) ([]model.Subscription, error)
+	GetSubscriptionsToRemind(ctx context.Context, remindBefore time.Duration, limit int) ([]model.Subscription, error)
+	MarkReminderSent(ctx context.Context, subscription *model.Subscription) error
+	AdvanceSubscription(ctx context.Context, subscription *model.Subscription) (bool, error)
+	ResolvePost(ctx context.Context, subscription *model.Subscription) (*model.Post, error)
+	CompleteCharge(ctx context.Context, subscription *model.Subscription, transaction *model.Transaction) error
+}
+
+var (
+	ErrSubscriptionNotFound          = errors.New("subscription not found")
+	ErrInvalidSubscriptionTransition = errors.New("invalid subscription status transition")
+	ErrPostEnded                     = errors.New("this fundraising has ended")
+	ErrPostNotPublished              = errors.New("this fundraising is not published")
+	ErrNoOpenPost                    = errors.New("institution has no open fundraising")
+	ErrSubscriptionChanged           = errors.New("subscription was changed by another request, please retry")
+)
+
+type SubscriptionUsecase struct {
+	subscriptionRepository repository.ISubscriptionRepository
+	transactionRepository  repository.ITransactionRepository
+}
+
+func NewSubscriptionUsecase(subscriptionRepository repository.ISubscriptionRepository, transactionRepository repository.ITransactionRepository) *SubscriptionUsecase {
+	return &SubscriptionUsecase{
+		subscriptionRepository: subscriptionRepository,
+		transactionRepository:  transactionRepository,
+	}
+}
+
+// CreateSubscription validates and stores a new active subscription whose
+// first charge is due now. The donor's profile name is resolved here, since
+// the scheduler charging later cycles has no user token to look it up with.
+func (u *SubscriptionUsecase) CreateSubscription(ctx context.Context, subscription *model.Subscription) (*model.Subscription, error) {
+	var e []string
+
+	if subscription.UserEmail == "" {
+		e = append(e, "User email is required")
+	}
+	if (subscription.PostID == "") == (subscription.InstitutionID == "") {
+		e = append(e, "Exactly one of Post ID or Institution ID is required")
+	}
+	if subscription.PostID != "" {
+		if _, err := uuid.Parse(subscription.PostID); err != nil {
+			e = append(e, "Post ID is invalid")
+		}
+	}
+	if subscription.InstitutionID != "" {
+		if _, err := uuid.Parse(subscription.InstitutionID); err != nil {
+			e = append(e, "Institution ID is invalid")
+		}
+	}
+	if subscription.Amount.Validate() != nil {
+		e = append(e, "Currency is not supported")
+	} else if subscription.Amount.Amount <= 0 {
+		e = append(e, "Amount must be greater than 0")
+	} else if subscription.Amount.Currency == model.CurrencyIDR && !subscription.Amount.IsWhole() {
+		e = append(e, "IDR amounts must be whole rupiah")
+	}
+	if subscription.Cadence == "" {
+		subscription.Cadence = model.SubscriptionCadenceMonthly
+	}
+	if !subscription.Cadence.IsValid() {
+		e = append(e, "Cadence must be MONTHLY")
+	}
+	if subscription.AccountNumber == "" {
+		e = append(e, "Account Number is required")
+	}
+	if subscription.AccountName == "" {
+		e = append(e, "Account Name is required")
+	}
+	e = append(e, normalizeDonorName(&subscription.DonorNameVisibility, &subscription.DonorName)...)
+
+	if len(e) > 0 {
+		return nil, errors.New(strings.Join(e, ", "))
+	}
+
+	if subscription.PostID != "" {
+		post, err := u.getPost(ctx, subscription.PostID)
+		if err != nil {
+			return nil, err
+		}
+		if !post.IsPublished() {
+			return nil, ErrPostNotPublished
+		}
+		if post.HasEnded(time.Now()) {
+			return nil, ErrPostEnded
+		}
+	}
+
+	if subscription.DonorNameVisibility == model.DonorNameProfile {
+		user, err := u.transactionRepository.GetUserByEmail(ctx, subscription.UserEmail)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get donor profile name: %w", err)
+		}
+		subscription.DonorName = strings.TrimSpace(user.Name)
+	}
+
+	now := time.Now()
+	subscription.Status = model.SubscriptionStatusActive
+	subscription.BillingDay = now.Day()
+	subscription.NextChargeAt = now
+	// The first charge is made straight away, so there is nothing to remind
+	// the donor of.
+	subscription.ReminderSentFor = now
+	subscription.CreatedAt = now
+	subscription.UpdatedAt = now
+
+	return u.subscriptionRepository.CreateSubscription(ctx, subscription)
+}
+
+func (u *SubscriptionUsecase) GetUserSubscriptionByID(ctx context.Context, email string, subscriptionID primitive.ObjectID) (*model.Subscription, error) {
+	subscription, err := u.subscriptionRepository.GetSubscriptionByID(ctx, subscriptionID)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrSubscriptionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if subscription.UserEmail != email {
+		return nil, ErrSubscriptionNotFound
+	}
+
+	return subscription, nil
+}
+
+func (u *SubscriptionUsecase) GetUserSubscriptions(ctx context.Context, email string) ([]model.Subscription, error) {
+	if email == "" {
+		return nil, errors.New("User email is required")
+	}
+
+	return u.subscriptionRepository.GetSubscriptionsByEmail(ctx, email)
+}
+
+func (u *SubscriptionUsecase) PauseSubscription(ctx context.Context, email string, subscriptionID primitive.ObjectID) (*model.Subscription, error) {
+	subscription, err := u.GetUserSubscriptionByID(ctx, email, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.transition(ctx, subscription, model.SubscriptionStatusPaused); err != nil {
+		return nil, err
+	}
+
+	return subscription, nil
+}
+
+// ResumeSubscription reactivates a paused subscription. Cycles missed while it
+// was paused are skipped, not charged.
+func (u *SubscriptionUsecase) ResumeSubscription(ctx context.Context, email string, subscriptionID primitive.ObjectID) (*model.Subscription, error) {
+	subscription, err := u.GetUserSubscriptionByID(ctx, email, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.transition(ctx, subscription, model.SubscriptionStatusActive); err != nil {
+		return nil, err
+	}
+
+	return subscription, nil
+}
+
+func (u *SubscriptionUsecase) CancelSubscription(ctx context.Context, email string, subscriptionID primitive.ObjectID) (*model.Subscription, error) {
+	subscription, err := u.GetUserSubscriptionByID(ctx, email, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.transition(ctx, subscription, model.SubscriptionStatusCancelled); err != nil {
+		return nil, err
+	}
+
+	return subscription, nil
+}
+
+// EndSubscription cancels a subscription whose post has ended.
+func (u *SubscriptionUsecase) EndSubscription(ctx context.Context, subscription *model.Subscription) error {
+	return u.transition(ctx, subscription, model.SubscriptionStatusCancelled)
+}
+
+func (u *SubscriptionUsecase) transition(ctx context.Context, subscription *model.Subscription, to model.SubscriptionStatus) error {
+	from := subscription.Status
+	if !from.CanTransitionTo(to) {
+		return fmt.Errorf("%w: %s to %s", ErrInvalidSubscriptionTransition, from, to)
+	}
+
+	now := time.Now()
+	subscription.Status = to
+	subscription.UpdatedAt = now
+	switch to {
+	case model.SubscriptionStatusActive:
+		if subscription.NextChargeAt.Before(now) {
+			subscription.NextChargeAt = subscription.ChargeAfter(now)
+		}
+	case model.SubscriptionStatusCancelled:
+		subscription.CancelledAt = now
+	}
+
+	updated, err := u.subscriptionRepository.UpdateSubscriptionStatus(ctx, subscription, from)
+	if err != nil {
+		return err
+	}
+	if !updated {
+		return ErrSubscriptionChanged
+	}
+
+	return nil
+}
+
+func (u *SubscriptionUsecase) GetDueSubscriptions(ctx context.Context, limit int) ([]model.Subscription, error) {
+	return u.subscriptionRepository.GetDueSubscriptions(ctx, time.Now(), int64(limit))
+}
+
+func (u *SubscriptionUsecase) GetSubscriptionsToRemind(ctx context.Context, remindBefore time.Duration, limit int) ([]model.Subscription, error) {
+	return u.subscriptionRepository.GetSubscriptionsToRemind(ctx, time.Now().Add(remindBefore), int64(limit))
+}
+
+func (u *SubscriptionUsecase) MarkReminderSent(ctx context.Context, subscription *model.Subscription) error {
+	if err := u.subscriptionRepository.MarkReminderSent(ctx, subscription.SubscriptionID, subscription.NextChargeAt); err != nil {
+		return err
+	}
+
+	subscription.ReminderSentFor = subscription.NextChargeAt
+	return nil
+}
+
+// AdvanceSubscription claims the subscription's due cycle by moving its next
+// charge to the first billing date after now, or, when an earlier pass claimed
+// a cycle without creating its transaction, claims the retry of that cycle.
+// It returns false when another scheduler claimed it first. A subscription
+// that fell behind is charged once, not once per missed cycle.
+func (u *SubscriptionUsecase) AdvanceSubscription(ctx context.Context, subscription *model.Subscription) (bool, error) {
+	now := time.Now()
+	retryAt := now.Add(model.ChargeRetryDelay)
+
+	if !subscription.ChargingCycle.IsZero() {
+		claimed, err := u.subscriptionRepository.RetryCharge(ctx, subscription, retryAt)
+		if err != nil || !claimed {
+			return claimed, err
+		}
+
+		subscription.ChargeRetryAt = retryAt
+		return true, nil
+	}
+
+	next := subscription.ChargeAfter(now)
+	transactionID := primitive.NewObjectID()
+
+	advanced, err := u.subscriptionRepository.AdvanceSubscription(ctx, subscription, next, retryAt, transactionID)
+	if err != nil || !advanced {
+		return advanced, err
+	}
+
+	subscription.ChargingCycle = subscription.NextChargeAt
+	subscription.ChargeRetryAt = retryAt
+	subscription.ChargeTransactionID = transactionID
+	subscription.NextChargeAt = next
+	return true, nil
+}
+
+// ResolvePost picks the post a cycle is donated to: the subscription's post,
+// or, for an institution subscription, its open post that ends soonest.
+func (u *SubscriptionUsecase) ResolvePost(ctx context.Context, subscription *model.Subscription) (*model.Post, error) {
+	now := time.Now()
+
+	if subscription.PostID != "" {
+		post, err := u.getPost(ctx, subscription.PostID)
+		if err != nil {
+			return nil, err
+		}
+		if !post.IsPublished() {
+			return nil, ErrPostNotPublished
+		}
+		if post.HasEnded(now) {
+			return nil, ErrPostEnded
+		}
+		return post, nil
+	}
+
+	institutionID, err := uuid.Parse(subscription.InstitutionID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid institution ID %q: %v", subscription.InstitutionID, err)
+	}
+
+	posts, err := u.transactionRepository.GetPostsByInstitutionID(ctx, institutionID)
+	if err != nil {
+		return nil, err
+	}
+
+	var resolved *model.Post
+	for i := range posts {
+		post := &posts[i]
+		if !post.IsPublished() || post.DateStart.After(now) || post.HasEnded(now) {
+			continue
+		}
+		fullyFunded := post.RemainingTarget().Amount <= 0
+		if fullyFunded && (post.OverflowPolicy == model.OverflowPolicyCap || post.OverflowPolicy == model.OverflowPolicyReject) {
+			continue
+		}
+		if resolved == nil || post.DateEnd.Before(resolved.DateEnd) {
+			resolved = post
+		}
+	}
+	if resolved == nil {
+		return nil, ErrNoOpenPost
+	}
+
+	return resolved, nil
+}
+
+// CompleteCharge releases the cycle claimed by AdvanceSubscription once its
+// transaction exists, or with a nil transaction when the cycle is skipped.
+func (u *SubscriptionUsecase) CompleteCharge(ctx context.Context, subscription *model.Subscription, transaction *model.Transaction) error {
+	var transactionID string
+	if transaction != nil {
+		transactionID = transaction.TransactionID.Hex()
+	}
+	if err := u.subscriptionRepository.CompleteCharge(ctx, subscription.SubscriptionID, transactionID); err != nil {
+		return err
+	}
+
+	if transactionID != "" {
+		subscription.LastTransactionID = transactionID
+	}
+	subscription.ChargingCycle = time.Time{}
+	subscription.ChargeRetryAt = time.Time{}
+	subscription.ChargeTransactionID = primitive.NilObjectID
+	return nil
+}
+
+func (u *SubscriptionUsecase) getPost(ctx context.Context, postID string) (*model.Post, error) {
+	parsedPostID, err := uuid.Parse(postID)
+	if err != nil {
+		return nil, ErrPostNotFound
+	}
+
+	post, err := u.transactionRepository.GetPostByID(ctx, parsedPostID)
+	if status.Code(err) == codes.NotFound {
+		return nil, ErrPostNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return post, nil
+}
@@ -0,0 +1,196 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"transaction-service/document"
+	"transaction-service/model"
+	"transaction-service/repository"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type ITaxStatementUsecase interface {
+	BuildTaxStatement(ctx context.Context, userID string, year int) (*model.TaxStatement, error)
+	RenderTaxStatement(statement *model.TaxStatement, format model.TaxStatementFormat) (*model.TaxStatementFile, error)
+	GetTaxStatementRecipients(ctx context.Context, year int, limit int) ([]model.TaxStatementRecipient, error)
+	MarkTaxStatementSent(ctx context.Context, userID string, year int) error
+	RecordTaxStatementFailure(ctx context.Context, recipient model.TaxStatementRecipient, year int) error
+}
+
+// firstTaxYear is the earliest year a statement can be requested for.
+const firstTaxYear = 2000
+
+var (
+	ErrInvalidTaxYear            = errors.New("year must be a past or the current year")
+	ErrInvalidTaxStatementFormat = errors.New("format must be pdf or csv")
+	ErrNoDonationsInTaxYear      = errors.New("no paid donations in this year")
+)
+
+type TaxStatementUsecase struct {
+	taxStatementRepository repository.ITaxStatementRepository
+	transactionRepository  repository.ITransactionRepository
+}
+
+func NewTaxStatementUsecase(taxStatementRepository repository.ITaxStatementRepository, transactionRepository repository.ITransactionRepository) *TaxStatementUsecase {
+	return &TaxStatementUsecase{
+		taxStatementRepository: taxStatementRepository,
+		transactionRepository:  transactionRepository,
+	}
+}
+
+// BuildTaxStatement aggregates the user's paid transactions of year by
+// institution. Partial refunds are deducted; fully refunded transactions are
+// no longer PAID and are left out.
+func (u *TaxStatementUsecase) BuildTaxStatement(ctx context.Context, userID string, year int) (*model.TaxStatement, error) {
+	if year < firstTaxYear || year > time.Now().In(model.TaxYearLocation).Year() {
+		return nil, ErrInvalidTaxYear
+	}
+
+	from, to := model.TaxYearRange(year)
+	transactions, err := u.taxStatementRepository.GetPaidTransactionsByUserID(ctx, userID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get paid transactions: %v", err)
+	}
+	if len(transactions) == 0 {
+		return nil, ErrNoDonationsInTaxYear
+	}
+
+	statement := &model.TaxStatement{
+		UserID:      userID,
+		Year:        year,
+		GeneratedAt: time.Now(),
+	}
+
+	posts := map[string]*model.Post{}
+	groups := map[uuid.UUID]*model.TaxStatementInstitution{}
+
+	for i := range transactions {
+		transaction := &transactions[i]
+
+		post, ok := posts[transaction.PostID]
+		if !ok {
+			post, err = u.getPost(ctx, transaction)
+			if err != nil {
+				return nil, err
+			}
+			posts[transaction.PostID] = post
+		}
+
+		group, ok := groups[post.InstitutionID]
+		if !ok {
+			institution, err := u.taxStatementRepository.GetInstitutionByID(ctx, post.InstitutionID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get institution: %v", err)
+			}
+			group = &model.TaxStatementInstitution{Institution: *institution}
+			groups[post.InstitutionID] = group
+		}
+
+		net := transaction.RefundableAmount()
+		group.Donations = append(group.Donations, model.TaxStatementDonation{
+			TransactionID: transaction.TransactionID,
+			PostTitle:     post.Title,
+			PaidAt:        transaction.PaidAt,
+			PaymentMethod: transaction.PaymentMethod,
+			Amount:        transaction.Amount,
+			Net:           net,
+		})
+		group.Totals = model.AddTotal(group.Totals, net)
+		statement.Totals = model.AddTotal(statement.Totals, net)
+
+		// Transactions are ordered by payment, so the latest donation
+		// decides the email the statement is addressed to.
+		statement.UserEmail = transaction.UserEmail
+	}
+
+	donor, err := u.transactionRepository.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get donor: %v", err)
+	}
+	statement.DonorName = donor.Name
+
+	for _, group := range groups {
+		statement.Institutions = append(statement.Institutions, *group)
+	}
+	sort.Slice(statement.Institutions, func(i, j int) bool {
+		return statement.Institutions[i].Institution.Name < statement.Institutions[j].Institution.Name
+	})
+
+	return statement, nil
+}
+
+// getPost falls back to the post title and institution stored on the
+// transaction when the post no longer exists. Any other error is returned so
+// the statement is retried rather than built from stale details.
+func (u *TaxStatementUsecase) getPost(ctx context.Context, transaction *model.Transaction) (*model.Post, error) {
+	postID, err := uuid.Parse(transaction.PostID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid PostID format: %v", err)
+	}
+
+	post, err := u.transactionRepository.GetPostByID(ctx, postID)
+	if err == nil {
+		return post, nil
+	}
+	if status.Code(err) != codes.NotFound {
+		return nil, fmt.Errorf("failed to get post: %v", err)
+	}
+
+	institutionID, parseErr := uuid.Parse(transaction.InstitutionID)
+	if parseErr != nil {
+		return nil, fmt.Errorf("failed to get post: %v", err)
+	}
+
+	return &model.Post{
+		PostID:        postID,
+		InstitutionID: institutionID,
+		Title:         transaction.PostTitle,
+	}, nil
+}
+
+func (u *TaxStatementUsecase) RenderTaxStatement(statement *model.TaxStatement, format model.TaxStatementFormat) (*model.TaxStatementFile, error) {
+	var (
+		content []byte
+		err     error
+	)
+
+	switch format {
+	case model.TaxStatementFormatPDF:
+		content, err = document.RenderTaxStatementPDF(statement)
+	case model.TaxStatementFormatCSV:
+		content, err = document.RenderTaxStatementCSV(statement)
+	default:
+		return nil, ErrInvalidTaxStatementFormat
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.TaxStatementFile{
+		Filename:    statement.Filename(format),
+		ContentType: format.ContentType(),
+		Content:     content,
+	}, nil
+}
+
+func (u *TaxStatementUsecase) GetTaxStatementRecipients(ctx context.Context, year int, limit int) ([]model.TaxStatementRecipient, error) {
+	return u.taxStatementRepository.GetTaxStatementRecipients(ctx, year, int64(limit))
+}
+
+func (u *TaxStatementUsecase) MarkTaxStatementSent(ctx context.Context, userID string, year int) error {
+	return u.taxStatementRepository.MarkTaxStatementSent(ctx, userID, year)
+}
+
+// RecordTaxStatementFailure puts the recipient's statement off until its next
+// retry; after MaxDeliveryAttempts it is no longer sent.
+func (u *TaxStatementUsecase) RecordTaxStatementFailure(ctx context.Context, recipient model.TaxStatementRecipient, year int) error {
+	attempts := recipient.Attempts + 1
+	return u.taxStatementRepository.RecordTaxStatementFailure(ctx, recipient.UserID, year, attempts, model.DeliveryRetryAt(attempts, time.Now()))
+}
@@ -0,0 +1,109 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"transaction-service/mocks"
+	"transaction-service/model"
+	"transaction-service/usecase"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestGetUserReceipt(t *testing.T) {
+	t.Run("success - stored receipt is returned", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockReceiptRepo := mocks.NewMockIReceiptRepository(ctrl)
+		mockTransactionRepo := mocks.NewMockITransactionRepository(ctrl)
+		receiptUsecase := usecase.NewReceiptUsecase(mockReceiptRepo, mockTransactionRepo)
+
+		transaction := newPaidTransaction()
+		stored := &model.Receipt{TransactionID: transaction.TransactionID, RefundedAmount: transaction.RefundedAmount, PDF: []byte("%PDF-stored")}
+
+		mockTransactionRepo.EXPECT().GetTransactionByID(gomock.Any(), transaction.TransactionID).Return(transaction, nil)
+		mockReceiptRepo.EXPECT().GetReceiptByTransactionID(gomock.Any(), transaction.TransactionID).Return(stored, nil)
+
+		receipt, err := receiptUsecase.GetUserReceipt(context.Background(), "donor@email.com", transaction.TransactionID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, stored, receipt)
+	})
+
+	t.Run("success - receipt outdated by a refund is issued again", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockReceiptRepo := mocks.NewMockIReceiptRepository(ctrl)
+		mockTransactionRepo := mocks.NewMockITransactionRepository(ctrl)
+		receiptUsecase := usecase.NewReceiptUsecase(mockReceiptRepo, mockTransactionRepo)
+
+		transaction := newPaidTransaction()
+		transaction.RefundedAmount = model.IDR(10000)
+		institutionID := uuid.New()
+
+		mockTransactionRepo.EXPECT().GetTransactionByID(gomock.Any(), transaction.TransactionID).Return(transaction, nil)
+		mockReceiptRepo.EXPECT().
+			GetReceiptByTransactionID(gomock.Any(), transaction.TransactionID).
+			Return(&model.Receipt{TransactionID: transaction.TransactionID}, nil)
+		mockTransactionRepo.EXPECT().
+			GetPostByID(gomock.Any(), uuid.MustParse(transaction.PostID)).
+			Return(&model.Post{InstitutionID: institutionID, Title: "Beasiswa"}, nil)
+		mockReceiptRepo.EXPECT().
+			GetInstitutionByID(gomock.Any(), institutionID).
+			Return(&model.Institution{InstitutionID: institutionID, Name: "Yayasan", Address: "Jakarta"}, nil)
+		mockReceiptRepo.EXPECT().
+			SaveReceipt(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, receipt *model.Receipt) (*model.Receipt, error) {
+				assert.Equal(t, transaction.TransactionID, receipt.TransactionID)
+				assert.Equal(t, model.IDR(10000), receipt.RefundedAmount)
+				assert.True(t, bytes.HasPrefix(receipt.PDF, []byte("%PDF-")))
+				return receipt, nil
+			})
+
+		receipt, err := receiptUsecase.GetUserReceipt(context.Background(), "donor@email.com", transaction.TransactionID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, model.IDR(10000), receipt.RefundedAmount)
+	})
+
+	t.Run("failed - another user's transaction", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransactionRepo := mocks.NewMockITransactionRepository(ctrl)
+		receiptUsecase := usecase.NewReceiptUsecase(mocks.NewMockIReceiptRepository(ctrl), mockTransactionRepo)
+
+		transaction := newPaidTransaction()
+		mockTransactionRepo.EXPECT().GetTransactionByID(gomock.Any(), transaction.TransactionID).Return(transaction, nil)
+
+		receipt, err := receiptUsecase.GetUserReceipt(context.Background(), "other@email.com", transaction.TransactionID)
+
+		assert.Nil(t, receipt)
+		assert.ErrorIs(t, err, usecase.ErrTransactionNotFound)
+	})
+
+	t.Run("failed - transaction is not paid", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockReceiptRepo := mocks.NewMockIReceiptRepository(ctrl)
+		mockTransactionRepo := mocks.NewMockITransactionRepository(ctrl)
+		receiptUsecase := usecase.NewReceiptUsecase(mockReceiptRepo, mockTransactionRepo)
+
+		transaction := newPendingTransaction()
+		mockTransactionRepo.EXPECT().GetTransactionByID(gomock.Any(), transaction.TransactionID).Return(transaction, nil)
+		mockReceiptRepo.EXPECT().GetReceiptByTransactionID(gomock.Any(), transaction.TransactionID).Return(nil, mongo.ErrNoDocuments)
+
+		receipt, err := receiptUsecase.GetUserReceipt(context.Background(), "donor@email.com", transaction.TransactionID)
+
+		assert.Nil(t, receipt)
+		assert.ErrorIs(t, err, usecase.ErrReceiptNotAvailable)
+	})
+}
@@ -0,0 +1,428 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"transaction-service/mocks"
+	"transaction-service/model"
+	"transaction-service/usecase"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func newSubscription() *model.Subscription {
+	return &model.Subscription{
+		UserID:              uuid.New().String(),
+		UserEmail:           "donor@email.com",
+		PostID:              uuid.New().String(),
+		Amount:              model.IDR(50000),
+		AccountNumber:       "1234567890",
+		AccountName:         "Donor",
+		DonorNameVisibility: model.DonorNameDisplay,
+		DonorName:           "Hamba Allah",
+	}
+}
+
+func TestCreateSubscription(t *testing.T) {
+	t.Run("success - first charge is due now and not reminded", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockSubscriptionRepo := mocks.NewMockISubscriptionRepository(ctrl)
+		mockTransactionRepo := mocks.NewMockITransactionRepository(ctrl)
+		subscriptionUsecase := usecase.NewSubscriptionUsecase(mockSubscriptionRepo, mockTransactionRepo)
+
+		subscription := newSubscription()
+		mockTransactionRepo.EXPECT().
+			GetPostByID(gomock.Any(), uuid.MustParse(subscription.PostID)).
+			Return(&model.Post{Status: model.PostStatusPublished, DateEnd: time.Now().AddDate(0, 6, 0)}, nil)
+		mockSubscriptionRepo.EXPECT().
+			CreateSubscription(gomock.Any(), subscription).
+			Return(subscription, nil)
+
+		result, err := subscriptionUsecase.CreateSubscription(context.Background(), subscription)
+
+		assert.NoError(t, err)
+		assert.Equal(t, model.SubscriptionStatusActive, result.Status)
+		assert.Equal(t, model.SubscriptionCadenceMonthly, result.Cadence)
+		assert.Equal(t, result.NextChargeAt.Day(), result.BillingDay)
+		assert.Equal(t, result.NextChargeAt, result.ReminderSentFor)
+	})
+
+	t.Run("success - profile name is resolved up front", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockSubscriptionRepo := mocks.NewMockISubscriptionRepository(ctrl)
+		mockTransactionRepo := mocks.NewMockITransactionRepository(ctrl)
+		subscriptionUsecase := usecase.NewSubscriptionUsecase(mockSubscriptionRepo, mockTransactionRepo)
+
+		subscription := newSubscription()
+		subscription.PostID = ""
+		subscription.InstitutionID = uuid.New().String()
+		subscription.DonorNameVisibility = model.DonorNameProfile
+		mockTransactionRepo.EXPECT().
+			GetUserByEmail(gomock.Any(), "donor@email.com").
+			Return(&model.User{Name: " Budi "}, nil)
+		mockSubscriptionRepo.EXPECT().
+			CreateSubscription(gomock.Any(), subscription).
+			Return(subscription, nil)
+
+		result, err := subscriptionUsecase.CreateSubscription(context.Background(), subscription)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Budi", result.DonorName)
+	})
+
+	t.Run("failed - invalid request", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		subscriptionUsecase := usecase.NewSubscriptionUsecase(mocks.NewMockISubscriptionRepository(ctrl), mocks.NewMockITransactionRepository(ctrl))
+
+		subscription := newSubscription()
+		subscription.InstitutionID = uuid.New().String()
+		subscription.Amount = model.IDR(0)
+		subscription.Cadence = "WEEKLY"
+		subscription.AccountNumber = ""
+
+		result, err := subscriptionUsecase.CreateSubscription(context.Background(), subscription)
+
+		assert.Nil(t, result)
+		assert.EqualError(t, err, "Exactly one of Post ID or Institution ID is required, Amount must be greater than 0, Cadence must be MONTHLY, Account Number is required")
+	})
+
+	t.Run("failed - post has ended", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransactionRepo := mocks.NewMockITransactionRepository(ctrl)
+		subscriptionUsecase := usecase.NewSubscriptionUsecase(mocks.NewMockISubscriptionRepository(ctrl), mockTransactionRepo)
+
+		mockTransactionRepo.EXPECT().
+			GetPostByID(gomock.Any(), gomock.Any()).
+			Return(&model.Post{Status: model.PostStatusPublished, DateEnd: time.Now().AddDate(0, 0, -1)}, nil)
+
+		result, err := subscriptionUsecase.CreateSubscription(context.Background(), newSubscription())
+
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, usecase.ErrPostEnded)
+	})
+
+	t.Run("failed - post not published", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransactionRepo := mocks.NewMockITransactionRepository(ctrl)
+		subscriptionUsecase := usecase.NewSubscriptionUsecase(mocks.NewMockISubscriptionRepository(ctrl), mockTransactionRepo)
+
+		mockTransactionRepo.EXPECT().
+			GetPostByID(gomock.Any(), gomock.Any()).
+			Return(&model.Post{Status: "CLOSED", DateEnd: time.Now().AddDate(0, 6, 0)}, nil)
+
+		result, err := subscriptionUsecase.CreateSubscription(context.Background(), newSubscription())
+
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, usecase.ErrPostNotPublished)
+	})
+
+	t.Run("failed - post not found", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransactionRepo := mocks.NewMockITransactionRepository(ctrl)
+		subscriptionUsecase := usecase.NewSubscriptionUsecase(mocks.NewMockISubscriptionRepository(ctrl), mockTransactionRepo)
+
+		mockTransactionRepo.EXPECT().
+			GetPostByID(gomock.Any(), gomock.Any()).
+			Return(nil, status.Error(codes.NotFound, "post not found"))
+
+		result, err := subscriptionUsecase.CreateSubscription(context.Background(), newSubscription())
+
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, usecase.ErrPostNotFound)
+	})
+}
+
+func TestSubscriptionTransitions(t *testing.T) {
+	t.Run("success - pause active subscription", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockSubscriptionRepo := mocks.NewMockISubscriptionRepository(ctrl)
+		subscriptionUsecase := usecase.NewSubscriptionUsecase(mockSubscriptionRepo, mocks.NewMockITransactionRepository(ctrl))
+
+		subscription := newSubscription()
+		subscription.SubscriptionID = primitive.NewObjectID()
+		subscription.Status = model.SubscriptionStatusActive
+		mockSubscriptionRepo.EXPECT().GetSubscriptionByID(gomock.Any(), subscription.SubscriptionID).Return(subscription, nil)
+		mockSubscriptionRepo.EXPECT().
+			UpdateSubscriptionStatus(gomock.Any(), subscription, model.SubscriptionStatusActive).
+			Return(true, nil)
+
+		result, err := subscriptionUsecase.PauseSubscription(context.Background(), "donor@email.com", subscription.SubscriptionID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, model.SubscriptionStatusPaused, result.Status)
+	})
+
+	t.Run("success - resume skips cycles missed while paused", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockSubscriptionRepo := mocks.NewMockISubscriptionRepository(ctrl)
+		subscriptionUsecase := usecase.NewSubscriptionUsecase(mockSubscriptionRepo, mocks.NewMockITransactionRepository(ctrl))
+
+		subscription := newSubscription()
+		subscription.SubscriptionID = primitive.NewObjectID()
+		subscription.Status = model.SubscriptionStatusPaused
+		subscription.NextChargeAt = time.Now().AddDate(0, -3, 0)
+		subscription.BillingDay = subscription.NextChargeAt.Day()
+		mockSubscriptionRepo.EXPECT().GetSubscriptionByID(gomock.Any(), subscription.SubscriptionID).Return(subscription, nil)
+		mockSubscriptionRepo.EXPECT().
+			UpdateSubscriptionStatus(gomock.Any(), subscription, model.SubscriptionStatusPaused).
+			Return(true, nil)
+
+		result, err := subscriptionUsecase.ResumeSubscription(context.Background(), "donor@email.com", subscription.SubscriptionID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, model.SubscriptionStatusActive, result.Status)
+		assert.True(t, result.NextChargeAt.After(time.Now()))
+		assert.True(t, result.NextChargeAt.Before(time.Now().AddDate(0, 1, 1)))
+	})
+
+	t.Run("failed - cancelled subscription cannot resume", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockSubscriptionRepo := mocks.NewMockISubscriptionRepository(ctrl)
+		subscriptionUsecase := usecase.NewSubscriptionUsecase(mockSubscriptionRepo, mocks.NewMockITransactionRepository(ctrl))
+
+		subscription := newSubscription()
+		subscription.SubscriptionID = primitive.NewObjectID()
+		subscription.Status = model.SubscriptionStatusCancelled
+		mockSubscriptionRepo.EXPECT().GetSubscriptionByID(gomock.Any(), subscription.SubscriptionID).Return(subscription, nil)
+
+		result, err := subscriptionUsecase.ResumeSubscription(context.Background(), "donor@email.com", subscription.SubscriptionID)
+
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, usecase.ErrInvalidSubscriptionTransition)
+	})
+
+	t.Run("failed - changed by a concurrent request", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockSubscriptionRepo := mocks.NewMockISubscriptionRepository(ctrl)
+		subscriptionUsecase := usecase.NewSubscriptionUsecase(mockSubscriptionRepo, mocks.NewMockITransactionRepository(ctrl))
+
+		subscription := newSubscription()
+		subscription.SubscriptionID = primitive.NewObjectID()
+		subscription.Status = model.SubscriptionStatusActive
+		mockSubscriptionRepo.EXPECT().GetSubscriptionByID(gomock.Any(), subscription.SubscriptionID).Return(subscription, nil)
+		mockSubscriptionRepo.EXPECT().
+			UpdateSubscriptionStatus(gomock.Any(), subscription, model.SubscriptionStatusActive).
+			Return(false, nil)
+
+		result, err := subscriptionUsecase.CancelSubscription(context.Background(), "donor@email.com", subscription.SubscriptionID)
+
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, usecase.ErrSubscriptionChanged)
+	})
+
+	t.Run("failed - another user's subscription", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockSubscriptionRepo := mocks.NewMockISubscriptionRepository(ctrl)
+		subscriptionUsecase := usecase.NewSubscriptionUsecase(mockSubscriptionRepo, mocks.NewMockITransactionRepository(ctrl))
+
+		subscription := newSubscription()
+		subscription.SubscriptionID = primitive.NewObjectID()
+		mockSubscriptionRepo.EXPECT().GetSubscriptionByID(gomock.Any(), subscription.SubscriptionID).Return(subscription, nil)
+
+		result, err := subscriptionUsecase.PauseSubscription(context.Background(), "other@email.com", subscription.SubscriptionID)
+
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, usecase.ErrSubscriptionNotFound)
+	})
+
+	t.Run("failed - subscription not found", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockSubscriptionRepo := mocks.NewMockISubscriptionRepository(ctrl)
+		subscriptionUsecase := usecase.NewSubscriptionUsecase(mockSubscriptionRepo, mocks.NewMockITransactionRepository(ctrl))
+
+		mockSubscriptionRepo.EXPECT().GetSubscriptionByID(gomock.Any(), gomock.Any()).Return(nil, mongo.ErrNoDocuments)
+
+		result, err := subscriptionUsecase.GetUserSubscriptionByID(context.Background(), "donor@email.com", primitive.NewObjectID())
+
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, usecase.ErrSubscriptionNotFound)
+	})
+}
+
+func TestResolveSubscriptionPost(t *testing.T) {
+	t.Run("success - institution's open post ending soonest", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransactionRepo := mocks.NewMockITransactionRepository(ctrl)
+		subscriptionUsecase := usecase.NewSubscriptionUsecase(mocks.NewMockISubscriptionRepository(ctrl), mockTransactionRepo)
+
+		institutionID := uuid.New()
+		now := time.Now()
+		open := func(endsIn int) model.Post {
+			return model.Post{
+				PostID:       uuid.New(),
+				Status:       model.PostStatusPublished,
+				DateStart:    now.AddDate(0, -1, 0),
+				DateEnd:      now.AddDate(0, 0, endsIn),
+				FundTarget:   model.IDR(1000000),
+				FundAchieved: model.IDR(0),
+			}
+		}
+		later, soonest := open(30), open(10)
+		ended := open(-1)
+		funded := open(5)
+		funded.FundAchieved = funded.FundTarget
+		funded.OverflowPolicy = model.OverflowPolicyReject
+		closed := open(1)
+		closed.Status = "CLOSED"
+
+		mockTransactionRepo.EXPECT().
+			GetPostsByInstitutionID(gomock.Any(), institutionID).
+			Return([]model.Post{later, ended, funded, closed, soonest}, nil)
+
+		post, err := subscriptionUsecase.ResolvePost(context.Background(), &model.Subscription{InstitutionID: institutionID.String()})
+
+		assert.NoError(t, err)
+		assert.Equal(t, soonest.PostID, post.PostID)
+	})
+
+	t.Run("failed - institution has no open post", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransactionRepo := mocks.NewMockITransactionRepository(ctrl)
+		subscriptionUsecase := usecase.NewSubscriptionUsecase(mocks.NewMockISubscriptionRepository(ctrl), mockTransactionRepo)
+
+		mockTransactionRepo.EXPECT().GetPostsByInstitutionID(gomock.Any(), gomock.Any()).Return(nil, nil)
+
+		post, err := subscriptionUsecase.ResolvePost(context.Background(), &model.Subscription{InstitutionID: uuid.New().String()})
+
+		assert.Nil(t, post)
+		assert.ErrorIs(t, err, usecase.ErrNoOpenPost)
+	})
+
+	t.Run("failed - institution posts unavailable", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransactionRepo := mocks.NewMockITransactionRepository(ctrl)
+		subscriptionUsecase := usecase.NewSubscriptionUsecase(mocks.NewMockISubscriptionRepository(ctrl), mockTransactionRepo)
+
+		mockTransactionRepo.EXPECT().GetPostsByInstitutionID(gomock.Any(), gomock.Any()).Return(nil, errors.New("unavailable"))
+
+		post, err := subscriptionUsecase.ResolvePost(context.Background(), &model.Subscription{InstitutionID: uuid.New().String()})
+
+		assert.Nil(t, post)
+		assert.EqualError(t, err, "unavailable")
+	})
+}
+
+func TestAdvanceSubscription(t *testing.T) {
+	t.Run("success - claims the due cycle until its transaction exists", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockSubscriptionRepo := mocks.NewMockISubscriptionRepository(ctrl)
+		subscriptionUsecase := usecase.NewSubscriptionUsecase(mockSubscriptionRepo, mocks.NewMockITransactionRepository(ctrl))
+
+		subscription := newSubscription()
+		subscription.SubscriptionID = primitive.NewObjectID()
+		subscription.Status = model.SubscriptionStatusActive
+		subscription.NextChargeAt = time.Now().Add(-time.Minute)
+		subscription.BillingDay = subscription.NextChargeAt.Day()
+		cycle := subscription.NextChargeAt
+		mockSubscriptionRepo.EXPECT().
+			AdvanceSubscription(gomock.Any(), subscription, gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(true, nil)
+
+		claimed, err := subscriptionUsecase.AdvanceSubscription(context.Background(), subscription)
+
+		assert.NoError(t, err)
+		assert.True(t, claimed)
+		assert.Equal(t, cycle, subscription.ChargingCycle)
+		assert.True(t, subscription.NextChargeAt.After(time.Now()))
+		assert.True(t, subscription.ChargeRetryAt.After(time.Now()))
+		assert.False(t, subscription.ChargeTransactionID.IsZero())
+
+		transaction := &model.Transaction{TransactionID: subscription.ChargeTransactionID}
+		mockSubscriptionRepo.EXPECT().
+			CompleteCharge(gomock.Any(), subscription.SubscriptionID, transaction.TransactionID.Hex()).
+			Return(nil)
+
+		assert.NoError(t, subscriptionUsecase.CompleteCharge(context.Background(), subscription, transaction))
+		assert.Equal(t, transaction.TransactionID.Hex(), subscription.LastTransactionID)
+		assert.True(t, subscription.ChargingCycle.IsZero())
+	})
+
+	t.Run("success - retries a claimed cycle without advancing", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockSubscriptionRepo := mocks.NewMockISubscriptionRepository(ctrl)
+		subscriptionUsecase := usecase.NewSubscriptionUsecase(mockSubscriptionRepo, mocks.NewMockITransactionRepository(ctrl))
+
+		subscription := newSubscription()
+		subscription.SubscriptionID = primitive.NewObjectID()
+		subscription.Status = model.SubscriptionStatusActive
+		subscription.NextChargeAt = time.Now().AddDate(0, 1, 0)
+		subscription.ChargingCycle = time.Now().Add(-time.Hour)
+		subscription.ChargeRetryAt = time.Now().Add(-time.Minute)
+		subscription.ChargeTransactionID = primitive.NewObjectID()
+		nextChargeAt := subscription.NextChargeAt
+		transactionID := subscription.ChargeTransactionID
+		mockSubscriptionRepo.EXPECT().RetryCharge(gomock.Any(), subscription, gomock.Any()).Return(true, nil)
+
+		claimed, err := subscriptionUsecase.AdvanceSubscription(context.Background(), subscription)
+
+		assert.NoError(t, err)
+		assert.True(t, claimed)
+		assert.Equal(t, nextChargeAt, subscription.NextChargeAt)
+		assert.Equal(t, transactionID, subscription.ChargeTransactionID)
+		assert.True(t, subscription.ChargeRetryAt.After(time.Now()))
+	})
+
+	t.Run("success - retry claimed elsewhere", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockSubscriptionRepo := mocks.NewMockISubscriptionRepository(ctrl)
+		subscriptionUsecase := usecase.NewSubscriptionUsecase(mockSubscriptionRepo, mocks.NewMockITransactionRepository(ctrl))
+
+		subscription := newSubscription()
+		subscription.SubscriptionID = primitive.NewObjectID()
+		subscription.ChargingCycle = time.Now().Add(-time.Hour)
+		subscription.ChargeRetryAt = time.Now().Add(-time.Minute)
+		retryAt := subscription.ChargeRetryAt
+		mockSubscriptionRepo.EXPECT().RetryCharge(gomock.Any(), subscription, gomock.Any()).Return(false, nil)
+
+		claimed, err := subscriptionUsecase.AdvanceSubscription(context.Background(), subscription)
+
+		assert.NoError(t, err)
+		assert.False(t, claimed)
+		assert.Equal(t, retryAt, subscription.ChargeRetryAt)
+	})
+}
@@ -0,0 +1,205 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"transaction-service/mocks"
+	"transaction-service/model"
+	"transaction-service/usecase"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func newYearTransaction(userID, postID string, amount model.Money, paidAt time.Time) model.Transaction {
+	return model.Transaction{
+		TransactionID: primitive.NewObjectID(),
+		UserID:        userID,
+		PostID:        postID,
+		UserEmail:     "donor@email.com",
+		PaymentStatus: model.PaymentStatusPaid,
+		PaidAt:        paidAt,
+		Amount:        amount,
+		AccountName:   "PT Santoso Jaya",
+	}
+}
+
+func TestBuildTaxStatement(t *testing.T) {
+	t.Run("success - groups donations by institution net of refunds", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTaxStatementRepo := mocks.NewMockITaxStatementRepository(ctrl)
+		mockTransactionRepo := mocks.NewMockITransactionRepository(ctrl)
+		taxStatementUsecase := usecase.NewTaxStatementUsecase(mockTaxStatementRepo, mockTransactionRepo)
+
+		userID := uuid.New().String()
+		yayasan, pesantren := uuid.New(), uuid.New()
+		beasiswa := &model.Post{PostID: uuid.New(), InstitutionID: yayasan, Title: "Beasiswa"}
+		asrama := &model.Post{PostID: uuid.New(), InstitutionID: pesantren, Title: "Asrama"}
+
+		refunded := newYearTransaction(userID, beasiswa.PostID.String(), model.IDR(100000), time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC))
+		refunded.RefundedAmount = model.IDR(40000)
+		transactions := []model.Transaction{
+			refunded,
+			newYearTransaction(userID, asrama.PostID.String(), model.NewMoney(2500, "USD"), time.Date(2024, time.June, 1, 0, 0, 0, 0, time.UTC)),
+			newYearTransaction(userID, beasiswa.PostID.String(), model.IDR(50000), time.Date(2024, time.December, 1, 0, 0, 0, 0, time.UTC)),
+		}
+
+		from, to := model.TaxYearRange(2024)
+		mockTaxStatementRepo.EXPECT().GetPaidTransactionsByUserID(gomock.Any(), userID, from, to).Return(transactions, nil)
+		mockTransactionRepo.EXPECT().GetPostByID(gomock.Any(), beasiswa.PostID).Return(beasiswa, nil).Times(1)
+		mockTransactionRepo.EXPECT().GetPostByID(gomock.Any(), asrama.PostID).Return(asrama, nil).Times(1)
+		mockTaxStatementRepo.EXPECT().
+			GetInstitutionByID(gomock.Any(), yayasan).
+			Return(&model.Institution{InstitutionID: yayasan, Name: "Yayasan Nusantara"}, nil).
+			Times(1)
+		mockTaxStatementRepo.EXPECT().
+			GetInstitutionByID(gomock.Any(), pesantren).
+			Return(&model.Institution{InstitutionID: pesantren, Name: "Pesantren Al-Ikhlas"}, nil).
+			Times(1)
+		mockTransactionRepo.EXPECT().GetUserByID(gomock.Any(), userID).Return(&model.User{UserID: userID, Name: "Budi Santoso"}, nil)
+
+		statement, err := taxStatementUsecase.BuildTaxStatement(context.Background(), userID, 2024)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Budi Santoso", statement.DonorName)
+		assert.Equal(t, "donor@email.com", statement.UserEmail)
+		assert.Len(t, statement.Institutions, 2)
+		assert.Equal(t, "Pesantren Al-Ikhlas", statement.Institutions[0].Institution.Name)
+		assert.Equal(t, []model.Money{model.NewMoney(2500, "USD")}, statement.Institutions[0].Totals)
+		assert.Equal(t, "Yayasan Nusantara", statement.Institutions[1].Institution.Name)
+		assert.Len(t, statement.Institutions[1].Donations, 2)
+		assert.Equal(t, model.IDR(60000), statement.Institutions[1].Donations[0].Net)
+		assert.Equal(t, []model.Money{model.IDR(110000)}, statement.Institutions[1].Totals)
+		assert.Equal(t, []model.Money{model.IDR(110000), model.NewMoney(2500, "USD")}, statement.Totals)
+	})
+
+	t.Run("success - deleted post uses the data stored on the transaction", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTaxStatementRepo := mocks.NewMockITaxStatementRepository(ctrl)
+		mockTransactionRepo := mocks.NewMockITransactionRepository(ctrl)
+		taxStatementUsecase := usecase.NewTaxStatementUsecase(mockTaxStatementRepo, mockTransactionRepo)
+
+		userID, postID, institutionID := uuid.New().String(), uuid.New(), uuid.New()
+		transaction := newYearTransaction(userID, postID.String(), model.IDR(100000), time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC))
+		transaction.PostTitle = "Beasiswa"
+		transaction.InstitutionID = institutionID.String()
+
+		mockTaxStatementRepo.EXPECT().GetPaidTransactionsByUserID(gomock.Any(), userID, gomock.Any(), gomock.Any()).Return([]model.Transaction{transaction}, nil)
+		mockTransactionRepo.EXPECT().GetPostByID(gomock.Any(), postID).Return(nil, status.Error(codes.NotFound, "post not found"))
+		mockTaxStatementRepo.EXPECT().
+			GetInstitutionByID(gomock.Any(), institutionID).
+			Return(&model.Institution{InstitutionID: institutionID, Name: "Yayasan Nusantara"}, nil)
+		mockTransactionRepo.EXPECT().GetUserByID(gomock.Any(), userID).Return(&model.User{UserID: userID, Name: "Budi Santoso"}, nil)
+
+		statement, err := taxStatementUsecase.BuildTaxStatement(context.Background(), userID, 2024)
+
+		assert.NoError(t, err)
+		assert.Len(t, statement.Institutions, 1)
+		assert.Equal(t, "Yayasan Nusantara", statement.Institutions[0].Institution.Name)
+		assert.Equal(t, "Beasiswa", statement.Institutions[0].Donations[0].PostTitle)
+	})
+
+	t.Run("failed - post service unavailable does not fall back to stale data", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTaxStatementRepo := mocks.NewMockITaxStatementRepository(ctrl)
+		mockTransactionRepo := mocks.NewMockITransactionRepository(ctrl)
+		taxStatementUsecase := usecase.NewTaxStatementUsecase(mockTaxStatementRepo, mockTransactionRepo)
+
+		userID, postID := uuid.New().String(), uuid.New()
+		transaction := newYearTransaction(userID, postID.String(), model.IDR(100000), time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC))
+		transaction.PostTitle = "Beasiswa"
+		transaction.InstitutionID = uuid.New().String()
+
+		mockTaxStatementRepo.EXPECT().GetPaidTransactionsByUserID(gomock.Any(), userID, gomock.Any(), gomock.Any()).Return([]model.Transaction{transaction}, nil)
+		mockTransactionRepo.EXPECT().GetPostByID(gomock.Any(), postID).Return(nil, status.Error(codes.Unavailable, "connection refused"))
+
+		statement, err := taxStatementUsecase.BuildTaxStatement(context.Background(), userID, 2024)
+
+		assert.Nil(t, statement)
+		assert.ErrorContains(t, err, "failed to get post")
+	})
+
+	t.Run("failed - donor profile unavailable", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTaxStatementRepo := mocks.NewMockITaxStatementRepository(ctrl)
+		mockTransactionRepo := mocks.NewMockITransactionRepository(ctrl)
+		taxStatementUsecase := usecase.NewTaxStatementUsecase(mockTaxStatementRepo, mockTransactionRepo)
+
+		userID := uuid.New().String()
+		post := &model.Post{PostID: uuid.New(), InstitutionID: uuid.New(), Title: "Beasiswa"}
+		transaction := newYearTransaction(userID, post.PostID.String(), model.IDR(100000), time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC))
+
+		mockTaxStatementRepo.EXPECT().GetPaidTransactionsByUserID(gomock.Any(), userID, gomock.Any(), gomock.Any()).Return([]model.Transaction{transaction}, nil)
+		mockTransactionRepo.EXPECT().GetPostByID(gomock.Any(), post.PostID).Return(post, nil)
+		mockTaxStatementRepo.EXPECT().
+			GetInstitutionByID(gomock.Any(), post.InstitutionID).
+			Return(&model.Institution{InstitutionID: post.InstitutionID, Name: "Yayasan Nusantara"}, nil)
+		mockTransactionRepo.EXPECT().GetUserByID(gomock.Any(), userID).Return(nil, status.Error(codes.Unavailable, "connection refused"))
+
+		statement, err := taxStatementUsecase.BuildTaxStatement(context.Background(), userID, 2024)
+
+		assert.Nil(t, statement)
+		assert.ErrorContains(t, err, "failed to get donor")
+	})
+
+	t.Run("failed - future year", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		taxStatementUsecase := usecase.NewTaxStatementUsecase(mocks.NewMockITaxStatementRepository(ctrl), mocks.NewMockITransactionRepository(ctrl))
+
+		statement, err := taxStatementUsecase.BuildTaxStatement(context.Background(), uuid.New().String(), time.Now().Year()+1)
+
+		assert.Nil(t, statement)
+		assert.ErrorIs(t, err, usecase.ErrInvalidTaxYear)
+	})
+
+	t.Run("failed - no paid donations in the year", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTaxStatementRepo := mocks.NewMockITaxStatementRepository(ctrl)
+		taxStatementUsecase := usecase.NewTaxStatementUsecase(mockTaxStatementRepo, mocks.NewMockITransactionRepository(ctrl))
+
+		mockTaxStatementRepo.EXPECT().GetPaidTransactionsByUserID(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil)
+
+		statement, err := taxStatementUsecase.BuildTaxStatement(context.Background(), uuid.New().String(), 2024)
+
+		assert.Nil(t, statement)
+		assert.ErrorIs(t, err, usecase.ErrNoDonationsInTaxYear)
+	})
+}
+
+func TestRenderTaxStatement(t *testing.T) {
+	taxStatementUsecase := usecase.NewTaxStatementUsecase(nil, nil)
+	statement := &model.TaxStatement{Year: 2024, Totals: []model.Money{model.IDR(50000)}}
+
+	t.Run("success - csv", func(t *testing.T) {
+		file, err := taxStatementUsecase.RenderTaxStatement(statement, model.TaxStatementFormatCSV)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "tax-statement-2024.csv", file.Filename)
+		assert.Equal(t, "text/csv", file.ContentType)
+	})
+
+	t.Run("failed - unsupported format", func(t *testing.T) {
+		file, err := taxStatementUsecase.RenderTaxStatement(statement, "xlsx")
+
+		assert.Nil(t, file)
+		assert.ErrorIs(t, err, usecase.ErrInvalidTaxStatementFormat)
+	})
+}
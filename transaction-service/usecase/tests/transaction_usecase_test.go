@@ -0,0 +1,1642 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"transaction-service/mocks"
+	"transaction-service/model"
+	"transaction-service/usecase"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+)
+
+func newPendingTransaction() *model.Transaction {
+	return &model.Transaction{
+		TransactionID: primitive.NewObjectID(),
+		UserID:        uuid.New().String(),
+		PostID:        uuid.New().String(),
+		UserEmail:     "donor@email.com",
+		PaymentID:     "invoice-id",
+		PaymentStatus: model.PaymentStatusPending,
+		Amount:        model.IDR(50000),
+		AccountNumber: "1234567890",
+		AccountName:   "Donor",
+	}
+}
+
+func TestSettleTransaction(t *testing.T) {
+	t.Run("success - settle pending transaction", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransactionRepo := mocks.NewMockITransactionRepository(ctrl)
+		transactionUsecase := usecase.NewTransactionUsecase(mockTransactionRepo, usecase.DonationLimits{})
+
+		transaction := newPendingTransaction()
+		transaction.DonorNameVisibility = model.DonorNameDisplay
+		transaction.DonorName = "Hamba Allah"
+		transaction.Message = "Semoga lekas pulih"
+
+		mockTransactionRepo.EXPECT().
+			UpdateTransactionStatusWithEvent(gomock.Any(), transaction, gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, transaction *model.Transaction, transition model.StatusTransition, event model.OutboxEvent) (bool, error) {
+				assert.Equal(t, model.PaymentStatusPending, transition.From)
+				assert.Equal(t, model.PaymentStatusPaid, transition.To)
+				assert.Equal(t, model.TransitionSourceWebhook, transition.Source)
+				assert.Equal(t, model.EventTypeDonationSettled, event.Type)
+
+				var settled model.DonationSettledEvent
+				assert.NoError(t, json.Unmarshal(event.Payload, &settled))
+				assert.Equal(t, event.EventID, settled.EventID)
+				assert.Equal(t, transaction.TransactionID.Hex(), settled.TransactionID)
+				assert.Equal(t, transaction.PostID, settled.PostID)
+				assert.Equal(t, "Hamba Allah", settled.UserName)
+				assert.Equal(t, "Semoga lekas pulih", settled.Message)
+				assert.Equal(t, transaction.Amount, settled.Amount)
+				assert.Nil(t, settled.OriginalAmount)
+				return true, nil
+			})
+
+		ctx := context.Background()
+		result, err := transactionUsecase.SettleTransaction(ctx, transaction, model.TransitionSourceWebhook)
+
+		assert.NoError(t, err)
+		assert.NotNil(t, result)
+		assert.Equal(t, model.PaymentStatusPaid, result.PaymentStatus)
+		assert.False(t, result.PaidAt.IsZero())
+		assert.Equal(t, model.ReceiptStatusPending, result.ReceiptStatus)
+		assert.Len(t, result.StatusHistory, 1)
+		assert.Len(t, result.Outbox, 1)
+	})
+
+	t.Run("success - already paid transaction is not credited again", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransactionRepo := mocks.NewMockITransactionRepository(ctrl)
+		transactionUsecase := usecase.NewTransactionUsecase(mockTransactionRepo, usecase.DonationLimits{})
+
+		transaction := newPendingTransaction()
+		transaction.PaymentStatus = model.PaymentStatusPaid
+
+		ctx := context.Background()
+		result, err := transactionUsecase.SettleTransaction(ctx, transaction, model.TransitionSourceRedirect)
+
+		assert.NoError(t, err)
+		assert.Equal(t, model.PaymentStatusPaid, result.PaymentStatus)
+	})
+
+	t.Run("success - repeated redirect and callback record one settled event", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransactionRepo := mocks.NewMockITransactionRepository(ctrl)
+		transactionUsecase := usecase.NewTransactionUsecase(mockTransactionRepo, usecase.DonationLimits{})
+
+		transaction := newPendingTransaction()
+
+		var outbox []model.OutboxEvent
+		paymentStatus := model.PaymentStatusPending
+
+		mockTransactionRepo.EXPECT().
+			UpdateTransactionStatusWithEvent(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, transaction *model.Transaction, transition model.StatusTransition, event model.OutboxEvent) (bool, error) {
+				if paymentStatus != transition.From {
+					return false, nil
+				}
+				paymentStatus = transition.To
+				outbox = append(outbox, event)
+				return true, nil
+			}).
+			AnyTimes()
+
+		ctx := context.Background()
+
+		// The redirect and the webhook both load the transaction while it is
+		// still pending, then each is replayed after it has been settled.
+		sources := []model.TransitionSource{model.TransitionSourceRedirect, model.TransitionSourceWebhook}
+		for _, source := range sources {
+			loaded := *transaction
+			_, err := transactionUsecase.SettleTransaction(ctx, &loaded, source)
+			assert.NoError(t, err)
+		}
+		for i := 0; i < 5; i++ {
+			loaded := *transaction
+			loaded.PaymentStatus = paymentStatus
+			_, err := transactionUsecase.SettleTransaction(ctx, &loaded, sources[i%2])
+			assert.NoError(t, err)
+		}
+
+		assert.Len(t, outbox, 1)
+		assert.Equal(t, model.PaymentStatusPaid, paymentStatus)
+	})
+
+	t.Run("failed - invalid post ID", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransactionRepo := mocks.NewMockITransactionRepository(ctrl)
+		transactionUsecase := usecase.NewTransactionUsecase(mockTransactionRepo, usecase.DonationLimits{})
+
+		transaction := newPendingTransaction()
+		transaction.PostID = "invalid"
+
+		ctx := context.Background()
+		result, err := transactionUsecase.SettleTransaction(ctx, transaction, model.TransitionSourceWebhook)
+
+		assert.Error(t, err)
+		assert.Nil(t, result)
+	})
+
+	t.Run("failed - update error records no event", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransactionRepo := mocks.NewMockITransactionRepository(ctrl)
+		transactionUsecase := usecase.NewTransactionUsecase(mockTransactionRepo, usecase.DonationLimits{})
+
+		transaction := newPendingTransaction()
+
+		mockTransactionRepo.EXPECT().
+			UpdateTransactionStatusWithEvent(gomock.Any(), transaction, gomock.Any(), gomock.Any()).
+			Return(false, errors.New("database error"))
+
+		ctx := context.Background()
+		result, err := transactionUsecase.SettleTransaction(ctx, transaction, model.TransitionSourceWebhook)
+
+		assert.Error(t, err)
+		assert.Nil(t, result)
+		assert.Equal(t, model.PaymentStatusPending, transaction.PaymentStatus)
+		assert.Empty(t, transaction.Outbox)
+	})
+
+	t.Run("failed - failed transaction cannot be settled", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransactionRepo := mocks.NewMockITransactionRepository(ctrl)
+		transactionUsecase := usecase.NewTransactionUsecase(mockTransactionRepo, usecase.DonationLimits{})
+
+		transaction := newPendingTransaction()
+		transaction.PaymentStatus = model.PaymentStatusFailed
+
+		ctx := context.Background()
+		result, err := transactionUsecase.SettleTransaction(ctx, transaction, model.TransitionSourceWebhook)
+
+		assert.ErrorIs(t, err, usecase.ErrInvalidStatusTransition)
+		assert.Nil(t, result)
+	})
+}
+
+func TestTransitionTransaction(t *testing.T) {
+	t.Run("success - pending to expired", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransactionRepo := mocks.NewMockITransactionRepository(ctrl)
+		transactionUsecase := usecase.NewTransactionUsecase(mockTransactionRepo, usecase.DonationLimits{})
+
+		transaction := newPendingTransaction()
+
+		mockTransactionRepo.EXPECT().
+			UpdateTransactionStatus(gomock.Any(), transaction, gomock.Any()).
+			DoAndReturn(func(ctx context.Context, transaction *model.Transaction, transition model.StatusTransition) (bool, error) {
+				assert.Equal(t, model.PaymentStatusPending, transition.From)
+				assert.Equal(t, model.PaymentStatusExpired, transition.To)
+				assert.Equal(t, model.TransitionSourceReconciler, transition.Source)
+				assert.False(t, transition.At.IsZero())
+				return true, nil
+			})
+
+		ctx := context.Background()
+		result, err := transactionUsecase.TransitionTransaction(ctx, transaction, model.PaymentStatusExpired, model.TransitionSourceReconciler)
+
+		assert.NoError(t, err)
+		assert.Equal(t, model.PaymentStatusExpired, result.PaymentStatus)
+		assert.Len(t, result.StatusHistory, 1)
+	})
+
+	t.Run("failed - illegal transition is rejected", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransactionRepo := mocks.NewMockITransactionRepository(ctrl)
+		transactionUsecase := usecase.NewTransactionUsecase(mockTransactionRepo, usecase.DonationLimits{})
+
+		transaction := newPendingTransaction()
+		transaction.PaymentStatus = model.PaymentStatusPaid
+
+		ctx := context.Background()
+		result, err := transactionUsecase.TransitionTransaction(ctx, transaction, model.PaymentStatusExpired, model.TransitionSourceWebhook)
+
+		assert.ErrorIs(t, err, usecase.ErrInvalidStatusTransition)
+		assert.Nil(t, result)
+		assert.Equal(t, model.PaymentStatusPaid, transaction.PaymentStatus)
+	})
+
+	t.Run("failed - status changed concurrently", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransactionRepo := mocks.NewMockITransactionRepository(ctrl)
+		transactionUsecase := usecase.NewTransactionUsecase(mockTransactionRepo, usecase.DonationLimits{})
+
+		transaction := newPendingTransaction()
+
+		mockTransactionRepo.EXPECT().
+			UpdateTransactionStatus(gomock.Any(), transaction, gomock.Any()).
+			Return(false, nil)
+
+		ctx := context.Background()
+		result, err := transactionUsecase.TransitionTransaction(ctx, transaction, model.PaymentStatusFailed, model.TransitionSourceWebhook)
+
+		assert.ErrorIs(t, err, usecase.ErrInvalidStatusTransition)
+		assert.Nil(t, result)
+	})
+}
+
+func TestGetStalePendingTransactions(t *testing.T) {
+	t.Run("success - looks up transactions created before the cutoff", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransactionRepo := mocks.NewMockITransactionRepository(ctrl)
+		transactionUsecase := usecase.NewTransactionUsecase(mockTransactionRepo, usecase.DonationLimits{})
+
+		transactions := []model.Transaction{*newPendingTransaction()}
+
+		mockTransactionRepo.EXPECT().
+			GetPendingTransactionsBefore(gomock.Any(), gomock.Any(), int64(25)).
+			DoAndReturn(func(ctx context.Context, createdBefore time.Time, limit int64) ([]model.Transaction, error) {
+				assert.WithinDuration(t, time.Now().Add(-15*time.Minute), createdBefore, time.Second)
+				return transactions, nil
+			})
+
+		ctx := context.Background()
+		result, err := transactionUsecase.GetStalePendingTransactions(ctx, 15*time.Minute, 25)
+
+		assert.NoError(t, err)
+		assert.Equal(t, transactions, result)
+	})
+
+	t.Run("failed - limit must be positive", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransactionRepo := mocks.NewMockITransactionRepository(ctrl)
+		transactionUsecase := usecase.NewTransactionUsecase(mockTransactionRepo, usecase.DonationLimits{})
+
+		ctx := context.Background()
+		result, err := transactionUsecase.GetStalePendingTransactions(ctx, 15*time.Minute, 0)
+
+		assert.Error(t, err)
+		assert.Nil(t, result)
+	})
+}
+
+func TestGetUserTransactionByID(t *testing.T) {
+	t.Run("success - owner reads transaction", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransactionRepo := mocks.NewMockITransactionRepository(ctrl)
+		transactionUsecase := usecase.NewTransactionUsecase(mockTransactionRepo, usecase.DonationLimits{})
+
+		transaction := newPendingTransaction()
+
+		mockTransactionRepo.EXPECT().
+			GetTransactionByID(gomock.Any(), transaction.TransactionID).
+			Return(transaction, nil)
+
+		ctx := context.Background()
+		result, err := transactionUsecase.GetUserTransactionByID(ctx, "donor@email.com", transaction.TransactionID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, transaction, result)
+	})
+
+	t.Run("failed - other user's transaction is not found", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransactionRepo := mocks.NewMockITransactionRepository(ctrl)
+		transactionUsecase := usecase.NewTransactionUsecase(mockTransactionRepo, usecase.DonationLimits{})
+
+		transaction := newPendingTransaction()
+
+		mockTransactionRepo.EXPECT().
+			GetTransactionByID(gomock.Any(), transaction.TransactionID).
+			Return(transaction, nil)
+
+		ctx := context.Background()
+		result, err := transactionUsecase.GetUserTransactionByID(ctx, "other@email.com", transaction.TransactionID)
+
+		assert.ErrorIs(t, err, usecase.ErrTransactionNotFound)
+		assert.Nil(t, result)
+	})
+
+	t.Run("failed - missing transaction", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransactionRepo := mocks.NewMockITransactionRepository(ctrl)
+		transactionUsecase := usecase.NewTransactionUsecase(mockTransactionRepo, usecase.DonationLimits{})
+
+		mockTransactionRepo.EXPECT().
+			GetTransactionByID(gomock.Any(), gomock.Any()).
+			Return(nil, mongo.ErrNoDocuments)
+
+		ctx := context.Background()
+		result, err := transactionUsecase.GetUserTransactionByID(ctx, "donor@email.com", primitive.NewObjectID())
+
+		assert.ErrorIs(t, err, usecase.ErrTransactionNotFound)
+		assert.Nil(t, result)
+	})
+}
+
+func TestGetUserTransactions(t *testing.T) {
+	newTransactions := func(n int) []model.Transaction {
+		transactions := make([]model.Transaction, n)
+		for i := range transactions {
+			transactions[i] = *newPendingTransaction()
+		}
+		return transactions
+	}
+
+	t.Run("success - full page returns next cursor", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransactionRepo := mocks.NewMockITransactionRepository(ctrl)
+		transactionUsecase := usecase.NewTransactionUsecase(mockTransactionRepo, usecase.DonationLimits{})
+
+		transactions := newTransactions(3)
+		dateFrom := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+		mockTransactionRepo.EXPECT().
+			GetTransactions(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, filter model.TransactionFilter) ([]model.Transaction, error) {
+				assert.Equal(t, "donor@email.com", filter.UserEmail)
+				assert.Empty(t, filter.PostID)
+				assert.Equal(t, model.PaymentStatusPaid, filter.Status)
+				assert.Equal(t, dateFrom, filter.DateFrom)
+				assert.Equal(t, 3, filter.Limit)
+				return transactions, nil
+			})
+
+		ctx := context.Background()
+		page, err := transactionUsecase.GetUserTransactions(ctx, "donor@email.com", model.TransactionFilter{
+			PostID:   uuid.New().String(),
+			Status:   model.PaymentStatusPaid,
+			DateFrom: dateFrom,
+			Limit:    2,
+		})
+
+		assert.NoError(t, err)
+		assert.Len(t, page.Transactions, 2)
+		assert.Equal(t, transactions[1].TransactionID.Hex(), page.NextCursor)
+	})
+
+	t.Run("success - last page has no cursor and default limit", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransactionRepo := mocks.NewMockITransactionRepository(ctrl)
+		transactionUsecase := usecase.NewTransactionUsecase(mockTransactionRepo, usecase.DonationLimits{})
+
+		transactions := newTransactions(2)
+
+		mockTransactionRepo.EXPECT().
+			GetTransactions(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, filter model.TransactionFilter) ([]model.Transaction, error) {
+				assert.Equal(t, usecase.DefaultTransactionPageSize+1, filter.Limit)
+				return transactions, nil
+			})
+
+		ctx := context.Background()
+		page, err := transactionUsecase.GetUserTransactions(ctx, "donor@email.com", model.TransactionFilter{})
+
+		assert.NoError(t, err)
+		assert.Len(t, page.Transactions, 2)
+		assert.Empty(t, page.NextCursor)
+	})
+
+	t.Run("failed - invalid filter", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransactionRepo := mocks.NewMockITransactionRepository(ctrl)
+		transactionUsecase := usecase.NewTransactionUsecase(mockTransactionRepo, usecase.DonationLimits{})
+
+		now := time.Now()
+
+		ctx := context.Background()
+		page, err := transactionUsecase.GetUserTransactions(ctx, "donor@email.com", model.TransactionFilter{
+			Status:   "UNKNOWN",
+			DateFrom: now,
+			DateTo:   now.Add(-time.Hour),
+			Limit:    -1,
+		})
+
+		assert.EqualError(t, err, "Unknown status UNKNOWN, Date from must be before date to, Limit must not be negative")
+		assert.Nil(t, page)
+	})
+}
+
+func TestGetPostTransactions(t *testing.T) {
+	t.Run("success - owning institution lists post transactions", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransactionRepo := mocks.NewMockITransactionRepository(ctrl)
+		transactionUsecase := usecase.NewTransactionUsecase(mockTransactionRepo, usecase.DonationLimits{})
+
+		post := &model.Post{PostID: uuid.New(), InstitutionID: uuid.New()}
+		transactions := []model.Transaction{*newPendingTransaction()}
+
+		mockTransactionRepo.EXPECT().
+			GetPostByID(gomock.Any(), post.PostID).
+			Return(post, nil)
+
+		mockTransactionRepo.EXPECT().
+			GetTransactions(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, filter model.TransactionFilter) ([]model.Transaction, error) {
+				assert.Equal(t, post.PostID.String(), filter.PostID)
+				assert.Empty(t, filter.UserEmail)
+				return transactions, nil
+			})
+
+		ctx := context.Background()
+		page, err := transactionUsecase.GetPostTransactions(ctx, post.InstitutionID, model.TransactionFilter{
+			PostID:    post.PostID.String(),
+			UserEmail: "donor@email.com",
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, transactions, page.Transactions)
+	})
+
+	t.Run("failed - post of another institution", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransactionRepo := mocks.NewMockITransactionRepository(ctrl)
+		transactionUsecase := usecase.NewTransactionUsecase(mockTransactionRepo, usecase.DonationLimits{})
+
+		post := &model.Post{PostID: uuid.New(), InstitutionID: uuid.New()}
+
+		mockTransactionRepo.EXPECT().
+			GetPostByID(gomock.Any(), post.PostID).
+			Return(post, nil)
+
+		ctx := context.Background()
+		page, err := transactionUsecase.GetPostTransactions(ctx, uuid.New(), model.TransactionFilter{PostID: post.PostID.String()})
+
+		assert.ErrorIs(t, err, usecase.ErrPostAccessDenied)
+		assert.Nil(t, page)
+	})
+
+	t.Run("failed - post not found", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransactionRepo := mocks.NewMockITransactionRepository(ctrl)
+		transactionUsecase := usecase.NewTransactionUsecase(mockTransactionRepo, usecase.DonationLimits{})
+
+		mockTransactionRepo.EXPECT().
+			GetPostByID(gomock.Any(), gomock.Any()).
+			Return(nil, status.Error(codes.NotFound, "post not found"))
+
+		ctx := context.Background()
+		page, err := transactionUsecase.GetPostTransactions(ctx, uuid.New(), model.TransactionFilter{PostID: uuid.New().String()})
+
+		assert.ErrorIs(t, err, usecase.ErrPostNotFound)
+		assert.Nil(t, page)
+	})
+
+	t.Run("failed - invalid post ID", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransactionRepo := mocks.NewMockITransactionRepository(ctrl)
+		transactionUsecase := usecase.NewTransactionUsecase(mockTransactionRepo, usecase.DonationLimits{})
+
+		ctx := context.Background()
+		page, err := transactionUsecase.GetPostTransactions(ctx, uuid.New(), model.TransactionFilter{PostID: "invalid"})
+
+		assert.Error(t, err)
+		assert.Nil(t, page)
+	})
+}
+
+func newPaidTransaction() *model.Transaction {
+	transaction := newPendingTransaction()
+	transaction.PaymentStatus = model.PaymentStatusPaid
+	return transaction
+}
+
+func TestResolveRefundAmount(t *testing.T) {
+	transactionUsecase := usecase.NewTransactionUsecase(nil, usecase.DonationLimits{})
+
+	t.Run("success - zero refunds the remainder", func(t *testing.T) {
+		transaction := newPaidTransaction()
+		transaction.RefundedAmount = model.IDR(20000)
+
+		amount, err := transactionUsecase.ResolveRefundAmount(transaction, model.Money{})
+
+		assert.NoError(t, err)
+		assert.Equal(t, model.IDR(30000), amount)
+	})
+
+	t.Run("failed - more than refundable", func(t *testing.T) {
+		transaction := newPaidTransaction()
+		transaction.RefundedAmount = model.IDR(20000)
+
+		_, err := transactionUsecase.ResolveRefundAmount(transaction, model.IDR(40000))
+
+		assert.ErrorIs(t, err, usecase.ErrRefundNotAllowed)
+	})
+
+	t.Run("failed - negative amount", func(t *testing.T) {
+		_, err := transactionUsecase.ResolveRefundAmount(newPaidTransaction(), model.IDR(-1))
+
+		assert.ErrorIs(t, err, usecase.ErrRefundNotAllowed)
+	})
+
+	t.Run("failed - transaction not paid", func(t *testing.T) {
+		_, err := transactionUsecase.ResolveRefundAmount(newPendingTransaction(), model.IDR(1000))
+
+		assert.ErrorIs(t, err, usecase.ErrRefundNotAllowed)
+	})
+}
+
+// withPendingRefund adds refund to the transaction as StartRefund does.
+func withPendingRefund(transaction *model.Transaction, refund model.Refund) model.Refund {
+	refund.ReferenceID = fmt.Sprintf("%s-refund-%d", transaction.TransactionID.Hex(), len(transaction.Refunds)+1)
+	refund.Status = model.RefundStatusPending
+	transaction.Refunds = append(transaction.Refunds, refund)
+
+	return refund
+}
+
+func TestStartRefund(t *testing.T) {
+	t.Run("success - records the refund as pending first", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransactionRepo := mocks.NewMockITransactionRepository(ctrl)
+		transactionUsecase := usecase.NewTransactionUsecase(mockTransactionRepo, usecase.DonationLimits{})
+
+		transaction := newPaidTransaction()
+		transaction.TransactionID = primitive.NewObjectID()
+
+		mockTransactionRepo.EXPECT().
+			AddPendingRefund(gomock.Any(), transaction, gomock.Any()).
+			DoAndReturn(func(ctx context.Context, transaction *model.Transaction, refund model.Refund) (bool, error) {
+				assert.Equal(t, model.RefundStatusPending, refund.Status)
+				assert.Empty(t, refund.RefundID)
+				return true, nil
+			})
+
+		ctx := context.Background()
+		refund, err := transactionUsecase.StartRefund(ctx, transaction, model.Refund{Amount: model.IDR(20000), Source: model.TransitionSourceInstitution})
+
+		assert.NoError(t, err)
+		assert.Equal(t, transaction.TransactionID.Hex()+"-refund-1", refund.ReferenceID)
+		assert.Equal(t, &refund, transaction.PendingRefund())
+		assert.Zero(t, transaction.RefundedAmount.Amount)
+	})
+
+	t.Run("success - failed refund no longer blocks another", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransactionRepo := mocks.NewMockITransactionRepository(ctrl)
+		transactionUsecase := usecase.NewTransactionUsecase(mockTransactionRepo, usecase.DonationLimits{})
+
+		transaction := newPaidTransaction()
+		failed := withPendingRefund(transaction, model.Refund{Amount: model.IDR(20000)})
+
+		mockTransactionRepo.EXPECT().FailRefund(gomock.Any(), transaction.TransactionID, failed.ReferenceID).Return(nil)
+		mockTransactionRepo.EXPECT().AddPendingRefund(gomock.Any(), transaction, gomock.Any()).Return(true, nil)
+
+		ctx := context.Background()
+		assert.NoError(t, transactionUsecase.FailRefund(ctx, transaction, failed))
+		assert.Equal(t, model.RefundStatusFailed, transaction.Refunds[0].Status)
+
+		refund, err := transactionUsecase.StartRefund(ctx, transaction, model.Refund{Amount: model.IDR(20000)})
+
+		assert.NoError(t, err)
+		assert.Equal(t, transaction.TransactionID.Hex()+"-refund-2", refund.ReferenceID)
+	})
+
+	t.Run("failed - another refund is pending", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		transactionUsecase := usecase.NewTransactionUsecase(mocks.NewMockITransactionRepository(ctrl), usecase.DonationLimits{})
+
+		transaction := newPaidTransaction()
+		withPendingRefund(transaction, model.Refund{Amount: model.IDR(20000)})
+
+		ctx := context.Background()
+		_, err := transactionUsecase.StartRefund(ctx, transaction, model.Refund{Amount: model.IDR(20000)})
+
+		assert.ErrorIs(t, err, usecase.ErrRefundNotAllowed)
+	})
+
+	t.Run("failed - refunded concurrently", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransactionRepo := mocks.NewMockITransactionRepository(ctrl)
+		transactionUsecase := usecase.NewTransactionUsecase(mockTransactionRepo, usecase.DonationLimits{})
+
+		transaction := newPaidTransaction()
+
+		mockTransactionRepo.EXPECT().AddPendingRefund(gomock.Any(), transaction, gomock.Any()).Return(false, nil)
+
+		ctx := context.Background()
+		_, err := transactionUsecase.StartRefund(ctx, transaction, model.Refund{Amount: model.IDR(20000)})
+
+		assert.ErrorIs(t, err, usecase.ErrRefundNotAllowed)
+		assert.Empty(t, transaction.Refunds)
+	})
+}
+
+func TestRefundTransaction(t *testing.T) {
+	t.Run("success - partial refund keeps transaction paid", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransactionRepo := mocks.NewMockITransactionRepository(ctrl)
+		transactionUsecase := usecase.NewTransactionUsecase(mockTransactionRepo, usecase.DonationLimits{})
+
+		transaction := newPaidTransaction()
+		refund := withPendingRefund(transaction, model.Refund{Amount: model.IDR(20000), Source: model.TransitionSourceInstitution})
+		refund.RefundID = "rfd-1"
+
+		mockTransactionRepo.EXPECT().
+			RecordRefund(gomock.Any(), transaction, gomock.Any(), gomock.Nil(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, transaction *model.Transaction, refund model.Refund, transition *model.StatusTransition, event model.OutboxEvent) (bool, error) {
+				assert.Equal(t, model.EventTypeDonationRefunded, event.Type)
+
+				var refunded model.DonationRefundedEvent
+				assert.NoError(t, json.Unmarshal(event.Payload, &refunded))
+				assert.Equal(t, event.EventID, refunded.EventID)
+				assert.Equal(t, transaction.TransactionID.Hex(), refunded.TransactionID)
+				assert.Equal(t, transaction.PostID, refunded.PostID)
+				assert.Equal(t, "rfd-1", refunded.RefundID)
+				assert.Equal(t, model.IDR(20000), refunded.Amount)
+				return true, nil
+			})
+
+		ctx := context.Background()
+		result, err := transactionUsecase.RefundTransaction(ctx, transaction, refund)
+
+		assert.NoError(t, err)
+		assert.Equal(t, model.PaymentStatusPaid, result.PaymentStatus)
+		assert.Equal(t, model.IDR(20000), result.RefundedAmount)
+		assert.Len(t, result.Refunds, 1)
+		assert.Equal(t, model.RefundStatusSucceeded, result.Refunds[0].Status)
+		assert.Equal(t, "rfd-1", result.Refunds[0].RefundID)
+		assert.Len(t, result.Outbox, 1)
+	})
+
+	t.Run("success - refunding the remainder moves to refunded", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransactionRepo := mocks.NewMockITransactionRepository(ctrl)
+		transactionUsecase := usecase.NewTransactionUsecase(mockTransactionRepo, usecase.DonationLimits{})
+
+		transaction := newPaidTransaction()
+		transaction.RefundedAmount = model.IDR(20000)
+		refund := withPendingRefund(transaction, model.Refund{Amount: model.IDR(30000), Source: model.TransitionSourceInstitution})
+		refund.RefundID = "rfd-2"
+
+		mockTransactionRepo.EXPECT().
+			RecordRefund(gomock.Any(), transaction, gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, transaction *model.Transaction, refund model.Refund, transition *model.StatusTransition, event model.OutboxEvent) (bool, error) {
+				assert.Equal(t, model.PaymentStatusPaid, transition.From)
+				assert.Equal(t, model.PaymentStatusRefunded, transition.To)
+				assert.Equal(t, model.TransitionSourceInstitution, transition.Source)
+
+				var refunded model.DonationRefundedEvent
+				assert.NoError(t, json.Unmarshal(event.Payload, &refunded))
+				assert.Equal(t, model.IDR(30000), refunded.Amount)
+				return true, nil
+			})
+
+		ctx := context.Background()
+		result, err := transactionUsecase.RefundTransaction(ctx, transaction, refund)
+
+		assert.NoError(t, err)
+		assert.Equal(t, model.PaymentStatusRefunded, result.PaymentStatus)
+		assert.Equal(t, transaction.Amount, result.RefundedAmount)
+		assert.Len(t, result.StatusHistory, 1)
+	})
+
+	t.Run("failed - refunded concurrently", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransactionRepo := mocks.NewMockITransactionRepository(ctrl)
+		transactionUsecase := usecase.NewTransactionUsecase(mockTransactionRepo, usecase.DonationLimits{})
+
+		transaction := newPaidTransaction()
+		refund := withPendingRefund(transaction, model.Refund{Amount: model.IDR(50000)})
+
+		mockTransactionRepo.EXPECT().
+			RecordRefund(gomock.Any(), transaction, gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(false, nil)
+
+		ctx := context.Background()
+		result, err := transactionUsecase.RefundTransaction(ctx, transaction, refund)
+
+		assert.ErrorIs(t, err, usecase.ErrRefundNotAllowed)
+		assert.Nil(t, result)
+		assert.Empty(t, transaction.Outbox)
+	})
+
+	t.Run("failed - refund not started", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		transactionUsecase := usecase.NewTransactionUsecase(mocks.NewMockITransactionRepository(ctrl), usecase.DonationLimits{})
+
+		ctx := context.Background()
+		result, err := transactionUsecase.RefundTransaction(ctx, newPaidTransaction(), model.Refund{RefundID: "rfd-1", Amount: model.IDR(50000)})
+
+		assert.ErrorIs(t, err, usecase.ErrRefundNotAllowed)
+		assert.Nil(t, result)
+	})
+
+	t.Run("failed - zero amount", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransactionRepo := mocks.NewMockITransactionRepository(ctrl)
+		transactionUsecase := usecase.NewTransactionUsecase(mockTransactionRepo, usecase.DonationLimits{})
+
+		ctx := context.Background()
+		result, err := transactionUsecase.RefundTransaction(ctx, newPaidTransaction(), model.Refund{})
+
+		assert.ErrorIs(t, err, usecase.ErrRefundNotAllowed)
+		assert.Nil(t, result)
+	})
+
+	t.Run("failed - record refund error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransactionRepo := mocks.NewMockITransactionRepository(ctrl)
+		transactionUsecase := usecase.NewTransactionUsecase(mockTransactionRepo, usecase.DonationLimits{})
+
+		transaction := newPaidTransaction()
+		refund := withPendingRefund(transaction, model.Refund{Amount: model.IDR(50000)})
+
+		mockTransactionRepo.EXPECT().
+			RecordRefund(gomock.Any(), transaction, gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(false, errors.New("database error"))
+
+		ctx := context.Background()
+		result, err := transactionUsecase.RefundTransaction(ctx, transaction, refund)
+
+		assert.Error(t, err)
+		assert.Nil(t, result)
+	})
+}
+
+func TestRetryTransaction(t *testing.T) {
+	t.Run("success - expired transaction gets a fresh invoice", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransactionRepo := mocks.NewMockITransactionRepository(ctrl)
+		transactionUsecase := usecase.NewTransactionUsecase(mockTransactionRepo, usecase.DonationLimits{})
+
+		transaction := newPendingTransaction()
+		transaction.PaymentStatus = model.PaymentStatusExpired
+		expiresAt := time.Now().Add(24 * time.Hour)
+
+		mockTransactionRepo.EXPECT().
+			UpdateTransactionStatus(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, retried *model.Transaction, transition model.StatusTransition) (bool, error) {
+				assert.Equal(t, transaction.TransactionID, retried.TransactionID)
+				assert.Equal(t, "new-invoice-id", retried.PaymentID)
+				assert.Equal(t, []string{"invoice-id"}, retried.PreviousPaymentIDs)
+				assert.Equal(t, model.PaymentStatusExpired, transition.From)
+				assert.Equal(t, model.PaymentStatusPending, transition.To)
+				return true, nil
+			})
+
+		ctx := context.Background()
+		result, err := transactionUsecase.RetryTransaction(ctx, transaction, "new-invoice-id", "https://pay/new", expiresAt)
+
+		assert.NoError(t, err)
+		assert.Equal(t, model.PaymentStatusPending, result.PaymentStatus)
+		assert.Equal(t, "https://pay/new", result.PaymentURL)
+		assert.Equal(t, expiresAt, result.ExpiresAt)
+		assert.Equal(t, model.PaymentStatusExpired, transaction.PaymentStatus)
+		assert.Empty(t, transaction.PreviousPaymentIDs)
+	})
+
+	t.Run("success - failed transaction without invoice", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransactionRepo := mocks.NewMockITransactionRepository(ctrl)
+		transactionUsecase := usecase.NewTransactionUsecase(mockTransactionRepo, usecase.DonationLimits{})
+
+		transaction := newPendingTransaction()
+		transaction.PaymentID = "pending"
+		transaction.PaymentStatus = model.PaymentStatusFailed
+
+		mockTransactionRepo.EXPECT().
+			UpdateTransactionStatus(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(true, nil)
+
+		ctx := context.Background()
+		result, err := transactionUsecase.RetryTransaction(ctx, transaction, "new-invoice-id", "https://pay/new", time.Now())
+
+		assert.NoError(t, err)
+		assert.Equal(t, model.PaymentStatusPending, result.PaymentStatus)
+		assert.Empty(t, result.PreviousPaymentIDs)
+	})
+
+	t.Run("failed - paid transaction cannot be retried", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransactionRepo := mocks.NewMockITransactionRepository(ctrl)
+		transactionUsecase := usecase.NewTransactionUsecase(mockTransactionRepo, usecase.DonationLimits{})
+
+		transaction := newPendingTransaction()
+		transaction.PaymentStatus = model.PaymentStatusPaid
+
+		ctx := context.Background()
+		result, err := transactionUsecase.RetryTransaction(ctx, transaction, "new-invoice-id", "https://pay/new", time.Now())
+
+		assert.ErrorIs(t, err, usecase.ErrRetryNotAllowed)
+		assert.Nil(t, result)
+	})
+
+	t.Run("failed - concurrent retry", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransactionRepo := mocks.NewMockITransactionRepository(ctrl)
+		transactionUsecase := usecase.NewTransactionUsecase(mockTransactionRepo, usecase.DonationLimits{})
+
+		transaction := newPendingTransaction()
+		transaction.PaymentStatus = model.PaymentStatusExpired
+
+		mockTransactionRepo.EXPECT().
+			UpdateTransactionStatus(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(false, nil)
+
+		ctx := context.Background()
+		result, err := transactionUsecase.RetryTransaction(ctx, transaction, "new-invoice-id", "https://pay/new", time.Now())
+
+		assert.ErrorIs(t, err, usecase.ErrInvalidStatusTransition)
+		assert.Nil(t, result)
+	})
+}
+
+func TestCreateTransactionDonationLimits(t *testing.T) {
+	limits := usecase.DonationLimits{MinAmount: model.IDR(10000), MaxAmount: model.IDR(100000000)}
+
+	t.Run("success - within limits", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransactionRepo := mocks.NewMockITransactionRepository(ctrl)
+		transactionUsecase := usecase.NewTransactionUsecase(mockTransactionRepo, limits)
+
+		transaction := newPendingTransaction()
+		transaction.Amount = model.IDR(10000)
+
+		mockTransactionRepo.EXPECT().
+			CreateTransaction(gomock.Any(), transaction).
+			Return(transaction, nil)
+
+		ctx := context.Background()
+		result, err := transactionUsecase.CreateTransaction(ctx, transaction)
+
+		assert.NoError(t, err)
+		assert.Equal(t, model.PaymentStatusCreated, result.PaymentStatus)
+	})
+
+	t.Run("failed - below minimum", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		transactionUsecase := usecase.NewTransactionUsecase(mocks.NewMockITransactionRepository(ctrl), limits)
+
+		transaction := newPendingTransaction()
+		transaction.Amount = model.IDR(500)
+
+		ctx := context.Background()
+		result, err := transactionUsecase.CreateTransaction(ctx, transaction)
+
+		assert.Nil(t, result)
+		assert.EqualError(t, err, "Amount must be at least 10000 IDR")
+	})
+
+	t.Run("failed - above maximum", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		transactionUsecase := usecase.NewTransactionUsecase(mocks.NewMockITransactionRepository(ctrl), limits)
+
+		transaction := newPendingTransaction()
+		transaction.Amount = model.IDR(150000000)
+
+		ctx := context.Background()
+		result, err := transactionUsecase.CreateTransaction(ctx, transaction)
+
+		assert.Nil(t, result)
+		assert.EqualError(t, err, "Amount must be at most 100000000 IDR")
+	})
+
+	t.Run("failed - fractional rupiah", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		transactionUsecase := usecase.NewTransactionUsecase(mocks.NewMockITransactionRepository(ctrl), limits)
+
+		transaction := newPendingTransaction()
+		transaction.Amount = model.NewMoney(1500050, model.CurrencyIDR)
+
+		ctx := context.Background()
+		result, err := transactionUsecase.CreateTransaction(ctx, transaction)
+
+		assert.Nil(t, result)
+		assert.EqualError(t, err, "Amount must be a whole number of IDR")
+	})
+
+	t.Run("failed - unsupported currency", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		transactionUsecase := usecase.NewTransactionUsecase(mocks.NewMockITransactionRepository(ctrl), limits)
+
+		transaction := newPendingTransaction()
+		transaction.Amount = model.NewMoney(2500, "XYZ")
+
+		ctx := context.Background()
+		result, err := transactionUsecase.CreateTransaction(ctx, transaction)
+
+		assert.Nil(t, result)
+		assert.EqualError(t, err, "Currency is not supported")
+	})
+
+	t.Run("success - foreign donation within limits once converted", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransactionRepo := mocks.NewMockITransactionRepository(ctrl)
+		transactionUsecase := usecase.NewTransactionUsecase(mockTransactionRepo, limits)
+
+		transaction := newPendingTransaction()
+		transaction.Amount = model.NewMoney(250, "USD")
+		transaction.ConvertedAmount = model.IDR(38750)
+
+		mockTransactionRepo.EXPECT().
+			CreateTransaction(gomock.Any(), transaction).
+			Return(transaction, nil)
+
+		ctx := context.Background()
+		result, err := transactionUsecase.CreateTransaction(ctx, transaction)
+
+		assert.NoError(t, err)
+		assert.Equal(t, model.IDR(38750), result.ConvertedAmount)
+	})
+
+	t.Run("failed - foreign donation below minimum once converted", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		transactionUsecase := usecase.NewTransactionUsecase(mocks.NewMockITransactionRepository(ctrl), limits)
+
+		transaction := newPendingTransaction()
+		transaction.Amount = model.NewMoney(50, "USD")
+		transaction.ConvertedAmount = model.IDR(7750)
+
+		ctx := context.Background()
+		result, err := transactionUsecase.CreateTransaction(ctx, transaction)
+
+		assert.Nil(t, result)
+		assert.EqualError(t, err, "Amount must be at least 10000 IDR")
+	})
+
+	t.Run("success - same-currency donation is converted to itself", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransactionRepo := mocks.NewMockITransactionRepository(ctrl)
+		transactionUsecase := usecase.NewTransactionUsecase(mockTransactionRepo, limits)
+
+		transaction := newPendingTransaction()
+
+		mockTransactionRepo.EXPECT().
+			CreateTransaction(gomock.Any(), transaction).
+			Return(transaction, nil)
+
+		ctx := context.Background()
+		result, err := transactionUsecase.CreateTransaction(ctx, transaction)
+
+		assert.NoError(t, err)
+		assert.Equal(t, transaction.Amount, result.ConvertedAmount)
+	})
+}
+
+func TestCreateTransactionDonorName(t *testing.T) {
+	t.Run("success - anonymous by default", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransactionRepo := mocks.NewMockITransactionRepository(ctrl)
+		transactionUsecase := usecase.NewTransactionUsecase(mockTransactionRepo, usecase.DonationLimits{})
+
+		transaction := newPendingTransaction()
+
+		mockTransactionRepo.EXPECT().
+			CreateTransaction(gomock.Any(), transaction).
+			Return(transaction, nil)
+
+		ctx := context.Background()
+		result, err := transactionUsecase.CreateTransaction(ctx, transaction)
+
+		assert.NoError(t, err)
+		assert.Equal(t, model.DonorNameAnonymous, result.DonorNameVisibility)
+		assert.Equal(t, model.AnonymousDonorName, result.PublicDonorName())
+	})
+
+	t.Run("success - profile name", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransactionRepo := mocks.NewMockITransactionRepository(ctrl)
+		transactionUsecase := usecase.NewTransactionUsecase(mockTransactionRepo, usecase.DonationLimits{})
+
+		transaction := newPendingTransaction()
+		transaction.DonorNameVisibility = model.DonorNameProfile
+
+		mockTransactionRepo.EXPECT().
+			GetUserByEmail(gomock.Any(), transaction.UserEmail).
+			Return(&model.User{UserID: transaction.UserID, Name: "Budi Santoso", Email: transaction.UserEmail}, nil)
+		mockTransactionRepo.EXPECT().
+			CreateTransaction(gomock.Any(), transaction).
+			Return(transaction, nil)
+
+		ctx := context.Background()
+		result, err := transactionUsecase.CreateTransaction(ctx, transaction)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Budi Santoso", result.PublicDonorName())
+	})
+
+	t.Run("success - display name", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransactionRepo := mocks.NewMockITransactionRepository(ctrl)
+		transactionUsecase := usecase.NewTransactionUsecase(mockTransactionRepo, usecase.DonationLimits{})
+
+		transaction := newPendingTransaction()
+		transaction.DonorNameVisibility = model.DonorNameDisplay
+		transaction.DonorName = "  Hamba Allah "
+
+		mockTransactionRepo.EXPECT().
+			CreateTransaction(gomock.Any(), transaction).
+			Return(transaction, nil)
+
+		ctx := context.Background()
+		result, err := transactionUsecase.CreateTransaction(ctx, transaction)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Hamba Allah", result.PublicDonorName())
+	})
+
+	t.Run("failed - display name is an email address", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		transactionUsecase := usecase.NewTransactionUsecase(mocks.NewMockITransactionRepository(ctrl), usecase.DonationLimits{})
+
+		transaction := newPendingTransaction()
+		transaction.DonorNameVisibility = model.DonorNameDisplay
+		transaction.DonorName = transaction.UserEmail
+
+		ctx := context.Background()
+		result, err := transactionUsecase.CreateTransaction(ctx, transaction)
+
+		assert.Nil(t, result)
+		assert.EqualError(t, err, "Display Name must not be an email address")
+	})
+
+	t.Run("failed - display name missing", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		transactionUsecase := usecase.NewTransactionUsecase(mocks.NewMockITransactionRepository(ctrl), usecase.DonationLimits{})
+
+		transaction := newPendingTransaction()
+		transaction.DonorNameVisibility = model.DonorNameDisplay
+
+		ctx := context.Background()
+		result, err := transactionUsecase.CreateTransaction(ctx, transaction)
+
+		assert.Nil(t, result)
+		assert.EqualError(t, err, "Display Name is required")
+	})
+
+	t.Run("failed - unknown visibility", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		transactionUsecase := usecase.NewTransactionUsecase(mocks.NewMockITransactionRepository(ctrl), usecase.DonationLimits{})
+
+		transaction := newPendingTransaction()
+		transaction.DonorNameVisibility = "EMAIL"
+
+		ctx := context.Background()
+		result, err := transactionUsecase.CreateTransaction(ctx, transaction)
+
+		assert.Nil(t, result)
+		assert.EqualError(t, err, "Donor Name Visibility must be PROFILE_NAME, DISPLAY_NAME or ANONYMOUS")
+	})
+}
+
+func TestCreateTransactionMessage(t *testing.T) {
+	t.Run("success - message is trimmed", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransactionRepo := mocks.NewMockITransactionRepository(ctrl)
+		transactionUsecase := usecase.NewTransactionUsecase(mockTransactionRepo, usecase.DonationLimits{})
+
+		transaction := newPendingTransaction()
+		transaction.Message = "  Semoga lekas pulih \n"
+
+		mockTransactionRepo.EXPECT().
+			CreateTransaction(gomock.Any(), transaction).
+			Return(transaction, nil)
+
+		ctx := context.Background()
+		result, err := transactionUsecase.CreateTransaction(ctx, transaction)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Semoga lekas pulih", result.Message)
+	})
+
+	t.Run("failed - message too long", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		transactionUsecase := usecase.NewTransactionUsecase(mocks.NewMockITransactionRepository(ctrl), usecase.DonationLimits{})
+
+		transaction := newPendingTransaction()
+		transaction.Message = strings.Repeat("a", 281)
+
+		ctx := context.Background()
+		result, err := transactionUsecase.CreateTransaction(ctx, transaction)
+
+		assert.Nil(t, result)
+		assert.EqualError(t, err, "Message must be at most 280 characters")
+	})
+}
+
+func TestApplyOverflowPolicy(t *testing.T) {
+	transactionUsecase := usecase.NewTransactionUsecase(nil, usecase.DonationLimits{MinAmount: model.IDR(10000), MaxAmount: model.IDR(100000000)})
+
+	newPost := func(policy model.OverflowPolicy, achieved int64) *model.Post {
+		return &model.Post{PostID: uuid.New(), FundTarget: model.IDR(1000000), FundAchieved: model.IDR(achieved), OverflowPolicy: policy}
+	}
+
+	t.Run("success - within remaining target", func(t *testing.T) {
+		amount, err := transactionUsecase.ApplyOverflowPolicy(newPost(model.OverflowPolicyReject, 900000), model.IDR(100000))
+
+		assert.NoError(t, err)
+		assert.Equal(t, model.IDR(100000), amount)
+	})
+
+	t.Run("success - accept overshoots", func(t *testing.T) {
+		amount, err := transactionUsecase.ApplyOverflowPolicy(newPost(model.OverflowPolicyAccept, 900000), model.IDR(500000))
+
+		assert.NoError(t, err)
+		assert.Equal(t, model.IDR(500000), amount)
+	})
+
+	t.Run("success - posts without a policy accept", func(t *testing.T) {
+		amount, err := transactionUsecase.ApplyOverflowPolicy(newPost("", 1000000), model.IDR(500000))
+
+		assert.NoError(t, err)
+		assert.Equal(t, model.IDR(500000), amount)
+	})
+
+	t.Run("success - cap to remaining", func(t *testing.T) {
+		amount, err := transactionUsecase.ApplyOverflowPolicy(newPost(model.OverflowPolicyCap, 900000), model.IDR(500000))
+
+		assert.NoError(t, err)
+		assert.Equal(t, model.IDR(100000), amount)
+	})
+
+	t.Run("success - cap never goes below the minimum", func(t *testing.T) {
+		amount, err := transactionUsecase.ApplyOverflowPolicy(newPost(model.OverflowPolicyCap, 997000), model.IDR(50000))
+
+		assert.NoError(t, err)
+		assert.Equal(t, model.IDR(10000), amount)
+	})
+
+	t.Run("failed - reject above remaining", func(t *testing.T) {
+		_, err := transactionUsecase.ApplyOverflowPolicy(newPost(model.OverflowPolicyReject, 900000), model.IDR(500000))
+
+		assert.ErrorIs(t, err, usecase.ErrDonationExceedsTarget)
+		assert.ErrorContains(t, err, "at most 100000 IDR")
+	})
+
+	t.Run("failed - cap on a fully funded post", func(t *testing.T) {
+		_, err := transactionUsecase.ApplyOverflowPolicy(newPost(model.OverflowPolicyCap, 1000000), model.IDR(50000))
+
+		assert.ErrorIs(t, err, usecase.ErrPostFullyFunded)
+	})
+
+	t.Run("success - cap rounds a remaining fraction of a rupiah up", func(t *testing.T) {
+		post := newPost(model.OverflowPolicyCap, 900000)
+		post.FundAchieved = model.NewMoney(89999950, model.CurrencyIDR)
+
+		amount, err := transactionUsecase.ApplyOverflowPolicy(post, model.IDR(500000))
+
+		assert.NoError(t, err)
+		assert.Equal(t, model.IDR(100001), amount)
+	})
+
+	t.Run("failed - donation in another currency", func(t *testing.T) {
+		_, err := transactionUsecase.ApplyOverflowPolicy(newPost(model.OverflowPolicyAccept, 0), model.NewMoney(2500, "USD"))
+
+		assert.ErrorIs(t, err, usecase.ErrCurrencyMismatch)
+	})
+}
+
+func newUSDToIDRRate() *model.FXRate {
+	return &model.FXRate{
+		FXRateID:      uuid.New(),
+		BaseCurrency:  "USD",
+		QuoteCurrency: "IDR",
+		Rate:          "15500",
+		SetBy:         "admin@edu-connect.id",
+		CreatedAt:     time.Now(),
+	}
+}
+
+func TestQuoteDonation(t *testing.T) {
+	limits := usecase.DonationLimits{MinAmount: model.IDR(10000), MaxAmount: model.IDR(100000000)}
+
+	newPost := func(policy model.OverflowPolicy, achieved int64) *model.Post {
+		return &model.Post{PostID: uuid.New(), FundTarget: model.IDR(1000000), FundAchieved: model.IDR(achieved), OverflowPolicy: policy}
+	}
+
+	t.Run("success - same currency needs no rate", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		transactionUsecase := usecase.NewTransactionUsecase(mocks.NewMockITransactionRepository(ctrl), limits)
+
+		ctx := context.Background()
+		quote, err := transactionUsecase.QuoteDonation(ctx, newPost(model.OverflowPolicyAccept, 0), model.IDR(50000))
+
+		assert.NoError(t, err)
+		assert.Equal(t, model.IDR(50000), quote.Amount)
+		assert.Equal(t, model.IDR(50000), quote.ConvertedAmount)
+		assert.Nil(t, quote.FXRate)
+	})
+
+	t.Run("success - converts with the direct rate", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransactionRepo := mocks.NewMockITransactionRepository(ctrl)
+		transactionUsecase := usecase.NewTransactionUsecase(mockTransactionRepo, limits)
+
+		rate := newUSDToIDRRate()
+		mockTransactionRepo.EXPECT().
+			GetLatestFXRate(gomock.Any(), "USD", "IDR").
+			Return(rate, nil)
+
+		ctx := context.Background()
+		quote, err := transactionUsecase.QuoteDonation(ctx, newPost(model.OverflowPolicyAccept, 0), model.NewMoney(1050, "USD"))
+
+		assert.NoError(t, err)
+		assert.Equal(t, model.NewMoney(1050, "USD"), quote.Amount)
+		assert.Equal(t, model.IDR(162750), quote.ConvertedAmount)
+		assert.Equal(t, rate.FXRateID.String(), quote.FXRate.FXRateID)
+	})
+
+	t.Run("success - converts with the inverse of the opposite rate", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransactionRepo := mocks.NewMockITransactionRepository(ctrl)
+		transactionUsecase := usecase.NewTransactionUsecase(mockTransactionRepo, limits)
+
+		mockTransactionRepo.EXPECT().
+			GetLatestFXRate(gomock.Any(), "IDR", "USD").
+			Return(nil, gorm.ErrRecordNotFound)
+		mockTransactionRepo.EXPECT().
+			GetLatestFXRate(gomock.Any(), "USD", "IDR").
+			Return(newUSDToIDRRate(), nil)
+
+		post := &model.Post{PostID: uuid.New(), FundTarget: model.NewMoney(100000, "USD"), FundAchieved: model.NewMoney(0, "USD")}
+
+		ctx := context.Background()
+		quote, err := transactionUsecase.QuoteDonation(ctx, post, model.IDR(155000))
+
+		assert.NoError(t, err)
+		assert.Equal(t, model.NewMoney(1000, "USD"), quote.ConvertedAmount)
+		assert.Equal(t, "IDR", quote.FXRate.BaseCurrency)
+		assert.Equal(t, "USD", quote.FXRate.QuoteCurrency)
+	})
+
+	t.Run("success - cap charges the capped amount in the donor's currency", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransactionRepo := mocks.NewMockITransactionRepository(ctrl)
+		transactionUsecase := usecase.NewTransactionUsecase(mockTransactionRepo, limits)
+
+		mockTransactionRepo.EXPECT().
+			GetLatestFXRate(gomock.Any(), "USD", "IDR").
+			Return(newUSDToIDRRate(), nil)
+
+		ctx := context.Background()
+		quote, err := transactionUsecase.QuoteDonation(ctx, newPost(model.OverflowPolicyCap, 900000), model.NewMoney(10000, "USD"))
+
+		assert.NoError(t, err)
+		assert.Equal(t, model.NewMoney(700, "USD"), quote.Amount)
+		assert.Equal(t, model.IDR(108500), quote.ConvertedAmount)
+	})
+
+	t.Run("failed - reject above remaining after conversion", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransactionRepo := mocks.NewMockITransactionRepository(ctrl)
+		transactionUsecase := usecase.NewTransactionUsecase(mockTransactionRepo, limits)
+
+		mockTransactionRepo.EXPECT().
+			GetLatestFXRate(gomock.Any(), "USD", "IDR").
+			Return(newUSDToIDRRate(), nil)
+
+		ctx := context.Background()
+		_, err := transactionUsecase.QuoteDonation(ctx, newPost(model.OverflowPolicyReject, 900000), model.NewMoney(10000, "USD"))
+
+		assert.ErrorIs(t, err, usecase.ErrDonationExceedsTarget)
+	})
+
+	t.Run("failed - no rate for the pair", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransactionRepo := mocks.NewMockITransactionRepository(ctrl)
+		transactionUsecase := usecase.NewTransactionUsecase(mockTransactionRepo, limits)
+
+		mockTransactionRepo.EXPECT().
+			GetLatestFXRate(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(nil, gorm.ErrRecordNotFound).
+			Times(2)
+
+		ctx := context.Background()
+		_, err := transactionUsecase.QuoteDonation(ctx, newPost(model.OverflowPolicyAccept, 0), model.NewMoney(1000, "SGD"))
+
+		assert.ErrorIs(t, err, usecase.ErrFXRateNotFound)
+	})
+
+	t.Run("failed - unsupported currency", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		transactionUsecase := usecase.NewTransactionUsecase(mocks.NewMockITransactionRepository(ctrl), limits)
+
+		ctx := context.Background()
+		_, err := transactionUsecase.QuoteDonation(ctx, newPost(model.OverflowPolicyAccept, 0), model.NewMoney(1000, "XYZ"))
+
+		assert.ErrorIs(t, err, usecase.ErrUnsupportedCurrency)
+	})
+}
+
+func TestSetFXRate(t *testing.T) {
+	t.Run("success - normalizes and stores the rate", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransactionRepo := mocks.NewMockITransactionRepository(ctrl)
+		transactionUsecase := usecase.NewTransactionUsecase(mockTransactionRepo, usecase.DonationLimits{})
+
+		mockTransactionRepo.EXPECT().
+			CreateFXRate(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, rate *model.FXRate) (*model.FXRate, error) {
+				return rate, nil
+			})
+
+		ctx := context.Background()
+		result, err := transactionUsecase.SetFXRate(ctx, &model.FXRate{
+			BaseCurrency:  "sgd",
+			QuoteCurrency: " idr",
+			Rate:          "11500.50",
+			SetBy:         "admin@edu-connect.id",
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "SGD", result.BaseCurrency)
+		assert.Equal(t, "IDR", result.QuoteCurrency)
+		assert.Equal(t, "11500.5", result.Rate)
+	})
+
+	t.Run("failed - invalid rate", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		transactionUsecase := usecase.NewTransactionUsecase(mocks.NewMockITransactionRepository(ctrl), usecase.DonationLimits{})
+
+		ctx := context.Background()
+		result, err := transactionUsecase.SetFXRate(ctx, &model.FXRate{
+			BaseCurrency:  "USD",
+			QuoteCurrency: "USD",
+			Rate:          "-1",
+			SetBy:         "admin@edu-connect.id",
+		})
+
+		assert.Nil(t, result)
+		assert.EqualError(t, err, "Base Currency and Quote Currency must differ, Rate must be a positive decimal number")
+	})
+}
+
+func TestSettleAndRefundConvertedTransaction(t *testing.T) {
+	newConvertedTransaction := func() *model.Transaction {
+		transaction := newPendingTransaction()
+		transaction.Amount = model.NewMoney(1000, "USD")
+		transaction.ConvertedAmount = model.IDR(155000)
+		transaction.FXRate = newUSDToIDRRate().Snapshot()
+		return transaction
+	}
+
+	t.Run("success - settle credits the converted amount", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransactionRepo := mocks.NewMockITransactionRepository(ctrl)
+		transactionUsecase := usecase.NewTransactionUsecase(mockTransactionRepo, usecase.DonationLimits{})
+
+		transaction := newConvertedTransaction()
+
+		mockTransactionRepo.EXPECT().
+			UpdateTransactionStatusWithEvent(gomock.Any(), transaction, gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, transaction *model.Transaction, transition model.StatusTransition, event model.OutboxEvent) (bool, error) {
+				var settled model.DonationSettledEvent
+				assert.NoError(t, json.Unmarshal(event.Payload, &settled))
+				assert.Equal(t, model.IDR(155000), settled.Amount)
+				assert.Equal(t, model.NewMoney(1000, "USD"), *settled.OriginalAmount)
+				return true, nil
+			})
+
+		ctx := context.Background()
+		_, err := transactionUsecase.SettleTransaction(ctx, transaction, model.TransitionSourceWebhook)
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("success - partial refunds debit the converted amount exactly", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransactionRepo := mocks.NewMockITransactionRepository(ctrl)
+		transactionUsecase := usecase.NewTransactionUsecase(mockTransactionRepo, usecase.DonationLimits{})
+
+		transaction := newConvertedTransaction()
+		transaction.PaymentStatus = model.PaymentStatusPaid
+
+		debited := model.IDR(0)
+		mockTransactionRepo.EXPECT().
+			RecordRefund(gomock.Any(), transaction, gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, transaction *model.Transaction, refund model.Refund, transition *model.StatusTransition, event model.OutboxEvent) (bool, error) {
+				var refunded model.DonationRefundedEvent
+				assert.NoError(t, json.Unmarshal(event.Payload, &refunded))
+				debited = debited.Add(refunded.Amount)
+				return true, nil
+			}).
+			Times(3)
+
+		ctx := context.Background()
+		for i, amount := range []int64{333, 333, 334} {
+			refund := withPendingRefund(transaction, model.Refund{Amount: model.NewMoney(amount, "USD"), Source: model.TransitionSourceInstitution})
+			refund.RefundID = fmt.Sprintf("rfd-%d", i)
+			_, err := transactionUsecase.RefundTransaction(ctx, transaction, refund)
+			assert.NoError(t, err)
+		}
+
+		assert.Equal(t, model.IDR(155000), debited)
+		assert.Equal(t, model.PaymentStatusRefunded, transaction.PaymentStatus)
+	})
+}
+
+func TestGetUnpublishedEvents(t *testing.T) {
+	t.Run("success - returns pending events", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransactionRepo := mocks.NewMockITransactionRepository(ctrl)
+		transactionUsecase := usecase.NewTransactionUsecase(mockTransactionRepo, usecase.DonationLimits{})
+
+		pending := []model.PendingOutboxEvent{{
+			TransactionID: primitive.NewObjectID(),
+			Event:         model.OutboxEvent{EventID: uuid.New().String(), Type: model.EventTypeDonationSettled},
+		}}
+
+		mockTransactionRepo.EXPECT().
+			GetUnpublishedEvents(gomock.Any(), int64(50)).
+			Return(pending, nil)
+
+		ctx := context.Background()
+		result, err := transactionUsecase.GetUnpublishedEvents(ctx, 50)
+
+		assert.NoError(t, err)
+		assert.Equal(t, pending, result)
+	})
+
+	t.Run("failed - limit must be positive", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransactionRepo := mocks.NewMockITransactionRepository(ctrl)
+		transactionUsecase := usecase.NewTransactionUsecase(mockTransactionRepo, usecase.DonationLimits{})
+
+		ctx := context.Background()
+		result, err := transactionUsecase.GetUnpublishedEvents(ctx, 0)
+
+		assert.EqualError(t, err, "Limit must be greater than 0")
+		assert.Nil(t, result)
+	})
+}
+
+func TestMarkEventPublished(t *testing.T) {
+	t.Run("success - marks the event on its transaction", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransactionRepo := mocks.NewMockITransactionRepository(ctrl)
+		transactionUsecase := usecase.NewTransactionUsecase(mockTransactionRepo, usecase.DonationLimits{})
+
+		pending := model.PendingOutboxEvent{
+			TransactionID: primitive.NewObjectID(),
+			Event:         model.OutboxEvent{EventID: uuid.New().String()},
+		}
+
+		mockTransactionRepo.EXPECT().
+			MarkEventPublished(gomock.Any(), pending.TransactionID, pending.Event.EventID).
+			Return(nil)
+
+		ctx := context.Background()
+		err := transactionUsecase.MarkEventPublished(ctx, pending)
+
+		assert.NoError(t, err)
+	})
+}
+
+func TestCreateTransactionWithID(t *testing.T) {
+	t.Run("failed - transaction already exists", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransactionRepo := mocks.NewMockITransactionRepository(ctrl)
+		transactionUsecase := usecase.NewTransactionUsecase(mockTransactionRepo, usecase.DonationLimits{})
+
+		transaction := newPendingTransaction()
+		transaction.TransactionID = primitive.NewObjectID()
+
+		mockTransactionRepo.EXPECT().
+			CreateTransaction(gomock.Any(), transaction).
+			Return(nil, mongo.WriteException{WriteErrors: mongo.WriteErrors{{Code: 11000, Message: "duplicate key"}}})
+
+		ctx := context.Background()
+		result, err := transactionUsecase.CreateTransaction(ctx, transaction)
+
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, usecase.ErrTransactionExists)
+	})
+}
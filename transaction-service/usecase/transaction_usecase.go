@@ -3,42 +3,111 @@ package usecase
 import (
 	"context"
 	"errors"
+	"fmt"
+	"log"
+	"math/big"
 	"strings"
+	"time"
+	"unicode/utf8"
 
 	"transaction-service/model"
+	"transaction-service/repository"
 
 	"github.com/google/uuid"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
 )
 
 type ITransactionUsecase interface {
 	CreateTransaction(ctx context.Context, transaction *model.Transaction) (*model.Transaction, error)
-	CreateFundCollect(ctx context.Context, fundCollect *model.FundCollect) (*model.FundCollect, error)
 	GetTransactionByID(ctx context.Context, transactionID primitive.ObjectID) (*model.Transaction, error)
 	GetPostByID(ctx context.Context, postID uuid.UUID) (*model.Post, error)
 	GetUserByEmail(ctx context.Context, email string) (*model.User, error)
 	UpdateTransaction(ctx context.Context, transaction *model.Transaction) (*model.Transaction, error)
-	AddPostFundAchieved(ctx context.Context, postID uuid.UUID, amount float64) (*model.Post, error)
+	TransitionTransaction(ctx context.Context, transaction *model.Transaction, to model.PaymentStatus, source model.TransitionSource) (*model.Transaction, error)
+	SettleTransaction(ctx context.Context, transaction *model.Transaction, source model.TransitionSource) (*model.Transaction, error)
+	GetStalePendingTransactions(ctx context.Context, olderThan time.Duration, limit int) ([]model.Transaction, error)
+	GetUserTransactionByID(ctx context.Context, email string, transactionID primitive.ObjectID) (*model.Transaction, error)
+	GetUserTransactions(ctx context.Context, email string, filter model.TransactionFilter) (*model.TransactionPage, error)
+	GetPostTransactions(ctx context.Context, institutionID uuid.UUID, filter model.TransactionFilter) (*model.TransactionPage, error)
+	GetInstitutionTransactionByID(ctx context.Context, institutionID uuid.UUID, transactionID primitive.ObjectID) (*model.Transaction, error)
+	ResolveRefundAmount(transaction *model.Transaction, amount model.Money) (model.Money, error)
+	StartRefund(ctx context.Context, transaction *model.Transaction, refund model.Refund) (model.Refund, error)
+	RefundTransaction(ctx context.Context, transaction *model.Transaction, refund model.Refund) (*model.Transaction, error)
+	FailRefund(ctx context.Context, transaction *model.Transaction, refund model.Refund) error
+	RetryTransaction(ctx context.Context, transaction *model.Transaction, paymentID, paymentURL string, expiresAt time.Time) (*model.Transaction, error)
+	ApplyOverflowPolicy(post *model.Post, amount model.Money) (model.Money, error)
+	QuoteDonation(ctx context.Context, post *model.Post, amount model.Money) (*model.DonationQuote, error)
+	SetFXRate(ctx context.Context, rate *model.FXRate) (*model.FXRate, error)
+	GetFXRates(ctx context.Context) ([]model.FXRate, error)
+	GetUnpublishedEvents(ctx context.Context, limit int) ([]model.PendingOutboxEvent, error)
+	MarkEventPublished(ctx context.Context, pending model.PendingOutboxEvent) error
+}
+
+var (
+	ErrInvalidStatusTransition = errors.New("invalid transaction status transition")
+	ErrTransactionNotFound     = errors.New("transaction not found")
+	ErrTransactionExists       = errors.New("transaction already exists")
+	ErrPostNotFound            = errors.New("post not found")
+	ErrPostAccessDenied        = errors.New("post does not belong to this institution")
+	ErrRefundNotAllowed        = errors.New("transaction cannot be refunded")
+	ErrRetryNotAllowed         = errors.New("only expired or failed payments can be retried")
+	ErrPostFullyFunded         = errors.New("this fundraising has reached its target")
+	ErrDonationExceedsTarget   = errors.New("donation exceeds the remaining fund target")
+	ErrCurrencyMismatch        = errors.New("donation currency does not match the fundraising")
+	ErrUnsupportedCurrency     = errors.New("currency is not supported")
+	ErrFXRateNotFound          = errors.New("no exchange rate is set for this currency")
+)
+
+const (
+	DefaultTransactionPageSize = 20
+	MaxTransactionPageSize     = 100
+	maxDisplayNameLength       = 50
+	maxDonationMessageLength   = 280
+)
+
+// DonationLimits bound every donation. A zero MinAmount or MaxAmount leaves
+// that side unchecked.
+type DonationLimits struct {
+	MinAmount model.Money
+	MaxAmount model.Money
 }
 
 type TransactionUsecase struct {
-	transactionRepository ITransactionUsecase
+	transactionRepository repository.ITransactionRepository
+	donationLimits        DonationLimits
 }
 
-func NewTransactionUsecase(transactionRepository ITransactionUsecase) *TransactionUsecase {
+func NewTransactionUsecase(transactionRepository repository.ITransactionRepository, donationLimits DonationLimits) *TransactionUsecase {
 	return &TransactionUsecase{
 		transactionRepository: transactionRepository,
+		donationLimits:        donationLimits,
 	}
 }
 
+// CreateTransaction validates and stores a new transaction. A transaction given
+// an ID up front is created at most once; creating it again returns
+// ErrTransactionExists.
 func (u *TransactionUsecase) CreateTransaction(ctx context.Context, transaction *model.Transaction) (*model.Transaction, error) {
 	var e []string
 
 	if transaction.PostID == "00000000-0000-0000-0000-000000000000" {
 		e = append(e, "Post ID is required")
 	}
-	if transaction.Amount <= 0 {
+	if transaction.ConvertedAmount.Currency == "" {
+		transaction.ConvertedAmount = transaction.Amount
+	}
+	if transaction.Amount.Validate() != nil {
+		e = append(e, "Currency is not supported")
+	} else if transaction.Amount.Amount <= 0 {
 		e = append(e, "Amount must be greater than 0")
+	} else if transaction.Amount.Currency == model.CurrencyIDR && !transaction.Amount.IsWhole() {
+		e = append(e, "Amount must be a whole number of IDR")
+	} else {
+		e = append(e, u.checkDonationLimits(transaction)...)
 	}
 	if transaction.AccountNumber == "" {
 		e = append(e, "Account Number is required")
@@ -46,16 +115,90 @@ func (u *TransactionUsecase) CreateTransaction(ctx context.Context, transaction
 	if transaction.AccountName == "" {
 		e = append(e, "Account Name is required")
 	}
+	e = append(e, normalizeDonorName(&transaction.DonorNameVisibility, &transaction.DonorName)...)
+	transaction.Message = strings.TrimSpace(transaction.Message)
+	if utf8.RuneCountInString(transaction.Message) > maxDonationMessageLength {
+		e = append(e, fmt.Sprintf("Message must be at most %d characters", maxDonationMessageLength))
+	}
 
 	if len(e) > 0 {
 		return nil, errors.New(strings.Join(e, ", "))
 	}
 
-	return u.transactionRepository.CreateTransaction(ctx, transaction)
+	if transaction.DonorNameVisibility == model.DonorNameProfile {
+		user, err := u.transactionRepository.GetUserByEmail(ctx, transaction.UserEmail)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get donor profile name: %w", err)
+		}
+		transaction.DonorName = strings.TrimSpace(user.Name)
+	}
+
+	transaction.PaymentStatus = model.PaymentStatusCreated
+	transaction.StatusHistory = []model.StatusTransition{{
+		To:     model.PaymentStatusCreated,
+		Source: model.TransitionSourceAPI,
+		At:     time.Now(),
+	}}
+
+	created, err := u.transactionRepository.CreateTransaction(ctx, transaction)
+	if mongo.IsDuplicateKeyError(err) {
+		return nil, ErrTransactionExists
+	}
+
+	return created, err
 }
 
-func (u *TransactionUsecase) CreateFundCollect(ctx context.Context, fundCollect *model.FundCollect) (*model.FundCollect, error) {
-	return u.transactionRepository.CreateFundCollect(ctx, fundCollect)
+// normalizeDonorName defaults the donor to anonymous and checks the display
+// name, which must not leak an email address onto public donor lists. The
+// profile name is looked up by the caller once the request is valid.
+func normalizeDonorName(visibility *model.DonorNameVisibility, name *string) []string {
+	var e []string
+
+	if *visibility == "" {
+		*visibility = model.DonorNameAnonymous
+	}
+
+	switch *visibility {
+	case model.DonorNameAnonymous:
+		*name = model.AnonymousDonorName
+	case model.DonorNameProfile:
+		*name = ""
+	case model.DonorNameDisplay:
+		*name = strings.TrimSpace(*name)
+		if *name == "" {
+			e = append(e, "Display Name is required")
+		} else if utf8.RuneCountInString(*name) > maxDisplayNameLength {
+			e = append(e, fmt.Sprintf("Display Name must be at most %d characters", maxDisplayNameLength))
+		} else if strings.Contains(*name, "@") {
+			e = append(e, "Display Name must not be an email address")
+		}
+	default:
+		e = append(e, "Donor Name Visibility must be PROFILE_NAME, DISPLAY_NAME or ANONYMOUS")
+	}
+
+	return e
+}
+
+// checkDonationLimits applies the limits to whichever side of the donation is
+// in their currency. A donation in another currency to a post in another
+// currency is not limited.
+func (u *TransactionUsecase) checkDonationLimits(transaction *model.Transaction) []string {
+	var e []string
+
+	min, max := u.donationLimits.MinAmount, u.donationLimits.MaxAmount
+
+	amount := transaction.Amount
+	if amount.Currency != min.Currency && amount.Currency != max.Currency {
+		amount = transaction.ConvertedAmount
+	}
+	if !min.IsZero() && amount.Currency == min.Currency && amount.Cmp(min) < 0 {
+		e = append(e, fmt.Sprintf("Amount must be at least %.0f %s", min.Major(), min.Currency))
+	}
+	if !max.IsZero() && amount.Currency == max.Currency && amount.Cmp(max) > 0 {
+		e = append(e, fmt.Sprintf("Amount must be at most %.0f %s", max.Major(), max.Currency))
+	}
+
+	return e
 }
 
 func (u *TransactionUsecase) GetTransactionByID(ctx context.Context, transactionID primitive.ObjectID) (*model.Transaction, error) {
@@ -74,6 +217,512 @@ func (u *TransactionUsecase) UpdateTransaction(ctx context.Context, transaction
 	return u.transactionRepository.UpdateTransaction(ctx, transaction)
 }
 
-func (u *TransactionUsecase) AddPostFundAchieved(ctx context.Context, postID uuid.UUID, amount float64) (*model.Post, error) {
-	return u.transactionRepository.AddPostFundAchieved(ctx, postID, amount)
+// TransitionTransaction moves the transaction to the given status, persisting
+// its payment details alongside, and records who made the change.
+func (u *TransactionUsecase) TransitionTransaction(ctx context.Context, transaction *model.Transaction, to model.PaymentStatus, source model.TransitionSource) (*model.Transaction, error) {
+	return u.transition(ctx, transaction, to, source, nil)
+}
+
+// transition is TransitionTransaction that also writes event, when given, to
+// the transaction's outbox in the same update as the status change.
+func (u *TransactionUsecase) transition(ctx context.Context, transaction *model.Transaction, to model.PaymentStatus, source model.TransitionSource, event *model.OutboxEvent) (*model.Transaction, error) {
+	from := transaction.PaymentStatus
+	if !from.CanTransitionTo(to) {
+		return nil, fmt.Errorf("%w: %s to %s", ErrInvalidStatusTransition, from, to)
+	}
+
+	transition := model.StatusTransition{
+		From:   from,
+		To:     to,
+		Source: source,
+		At:     time.Now(),
+	}
+
+	var updated bool
+	var err error
+	if event != nil {
+		updated, err = u.transactionRepository.UpdateTransactionStatusWithEvent(ctx, transaction, transition, *event)
+	} else {
+		updated, err = u.transactionRepository.UpdateTransactionStatus(ctx, transaction, transition)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update transaction: %v", err)
+	}
+	if !updated {
+		return nil, fmt.Errorf("%w: transaction %s is no longer %s", ErrInvalidStatusTransition, transaction.TransactionID.Hex(), from)
+	}
+
+	transaction.PaymentStatus = to
+	transaction.StatusHistory = append(transaction.StatusHistory, transition)
+	if event != nil {
+		transaction.Outbox = append(transaction.Outbox, *event)
+	}
+
+	return transaction, nil
+}
+
+// SettleTransaction marks a transaction PAID and, in the same write, records
+// the DonationSettled event that credits its post. Only the call that moves
+// the transaction to PAID records the event, no matter how many times the
+// success redirect or the invoice callback replay it.
+func (u *TransactionUsecase) SettleTransaction(ctx context.Context, transaction *model.Transaction, source model.TransitionSource) (*model.Transaction, error) {
+	if transaction.PaymentStatus == model.PaymentStatusPaid {
+		return transaction, nil
+	}
+
+	if !transaction.PaymentStatus.CanTransitionTo(model.PaymentStatusPaid) {
+		return nil, fmt.Errorf("%w: %s to %s", ErrInvalidStatusTransition, transaction.PaymentStatus, model.PaymentStatusPaid)
+	}
+
+	if _, err := uuid.Parse(transaction.PostID); err != nil {
+		return nil, fmt.Errorf("invalid PostID format: %v", err)
+	}
+
+	if transaction.PaidAt.IsZero() {
+		transaction.PaidAt = time.Now()
+	}
+	transaction.ReceiptStatus = model.ReceiptStatusPending
+
+	event, err := model.NewDonationSettledEvent(transaction, transaction.PublicDonorName())
+	if err != nil {
+		return nil, fmt.Errorf("failed to build donation settled event: %v", err)
+	}
+
+	settled, err := u.transition(ctx, transaction, model.PaymentStatusPaid, source, &event)
+	if errors.Is(err, ErrInvalidStatusTransition) {
+		log.Printf("Transaction %s was settled concurrently: %v", transaction.TransactionID.Hex(), err)
+		transaction.PaymentStatus = model.PaymentStatusPaid
+		return transaction, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return settled, nil
+}
+
+func (u *TransactionUsecase) GetStalePendingTransactions(ctx context.Context, olderThan time.Duration, limit int) ([]model.Transaction, error) {
+	if limit <= 0 {
+		return nil, errors.New("Limit must be greater than 0")
+	}
+
+	return u.transactionRepository.GetPendingTransactionsBefore(ctx, time.Now().Add(-olderThan), int64(limit))
+}
+
+// GetUserTransactionByID reports another user's transaction as not found, so
+// transaction IDs cannot be probed.
+func (u *TransactionUsecase) GetUserTransactionByID(ctx context.Context, email string, transactionID primitive.ObjectID) (*model.Transaction, error) {
+	transaction, err := u.transactionRepository.GetTransactionByID(ctx, transactionID)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrTransactionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if transaction.UserEmail != email {
+		return nil, ErrTransactionNotFound
+	}
+
+	return transaction, nil
+}
+
+func (u *TransactionUsecase) GetUserTransactions(ctx context.Context, email string, filter model.TransactionFilter) (*model.TransactionPage, error) {
+	if email == "" {
+		return nil, errors.New("User email is required")
+	}
+
+	filter.UserEmail = email
+	filter.PostID = ""
+
+	return u.getTransactionPage(ctx, filter)
+}
+
+func (u *TransactionUsecase) GetPostTransactions(ctx context.Context, institutionID uuid.UUID, filter model.TransactionFilter) (*model.TransactionPage, error) {
+	if err := u.authorizePost(ctx, institutionID, filter.PostID); err != nil {
+		return nil, err
+	}
+
+	filter.UserEmail = ""
+
+	return u.getTransactionPage(ctx, filter)
+}
+
+func (u *TransactionUsecase) getTransactionPage(ctx context.Context, filter model.TransactionFilter) (*model.TransactionPage, error) {
+	var e []string
+
+	if filter.Status != "" && !filter.Status.IsValid() {
+		e = append(e, fmt.Sprintf("Unknown status %s", filter.Status))
+	}
+	if !filter.DateFrom.IsZero() && !filter.DateTo.IsZero() && !filter.DateFrom.Before(filter.DateTo) {
+		e = append(e, "Date from must be before date to")
+	}
+	if filter.Limit < 0 {
+		e = append(e, "Limit must not be negative")
+	}
+
+	if len(e) > 0 {
+		return nil, errors.New(strings.Join(e, ", "))
+	}
+
+	limit := filter.Limit
+	if limit == 0 {
+		limit = DefaultTransactionPageSize
+	}
+	if limit > MaxTransactionPageSize {
+		limit = MaxTransactionPageSize
+	}
+
+	// Fetch one extra row to learn whether another page follows.
+	filter.Limit = limit + 1
+
+	transactions, err := u.transactionRepository.GetTransactions(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &model.TransactionPage{Transactions: transactions}
+	if len(transactions) > limit {
+		page.Transactions = transactions[:limit]
+		page.NextCursor = page.Transactions[limit-1].TransactionID.Hex()
+	}
+
+	return page, nil
+}
+
+func (u *TransactionUsecase) GetInstitutionTransactionByID(ctx context.Context, institutionID uuid.UUID, transactionID primitive.ObjectID) (*model.Transaction, error) {
+	transaction, err := u.transactionRepository.GetTransactionByID(ctx, transactionID)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrTransactionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.authorizePost(ctx, institutionID, transaction.PostID); err != nil {
+		return nil, err
+	}
+
+	return transaction, nil
+}
+
+// ResolveRefundAmount checks a refund request against what is still
+// refundable. A zero amount means a full refund of the remainder.
+func (u *TransactionUsecase) ResolveRefundAmount(transaction *model.Transaction, amount model.Money) (model.Money, error) {
+	if transaction.PaymentStatus != model.PaymentStatusPaid {
+		return model.Money{}, fmt.Errorf("%w: transaction is %s", ErrRefundNotAllowed, transaction.PaymentStatus)
+	}
+
+	refundable := transaction.RefundableAmount()
+	if amount.IsZero() {
+		return refundable, nil
+	}
+
+	if amount.Currency != refundable.Currency {
+		return model.Money{}, fmt.Errorf("%w: amount must be in %s", ErrRefundNotAllowed, refundable.Currency)
+	}
+	if amount.Amount < 0 {
+		return model.Money{}, fmt.Errorf("%w: amount must be greater than 0", ErrRefundNotAllowed)
+	}
+	if amount.Cmp(refundable) > 0 {
+		return model.Money{}, fmt.Errorf("%w: amount %s exceeds refundable %s", ErrRefundNotAllowed, amount, refundable)
+	}
+
+	return amount, nil
+}
+
+// StartRefund records the refund as PENDING under a new ReferenceID before the
+// gateway is asked to make it, so that no refund the gateway makes goes
+// unrecorded. A transaction has at most one pending refund at a time.
+func (u *TransactionUsecase) StartRefund(ctx context.Context, transaction *model.Transaction, refund model.Refund) (model.Refund, error) {
+	if _, err := u.ResolveRefundAmount(transaction, refund.Amount); err != nil {
+		return model.Refund{}, err
+	}
+	if refund.Amount.IsZero() {
+		return model.Refund{}, fmt.Errorf("%w: amount must be greater than 0", ErrRefundNotAllowed)
+	}
+	if pending := transaction.PendingRefund(); pending != nil {
+		return model.Refund{}, fmt.Errorf("%w: refund %s is still pending", ErrRefundNotAllowed, pending.ReferenceID)
+	}
+
+	refund.ReferenceID = fmt.Sprintf("%s-refund-%d", transaction.TransactionID.Hex(), len(transaction.Refunds)+1)
+	refund.Status = model.RefundStatusPending
+	if refund.At.IsZero() {
+		refund.At = time.Now()
+	}
+
+	started, err := u.transactionRepository.AddPendingRefund(ctx, transaction, refund)
+	if err != nil {
+		return model.Refund{}, fmt.Errorf("failed to record pending refund: %v", err)
+	}
+	if !started {
+		return model.Refund{}, fmt.Errorf("%w: transaction %s changed concurrently", ErrRefundNotAllowed, transaction.TransactionID.Hex())
+	}
+
+	transaction.Refunds = append(transaction.Refunds, refund)
+	return refund, nil
+}
+
+// RefundTransaction settles a refund started with StartRefund that the
+// gateway has made and, in the same write, records the DonationRefunded event
+// that takes the amount back off the post. A partial refund leaves the
+// transaction PAID; the refund that brings it to zero moves it to REFUNDED.
+func (u *TransactionUsecase) RefundTransaction(ctx context.Context, transaction *model.Transaction, refund model.Refund) (*model.Transaction, error) {
+	if _, err := u.ResolveRefundAmount(transaction, refund.Amount); err != nil {
+		return nil, err
+	}
+	if refund.Amount.IsZero() {
+		return nil, fmt.Errorf("%w: amount must be greater than 0", ErrRefundNotAllowed)
+	}
+	pending := transaction.PendingRefund()
+	if pending == nil || pending.ReferenceID != refund.ReferenceID {
+		return nil, fmt.Errorf("%w: refund %s is not pending", ErrRefundNotAllowed, refund.ReferenceID)
+	}
+
+	if _, err := uuid.Parse(transaction.PostID); err != nil {
+		return nil, fmt.Errorf("invalid PostID format: %v", err)
+	}
+
+	refund.Status = model.RefundStatusSucceeded
+	if refund.At.IsZero() {
+		refund.At = time.Now()
+	}
+
+	var transition *model.StatusTransition
+	if refund.Amount.Cmp(transaction.RefundableAmount()) == 0 {
+		transition = &model.StatusTransition{
+			From:   transaction.PaymentStatus,
+			To:     model.PaymentStatusRefunded,
+			Source: refund.Source,
+			At:     refund.At,
+		}
+	}
+
+	debit := transaction.CampaignShare(transaction.RefundedAmount.Add(refund.Amount)).
+		Sub(transaction.CampaignShare(transaction.RefundedAmount))
+
+	event, err := model.NewDonationRefundedEvent(transaction, refund, debit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build donation refunded event: %v", err)
+	}
+
+	recorded, err := u.transactionRepository.RecordRefund(ctx, transaction, refund, transition, event)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record refund: %v", err)
+	}
+	if !recorded {
+		return nil, fmt.Errorf("%w: transaction %s changed concurrently", ErrRefundNotAllowed, transaction.TransactionID.Hex())
+	}
+
+	transaction.RefundedAmount = transaction.RefundedAmount.Add(refund.Amount)
+	*pending = refund
+	transaction.Outbox = append(transaction.Outbox, event)
+	if transition != nil {
+		transaction.PaymentStatus = transition.To
+		transaction.StatusHistory = append(transaction.StatusHistory, *transition)
+	}
+
+	return transaction, nil
+}
+
+// FailRefund marks a refund started with StartRefund that the gateway did not
+// make as FAILED, so the transaction can be refunded again.
+func (u *TransactionUsecase) FailRefund(ctx context.Context, transaction *model.Transaction, refund model.Refund) error {
+	if err := u.transactionRepository.FailRefund(ctx, transaction.TransactionID, refund.ReferenceID); err != nil {
+		return err
+	}
+
+	if pending := transaction.PendingRefund(); pending != nil && pending.ReferenceID == refund.ReferenceID {
+		pending.Status = model.RefundStatusFailed
+	}
+	return nil
+}
+
+func (u *TransactionUsecase) authorizePost(ctx context.Context, institutionID uuid.UUID, postID string) error {
+	parsedPostID, err := uuid.Parse(postID)
+	if err != nil {
+		return fmt.Errorf("invalid PostID format: %v", err)
+	}
+
+	post, err := u.transactionRepository.GetPostByID(ctx, parsedPostID)
+	if status.Code(err) == codes.NotFound {
+		return ErrPostNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	if post.InstitutionID != institutionID {
+		return ErrPostAccessDenied
+	}
+
+	return nil
+}
+
+// RetryTransaction moves an EXPIRED or FAILED transaction back to PENDING on a
+// fresh invoice, keeping the replaced invoice ID so late callbacks for it can
+// be recognised.
+func (u *TransactionUsecase) RetryTransaction(ctx context.Context, transaction *model.Transaction, paymentID, paymentURL string, expiresAt time.Time) (*model.Transaction, error) {
+	if !transaction.CanRetryPayment() {
+		return nil, fmt.Errorf("%w: transaction is %s", ErrRetryNotAllowed, transaction.PaymentStatus)
+	}
+	if paymentID == "" {
+		return nil, errors.New("Payment ID is required")
+	}
+
+	retried := *transaction
+	if transaction.PaymentID != "" && transaction.PaymentID != "pending" {
+		retried.PreviousPaymentIDs = append(append([]string{}, transaction.PreviousPaymentIDs...), transaction.PaymentID)
+	}
+	retried.PaymentID = paymentID
+	retried.PaymentURL = paymentURL
+	retried.ExpiresAt = expiresAt
+
+	return u.TransitionTransaction(ctx, &retried, model.PaymentStatusPending, model.TransitionSourceAPI)
+}
+
+// ApplyOverflowPolicy returns the amount to charge for a donation to post. A
+// CAP post lowers it to what is left of the target, though never below the
+// platform minimum so the last donor can still close the campaign. A REJECT
+// post refuses it instead.
+func (u *TransactionUsecase) ApplyOverflowPolicy(post *model.Post, amount model.Money) (model.Money, error) {
+	if amount.Currency != post.FundTarget.Currency {
+		return model.Money{}, fmt.Errorf("%w: this fundraising accepts %s", ErrCurrencyMismatch, post.FundTarget.Currency)
+	}
+
+	remaining := post.RemainingTarget().CeilMajor()
+	if amount.Cmp(remaining) <= 0 {
+		return amount, nil
+	}
+
+	switch post.OverflowPolicy {
+	case model.OverflowPolicyCap:
+		if remaining.Amount <= 0 {
+			return model.Money{}, ErrPostFullyFunded
+		}
+		if remaining.Currency == u.donationLimits.MinAmount.Currency && remaining.Cmp(u.donationLimits.MinAmount) < 0 {
+			remaining = u.donationLimits.MinAmount
+		}
+		if amount.Cmp(remaining) < 0 {
+			return amount, nil
+		}
+		return remaining, nil
+	case model.OverflowPolicyReject:
+		if remaining.Amount <= 0 {
+			return model.Money{}, ErrPostFullyFunded
+		}
+		return model.Money{}, fmt.Errorf("%w: at most %.0f %s can still be donated", ErrDonationExceedsTarget, remaining.Major(), remaining.Currency)
+	default:
+		return amount, nil
+	}
+}
+
+// QuoteDonation converts a donation into the post's currency and applies the
+// post's overflow policy. When the policy caps the donation, the donor is
+// charged the capped amount converted back and rounded up to a whole unit.
+func (u *TransactionUsecase) QuoteDonation(ctx context.Context, post *model.Post, amount model.Money) (*model.DonationQuote, error) {
+	if amount.Validate() != nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedCurrency, amount.Currency)
+	}
+
+	if amount.Currency == post.FundTarget.Currency {
+		credit, err := u.ApplyOverflowPolicy(post, amount)
+		if err != nil {
+			return nil, err
+		}
+		return &model.DonationQuote{Amount: credit, ConvertedAmount: credit}, nil
+	}
+
+	rate, err := u.getFXRate(ctx, amount.Currency, post.FundTarget.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	converted, err := rate.Convert(amount)
+	if err != nil {
+		return nil, err
+	}
+
+	credit, err := u.ApplyOverflowPolicy(post, converted)
+	if err != nil {
+		return nil, err
+	}
+	if credit == converted {
+		return &model.DonationQuote{Amount: amount, ConvertedAmount: converted, FXRate: rate}, nil
+	}
+
+	charge, err := rate.Invert().Convert(credit)
+	if err != nil {
+		return nil, err
+	}
+	charge = charge.CeilMajor()
+	if charge.Cmp(amount) > 0 {
+		charge = amount
+	}
+
+	converted, err = rate.Convert(charge)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.DonationQuote{Amount: charge, ConvertedAmount: converted, FXRate: rate}, nil
+}
+
+// getFXRate finds the latest rate from base to quote, reading a rate set the
+// other way round inverted.
+func (u *TransactionUsecase) getFXRate(ctx context.Context, base, quote string) (*model.FXRateSnapshot, error) {
+	rate, err := u.transactionRepository.GetLatestFXRate(ctx, base, quote)
+	if err == nil {
+		return rate.Snapshot(), nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	rate, err = u.transactionRepository.GetLatestFXRate(ctx, quote, base)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("%w: %s to %s", ErrFXRateNotFound, base, quote)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return rate.Snapshot().Invert(), nil
+}
+
+func (u *TransactionUsecase) SetFXRate(ctx context.Context, rate *model.FXRate) (*model.FXRate, error) {
+	rate.BaseCurrency = strings.ToUpper(strings.TrimSpace(rate.BaseCurrency))
+	rate.QuoteCurrency = strings.ToUpper(strings.TrimSpace(rate.QuoteCurrency))
+	rate.Rate = strings.TrimSpace(rate.Rate)
+
+	if err := rate.Validate(); err != nil {
+		return nil, err
+	}
+	if rate.SetBy == "" {
+		return nil, errors.New("Set By is required")
+	}
+
+	value, _ := new(big.Rat).SetString(rate.Rate)
+	rate.Rate = strings.TrimRight(strings.TrimRight(value.FloatString(12), "0"), ".")
+	if rate.Rate == "0" {
+		return nil, errors.New("Rate must have at most 12 decimal places")
+	}
+
+	return u.transactionRepository.CreateFXRate(ctx, rate)
+}
+
+func (u *TransactionUsecase) GetFXRates(ctx context.Context) ([]model.FXRate, error) {
+	return u.transactionRepository.GetLatestFXRates(ctx)
+}
+
+func (u *TransactionUsecase) GetUnpublishedEvents(ctx context.Context, limit int) ([]model.PendingOutboxEvent, error) {
+	if limit <= 0 {
+		return nil, errors.New("Limit must be greater than 0")
+	}
+
+	return u.transactionRepository.GetUnpublishedEvents(ctx, int64(limit))
+}
+
+func (u *TransactionUsecase) MarkEventPublished(ctx context.Context, pending model.PendingOutboxEvent) error {
+	return u.transactionRepository.MarkEventPublished(ctx, pending.TransactionID, pending.Event.EventID)
 }
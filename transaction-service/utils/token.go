@@ -1,14 +1,51 @@
 package utils
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/dgrijalva/jwt-go"
 	"github.com/joho/godotenv"
+	"google.golang.org/grpc/metadata"
 )
 
+// ServiceName is the service claim of the tokens transaction-service signs
+// for its own calls to other services.
+const ServiceName = "transaction-service"
+
+// GenerateServiceToken signs a short-lived token with the shared JWT_SECRET
+// that identifies transaction-service rather than a user or institution.
+func GenerateServiceToken() (string, error) {
+	JWTSecret := os.Getenv("JWT_SECRET")
+	if JWTSecret == "" {
+		return "", fmt.Errorf("server error: JWT secret is missing")
+	}
+
+	claims := jwt.MapClaims{}
+	claims["service"] = ServiceName
+	claims["exp"] = time.Now().Add(5 * time.Minute).Unix()
+
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(JWTSecret))
+}
+
+// WithServiceToken returns ctx with its outgoing authorization replaced by a
+// service token, for calls only other services may make.
+func WithServiceToken(ctx context.Context) (context.Context, error) {
+	token, err := GenerateServiceToken()
+	if err != nil {
+		return nil, err
+	}
+
+	md, _ := metadata.FromOutgoingContext(ctx)
+	md = md.Copy()
+	md.Set("authorization", "Bearer "+token)
+
+	return metadata.NewOutgoingContext(ctx, md), nil
+}
+
 func ValidateJWT(tokenString string) (string, string, error) {
 	tokenString = strings.TrimPrefix(tokenString, "Bearer ")
 
@@ -54,6 +91,42 @@ func ValidateJWT(tokenString string) (string, string, error) {
 	return "", "", fmt.Errorf("invalid token")
 }
 
+// ValidateInstitutionJWT validates a token issued by institution-service and
+// returns its institution ID.
+func ValidateInstitutionJWT(tokenString string) (string, error) {
+	tokenString = strings.TrimPrefix(tokenString, "Bearer ")
+
+	err := godotenv.Load(".env")
+	if err != nil {
+		return "", fmt.Errorf("server error: failed to load environment variables")
+	}
+
+	JWTSecret := os.Getenv("JWT_SECRET")
+	if JWTSecret == "" {
+		return "", fmt.Errorf("server error: JWT secret is missing")
+	}
+
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method")
+		}
+		return []byte(JWTSecret), nil
+	})
+
+	if err != nil {
+		return "", err
+	}
+
+	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
+		if id, idOk := claims["institution_id"].(string); idOk && id != "" {
+			return id, nil
+		}
+		return "", fmt.Errorf("institution_id not found in token claims")
+	}
+
+	return "", fmt.Errorf("invalid token")
+}
+
 // func ValidateToken(tokenString string) (*jwt.MapClaims, error) {
 // 	err := godotenv.Load(".env")
 // 	if err != nil {
@@ -0,0 +1,78 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"transaction-service/config"
+	"transaction-service/queue"
+	"transaction-service/usecase"
+)
+
+// OutboxRelay publishes the events recorded in transaction outboxes to
+// RabbitMQ. An event published but not yet marked is published again on the
+// next pass, so consumers see every event at least once.
+type OutboxRelay struct {
+	transactionUsecase usecase.ITransactionUsecase
+	eventPublisher     queue.IEventPublisher
+	config             config.WorkerConfig
+}
+
+func NewOutboxRelay(
+	transactionUsecase usecase.ITransactionUsecase,
+	eventPublisher queue.IEventPublisher,
+	cfg config.WorkerConfig,
+) *OutboxRelay {
+	return &OutboxRelay{
+		transactionUsecase: transactionUsecase,
+		eventPublisher:     eventPublisher,
+		config:             cfg,
+	}
+}
+
+// Start runs a relay pass every Interval until ctx is cancelled.
+func (r *OutboxRelay) Start(ctx context.Context) {
+	ticker := time.NewTicker(r.config.Interval)
+	defer ticker.Stop()
+
+	log.Printf("Starting outbox relay every %s", r.config.Interval)
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Printf("Stopping outbox relay")
+			return
+		case <-ticker.C:
+			if _, err := r.RelayOnce(ctx); err != nil {
+				log.Printf("Outbox relay pass failed: %v", err)
+			}
+		}
+	}
+}
+
+// RelayOnce publishes one batch of events and returns how many were marked
+// published. It stops at the first event the broker does not take, leaving
+// the rest for the next pass.
+func (r *OutboxRelay) RelayOnce(ctx context.Context) (int, error) {
+	events, err := r.transactionUsecase.GetUnpublishedEvents(ctx, r.config.BatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get unpublished events: %v", err)
+	}
+
+	relayed := 0
+	for _, pending := range events {
+		if err := r.eventPublisher.PublishEvent(ctx, pending.Event); err != nil {
+			return relayed, fmt.Errorf("failed to publish %s event %s: %v", pending.Event.Type, pending.Event.EventID, err)
+		}
+
+		if err := r.transactionUsecase.MarkEventPublished(ctx, pending); err != nil {
+			log.Printf("Event %s was published but not marked, it will be published again: %v", pending.Event.EventID, err)
+			continue
+		}
+		relayed++
+	}
+
+	return relayed, nil
+}
@@ -0,0 +1,90 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"transaction-service/config"
+	"transaction-service/model"
+	"transaction-service/queue"
+	"transaction-service/usecase"
+)
+
+// ReceiptSender issues the receipt of each newly paid transaction and emails
+// it to the donor.
+type ReceiptSender struct {
+	receiptUsecase usecase.IReceiptUsecase
+	emailPublisher queue.IEmailPublisher
+	config         config.WorkerConfig
+}
+
+func NewReceiptSender(
+	receiptUsecase usecase.IReceiptUsecase,
+	emailPublisher queue.IEmailPublisher,
+	cfg config.WorkerConfig,
+) *ReceiptSender {
+	return &ReceiptSender{
+		receiptUsecase: receiptUsecase,
+		emailPublisher: emailPublisher,
+		config:         cfg,
+	}
+}
+
+// Start sends pending receipts every Interval until ctx is cancelled.
+func (s *ReceiptSender) Start(ctx context.Context) {
+	ticker := time.NewTicker(s.config.Interval)
+	defer ticker.Stop()
+
+	log.Printf("Starting receipt sender every %s", s.config.Interval)
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Printf("Stopping receipt sender")
+			return
+		case <-ticker.C:
+			if _, err := s.SendOnce(ctx); err != nil {
+				log.Printf("Receipt sender pass failed: %v", err)
+			}
+		}
+	}
+}
+
+// SendOnce sends one batch of pending receipts and returns how many were
+// sent. A receipt that fails is retried with a growing delay, up to
+// MaxDeliveryAttempts times.
+func (s *ReceiptSender) SendOnce(ctx context.Context) (int, error) {
+	transactions, err := s.receiptUsecase.GetTransactionsAwaitingReceipt(ctx, s.config.BatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get transactions awaiting receipt: %v", err)
+	}
+
+	sent := 0
+	for i := range transactions {
+		if err := s.send(ctx, &transactions[i]); err != nil {
+			log.Printf("Failed to send receipt of transaction %s: %v", transactions[i].TransactionID.Hex(), err)
+			if err := s.receiptUsecase.RecordReceiptFailure(ctx, &transactions[i]); err != nil {
+				log.Printf("Failed to record receipt failure of transaction %s: %v", transactions[i].TransactionID.Hex(), err)
+			}
+			continue
+		}
+		sent++
+	}
+
+	return sent, nil
+}
+
+func (s *ReceiptSender) send(ctx context.Context, transaction *model.Transaction) error {
+	receipt, err := s.receiptUsecase.IssueReceipt(ctx, transaction)
+	if err != nil {
+		return err
+	}
+
+	if err := s.emailPublisher.PublishReceipt(transaction, receipt); err != nil {
+		return err
+	}
+
+	return s.receiptUsecase.MarkReceiptSent(ctx, transaction)
+}
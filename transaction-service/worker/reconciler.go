@@ -0,0 +1,114 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"transaction-service/client"
+	"transaction-service/config"
+	"transaction-service/model"
+	"transaction-service/usecase"
+)
+
+// Reconciler catches invoices whose callback never arrived by polling the
+// payment gateway for transactions that have been PENDING longer than
+// StaleAfter.
+type Reconciler struct {
+	transactionUsecase usecase.ITransactionUsecase
+	paymentGateway     client.PaymentGateway
+	config             config.ReconcilerConfig
+}
+
+func NewReconciler(
+	transactionUsecase usecase.ITransactionUsecase,
+	paymentGateway client.PaymentGateway,
+	cfg config.ReconcilerConfig,
+) *Reconciler {
+	return &Reconciler{
+		transactionUsecase: transactionUsecase,
+		paymentGateway:     paymentGateway,
+		config:             cfg,
+	}
+}
+
+// Start runs a reconcile pass every Interval until ctx is cancelled.
+func (r *Reconciler) Start(ctx context.Context) {
+	ticker := time.NewTicker(r.config.Interval)
+	defer ticker.Stop()
+
+	log.Printf("Starting payment reconciler every %s for transactions pending over %s", r.config.Interval, r.config.StaleAfter)
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Printf("Stopping payment reconciler")
+			return
+		case <-ticker.C:
+			if err := r.ReconcileOnce(ctx); err != nil {
+				log.Printf("Payment reconciler pass failed: %v", err)
+			}
+		}
+	}
+}
+
+// ReconcileOnce checks one batch of stale transactions against the gateway. A
+// transaction that cannot be reconciled is logged and left for the next pass.
+func (r *Reconciler) ReconcileOnce(ctx context.Context) error {
+	transactions, err := r.transactionUsecase.GetStalePendingTransactions(ctx, r.config.StaleAfter, r.config.BatchSize)
+	if err != nil {
+		return fmt.Errorf("failed to get stale transactions: %v", err)
+	}
+
+	for i := range transactions {
+		if err := r.reconcileTransaction(ctx, &transactions[i]); err != nil {
+			log.Printf("Failed to reconcile transaction %s: %v", transactions[i].TransactionID.Hex(), err)
+		}
+	}
+
+	return nil
+}
+
+func (r *Reconciler) reconcileTransaction(ctx context.Context, transaction *model.Transaction) error {
+	if transaction.PaymentID == "" {
+		return errors.New("transaction has no invoice")
+	}
+
+	invoice, err := r.paymentGateway.GetInvoice(transaction.PaymentID)
+	if err != nil {
+		return err
+	}
+
+	if invoice.ExternalID != transaction.TransactionID.Hex() {
+		return errors.New("invoice does not belong to this transaction")
+	}
+	if model.MoneyFromMajor(invoice.Amount, transaction.Amount.Currency) != transaction.Amount {
+		return errors.New("invoice amount does not match transaction")
+	}
+
+	switch strings.ToUpper(invoice.Status) {
+	case "PAID", "SETTLED":
+		transaction.PaymentMethod = invoice.PaymentMethod
+		transaction.PaidAt = invoice.PaidAt
+		_, err = r.transactionUsecase.SettleTransaction(ctx, transaction, model.TransitionSourceReconciler)
+	case "EXPIRED":
+		_, err = r.transactionUsecase.TransitionTransaction(ctx, transaction, model.PaymentStatusExpired, model.TransitionSourceReconciler)
+	default:
+		return nil
+	}
+
+	if errors.Is(err, usecase.ErrInvalidStatusTransition) {
+		// The callback got there first.
+		log.Printf("Skipping transaction %s: %v", transaction.TransactionID.Hex(), err)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Reconciled transaction %s to %s", transaction.TransactionID.Hex(), transaction.PaymentStatus)
+	return nil
+}
@@ -0,0 +1,214 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"transaction-service/client"
+	"transaction-service/config"
+	"transaction-service/model"
+	"transaction-service/queue"
+	"transaction-service/usecase"
+)
+
+// SubscriptionScheduler emails donors ahead of each recurring charge and, once
+// a charge is due, creates the cycle's transaction and invoice.
+type SubscriptionScheduler struct {
+	subscriptionUsecase usecase.ISubscriptionUsecase
+	transactionUsecase  usecase.ITransactionUsecase
+	paymentGateway      client.PaymentGateway
+	emailPublisher      queue.IEmailPublisher
+	config              *config.Config
+}
+
+func NewSubscriptionScheduler(
+	subscriptionUsecase usecase.ISubscriptionUsecase,
+	transactionUsecase usecase.ITransactionUsecase,
+	paymentGateway client.PaymentGateway,
+	emailPublisher queue.IEmailPublisher,
+	cfg *config.Config,
+) *SubscriptionScheduler {
+	return &SubscriptionScheduler{
+		subscriptionUsecase: subscriptionUsecase,
+		transactionUsecase:  transactionUsecase,
+		paymentGateway:      paymentGateway,
+		emailPublisher:      emailPublisher,
+		config:              cfg,
+	}
+}
+
+// Start runs a scheduler pass every Interval until ctx is cancelled.
+func (s *SubscriptionScheduler) Start(ctx context.Context) {
+	ticker := time.NewTicker(s.config.SubscriptionScheduler.Interval)
+	defer ticker.Stop()
+
+	log.Printf("Starting subscription scheduler every %s, reminding %s before each charge", s.config.SubscriptionScheduler.Interval, s.config.SubscriptionScheduler.RemindBefore)
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Printf("Stopping subscription scheduler")
+			return
+		case <-ticker.C:
+			if err := s.ScheduleOnce(ctx); err != nil {
+				log.Printf("Subscription scheduler pass failed: %v", err)
+			}
+		}
+	}
+}
+
+// ScheduleOnce sends one batch of reminders and charges one batch of due
+// subscriptions. A subscription that fails is logged, and its claimed cycle is
+// retried by a pass after model.ChargeRetryDelay.
+func (s *SubscriptionScheduler) ScheduleOnce(ctx context.Context) error {
+	if err := s.remind(ctx); err != nil {
+		return err
+	}
+
+	subscriptions, err := s.subscriptionUsecase.GetDueSubscriptions(ctx, s.config.SubscriptionScheduler.BatchSize)
+	if err != nil {
+		return fmt.Errorf("failed to get due subscriptions: %v", err)
+	}
+
+	for i := range subscriptions {
+		if err := s.charge(ctx, &subscriptions[i]); err != nil {
+			log.Printf("Failed to charge subscription %s: %v", subscriptions[i].SubscriptionID.Hex(), err)
+		}
+	}
+
+	return nil
+}
+
+func (s *SubscriptionScheduler) remind(ctx context.Context) error {
+	subscriptions, err := s.subscriptionUsecase.GetSubscriptionsToRemind(ctx, s.config.SubscriptionScheduler.RemindBefore, s.config.SubscriptionScheduler.BatchSize)
+	if err != nil {
+		return fmt.Errorf("failed to get subscriptions to remind: %v", err)
+	}
+
+	for i := range subscriptions {
+		subscription := &subscriptions[i]
+		if err := s.emailPublisher.PublishSubscriptionReminder(subscription); err != nil {
+			log.Printf("Failed to send reminder for subscription %s: %v", subscription.SubscriptionID.Hex(), err)
+			continue
+		}
+		if err := s.subscriptionUsecase.MarkReminderSent(ctx, subscription); err != nil {
+			log.Printf("Failed to mark reminder sent for subscription %s: %v", subscription.SubscriptionID.Hex(), err)
+		}
+	}
+
+	return nil
+}
+
+func (s *SubscriptionScheduler) charge(ctx context.Context, subscription *model.Subscription) error {
+	claimed, err := s.subscriptionUsecase.AdvanceSubscription(ctx, subscription)
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		// Another scheduler, or the donor pausing it, got there first.
+		return nil
+	}
+
+	// Until CompleteCharge, the cycle stays claimed and a later pass retries
+	// it, so a failure here does not skip the donor's month.
+
+	post, err := s.subscriptionUsecase.ResolvePost(ctx, subscription)
+	if errors.Is(err, usecase.ErrPostEnded) || errors.Is(err, usecase.ErrPostNotPublished) || errors.Is(err, usecase.ErrPostNotFound) {
+		log.Printf("Ending subscription %s: %v", subscription.SubscriptionID.Hex(), err)
+		return s.subscriptionUsecase.EndSubscription(ctx, subscription)
+	}
+	if errors.Is(err, usecase.ErrNoOpenPost) {
+		log.Printf("Skipping this cycle of subscription %s: %v", subscription.SubscriptionID.Hex(), err)
+		return s.subscriptionUsecase.CompleteCharge(ctx, subscription, nil)
+	}
+	if err != nil {
+		return err
+	}
+
+	quote, err := s.transactionUsecase.QuoteDonation(ctx, post, subscription.Amount)
+	if err != nil {
+		return fmt.Errorf("failed to quote donation to post %s: %v", post.PostID, err)
+	}
+
+	// The profile name was resolved when the subscription was created, and
+	// there is no user token here to look it up again.
+	visibility := subscription.DonorNameVisibility
+	if visibility == model.DonorNameProfile {
+		visibility = model.DonorNameDisplay
+	}
+
+	transaction, err := s.transactionUsecase.CreateTransaction(ctx, &model.Transaction{
+		TransactionID:       subscription.ChargeTransactionID,
+		UserID:              subscription.UserID,
+		PostID:              post.PostID.String(),
+		PostTitle:           post.Title,
+		InstitutionID:       post.InstitutionID.String(),
+		UserEmail:           subscription.UserEmail,
+		PaymentID:           "pending",
+		Amount:              quote.Amount,
+		ConvertedAmount:     quote.ConvertedAmount,
+		FXRate:              quote.FXRate,
+		AccountNumber:       subscription.AccountNumber,
+		AccountName:         subscription.AccountName,
+		DonorNameVisibility: visibility,
+		DonorName:           subscription.DonorName,
+		SubscriptionID:      subscription.SubscriptionID.Hex(),
+	})
+	if errors.Is(err, usecase.ErrTransactionExists) {
+		// An earlier pass created it but failed to complete the charge.
+		log.Printf("Transaction %s of subscription %s already exists", subscription.ChargeTransactionID.Hex(), subscription.SubscriptionID.Hex())
+		return s.subscriptionUsecase.CompleteCharge(ctx, subscription, &model.Transaction{TransactionID: subscription.ChargeTransactionID})
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create transaction: %v", err)
+	}
+
+	transactionIDStr := transaction.TransactionID.Hex()
+
+	if err := s.subscriptionUsecase.CompleteCharge(ctx, subscription, transaction); err != nil {
+		log.Printf("Failed to record transaction %s on subscription %s: %v", transactionIDStr, subscription.SubscriptionID.Hex(), err)
+	}
+
+	invoice, err := s.paymentGateway.CreateInvoice(s.newInvoiceRequest(transaction, post))
+	if err != nil {
+		if _, transitionErr := s.transactionUsecase.TransitionTransaction(ctx, transaction, model.PaymentStatusFailed, model.TransitionSourceScheduler); transitionErr != nil {
+			log.Printf("Failed to mark transaction %s as failed: %v", transactionIDStr, transitionErr)
+		}
+		return fmt.Errorf("failed to create payment invoice: %v", err)
+	}
+
+	transaction.PaymentID = invoice.ID
+	transaction.PaymentURL = invoice.InvoiceURL
+	transaction.ExpiresAt = invoice.ExpiryDate
+
+	if _, err := s.transactionUsecase.TransitionTransaction(ctx, transaction, model.PaymentStatusPending, model.TransitionSourceScheduler); err != nil {
+		return fmt.Errorf("failed to update transaction %s with payment details: %v", transactionIDStr, err)
+	}
+
+	if err := s.emailPublisher.PublishSubscriptionInvoice(subscription, transaction); err != nil {
+		log.Printf("Failed to send invoice for subscription %s: %v", subscription.SubscriptionID.Hex(), err)
+	}
+
+	log.Printf("Charged subscription %s with transaction %s", subscription.SubscriptionID.Hex(), transactionIDStr)
+	return nil
+}
+
+func (s *SubscriptionScheduler) newInvoiceRequest(transaction *model.Transaction, post *model.Post) client.CreateInvoiceRequest {
+	transactionIDStr := transaction.TransactionID.Hex()
+
+	return client.CreateInvoiceRequest{
+		ExternalID:         transactionIDStr,
+		Amount:             transaction.Amount.Major(),
+		Currency:           transaction.Amount.Currency,
+		PayerEmail:         transaction.UserEmail,
+		Description:        fmt.Sprintf("Monthly donation for %s", post.Title),
+		CustomerName:       transaction.PublicDonorName(),
+		InvoiceDuration:    int(s.config.InvoiceDuration.Seconds()),
+		SuccessRedirectURL: s.config.SuccessRedirectURL(transactionIDStr),
+		FailureRedirectURL: s.config.FailureRedirectURL(transactionIDStr),
+		CallbackURL:        s.config.CallbackURL(),
+	}
+}
@@ -0,0 +1,104 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"transaction-service/config"
+	"transaction-service/model"
+	"transaction-service/queue"
+	"transaction-service/usecase"
+)
+
+// TaxStatementSender emails every donor the statement of the previous year
+// during January, once per donor and year.
+type TaxStatementSender struct {
+	taxStatementUsecase usecase.ITaxStatementUsecase
+	emailPublisher      queue.IEmailPublisher
+	config              config.WorkerConfig
+}
+
+func NewTaxStatementSender(
+	taxStatementUsecase usecase.ITaxStatementUsecase,
+	emailPublisher queue.IEmailPublisher,
+	cfg config.WorkerConfig,
+) *TaxStatementSender {
+	return &TaxStatementSender{
+		taxStatementUsecase: taxStatementUsecase,
+		emailPublisher:      emailPublisher,
+		config:              cfg,
+	}
+}
+
+// Start sends pending statements every Interval while it is January, until
+// ctx is cancelled.
+func (s *TaxStatementSender) Start(ctx context.Context) {
+	ticker := time.NewTicker(s.config.Interval)
+	defer ticker.Stop()
+
+	log.Printf("Starting tax statement sender every %s", s.config.Interval)
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Printf("Stopping tax statement sender")
+			return
+		case <-ticker.C:
+			now := time.Now().In(model.TaxYearLocation)
+			if now.Month() != time.January {
+				continue
+			}
+			if _, err := s.SendOnce(ctx, now.Year()-1); err != nil {
+				log.Printf("Tax statement sender pass failed: %v", err)
+			}
+		}
+	}
+}
+
+// SendOnce sends one batch of the statements of year and returns how many
+// were sent. A statement that fails is retried with a growing delay, up to
+// MaxDeliveryAttempts times.
+func (s *TaxStatementSender) SendOnce(ctx context.Context, year int) (int, error) {
+	recipients, err := s.taxStatementUsecase.GetTaxStatementRecipients(ctx, year, s.config.BatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get tax statement recipients: %v", err)
+	}
+
+	sent := 0
+	for _, recipient := range recipients {
+		if err := s.send(ctx, recipient, year); err != nil {
+			log.Printf("Failed to send %d tax statement of user %s: %v", year, recipient.UserID, err)
+			if err := s.taxStatementUsecase.RecordTaxStatementFailure(ctx, recipient, year); err != nil {
+				log.Printf("Failed to record %d tax statement failure of user %s: %v", year, recipient.UserID, err)
+			}
+			continue
+		}
+		sent++
+	}
+
+	return sent, nil
+}
+
+func (s *TaxStatementSender) send(ctx context.Context, recipient model.TaxStatementRecipient, year int) error {
+	statement, err := s.taxStatementUsecase.BuildTaxStatement(ctx, recipient.UserID, year)
+	if err != nil {
+		return err
+	}
+
+	files := make([]model.TaxStatementFile, 0, 2)
+	for _, format := range []model.TaxStatementFormat{model.TaxStatementFormatPDF, model.TaxStatementFormatCSV} {
+		file, err := s.taxStatementUsecase.RenderTaxStatement(statement, format)
+		if err != nil {
+			return err
+		}
+		files = append(files, *file)
+	}
+
+	if err := s.emailPublisher.PublishTaxStatement(statement, files...); err != nil {
+		return err
+	}
+
+	return s.taxStatementUsecase.MarkTaxStatementSent(ctx, recipient.UserID, year)
+}
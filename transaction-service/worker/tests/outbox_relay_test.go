@@ -0,0 +1,124 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"transaction-service/config"
+	"transaction-service/mocks"
+	"transaction-service/model"
+	"transaction-service/worker"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func newPendingEvent() model.PendingOutboxEvent {
+	return model.PendingOutboxEvent{
+		TransactionID: primitive.NewObjectID(),
+		Event: model.OutboxEvent{
+			EventID:   uuid.New().String(),
+			Type:      model.EventTypeDonationSettled,
+			Payload:   []byte(`{}`),
+			CreatedAt: time.Now(),
+		},
+	}
+}
+
+func TestOutboxRelayOnce(t *testing.T) {
+	relayConfig := config.WorkerConfig{Interval: time.Second, BatchSize: 10}
+
+	t.Run("success - publishes and marks each event", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransactionUsecase := mocks.NewMockITransactionUsecase(ctrl)
+		mockEventPublisher := mocks.NewMockIEventPublisher(ctrl)
+		relay := worker.NewOutboxRelay(mockTransactionUsecase, mockEventPublisher, relayConfig)
+
+		first, second := newPendingEvent(), newPendingEvent()
+
+		mockTransactionUsecase.EXPECT().
+			GetUnpublishedEvents(gomock.Any(), 10).
+			Return([]model.PendingOutboxEvent{first, second}, nil)
+		gomock.InOrder(
+			mockEventPublisher.EXPECT().PublishEvent(gomock.Any(), first.Event).Return(nil),
+			mockTransactionUsecase.EXPECT().MarkEventPublished(gomock.Any(), first).Return(nil),
+			mockEventPublisher.EXPECT().PublishEvent(gomock.Any(), second.Event).Return(nil),
+			mockTransactionUsecase.EXPECT().MarkEventPublished(gomock.Any(), second).Return(nil),
+		)
+
+		relayed, err := relay.RelayOnce(context.Background())
+
+		assert.NoError(t, err)
+		assert.Equal(t, 2, relayed)
+	})
+
+	t.Run("success - unmarked event is left for the next pass", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransactionUsecase := mocks.NewMockITransactionUsecase(ctrl)
+		mockEventPublisher := mocks.NewMockIEventPublisher(ctrl)
+		relay := worker.NewOutboxRelay(mockTransactionUsecase, mockEventPublisher, relayConfig)
+
+		first, second := newPendingEvent(), newPendingEvent()
+
+		mockTransactionUsecase.EXPECT().
+			GetUnpublishedEvents(gomock.Any(), 10).
+			Return([]model.PendingOutboxEvent{first, second}, nil)
+		mockEventPublisher.EXPECT().PublishEvent(gomock.Any(), gomock.Any()).Return(nil).Times(2)
+		mockTransactionUsecase.EXPECT().MarkEventPublished(gomock.Any(), first).Return(errors.New("database error"))
+		mockTransactionUsecase.EXPECT().MarkEventPublished(gomock.Any(), second).Return(nil)
+
+		relayed, err := relay.RelayOnce(context.Background())
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, relayed)
+	})
+
+	t.Run("failed - publish error stops the pass", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransactionUsecase := mocks.NewMockITransactionUsecase(ctrl)
+		mockEventPublisher := mocks.NewMockIEventPublisher(ctrl)
+		relay := worker.NewOutboxRelay(mockTransactionUsecase, mockEventPublisher, relayConfig)
+
+		first, second := newPendingEvent(), newPendingEvent()
+
+		mockTransactionUsecase.EXPECT().
+			GetUnpublishedEvents(gomock.Any(), 10).
+			Return([]model.PendingOutboxEvent{first, second}, nil)
+		mockEventPublisher.EXPECT().
+			PublishEvent(gomock.Any(), first.Event).
+			Return(errors.New("broker unavailable"))
+
+		relayed, err := relay.RelayOnce(context.Background())
+
+		assert.Error(t, err)
+		assert.Equal(t, 0, relayed)
+	})
+
+	t.Run("failed - outbox lookup error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransactionUsecase := mocks.NewMockITransactionUsecase(ctrl)
+		mockEventPublisher := mocks.NewMockIEventPublisher(ctrl)
+		relay := worker.NewOutboxRelay(mockTransactionUsecase, mockEventPublisher, relayConfig)
+
+		mockTransactionUsecase.EXPECT().
+			GetUnpublishedEvents(gomock.Any(), 10).
+			Return(nil, errors.New("database error"))
+
+		relayed, err := relay.RelayOnce(context.Background())
+
+		assert.Error(t, err)
+		assert.Equal(t, 0, relayed)
+	})
+}
@@ -0,0 +1,182 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"transaction-service/config"
+	"transaction-service/mocks"
+	"transaction-service/model"
+	"transaction-service/usecase"
+	"transaction-service/worker"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestReceiptSenderOnce(t *testing.T) {
+	senderConfig := config.WorkerConfig{Interval: time.Minute, BatchSize: 10}
+
+	newPaid := func() model.Transaction {
+		transaction := newStaleTransaction("invoice-id")
+		transaction.PaymentStatus = model.PaymentStatusPaid
+		transaction.ReceiptStatus = model.ReceiptStatusPending
+		return transaction
+	}
+
+	t.Run("success - issues, emails and marks each receipt", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockReceiptUsecase := mocks.NewMockIReceiptUsecase(ctrl)
+		mockEmailPublisher := mocks.NewMockIEmailPublisher(ctrl)
+		sender := worker.NewReceiptSender(mockReceiptUsecase, mockEmailPublisher, senderConfig)
+
+		transaction := newPaid()
+		receipt := &model.Receipt{ReceiptID: primitive.NewObjectID(), TransactionID: transaction.TransactionID, PDF: []byte("%PDF-")}
+
+		mockReceiptUsecase.EXPECT().GetTransactionsAwaitingReceipt(gomock.Any(), 10).Return([]model.Transaction{transaction}, nil)
+		gomock.InOrder(
+			mockReceiptUsecase.EXPECT().IssueReceipt(gomock.Any(), gomock.Any()).Return(receipt, nil),
+			mockEmailPublisher.EXPECT().PublishReceipt(gomock.Any(), receipt).Return(nil),
+			mockReceiptUsecase.EXPECT().MarkReceiptSent(gomock.Any(), gomock.Any()).Return(nil),
+		)
+
+		sent, err := sender.SendOnce(context.Background())
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, sent)
+	})
+
+	t.Run("success - unsent receipt is retried later", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockReceiptUsecase := mocks.NewMockIReceiptUsecase(ctrl)
+		mockEmailPublisher := mocks.NewMockIEmailPublisher(ctrl)
+		sender := worker.NewReceiptSender(mockReceiptUsecase, mockEmailPublisher, senderConfig)
+
+		mockReceiptUsecase.EXPECT().GetTransactionsAwaitingReceipt(gomock.Any(), 10).Return([]model.Transaction{newPaid()}, nil)
+		mockReceiptUsecase.EXPECT().IssueReceipt(gomock.Any(), gomock.Any()).Return(&model.Receipt{}, nil)
+		mockEmailPublisher.EXPECT().PublishReceipt(gomock.Any(), gomock.Any()).Return(errors.New("channel closed"))
+		mockReceiptUsecase.EXPECT().RecordReceiptFailure(gomock.Any(), gomock.Any()).Return(nil)
+
+		sent, err := sender.SendOnce(context.Background())
+
+		assert.NoError(t, err)
+		assert.Equal(t, 0, sent)
+	})
+
+	t.Run("failed - pending receipts unavailable", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockReceiptUsecase := mocks.NewMockIReceiptUsecase(ctrl)
+		sender := worker.NewReceiptSender(mockReceiptUsecase, mocks.NewMockIEmailPublisher(ctrl), senderConfig)
+
+		mockReceiptUsecase.EXPECT().GetTransactionsAwaitingReceipt(gomock.Any(), 10).Return(nil, errors.New("database error"))
+
+		sent, err := sender.SendOnce(context.Background())
+
+		assert.EqualError(t, err, "failed to get transactions awaiting receipt: database error")
+		assert.Equal(t, 0, sent)
+	})
+}
+
+// TestReceiptSenderFailingBatch fills the first batch with receipts that can
+// never be issued and checks that they neither block the receipts paid after
+// them nor get retried forever.
+func TestReceiptSenderFailingBatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockReceiptRepo := mocks.NewMockIReceiptRepository(ctrl)
+	mockTransactionRepo := mocks.NewMockITransactionRepository(ctrl)
+	mockEmailPublisher := mocks.NewMockIEmailPublisher(ctrl)
+	receiptUsecase := usecase.NewReceiptUsecase(mockReceiptRepo, mockTransactionRepo)
+	sender := worker.NewReceiptSender(receiptUsecase, mockEmailPublisher, config.WorkerConfig{Interval: time.Minute, BatchSize: 2})
+
+	deletedPostID, postID := uuid.New(), uuid.New()
+	var transactions []*model.Transaction
+	for i, post := range []uuid.UUID{deletedPostID, deletedPostID, postID} {
+		transaction := newStaleTransaction("invoice-id")
+		transaction.PostID = post.String()
+		transaction.PaymentStatus = model.PaymentStatusPaid
+		transaction.PaidAt = time.Now().Add(time.Duration(i-3) * time.Hour)
+		transaction.ReceiptStatus = model.ReceiptStatusPending
+		transactions = append(transactions, &transaction)
+	}
+	find := func(transactionID primitive.ObjectID) *model.Transaction {
+		for _, transaction := range transactions {
+			if transaction.TransactionID == transactionID {
+				return transaction
+			}
+		}
+		return nil
+	}
+
+	mockReceiptRepo.EXPECT().
+		GetTransactionsAwaitingReceipt(gomock.Any(), int64(2)).
+		DoAndReturn(func(ctx context.Context, limit int64) ([]model.Transaction, error) {
+			var pending []model.Transaction
+			for _, transaction := range transactions {
+				if transaction.ReceiptStatus == model.ReceiptStatusPending && !transaction.ReceiptRetryAt.After(time.Now()) && int64(len(pending)) < limit {
+					pending = append(pending, *transaction)
+				}
+			}
+			return pending, nil
+		}).
+		AnyTimes()
+	mockReceiptRepo.EXPECT().
+		RecordReceiptFailure(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, transactionID primitive.ObjectID, status model.ReceiptStatus, attempts int, retryAt time.Time) error {
+			transaction := find(transactionID)
+			transaction.ReceiptStatus = status
+			transaction.ReceiptAttempts = attempts
+			transaction.ReceiptRetryAt = retryAt
+			return nil
+		}).
+		AnyTimes()
+	mockReceiptRepo.EXPECT().
+		MarkReceiptSent(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, transactionID primitive.ObjectID) error {
+			find(transactionID).ReceiptStatus = model.ReceiptStatusSent
+			return nil
+		}).
+		AnyTimes()
+	mockReceiptRepo.EXPECT().SaveReceipt(gomock.Any(), gomock.Any()).Return(&model.Receipt{}, nil).AnyTimes()
+	mockReceiptRepo.EXPECT().GetInstitutionByID(gomock.Any(), gomock.Any()).Return(&model.Institution{Name: "Yayasan"}, nil).AnyTimes()
+	mockTransactionRepo.EXPECT().GetPostByID(gomock.Any(), deletedPostID).Return(nil, errors.New("post not found")).AnyTimes()
+	mockTransactionRepo.EXPECT().GetPostByID(gomock.Any(), postID).Return(&model.Post{PostID: postID, Title: "School library"}, nil).AnyTimes()
+	mockEmailPublisher.EXPECT().PublishReceipt(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+
+	sent, err := sender.SendOnce(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 0, sent)
+
+	sent, err = sender.SendOnce(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, sent)
+	assert.Equal(t, model.ReceiptStatusSent, transactions[2].ReceiptStatus)
+
+	// Each time the backoff elapses the failing receipts are tried again,
+	// until they are given up on.
+	for attempt := 1; attempt < model.MaxDeliveryAttempts; attempt++ {
+		for _, transaction := range transactions[:2] {
+			assert.Equal(t, model.ReceiptStatusPending, transaction.ReceiptStatus)
+			transaction.ReceiptRetryAt = time.Now().Add(-time.Second)
+		}
+		sent, err = sender.SendOnce(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, 0, sent)
+	}
+
+	for _, transaction := range transactions[:2] {
+		assert.Equal(t, model.ReceiptStatusFailed, transaction.ReceiptStatus)
+		assert.Equal(t, model.MaxDeliveryAttempts, transaction.ReceiptAttempts)
+	}
+}
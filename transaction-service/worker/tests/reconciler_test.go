@@ -0,0 +1,318 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"transaction-service/client"
+	"transaction-service/config"
+	"transaction-service/mocks"
+	"transaction-service/model"
+	"transaction-service/usecase"
+	"transaction-service/worker"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// fakeXendit serves GET /invoices/{id} from an in-memory set of invoices.
+type fakeXendit struct {
+	mu       sync.Mutex
+	invoices map[string]client.InvoiceResponse
+	requests []string
+}
+
+func newFakeXendit(t *testing.T, invoices ...client.InvoiceResponse) (*fakeXendit, *client.XenditClient) {
+	fake := &fakeXendit{invoices: map[string]client.InvoiceResponse{}}
+	for _, invoice := range invoices {
+		fake.invoices[invoice.ID] = invoice
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if username, _, ok := r.BasicAuth(); !ok || username != "xnd_test" {
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(map[string]string{"error_code": "INVALID_API_KEY"})
+			return
+		}
+
+		id := strings.TrimPrefix(r.URL.Path, "/invoices/")
+
+		fake.mu.Lock()
+		fake.requests = append(fake.requests, id)
+		invoice, ok := fake.invoices[id]
+		fake.mu.Unlock()
+
+		if r.Method != http.MethodGet || !ok {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(map[string]string{"error_code": "INVOICE_NOT_FOUND_ERROR"})
+			return
+		}
+
+		json.NewEncoder(w).Encode(invoice)
+	}))
+	t.Cleanup(server.Close)
+
+	return fake, &client.XenditClient{
+		APIKey:     "xnd_test",
+		BaseURL:    server.URL,
+		HTTPClient: server.Client(),
+	}
+}
+
+func newStaleTransaction(paymentID string) model.Transaction {
+	return model.Transaction{
+		TransactionID: primitive.NewObjectID(),
+		UserID:        uuid.New().String(),
+		PostID:        uuid.New().String(),
+		UserEmail:     "donor@email.com",
+		PaymentID:     paymentID,
+		PaymentStatus: model.PaymentStatusPending,
+		Amount:        model.IDR(50000),
+		AccountNumber: "1234567890",
+		AccountName:   "Donor",
+		CreatedAt:     time.Now().Add(-time.Hour),
+	}
+}
+
+func invoiceFor(transaction model.Transaction, status string) client.InvoiceResponse {
+	return client.InvoiceResponse{
+		ID:         transaction.PaymentID,
+		ExternalID: transaction.TransactionID.Hex(),
+		Status:     status,
+		Amount:     transaction.Amount.Major(),
+	}
+}
+
+var testConfig = config.ReconcilerConfig{
+	Interval:   time.Minute,
+	BatchSize:  10,
+	StaleAfter: 15 * time.Minute,
+}
+
+func TestReconcileOnce(t *testing.T) {
+	t.Run("success - paid invoice is settled", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransactionUsecase := mocks.NewMockITransactionUsecase(ctrl)
+
+		transaction := newStaleTransaction("inv-paid")
+		invoice := invoiceFor(transaction, "PAID")
+		invoice.PaymentMethod = "BANK_TRANSFER"
+		invoice.PaidAt = time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+		_, xenditClient := newFakeXendit(t, invoice)
+
+		mockTransactionUsecase.EXPECT().
+			GetStalePendingTransactions(gomock.Any(), testConfig.StaleAfter, testConfig.BatchSize).
+			Return([]model.Transaction{transaction}, nil)
+
+		mockTransactionUsecase.EXPECT().
+			SettleTransaction(gomock.Any(), gomock.Any(), model.TransitionSourceReconciler).
+			DoAndReturn(func(ctx context.Context, settled *model.Transaction, source model.TransitionSource) (*model.Transaction, error) {
+				assert.Equal(t, transaction.TransactionID, settled.TransactionID)
+				assert.Equal(t, "BANK_TRANSFER", settled.PaymentMethod)
+				assert.True(t, invoice.PaidAt.Equal(settled.PaidAt))
+				settled.PaymentStatus = model.PaymentStatusPaid
+				return settled, nil
+			})
+
+		reconciler := worker.NewReconciler(mockTransactionUsecase, xenditClient, testConfig)
+		err := reconciler.ReconcileOnce(context.Background())
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("success - settled invoice is settled", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransactionUsecase := mocks.NewMockITransactionUsecase(ctrl)
+
+		transaction := newStaleTransaction("inv-settled")
+		_, xenditClient := newFakeXendit(t, invoiceFor(transaction, "SETTLED"))
+
+		mockTransactionUsecase.EXPECT().
+			GetStalePendingTransactions(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return([]model.Transaction{transaction}, nil)
+
+		mockTransactionUsecase.EXPECT().
+			SettleTransaction(gomock.Any(), gomock.Any(), model.TransitionSourceReconciler).
+			Return(&transaction, nil)
+
+		reconciler := worker.NewReconciler(mockTransactionUsecase, xenditClient, testConfig)
+		err := reconciler.ReconcileOnce(context.Background())
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("success - expired invoice is marked expired", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransactionUsecase := mocks.NewMockITransactionUsecase(ctrl)
+
+		transaction := newStaleTransaction("inv-expired")
+		_, xenditClient := newFakeXendit(t, invoiceFor(transaction, "EXPIRED"))
+
+		mockTransactionUsecase.EXPECT().
+			GetStalePendingTransactions(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return([]model.Transaction{transaction}, nil)
+
+		mockTransactionUsecase.EXPECT().
+			TransitionTransaction(gomock.Any(), gomock.Any(), model.PaymentStatusExpired, model.TransitionSourceReconciler).
+			Return(&transaction, nil)
+
+		reconciler := worker.NewReconciler(mockTransactionUsecase, xenditClient, testConfig)
+		err := reconciler.ReconcileOnce(context.Background())
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("success - pending invoice is left alone", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransactionUsecase := mocks.NewMockITransactionUsecase(ctrl)
+
+		transaction := newStaleTransaction("inv-pending")
+		fake, xenditClient := newFakeXendit(t, invoiceFor(transaction, "PENDING"))
+
+		mockTransactionUsecase.EXPECT().
+			GetStalePendingTransactions(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return([]model.Transaction{transaction}, nil)
+
+		reconciler := worker.NewReconciler(mockTransactionUsecase, xenditClient, testConfig)
+		err := reconciler.ReconcileOnce(context.Background())
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"inv-pending"}, fake.requests)
+	})
+
+	t.Run("success - transaction settled by callback in the meantime", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransactionUsecase := mocks.NewMockITransactionUsecase(ctrl)
+
+		transaction := newStaleTransaction("inv-raced")
+		_, xenditClient := newFakeXendit(t, invoiceFor(transaction, "EXPIRED"))
+
+		mockTransactionUsecase.EXPECT().
+			GetStalePendingTransactions(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return([]model.Transaction{transaction}, nil)
+
+		mockTransactionUsecase.EXPECT().
+			TransitionTransaction(gomock.Any(), gomock.Any(), model.PaymentStatusExpired, model.TransitionSourceReconciler).
+			Return(nil, usecase.ErrInvalidStatusTransition)
+
+		reconciler := worker.NewReconciler(mockTransactionUsecase, xenditClient, testConfig)
+		err := reconciler.ReconcileOnce(context.Background())
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("success - one bad invoice does not stop the batch", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransactionUsecase := mocks.NewMockITransactionUsecase(ctrl)
+
+		missing := newStaleTransaction("inv-missing")
+		mismatched := newStaleTransaction("inv-mismatched")
+		paid := newStaleTransaction("inv-paid")
+
+		mismatchedInvoice := invoiceFor(mismatched, "PAID")
+		mismatchedInvoice.Amount = 1000
+
+		fake, xenditClient := newFakeXendit(t, mismatchedInvoice, invoiceFor(paid, "PAID"))
+
+		mockTransactionUsecase.EXPECT().
+			GetStalePendingTransactions(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return([]model.Transaction{missing, mismatched, paid}, nil)
+
+		mockTransactionUsecase.EXPECT().
+			SettleTransaction(gomock.Any(), gomock.Any(), model.TransitionSourceReconciler).
+			DoAndReturn(func(ctx context.Context, settled *model.Transaction, source model.TransitionSource) (*model.Transaction, error) {
+				assert.Equal(t, paid.TransactionID, settled.TransactionID)
+				return settled, nil
+			})
+
+		reconciler := worker.NewReconciler(mockTransactionUsecase, xenditClient, testConfig)
+		err := reconciler.ReconcileOnce(context.Background())
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"inv-missing", "inv-mismatched", "inv-paid"}, fake.requests)
+	})
+
+	t.Run("failed - get stale transactions error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransactionUsecase := mocks.NewMockITransactionUsecase(ctrl)
+		fake, xenditClient := newFakeXendit(t)
+
+		mockTransactionUsecase.EXPECT().
+			GetStalePendingTransactions(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(nil, errors.New("database error"))
+
+		reconciler := worker.NewReconciler(mockTransactionUsecase, xenditClient, testConfig)
+		err := reconciler.ReconcileOnce(context.Background())
+
+		assert.Error(t, err)
+		assert.Empty(t, fake.requests)
+	})
+}
+
+func TestReconcilerStart(t *testing.T) {
+	t.Run("success - polls every interval until cancelled", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransactionUsecase := mocks.NewMockITransactionUsecase(ctrl)
+		_, xenditClient := newFakeXendit(t)
+
+		passes := make(chan struct{}, 10)
+		mockTransactionUsecase.EXPECT().
+			GetStalePendingTransactions(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, olderThan time.Duration, limit int) ([]model.Transaction, error) {
+				passes <- struct{}{}
+				return nil, nil
+			}).
+			MinTimes(2)
+
+		reconcilerConfig := testConfig
+		reconcilerConfig.Interval = 10 * time.Millisecond
+		reconciler := worker.NewReconciler(mockTransactionUsecase, xenditClient, reconcilerConfig)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			reconciler.Start(ctx)
+			close(done)
+		}()
+
+		for i := 0; i < 2; i++ {
+			select {
+			case <-passes:
+			case <-time.After(time.Second):
+				t.Fatal("reconciler did not run")
+			}
+		}
+
+		cancel()
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("reconciler did not stop")
+		}
+	})
+}
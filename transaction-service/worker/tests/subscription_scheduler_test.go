@@ -0,0 +1,242 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"transaction-service/client"
+	"transaction-service/config"
+	"transaction-service/mocks"
+	"transaction-service/model"
+	"transaction-service/usecase"
+	"transaction-service/worker"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func newDueSubscription() model.Subscription {
+	return model.Subscription{
+		SubscriptionID:      primitive.NewObjectID(),
+		UserID:              uuid.New().String(),
+		UserEmail:           "donor@email.com",
+		PostID:              uuid.New().String(),
+		Amount:              model.IDR(50000),
+		Cadence:             model.SubscriptionCadenceMonthly,
+		Status:              model.SubscriptionStatusActive,
+		NextChargeAt:        time.Now().Add(-time.Minute),
+		AccountNumber:       "1234567890",
+		AccountName:         "Donor",
+		DonorNameVisibility: model.DonorNameProfile,
+		DonorName:           "Budi",
+	}
+}
+
+type schedulerMocks struct {
+	subscriptionUsecase *mocks.MockISubscriptionUsecase
+	transactionUsecase  *mocks.MockITransactionUsecase
+	emailPublisher      *mocks.MockIEmailPublisher
+	gateway             *client.FakeGateway
+}
+
+func newSubscriptionScheduler(ctrl *gomock.Controller) (*worker.SubscriptionScheduler, schedulerMocks) {
+	m := schedulerMocks{
+		subscriptionUsecase: mocks.NewMockISubscriptionUsecase(ctrl),
+		transactionUsecase:  mocks.NewMockITransactionUsecase(ctrl),
+		emailPublisher:      mocks.NewMockIEmailPublisher(ctrl),
+		gateway:             client.NewFakeGateway(),
+	}
+
+	cfg := config.Default()
+	cfg.PublicBaseURL = "http://localhost:8082"
+	cfg.FrontendSuccessURL = "http://localhost:3000/payment/success"
+	cfg.FrontendFailureURL = "http://localhost:3000/payment/failed"
+	cfg.SubscriptionScheduler = config.SubscriptionSchedulerConfig{Interval: time.Minute, BatchSize: 10, RemindBefore: 72 * time.Hour}
+
+	scheduler := worker.NewSubscriptionScheduler(
+		m.subscriptionUsecase,
+		m.transactionUsecase,
+		m.gateway,
+		m.emailPublisher,
+		&cfg,
+	)
+
+	return scheduler, m
+}
+
+func TestSubscriptionSchedulerOnce(t *testing.T) {
+	t.Run("success - reminds upcoming and charges due subscriptions", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		scheduler, m := newSubscriptionScheduler(ctrl)
+
+		upcoming := newDueSubscription()
+		upcoming.NextChargeAt = time.Now().Add(48 * time.Hour)
+		due := newDueSubscription()
+		post := &model.Post{PostID: uuid.MustParse(due.PostID), Title: "Beasiswa", FundTarget: model.IDR(1000000)}
+
+		m.subscriptionUsecase.EXPECT().GetSubscriptionsToRemind(gomock.Any(), 72*time.Hour, 10).Return([]model.Subscription{upcoming}, nil)
+		m.emailPublisher.EXPECT().PublishSubscriptionReminder(gomock.Any()).Return(nil)
+		m.subscriptionUsecase.EXPECT().MarkReminderSent(gomock.Any(), gomock.Any()).Return(nil)
+
+		m.subscriptionUsecase.EXPECT().GetDueSubscriptions(gomock.Any(), 10).Return([]model.Subscription{due}, nil)
+		m.subscriptionUsecase.EXPECT().AdvanceSubscription(gomock.Any(), gomock.Any()).Return(true, nil)
+		m.subscriptionUsecase.EXPECT().ResolvePost(gomock.Any(), gomock.Any()).Return(post, nil)
+		m.transactionUsecase.EXPECT().
+			QuoteDonation(gomock.Any(), post, due.Amount).
+			Return(&model.DonationQuote{Amount: due.Amount, ConvertedAmount: due.Amount}, nil)
+		m.transactionUsecase.EXPECT().
+			CreateTransaction(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, transaction *model.Transaction) (*model.Transaction, error) {
+				assert.Equal(t, due.SubscriptionID.Hex(), transaction.SubscriptionID)
+				assert.Equal(t, due.PostID, transaction.PostID)
+				assert.Equal(t, model.DonorNameDisplay, transaction.DonorNameVisibility)
+				assert.Equal(t, "Budi", transaction.DonorName)
+				transaction.TransactionID = primitive.NewObjectID()
+				transaction.PaymentStatus = model.PaymentStatusCreated
+				return transaction, nil
+			})
+		m.subscriptionUsecase.EXPECT().CompleteCharge(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+		m.transactionUsecase.EXPECT().
+			TransitionTransaction(gomock.Any(), gomock.Any(), model.PaymentStatusPending, model.TransitionSourceScheduler).
+			DoAndReturn(func(ctx context.Context, transaction *model.Transaction, to model.PaymentStatus, source model.TransitionSource) (*model.Transaction, error) {
+				assert.Contains(t, transaction.PaymentURL, m.gateway.BaseURL)
+				return transaction, nil
+			})
+		m.emailPublisher.EXPECT().PublishSubscriptionInvoice(gomock.Any(), gomock.Any()).Return(nil)
+
+		assert.NoError(t, scheduler.ScheduleOnce(context.Background()))
+	})
+
+	t.Run("success - cycle claimed elsewhere is not charged", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		scheduler, m := newSubscriptionScheduler(ctrl)
+
+		m.subscriptionUsecase.EXPECT().GetSubscriptionsToRemind(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil)
+		m.subscriptionUsecase.EXPECT().GetDueSubscriptions(gomock.Any(), 10).Return([]model.Subscription{newDueSubscription()}, nil)
+		m.subscriptionUsecase.EXPECT().AdvanceSubscription(gomock.Any(), gomock.Any()).Return(false, nil)
+
+		assert.NoError(t, scheduler.ScheduleOnce(context.Background()))
+	})
+
+	t.Run("success - ended post cancels the subscription", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		scheduler, m := newSubscriptionScheduler(ctrl)
+
+		m.subscriptionUsecase.EXPECT().GetSubscriptionsToRemind(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil)
+		m.subscriptionUsecase.EXPECT().GetDueSubscriptions(gomock.Any(), 10).Return([]model.Subscription{newDueSubscription()}, nil)
+		m.subscriptionUsecase.EXPECT().AdvanceSubscription(gomock.Any(), gomock.Any()).Return(true, nil)
+		m.subscriptionUsecase.EXPECT().ResolvePost(gomock.Any(), gomock.Any()).Return(nil, usecase.ErrPostEnded)
+		m.subscriptionUsecase.EXPECT().EndSubscription(gomock.Any(), gomock.Any()).Return(nil)
+
+		assert.NoError(t, scheduler.ScheduleOnce(context.Background()))
+	})
+
+	t.Run("success - failed invoice marks the transaction failed", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		scheduler, m := newSubscriptionScheduler(ctrl)
+		m.gateway.Script(client.FakeOutcomeError)
+
+		due := newDueSubscription()
+		post := &model.Post{PostID: uuid.MustParse(due.PostID), FundTarget: model.IDR(1000000)}
+
+		m.subscriptionUsecase.EXPECT().GetSubscriptionsToRemind(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil)
+		m.subscriptionUsecase.EXPECT().GetDueSubscriptions(gomock.Any(), 10).Return([]model.Subscription{due}, nil)
+		m.subscriptionUsecase.EXPECT().AdvanceSubscription(gomock.Any(), gomock.Any()).Return(true, nil)
+		m.subscriptionUsecase.EXPECT().ResolvePost(gomock.Any(), gomock.Any()).Return(post, nil)
+		m.transactionUsecase.EXPECT().
+			QuoteDonation(gomock.Any(), post, due.Amount).
+			Return(&model.DonationQuote{Amount: due.Amount, ConvertedAmount: due.Amount}, nil)
+		m.transactionUsecase.EXPECT().
+			CreateTransaction(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, transaction *model.Transaction) (*model.Transaction, error) {
+				transaction.TransactionID = primitive.NewObjectID()
+				return transaction, nil
+			})
+		m.subscriptionUsecase.EXPECT().CompleteCharge(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+		m.transactionUsecase.EXPECT().
+			TransitionTransaction(gomock.Any(), gomock.Any(), model.PaymentStatusFailed, model.TransitionSourceScheduler).
+			Return(nil, nil)
+
+		assert.NoError(t, scheduler.ScheduleOnce(context.Background()))
+	})
+
+	t.Run("success - failed quote leaves the cycle claimed for a retry", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		scheduler, m := newSubscriptionScheduler(ctrl)
+
+		due := newDueSubscription()
+		post := &model.Post{PostID: uuid.MustParse(due.PostID), FundTarget: model.IDR(1000000)}
+
+		m.subscriptionUsecase.EXPECT().GetSubscriptionsToRemind(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil)
+		m.subscriptionUsecase.EXPECT().GetDueSubscriptions(gomock.Any(), 10).Return([]model.Subscription{due}, nil)
+		m.subscriptionUsecase.EXPECT().AdvanceSubscription(gomock.Any(), gomock.Any()).Return(true, nil)
+		m.subscriptionUsecase.EXPECT().ResolvePost(gomock.Any(), gomock.Any()).Return(post, nil)
+		m.transactionUsecase.EXPECT().
+			QuoteDonation(gomock.Any(), post, due.Amount).
+			Return(nil, errors.New("no FX rate"))
+
+		// No CompleteCharge, so the claimed cycle is charged again later.
+		assert.NoError(t, scheduler.ScheduleOnce(context.Background()))
+	})
+
+	t.Run("success - retried cycle whose transaction exists is completed", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		scheduler, m := newSubscriptionScheduler(ctrl)
+
+		due := newDueSubscription()
+		due.ChargingCycle = due.NextChargeAt
+		due.ChargeRetryAt = time.Now().Add(-time.Minute)
+		due.ChargeTransactionID = primitive.NewObjectID()
+		post := &model.Post{PostID: uuid.MustParse(due.PostID), FundTarget: model.IDR(1000000)}
+
+		m.subscriptionUsecase.EXPECT().GetSubscriptionsToRemind(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil)
+		m.subscriptionUsecase.EXPECT().GetDueSubscriptions(gomock.Any(), 10).Return([]model.Subscription{due}, nil)
+		m.subscriptionUsecase.EXPECT().AdvanceSubscription(gomock.Any(), gomock.Any()).Return(true, nil)
+		m.subscriptionUsecase.EXPECT().ResolvePost(gomock.Any(), gomock.Any()).Return(post, nil)
+		m.transactionUsecase.EXPECT().
+			QuoteDonation(gomock.Any(), post, due.Amount).
+			Return(&model.DonationQuote{Amount: due.Amount, ConvertedAmount: due.Amount}, nil)
+		m.transactionUsecase.EXPECT().
+			CreateTransaction(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, transaction *model.Transaction) (*model.Transaction, error) {
+				assert.Equal(t, due.ChargeTransactionID, transaction.TransactionID)
+				return nil, usecase.ErrTransactionExists
+			})
+		m.subscriptionUsecase.EXPECT().
+			CompleteCharge(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, subscription *model.Subscription, transaction *model.Transaction) error {
+				assert.Equal(t, due.ChargeTransactionID, transaction.TransactionID)
+				return nil
+			})
+
+		assert.NoError(t, scheduler.ScheduleOnce(context.Background()))
+	})
+
+	t.Run("failed - due subscriptions unavailable", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		scheduler, m := newSubscriptionScheduler(ctrl)
+
+		m.subscriptionUsecase.EXPECT().GetSubscriptionsToRemind(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil)
+		m.subscriptionUsecase.EXPECT().GetDueSubscriptions(gomock.Any(), 10).Return(nil, errors.New("database error"))
+
+		assert.EqualError(t, scheduler.ScheduleOnce(context.Background()), "failed to get due subscriptions: database error")
+	})
+}
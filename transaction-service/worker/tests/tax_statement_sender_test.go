@@ -0,0 +1,186 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"transaction-service/config"
+	"transaction-service/mocks"
+	"transaction-service/model"
+	"transaction-service/usecase"
+	"transaction-service/worker"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTaxStatementSenderOnce(t *testing.T) {
+	senderConfig := config.WorkerConfig{Interval: time.Hour, BatchSize: 10}
+	recipient := model.TaxStatementRecipient{UserID: "user-id", UserEmail: "donor@email.com"}
+
+	t.Run("success - emails the statement as PDF and CSV", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTaxStatementUsecase := mocks.NewMockITaxStatementUsecase(ctrl)
+		mockEmailPublisher := mocks.NewMockIEmailPublisher(ctrl)
+		sender := worker.NewTaxStatementSender(mockTaxStatementUsecase, mockEmailPublisher, senderConfig)
+
+		statement := &model.TaxStatement{UserID: recipient.UserID, UserEmail: recipient.UserEmail, Year: 2024}
+		pdf := &model.TaxStatementFile{Filename: "tax-statement-2024.pdf"}
+		csv := &model.TaxStatementFile{Filename: "tax-statement-2024.csv"}
+
+		mockTaxStatementUsecase.EXPECT().GetTaxStatementRecipients(gomock.Any(), 2024, 10).Return([]model.TaxStatementRecipient{recipient}, nil)
+		gomock.InOrder(
+			mockTaxStatementUsecase.EXPECT().BuildTaxStatement(gomock.Any(), recipient.UserID, 2024).Return(statement, nil),
+			mockTaxStatementUsecase.EXPECT().RenderTaxStatement(statement, model.TaxStatementFormatPDF).Return(pdf, nil),
+			mockTaxStatementUsecase.EXPECT().RenderTaxStatement(statement, model.TaxStatementFormatCSV).Return(csv, nil),
+			mockEmailPublisher.EXPECT().PublishTaxStatement(statement, *pdf, *csv).Return(nil),
+			mockTaxStatementUsecase.EXPECT().MarkTaxStatementSent(gomock.Any(), recipient.UserID, 2024).Return(nil),
+		)
+
+		sent, err := sender.SendOnce(context.Background(), 2024)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, sent)
+	})
+
+	t.Run("success - failed statement is retried later", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTaxStatementUsecase := mocks.NewMockITaxStatementUsecase(ctrl)
+		sender := worker.NewTaxStatementSender(mockTaxStatementUsecase, mocks.NewMockIEmailPublisher(ctrl), senderConfig)
+
+		mockTaxStatementUsecase.EXPECT().GetTaxStatementRecipients(gomock.Any(), 2024, 10).Return([]model.TaxStatementRecipient{recipient}, nil)
+		mockTaxStatementUsecase.EXPECT().BuildTaxStatement(gomock.Any(), recipient.UserID, 2024).Return(nil, usecase.ErrNoDonationsInTaxYear)
+		mockTaxStatementUsecase.EXPECT().RecordTaxStatementFailure(gomock.Any(), recipient, 2024).Return(nil)
+
+		sent, err := sender.SendOnce(context.Background(), 2024)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 0, sent)
+	})
+
+	t.Run("failed - recipients unavailable", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTaxStatementUsecase := mocks.NewMockITaxStatementUsecase(ctrl)
+		sender := worker.NewTaxStatementSender(mockTaxStatementUsecase, mocks.NewMockIEmailPublisher(ctrl), senderConfig)
+
+		mockTaxStatementUsecase.EXPECT().GetTaxStatementRecipients(gomock.Any(), 2024, 10).Return(nil, errors.New("database error"))
+
+		sent, err := sender.SendOnce(context.Background(), 2024)
+
+		assert.EqualError(t, err, "failed to get tax statement recipients: database error")
+		assert.Equal(t, 0, sent)
+	})
+}
+
+// TestTaxStatementSenderFailingBatch fills the first batch with donors whose
+// statement can never be built and checks that they neither block the donors
+// after them nor get retried forever.
+func TestTaxStatementSenderFailingBatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockTaxStatementRepo := mocks.NewMockITaxStatementRepository(ctrl)
+	mockTransactionRepo := mocks.NewMockITransactionRepository(ctrl)
+	mockEmailPublisher := mocks.NewMockIEmailPublisher(ctrl)
+	taxStatementUsecase := usecase.NewTaxStatementUsecase(mockTaxStatementRepo, mockTransactionRepo)
+	sender := worker.NewTaxStatementSender(taxStatementUsecase, mockEmailPublisher, config.WorkerConfig{Interval: time.Hour, BatchSize: 2})
+
+	// deliveries stands in for the tax_statements collection, keyed by user.
+	type delivery struct {
+		sent     bool
+		attempts int
+		retryAt  time.Time
+	}
+	users := []string{"user-a", "user-b", "user-c"}
+	deliveries := map[string]*delivery{}
+	for _, userID := range users {
+		deliveries[userID] = &delivery{}
+	}
+
+	mockTaxStatementRepo.EXPECT().
+		GetTaxStatementRecipients(gomock.Any(), 2024, int64(2)).
+		DoAndReturn(func(ctx context.Context, year int, limit int64) ([]model.TaxStatementRecipient, error) {
+			var recipients []model.TaxStatementRecipient
+			for _, userID := range users {
+				d := deliveries[userID]
+				if !d.sent && d.attempts < model.MaxDeliveryAttempts && !d.retryAt.After(time.Now()) && int64(len(recipients)) < limit {
+					recipients = append(recipients, model.TaxStatementRecipient{UserID: userID, Attempts: d.attempts})
+				}
+			}
+			return recipients, nil
+		}).
+		AnyTimes()
+	mockTaxStatementRepo.EXPECT().
+		RecordTaxStatementFailure(gomock.Any(), gomock.Any(), 2024, gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, userID string, year int, attempts int, retryAt time.Time) error {
+			deliveries[userID].attempts = attempts
+			deliveries[userID].retryAt = retryAt
+			return nil
+		}).
+		AnyTimes()
+	mockTaxStatementRepo.EXPECT().
+		MarkTaxStatementSent(gomock.Any(), gomock.Any(), 2024).
+		DoAndReturn(func(ctx context.Context, userID string, year int) error {
+			deliveries[userID].sent = true
+			return nil
+		}).
+		AnyTimes()
+
+	// The first two donors' transactions cannot be read at all.
+	postID, institutionID := uuid.New(), uuid.New()
+	mockTaxStatementRepo.EXPECT().
+		GetPaidTransactionsByUserID(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, userID string, from, to time.Time) ([]model.Transaction, error) {
+			if userID != "user-c" {
+				return nil, errors.New("corrupt transaction")
+			}
+			transaction := newStaleTransaction("invoice-id")
+			transaction.UserID = userID
+			transaction.PostID = postID.String()
+			transaction.PaymentStatus = model.PaymentStatusPaid
+			transaction.PaidAt = time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+			return []model.Transaction{transaction}, nil
+		}).
+		AnyTimes()
+	mockTransactionRepo.EXPECT().GetPostByID(gomock.Any(), postID).Return(&model.Post{PostID: postID, InstitutionID: institutionID, Title: "Beasiswa"}, nil).AnyTimes()
+	mockTaxStatementRepo.EXPECT().GetInstitutionByID(gomock.Any(), institutionID).Return(&model.Institution{InstitutionID: institutionID, Name: "Yayasan"}, nil).AnyTimes()
+	mockTransactionRepo.EXPECT().GetUserByID(gomock.Any(), "user-c").Return(&model.User{UserID: "user-c", Name: "Budi Santoso"}, nil).AnyTimes()
+	mockEmailPublisher.EXPECT().PublishTaxStatement(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+
+	sent, err := sender.SendOnce(context.Background(), 2024)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, sent)
+
+	sent, err = sender.SendOnce(context.Background(), 2024)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, sent)
+	assert.True(t, deliveries["user-c"].sent)
+
+	// Each time the backoff elapses the failing statements are tried again,
+	// until they are given up on.
+	for attempt := 1; attempt < model.MaxDeliveryAttempts; attempt++ {
+		for _, userID := range users[:2] {
+			deliveries[userID].retryAt = time.Now().Add(-time.Second)
+		}
+		sent, err = sender.SendOnce(context.Background(), 2024)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, sent)
+	}
+
+	for _, userID := range users[:2] {
+		assert.Equal(t, model.MaxDeliveryAttempts, deliveries[userID].attempts)
+		assert.False(t, deliveries[userID].sent)
+	}
+	recipients, err := taxStatementUsecase.GetTaxStatementRecipients(context.Background(), 2024, 2)
+	assert.NoError(t, err)
+	assert.Empty(t, recipients)
+}